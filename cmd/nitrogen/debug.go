@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/nitrogen-lang/nitrogen/src/ast"
+	"github.com/nitrogen-lang/nitrogen/src/debug"
+	"github.com/nitrogen-lang/nitrogen/src/eval"
+	"github.com/nitrogen-lang/nitrogen/src/object"
+)
+
+// runDebugger runs program stopped at its first statement with commands read from standard input.
+func runDebugger(program *ast.Program, env *object.Environment) object.Object {
+	interpreter := eval.NewInterpreter()
+	d := debug.New(interpreter)
+	d.StopOnEntry = true
+	cli := debug.NewCLI(d, os.Stdin, os.Stderr)
+
+	result, err := d.Run(program, env, cli.Stopped)
+	if err == debug.ErrQuit {
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	return result
+}
+
+// dapCommand runs a Debug Adapter Protocol server on standard input and output.
+func dapCommand(args []string) int {
+	flags := flag.NewFlagSet("dap", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: nitrogen dap")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	server := debug.NewDAPServer(os.Stdin, os.Stdout)
+	server.NewEnv = func(program string, args []string) *object.Environment {
+		env := object.NewEnvironment()
+		env.CreateConst("_ENV", getEnvironment())
+		env.CreateConst("_ARGV", makeScriptArgs(program, args))
+		return env
+	}
+
+	if err := server.Run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/nitrogen-lang/nitrogen/src/format"
+)
+
+// fmtCommand formats Nitrogen source files. With no files it formats standard input.
+func fmtCommand(args []string) int {
+	flags := flag.NewFlagSet("fmt", flag.ExitOnError)
+	write := flags.Bool("w", false, "Write the result to the file instead of standard output")
+	diff := flags.Bool("d", false, "Print a diff of the changes instead of the formatted source")
+	check := flags.Bool("check", false, "List files that aren't formatted and exit with status 1 if there are any")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: nitrogen fmt [flags] [files or directories]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	f := &formatter{write: *write, diff: *diff, check: *check, out: os.Stdout}
+
+	if flags.NArg() == 0 {
+		if f.write {
+			fmt.Fprintln(os.Stderr, "Can't use -w with standard input")
+			return 2
+		}
+		src, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		f.format("<standard input>", src, 0)
+		return f.status
+	}
+
+	walkSources(flags.Args(), f.formatFile, f.error)
+	return f.status
+}
+
+type formatter struct {
+	write, diff, check bool
+	out                io.Writer
+	// status is 1 if check found unformatted files, 2 if there was an error
+	status int
+}
+
+func (f *formatter) error(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	f.status = 2
+}
+
+func (f *formatter) formatFile(path string, info os.FileInfo) {
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		f.error(err)
+		return
+	}
+	f.format(path, src, info.Mode().Perm())
+}
+
+func (f *formatter) format(name string, src []byte, perm os.FileMode) {
+	res, err := format.Source(src)
+	if err != nil {
+		f.error(fmt.Errorf("%s: %s", name, err))
+		return
+	}
+
+	changed := !bytes.Equal(src, res)
+	if f.check {
+		if changed {
+			fmt.Fprintln(f.out, name)
+			if f.status == 0 {
+				f.status = 1
+			}
+		}
+		return
+	}
+
+	if f.diff && changed {
+		io.WriteString(f.out, unifiedDiff(name, src, res))
+	}
+	if f.write {
+		if changed {
+			if err := ioutil.WriteFile(name, res, perm); err != nil {
+				f.error(err)
+			}
+		}
+		return
+	}
+	if !f.diff {
+		f.out.Write(res)
+	}
+}
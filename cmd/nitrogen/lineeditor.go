@@ -0,0 +1,262 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// errInterrupted is returned by readLine when Ctrl-C is pressed.
+var errInterrupted = errors.New("interrupted")
+
+// lineReader reads the lines typed at the REPL.
+type lineReader interface {
+	readLine(prompt string) (string, error)
+	addHistory(line string)
+}
+
+// plainReader reads lines without editing, it's used when input isn't a terminal.
+type plainReader struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func (r *plainReader) readLine(prompt string) (string, error) {
+	fmt.Fprint(r.out, prompt)
+	line, err := r.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (r *plainReader) addHistory(line string) {}
+
+// completer returns the candidates to complete the text before the cursor and
+// where the completed word starts.
+type completer func(line string) (start int, candidates []string)
+
+// lineEditor reads lines from a terminal with cursor movement, history, and tab completion.
+type lineEditor struct {
+	fd       int
+	in       *bufio.Reader
+	out      io.Writer
+	history  []string
+	complete completer
+
+	// State of the line being edited
+	prompt string
+	buf    []rune
+	pos    int
+}
+
+func newLineEditor(in *os.File, out io.Writer, complete completer) *lineEditor {
+	return &lineEditor{
+		fd:       int(in.Fd()),
+		in:       bufio.NewReader(in),
+		out:      out,
+		complete: complete,
+	}
+}
+
+func (e *lineEditor) addHistory(line string) {
+	if line == "" || (len(e.history) > 0 && e.history[len(e.history)-1] == line) {
+		return
+	}
+	e.history = append(e.history, line)
+}
+
+// readLine puts the terminal in raw mode only while reading so scripts run with
+// the terminal's normal settings.
+func (e *lineEditor) readLine(prompt string) (string, error) {
+	restore, err := makeRaw(e.fd)
+	if err != nil {
+		return "", err
+	}
+	defer restore()
+
+	e.prompt, e.buf, e.pos = prompt, nil, 0
+	historyIndex := len(e.history)
+	edited := "" // The new line while browsing history
+	e.refresh()
+
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+
+		switch r {
+		case '\r', '\n':
+			fmt.Fprint(e.out, "\r\n")
+			return string(e.buf), nil
+		case 3: // Ctrl-C
+			fmt.Fprint(e.out, "^C\r\n")
+			return "", errInterrupted
+		case 4: // Ctrl-D
+			if len(e.buf) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+			e.deleteAt(e.pos)
+		case 127, 8: // Backspace
+			if e.pos > 0 {
+				e.pos--
+				e.deleteAt(e.pos)
+			}
+		case 1: // Ctrl-A
+			e.pos = 0
+		case 5: // Ctrl-E
+			e.pos = len(e.buf)
+		case 2: // Ctrl-B
+			e.move(-1)
+		case 6: // Ctrl-F
+			e.move(1)
+		case 11: // Ctrl-K
+			e.buf = e.buf[:e.pos]
+		case 21: // Ctrl-U
+			e.buf = e.buf[e.pos:]
+			e.pos = 0
+		case 23: // Ctrl-W
+			e.deleteWord()
+		case 12: // Ctrl-L
+			fmt.Fprint(e.out, "\x1b[H\x1b[2J")
+		case '\t':
+			e.completeWord()
+		case 27: // Escape sequence
+			switch e.readEscape() {
+			case "[A", "OA": // Up
+				if historyIndex > 0 {
+					if historyIndex == len(e.history) {
+						edited = string(e.buf)
+					}
+					historyIndex--
+					e.setLine(e.history[historyIndex])
+				}
+			case "[B", "OB": // Down
+				if historyIndex < len(e.history) {
+					historyIndex++
+					if historyIndex == len(e.history) {
+						e.setLine(edited)
+					} else {
+						e.setLine(e.history[historyIndex])
+					}
+				}
+			case "[C", "OC":
+				e.move(1)
+			case "[D", "OD":
+				e.move(-1)
+			case "[H", "OH", "[1~", "[7~":
+				e.pos = 0
+			case "[F", "OF", "[4~", "[8~":
+				e.pos = len(e.buf)
+			case "[3~": // Delete
+				e.deleteAt(e.pos)
+			}
+		default:
+			if r >= ' ' {
+				e.insert(r)
+			}
+		}
+		e.refresh()
+	}
+}
+
+// readEscape reads the rest of an escape sequence such as "[A" for the up arrow.
+func (e *lineEditor) readEscape() string {
+	var seq []rune
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return ""
+		}
+		seq = append(seq, r)
+		// Sequences end with a letter or ~, except for the [ or O that starts them
+		if len(seq) > 1 && (r == '~' || r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z') {
+			return string(seq)
+		}
+		if len(seq) == 1 && r != '[' && r != 'O' || len(seq) > 8 {
+			return string(seq)
+		}
+	}
+}
+
+// refresh redraws the line and puts the cursor in its place.
+func (e *lineEditor) refresh() {
+	fmt.Fprintf(e.out, "\r%s%s\x1b[K", e.prompt, string(e.buf))
+	if back := len(e.buf) - e.pos; back > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dD", back)
+	}
+}
+
+func (e *lineEditor) setLine(line string) {
+	e.buf = []rune(line)
+	e.pos = len(e.buf)
+}
+
+func (e *lineEditor) move(n int) {
+	if pos := e.pos + n; pos >= 0 && pos <= len(e.buf) {
+		e.pos = pos
+	}
+}
+
+func (e *lineEditor) insert(r ...rune) {
+	buf := make([]rune, 0, len(e.buf)+len(r))
+	buf = append(buf, e.buf[:e.pos]...)
+	buf = append(buf, r...)
+	e.buf = append(buf, e.buf[e.pos:]...)
+	e.pos += len(r)
+}
+
+func (e *lineEditor) deleteAt(pos int) {
+	if pos < len(e.buf) {
+		e.buf = append(e.buf[:pos], e.buf[pos+1:]...)
+	}
+}
+
+func (e *lineEditor) deleteWord() {
+	start := e.pos
+	for start > 0 && e.buf[start-1] == ' ' {
+		start--
+	}
+	for start > 0 && e.buf[start-1] != ' ' {
+		start--
+	}
+	e.buf = append(e.buf[:start], e.buf[e.pos:]...)
+	e.pos = start
+}
+
+// completeWord completes the word before the cursor. If there are several
+// candidates, their common prefix is inserted, or they're listed if there's
+// nothing more to insert.
+func (e *lineEditor) completeWord() {
+	if e.complete == nil {
+		return
+	}
+	before := string(e.buf[:e.pos])
+	start, candidates := e.complete(before)
+	if len(candidates) == 0 {
+		return
+	}
+
+	word := before[start:]
+	prefix := candidates[0]
+	for _, c := range candidates[1:] {
+		for !strings.HasPrefix(c, prefix) {
+			_, size := utf8.DecodeLastRuneInString(prefix)
+			prefix = prefix[:len(prefix)-size]
+		}
+	}
+
+	if len(prefix) > len(word) {
+		e.insert([]rune(prefix[len(word):])...)
+		return
+	}
+	if len(candidates) > 1 {
+		fmt.Fprintf(e.out, "\r\n%s\r\n", strings.Join(candidates, "  "))
+	}
+}
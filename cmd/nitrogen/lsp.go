@@ -0,0 +1,39 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/nitrogen-lang/nitrogen/src/lsp"
+)
+
+// lspCommand runs a language server on standard input and output.
+func lspCommand(args []string) int {
+	flags := flag.NewFlagSet("lsp", flag.ExitOnError)
+	logFile := flags.String("log", "", "Write a log of messages and errors to a file")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: nitrogen lsp [flags]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	server := lsp.NewServer(os.Stdin, os.Stdout)
+	if *logFile != "" {
+		f, err := os.OpenFile(*logFile, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		defer f.Close()
+		server.Log = log.New(f, "", log.LstdFlags)
+	}
+
+	if err := server.Run(); err != nil {
+		server.Log.Println(err)
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
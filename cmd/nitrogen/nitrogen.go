@@ -1,26 +1,22 @@
 package main
 
 import (
-	"bufio"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/nitrogen-lang/nitrogen/src/eval"
-	"github.com/nitrogen-lang/nitrogen/src/lexer"
 	"github.com/nitrogen-lang/nitrogen/src/moduleutils"
 	"github.com/nitrogen-lang/nitrogen/src/object"
-	"github.com/nitrogen-lang/nitrogen/src/parser"
+	"github.com/nitrogen-lang/nitrogen/src/vm"
 
 	_ "github.com/nitrogen-lang/nitrogen/src/builtins"
 )
 
-const (
-	interactivePrompt = ">> "
-	version           = "0.1.0"
-)
+const version = "0.1.0"
 
 var (
 	interactive       bool
@@ -32,6 +28,8 @@ var (
 	modulePath        string
 	printVersion      bool
 	fullDebug         bool
+	backend           string
+	debugRun          bool
 )
 
 func init() {
@@ -44,9 +42,25 @@ func init() {
 	flag.StringVar(&modulePath, "modules", "", "Module directory")
 	flag.BoolVar(&printVersion, "version", false, "Print version information")
 	flag.BoolVar(&fullDebug, "debug", false, "Enable debug mode")
+	flag.StringVar(&backend, "backend", "eval", "Execution backend, \"eval\" (tree walking) or \"vm\" (bytecode)")
+	flag.BoolVar(&debugRun, "debug-run", false, "Run the script in the step debugger")
+}
+
+// subcommands are run with "nitrogen <command> [args]" and return the exit status.
+var subcommands = map[string]func(args []string) int{
+	"dap": dapCommand,
+	"fmt": fmtCommand,
+	"lsp": lspCommand,
+	"vet": vetCommand,
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		if cmd, ok := subcommands[os.Args[1]]; ok {
+			os.Exit(cmd(os.Args[2:]))
+		}
+	}
+
 	flag.Parse()
 
 	if printVersion {
@@ -54,6 +68,11 @@ func main() {
 		return
 	}
 
+	if backend != "eval" && backend != "vm" {
+		fmt.Printf("Unknown backend %q\n", backend)
+		os.Exit(1)
+	}
+
 	modulesPath := os.Getenv("NITROGEN_MODULES")
 	if modulePath != "" {
 		modulesPath = modulePath
@@ -99,13 +118,22 @@ func main() {
 
 	env.CreateConst("_ARGV", getScriptArgs(flag.Arg(0)))
 
-	interpreter := eval.NewInterpreter()
-	result := interpreter.Eval(program, env)
+	var result object.Object
+	if debugRun {
+		if backend != "eval" {
+			fmt.Println("The debugger only supports the eval backend")
+			os.Exit(1)
+		}
+		result = runDebugger(program, env)
+	} else {
+		result = newInterpreter(os.Stdout).Eval(program, env)
+	}
 	if result != nil && result != object.NullConst {
 		if e, ok := result.(*object.Exception); ok {
 			os.Stdout.WriteString("Uncaught Exception: ")
 			os.Stdout.WriteString(e.Message)
 			os.Stdout.Write([]byte{'\n'})
+			os.Stdout.WriteString(e.StackTrace())
 			os.Exit(1)
 		}
 		os.Stdout.WriteString(result.Inspect())
@@ -113,6 +141,19 @@ func main() {
 	}
 }
 
+// newInterpreter creates an interpreter for the selected backend that writes to stdout.
+func newInterpreter(stdout io.Writer) object.Interpreter {
+	if backend == "vm" {
+		machine := vm.New()
+		machine.Stdout = stdout
+		return machine
+	}
+
+	interpreter := eval.NewInterpreter()
+	interpreter.Stdout = stdout
+	return interpreter
+}
+
 func getEnvironment() *object.Hash {
 	return makeEnvironment(getEnvironmentMap())
 }
@@ -128,19 +169,24 @@ func getEnvironmentMap() map[string]string {
 }
 
 func makeEnvironment(env map[string]string) *object.Hash {
-	m := &object.Hash{Pairs: make(map[object.HashKey]object.HashPair)}
-	for k, v := range env {
-		key := &object.String{Value: k}
-		m.Pairs[key.HashKey()] = object.HashPair{
-			Key:   key,
-			Value: &object.String{Value: v},
-		}
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	m := object.NewHash(len(keys))
+	for _, k := range keys {
+		m.Set(&object.String{Value: k}, &object.String{Value: env[k]})
 	}
 	return m
 }
 
 func getScriptArgs(filepath string) *object.Array {
-	s := flag.Args()[1:]
+	return makeScriptArgs(filepath, flag.Args()[1:])
+}
+
+func makeScriptArgs(filepath string, s []string) *object.Array {
 	length := len(s) + 1
 	newElements := make([]object.Object, length, length)
 	newElements[0] = &object.String{Value: filepath}
@@ -149,43 +195,3 @@ func getScriptArgs(filepath string) *object.Array {
 	}
 	return &object.Array{Elements: newElements}
 }
-
-func startRepl(in io.Reader, out io.Writer) {
-	scanner := bufio.NewScanner(in)
-	env := object.NewEnvironment()
-
-	interpreter := eval.NewInterpreter()
-	for {
-		fmt.Fprint(out, interactivePrompt)
-		scanned := scanner.Scan()
-		if !scanned {
-			return
-		}
-
-		line := scanner.Text()
-		if line == ".quit" {
-			return
-		}
-
-		l := lexer.NewString(line)
-		p := parser.New(l, moduleutils.ParserSettings)
-
-		program := p.ParseProgram()
-		if len(p.Errors()) != 0 {
-			printParserErrors(out, p.Errors())
-			continue
-		}
-
-		result := interpreter.Eval(program, env)
-		if result != nil && result != object.NullConst {
-			io.WriteString(out, result.Inspect())
-			io.WriteString(out, "\n")
-		}
-	}
-}
-
-func printParserErrors(out io.Writer, errors []string) {
-	for _, msg := range errors {
-		fmt.Fprintf(out, "ERROR: %s\n", msg)
-	}
-}
@@ -0,0 +1,362 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nitrogen-lang/nitrogen/src/eval"
+	"github.com/nitrogen-lang/nitrogen/src/lexer"
+	"github.com/nitrogen-lang/nitrogen/src/moduleutils"
+	"github.com/nitrogen-lang/nitrogen/src/object"
+	"github.com/nitrogen-lang/nitrogen/src/parser"
+	"github.com/nitrogen-lang/nitrogen/src/token"
+)
+
+const (
+	interactivePrompt  = ">> "
+	continuationPrompt = ".. "
+
+	// maxHistory is the number of lines kept in the history file.
+	maxHistory = 1000
+)
+
+var replCommands = []struct {
+	name, args, help string
+}{
+	{".help", "", "Show this help"},
+	{".quit", "", "Exit the REPL, Ctrl-D also exits"},
+	{".load", "file", "Run a script in the REPL's environment"},
+	{".env", "", "List the variables defined at the prompt"},
+	{".ast", "code", "Print the parsed form of code"},
+	{".time", "code", "Run code and print how long it took"},
+	{".reset", "", "Remove all variables"},
+}
+
+type repl struct {
+	out         io.Writer
+	reader      lineReader
+	interpreter object.Interpreter
+	env         *object.Environment
+	historyFile *os.File
+}
+
+func startRepl(in io.Reader, out io.Writer) {
+	r := &repl{
+		out:         out,
+		interpreter: newInterpreter(out),
+		env:         object.NewEnvironment(),
+	}
+
+	if f, ok := in.(*os.File); ok && isTerminal(int(f.Fd())) {
+		editor := newLineEditor(f, out, r.complete)
+		r.reader = editor
+		r.loadHistory(editor)
+	} else {
+		r.reader = &plainReader{in: bufio.NewReader(in), out: out}
+	}
+
+	if r.historyFile != nil {
+		defer r.historyFile.Close()
+	}
+	r.run()
+}
+
+func (r *repl) run() {
+	for {
+		src, err := r.readInput()
+		if err == errInterrupted {
+			continue
+		}
+		if err != nil {
+			return
+		}
+
+		trimmed := strings.TrimSpace(src)
+		if strings.HasPrefix(trimmed, ".") {
+			if !r.command(trimmed) {
+				return
+			}
+			continue
+		}
+		r.eval(src)
+	}
+}
+
+// readInput reads lines until they form complete code or a command.
+func (r *repl) readInput() (string, error) {
+	var lines []string
+	prompt := interactivePrompt
+
+	for {
+		line, err := r.reader.readLine(prompt)
+		if err != nil {
+			return "", err
+		}
+		r.addHistory(line)
+		lines = append(lines, line)
+
+		// Commands are one line
+		if len(lines) == 1 && strings.HasPrefix(strings.TrimSpace(line), ".") {
+			return line, nil
+		}
+
+		src := strings.Join(lines, "\n")
+		// A blank line ends input that's only incomplete because of a parse error
+		if !needsMoreInput(src, len(lines) > 1 && strings.TrimSpace(line) == "") {
+			return src, nil
+		}
+		prompt = continuationPrompt
+	}
+}
+
+// needsMoreInput reports if src ends inside brackets, a block, or a raw string.
+// Unless ended is true, src also needs more input if the parser reached its end
+// before the code was complete, such as after an operator.
+func needsMoreInput(src string, ended bool) bool {
+	l := lexer.NewString(src)
+	depth := 0
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		switch tok.Type {
+		case token.LParen, token.LBrace, token.LSquare, token.StringStart:
+			depth++
+		case token.RParen, token.RBrace, token.RSquare, token.StringEnd:
+			depth--
+		case token.Illegal:
+			return tok.Literal == lexer.UnterminatedRawString
+		}
+	}
+	if depth > 0 {
+		return true
+	}
+	if ended {
+		return false
+	}
+
+	p := parser.New(lexer.NewString(src), moduleutils.ParserSettings)
+	p.ParseProgram()
+	for _, msg := range p.Errors() {
+		if strings.Contains(msg, "EOF") {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *repl) eval(src string) {
+	p := parser.New(lexer.NewString(src), moduleutils.ParserSettings)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		printParserErrors(r.out, p.Errors())
+		return
+	}
+	r.printResult(r.interpreter.Eval(program, r.env))
+}
+
+// printResult prints the result of code run at the prompt and binds it to _.
+func (r *repl) printResult(result object.Object) {
+	if result == nil || result == object.NullConst {
+		return
+	}
+	if e, ok := result.(*object.Exception); ok {
+		fmt.Fprintf(r.out, "Uncaught Exception: %s\n%s", e.Message, e.StackTrace())
+		return
+	}
+
+	r.env.SetForce("_", result, false)
+	io.WriteString(r.out, result.Inspect())
+	io.WriteString(r.out, "\n")
+}
+
+// command runs a REPL command. It returns false if the REPL should exit.
+func (r *repl) command(line string) bool {
+	name, arg := line, ""
+	if i := strings.IndexAny(line, " \t"); i > -1 {
+		name, arg = line[:i], strings.TrimSpace(line[i+1:])
+	}
+
+	switch name {
+	case ".quit", ".exit":
+		return false
+	case ".help":
+		for _, c := range replCommands {
+			fmt.Fprintf(r.out, "%-16s %s\n", strings.TrimSpace(c.name+" "+c.args), c.help)
+		}
+	case ".load":
+		r.load(arg)
+	case ".env":
+		for _, name := range r.env.Names() {
+			val, _ := r.env.Get(name)
+			kind := "let"
+			if r.env.IsConst(name) {
+				kind = "always"
+			}
+			fmt.Fprintf(r.out, "%s %s = %s\n", kind, name, val.Inspect())
+		}
+	case ".ast":
+		p := parser.New(lexer.NewString(arg), moduleutils.ParserSettings)
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			printParserErrors(r.out, p.Errors())
+			break
+		}
+		fmt.Fprintln(r.out, program.String())
+	case ".time":
+		start := time.Now()
+		r.eval(arg)
+		fmt.Fprintf(r.out, "Took %s\n", time.Since(start))
+	case ".reset":
+		r.env = object.NewEnvironment()
+	default:
+		fmt.Fprintf(r.out, "Unknown command %s, type .help for a list of commands\n", name)
+	}
+	return true
+}
+
+func (r *repl) load(path string) {
+	if path == "" {
+		fmt.Fprintln(r.out, "ERROR: .load needs a file")
+		return
+	}
+
+	l, err := lexer.NewFile(path)
+	if err != nil {
+		fmt.Fprintf(r.out, "ERROR: %s\n", err)
+		return
+	}
+	p := parser.New(l, moduleutils.ParserSettings)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		printParserErrors(r.out, p.Errors())
+		return
+	}
+	r.printResult(r.interpreter.Eval(program, r.env))
+}
+
+// complete completes commands, identifiers, members of modules and maps after
+// a dot, and module names in a call to module().
+func (r *repl) complete(line string) (int, []string) {
+	if strings.HasPrefix(line, ".") && !strings.ContainsAny(line, " \t") {
+		var names []string
+		for _, c := range replCommands {
+			names = append(names, c.name)
+		}
+		return 0, matching(names, line)
+	}
+
+	start := len(line)
+	for start > 0 && isIdentOrDot(line[start-1]) {
+		start--
+	}
+	word := line[start:]
+
+	if before := strings.TrimRight(line[:start], " "); strings.HasSuffix(before, `module("`) || strings.HasSuffix(before, "module('") {
+		return start, matching(eval.ModuleNames(), word)
+	}
+
+	if dot := strings.LastIndexByte(word, '.'); dot > -1 {
+		val, ok := r.env.Get(word[:dot])
+		if !ok {
+			return 0, nil
+		}
+		return start + dot + 1, matching(memberNames(val), word[dot+1:])
+	}
+
+	names := append(r.env.Names(), eval.BuiltinNames()...)
+	names = append(names, token.Keywords()...)
+	return start, matching(names, word)
+}
+
+func isIdentOrDot(c byte) bool {
+	return c == '_' || c == '.' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+// memberNames returns the names that can follow a dot after val.
+func memberNames(val object.Object) []string {
+	var names []string
+	switch val := val.(type) {
+	case *object.Module:
+		for name := range val.Methods {
+			names = append(names, name)
+		}
+		for name := range val.Vars {
+			names = append(names, name)
+		}
+	case *object.Hash:
+		for _, pair := range val.Pairs() {
+			if key, ok := pair.Key.(*object.String); ok {
+				names = append(names, key.Value)
+			}
+		}
+	}
+	return names
+}
+
+// matching returns the sorted names that start with prefix, without duplicates.
+func matching(names []string, prefix string) []string {
+	seen := make(map[string]bool)
+	var matches []string
+	for _, name := range names {
+		if strings.HasPrefix(name, prefix) && !seen[name] {
+			seen[name] = true
+			matches = append(matches, name)
+		}
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+// historyPath is $NITROGEN_HISTORY or ~/.nitrogen_history. Setting
+// NITROGEN_HISTORY to an empty string turns off saving history.
+func historyPath() string {
+	if path, set := os.LookupEnv("NITROGEN_HISTORY"); set {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".nitrogen_history")
+}
+
+// loadHistory reads previous lines into the editor and opens the history file to add new ones.
+func (r *repl) loadHistory(editor *lineEditor) {
+	path := historyPath()
+	if path == "" {
+		return
+	}
+
+	if data, err := os.ReadFile(path); err == nil {
+		lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+		if len(lines) > maxHistory {
+			lines = lines[len(lines)-maxHistory:]
+			os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0600)
+		}
+		for _, line := range lines {
+			editor.addHistory(line)
+		}
+	}
+
+	r.historyFile, _ = os.OpenFile(path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0600)
+}
+
+func (r *repl) addHistory(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	r.reader.addHistory(line)
+	if r.historyFile != nil {
+		fmt.Fprintln(r.historyFile, line)
+	}
+}
+
+func printParserErrors(out io.Writer, errors []string) {
+	for _, msg := range errors {
+		fmt.Fprintf(out, "ERROR: %s\n", msg)
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNeedsMoreInput(t *testing.T) {
+	tests := []struct {
+		input    string
+		ended    bool
+		expected bool
+	}{
+		{`func f() {`, false, true},
+		{"let a = [1,\n2", false, true},
+		{`1 +`, false, true},
+		{`1 +`, true, false},
+		{`'raw`, false, true},
+		{"'raw\nstring", true, true},
+		{`"abc`, false, false},
+		{`"a${b}c`, false, false},
+		{`println("done")`, false, false},
+	}
+
+	for _, tt := range tests {
+		if got := needsMoreInput(tt.input, tt.ended); got != tt.expected {
+			t.Errorf("needsMoreInput(%q, %t): expected %t, got %t", tt.input, tt.ended, tt.expected, got)
+		}
+	}
+}
+
+func TestReplSession(t *testing.T) {
+	input := strings.Join([]string{
+		`"abc`,
+		`let s = 'a`,
+		`b'`,
+		`s`,
+		`func f() { throw "bad" }`,
+		`f()`,
+	}, "\n") + "\n"
+
+	var out bytes.Buffer
+	startRepl(strings.NewReader(input), &out)
+
+	for _, expected := range []string{
+		"Unterminated string",
+		"a\nb\n",
+		"Uncaught Exception: bad\n    at f (",
+		"    at <main> (",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("REPL output doesn't contain %q:\n%s", expected, out.String())
+		}
+	}
+}
@@ -14,7 +14,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/nitrogen-lang/nitrogen/src/eval"
 	"github.com/nitrogen-lang/nitrogen/src/object"
 )
 
@@ -168,14 +167,13 @@ func (w *worker) run(conn net.Conn) {
 	}
 
 	// Execute script
-	interpreter := eval.NewInterpreter()
-	interpreter.Stdout = conn
-
+	interpreter := newInterpreter(conn)
 	result := interpreter.Eval(program, env)
 	if result != nil && result != object.NullConst {
 		if e, ok := result.(*object.Exception); ok {
 			os.Stderr.WriteString(e.Message)
 			os.Stderr.Write([]byte{'\n'})
+			os.Stderr.WriteString(e.StackTrace())
 		}
 	}
 }
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// walkSources calls fn for each path that's a file and each .ni file in the
+// paths that are directories. Errors are passed to onError.
+func walkSources(paths []string, fn func(path string, info os.FileInfo), onError func(error)) {
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			onError(err)
+			continue
+		}
+		if !info.IsDir() {
+			fn(path, info)
+			continue
+		}
+
+		err = filepath.Walk(path, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() && strings.HasSuffix(path, ".ni") {
+				fn(path, info)
+			}
+			return nil
+		})
+		if err != nil {
+			onError(err)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package main
+
+import "errors"
+
+// Line editing isn't supported on this platform, the REPL reads plain lines.
+
+func isTerminal(fd int) bool { return false }
+
+func makeRaw(fd int) (func(), error) { return nil, errors.New("raw terminal mode isn't supported") }
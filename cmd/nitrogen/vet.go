@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/nitrogen-lang/nitrogen/src/vet"
+)
+
+// vetCommand reports likely mistakes in Nitrogen source files. With no files it checks standard input.
+func vetCommand(args []string) int {
+	flags := flag.NewFlagSet("vet", flag.ExitOnError)
+	jsonOut := flags.Bool("json", false, "Print the problems as a JSON array")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: nitrogen vet [flags] [files or directories]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	v := &vetter{diags: []*vet.Diagnostic{}}
+
+	if flags.NArg() == 0 {
+		src, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			v.error(err)
+		} else {
+			v.vet("<standard input>", src)
+		}
+	} else {
+		walkSources(flags.Args(), v.vetFile, v.error)
+	}
+
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(v.diags)
+	} else {
+		for _, d := range v.diags {
+			fmt.Println(d)
+		}
+	}
+
+	if v.status == 0 && len(v.diags) > 0 {
+		v.status = 1
+	}
+	return v.status
+}
+
+type vetter struct {
+	diags []*vet.Diagnostic
+	// status is 2 if a file couldn't be read or parsed
+	status int
+}
+
+func (v *vetter) error(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	v.status = 2
+}
+
+func (v *vetter) vetFile(path string, info os.FileInfo) {
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		v.error(err)
+		return
+	}
+	v.vet(path, src)
+}
+
+func (v *vetter) vet(name string, src []byte) {
+	diags, err := vet.Source(name, src)
+	if err != nil {
+		v.error(fmt.Errorf("%s: %s", name, err))
+		return
+	}
+	v.diags = append(v.diags, diags...)
+}
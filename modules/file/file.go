@@ -42,6 +42,9 @@ func (f *fileResource) Inspect() string         { return "File resource" }
 func (f *fileResource) Type() object.ObjectType { return object.ResourceObj }
 func (f *fileResource) Dup() object.Object      { return object.NullConst } // Duplicating a file resource isn't allowed
 
+// Read lets other modules, such as json, read from an open file.
+func (f *fileResource) Read(p []byte) (int, error) { return f.file.Read(p) }
+
 var modes = map[string]int{
 	"r":  os.O_RDONLY,
 	"r+": os.O_RDWR,
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"container/list"
+	"regexp"
+	"sync"
+
+	"github.com/nitrogen-lang/nitrogen/src/eval"
+	"github.com/nitrogen-lang/nitrogen/src/moduleutils"
+	"github.com/nitrogen-lang/nitrogen/src/object"
+)
+
+func init() {
+	eval.RegisterModule(ModuleName, &object.Module{
+		Name: ModuleName,
+		Methods: map[string]object.BuiltinFunction{
+			"compile":    compileRegex,
+			"match":      matchRegex,
+			"find":       findRegex,
+			"findAll":    findAllRegex,
+			"submatches": submatchesRegex,
+			"replace":    replaceRegex,
+			"split":      splitRegex,
+			"quote":      quoteRegex,
+		},
+		Vars: map[string]object.Object{
+			"name": object.MakeStringObj(ModuleName),
+		},
+	})
+}
+
+func main() {}
+
+var ModuleName = "regex"
+
+type regexResource struct {
+	re *regexp.Regexp
+}
+
+func (r *regexResource) Inspect() string         { return "Regex resource " + r.re.String() }
+func (r *regexResource) Type() object.ObjectType { return object.ResourceObj }
+func (r *regexResource) Dup() object.Object      { return r } // Compiled patterns are immutable
+
+// maxCachedPatterns limits the number of patterns compiled from strings that are kept.
+const maxCachedPatterns = 256
+
+// patternCache holds patterns compiled from strings so functions called in
+// a loop with the same pattern string don't recompile it. When it's full the
+// least recently used pattern is evicted.
+var patternCache = struct {
+	sync.Mutex
+	patterns map[string]*list.Element
+	// recent holds the cached patterns, most recently used first
+	recent *list.List
+}{patterns: make(map[string]*list.Element), recent: list.New()}
+
+type cachedPattern struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+func compilePattern(pattern string) (*regexp.Regexp, object.Object) {
+	patternCache.Lock()
+	defer patternCache.Unlock()
+
+	if elem, ok := patternCache.patterns[pattern]; ok {
+		patternCache.recent.MoveToFront(elem)
+		return elem.Value.(*cachedPattern).re, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, object.NewArgumentError("Invalid regex: %s", err.Error())
+	}
+
+	if patternCache.recent.Len() >= maxCachedPatterns {
+		oldest := patternCache.recent.Remove(patternCache.recent.Back()).(*cachedPattern)
+		delete(patternCache.patterns, oldest.pattern)
+	}
+	patternCache.patterns[pattern] = patternCache.recent.PushFront(&cachedPattern{pattern: pattern, re: re})
+	return re, nil
+}
+
+// getRegex returns the pattern of a function, either a compiled regex or a string.
+func getRegex(name string, arg object.Object) (*regexp.Regexp, object.Object) {
+	switch arg := arg.(type) {
+	case *regexResource:
+		return arg.re, nil
+	case *object.String:
+		return compilePattern(arg.Value)
+	}
+	return nil, object.NewTypeError("Pattern of `%s` must be a regex or STRING, got %s", name, arg.Type())
+}
+
+// regexArgs checks the pattern and subject string common to all functions.
+func regexArgs(name string, args []object.Object) (*regexp.Regexp, string, object.Object) {
+	re, err := getRegex(name, args[0])
+	if err != nil {
+		return nil, "", err
+	}
+	str, ok := args[1].(*object.String)
+	if !ok {
+		return nil, "", object.NewTypeError("Argument 2 to `%s` must be STRING, got %s", name, args[1].Type())
+	}
+	return re, str.Value, nil
+}
+
+// limitArg returns the optional limit argument at index i, -1 means no limit.
+func limitArg(name string, args []object.Object, i int) (int, object.Object) {
+	if len(args) <= i {
+		return -1, nil
+	}
+	n, ok := args[i].(*object.Integer)
+	if !ok {
+		return 0, object.NewTypeError("Limit of `%s` must be INTEGER, got %s", name, args[i].Type())
+	}
+	return int(n.Value), nil
+}
+
+func compileRegex(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("compile", 1, args...); ac != nil {
+		return ac
+	}
+	pattern, ok := args[0].(*object.String)
+	if !ok {
+		return object.NewTypeError("Pattern of `compile` must be STRING, got %s", args[0].Type())
+	}
+
+	re, err := compilePattern(pattern.Value)
+	if err != nil {
+		return err
+	}
+	return &regexResource{re: re}
+}
+
+func matchRegex(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("match", 2, args...); ac != nil {
+		return ac
+	}
+	re, str, err := regexArgs("match", args)
+	if err != nil {
+		return err
+	}
+	return object.NativeBoolToBooleanObj(re.MatchString(str))
+}
+
+func findRegex(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("find", 2, args...); ac != nil {
+		return ac
+	}
+	re, str, err := regexArgs("find", args)
+	if err != nil {
+		return err
+	}
+
+	loc := re.FindStringIndex(str)
+	if loc == nil {
+		return object.NullConst
+	}
+	return object.MakeStringObj(str[loc[0]:loc[1]])
+}
+
+func findAllRegex(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckMinArgs("findAll", 2, args...); ac != nil {
+		return ac
+	}
+	re, str, err := regexArgs("findAll", args)
+	if err != nil {
+		return err
+	}
+	n, err := limitArg("findAll", args, 2)
+	if err != nil {
+		return err
+	}
+
+	matches := re.FindAllString(str, n)
+	if matches == nil {
+		return &object.Array{Elements: []object.Object{}}
+	}
+	return object.MakeStringArray(matches)
+}
+
+// groupsMap makes a map of the groups of a match. Groups are keyed by their
+// index and named groups also by their name. Groups that didn't match are nil.
+func groupsMap(re *regexp.Regexp, str string, loc []int) *object.Hash {
+	names := re.SubexpNames()
+	groups := object.NewHash(len(names))
+
+	for i, name := range names {
+		var value object.Object = object.NullConst
+		if loc[2*i] >= 0 {
+			value = object.MakeStringObj(str[loc[2*i]:loc[2*i+1]])
+		}
+		groups.Set(&object.Integer{Value: int64(i)}, value)
+		if name != "" {
+			groups.Set(object.MakeStringObj(name), value)
+		}
+	}
+	return groups
+}
+
+func submatchesRegex(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("submatches", 2, args...); ac != nil {
+		return ac
+	}
+	re, str, err := regexArgs("submatches", args)
+	if err != nil {
+		return err
+	}
+
+	loc := re.FindStringSubmatchIndex(str)
+	if loc == nil {
+		return object.NullConst
+	}
+	return groupsMap(re, str, loc)
+}
+
+func replaceRegex(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("replace", 3, args...); ac != nil {
+		return ac
+	}
+	re, str, err := regexArgs("replace", args)
+	if err != nil {
+		return err
+	}
+
+	switch repl := args[2].(type) {
+	case *object.String:
+		return object.MakeStringObj(re.ReplaceAllString(str, repl.Value))
+	case *object.Function, *object.Builtin, *object.BuiltinMethod:
+		return replaceFunc(interpreter, re, str, repl)
+	}
+	return object.NewTypeError("Replacement of `replace` must be STRING or a function, got %s", args[2].Type())
+}
+
+// replaceFunc replaces each match with the result of calling fn with the match and a map of its groups.
+func replaceFunc(interpreter object.Interpreter, re *regexp.Regexp, str string, fn object.Object) object.Object {
+	var out []byte
+	last := 0
+
+	for _, loc := range re.FindAllStringSubmatchIndex(str, -1) {
+		result := interpreter.Call(fn, []object.Object{
+			object.MakeStringObj(str[loc[0]:loc[1]]),
+			groupsMap(re, str, loc),
+		})
+		if eval.IsException(result) || eval.IsPanic(result) {
+			return result
+		}
+		replacement, ok := result.(*object.String)
+		if !ok {
+			return object.NewTypeError("Replacement function of `replace` must return STRING, got %s", result.Type())
+		}
+
+		out = append(out, str[last:loc[0]]...)
+		out = append(out, replacement.Value...)
+		last = loc[1]
+	}
+
+	out = append(out, str[last:]...)
+	return object.MakeStringObj(string(out))
+}
+
+func splitRegex(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckMinArgs("split", 2, args...); ac != nil {
+		return ac
+	}
+	re, str, err := regexArgs("split", args)
+	if err != nil {
+		return err
+	}
+	n, err := limitArg("split", args, 2)
+	if err != nil {
+		return err
+	}
+	return object.MakeStringArray(re.Split(str, n))
+}
+
+func quoteRegex(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("quote", 1, args...); ac != nil {
+		return ac
+	}
+	str, ok := args[0].(*object.String)
+	if !ok {
+		return object.NewTypeError("Argument to `quote` must be STRING, got %s", args[0].Type())
+	}
+	return object.MakeStringObj(regexp.QuoteMeta(str.Value))
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/nitrogen-lang/nitrogen/src/moduleutils"
+	"github.com/nitrogen-lang/nitrogen/src/object"
+)
+
+func TestRegex(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`regex.match("^a+b$", "aaab")`, "true"},
+		{`regex.match("^a+b$", "aaabc")`, "false"},
+		{`regex.match(regex.compile("\\d"), "a1")`, "true"},
+		{`regex.find("\\d+", "ab 12 34")`, "12"},
+		{`regex.find("\\d+", "ab")`, "nil"},
+		{`regex.findAll("\\d+", "1 22 333")`, `["1", "22", "333"]`},
+		{`regex.findAll("\\d+", "1 22 333", 2)`, `["1", "22"]`},
+		{`regex.findAll("\\d+", "none")`, "[]"},
+		{`regex.submatches("(?P<key>\\w+)=(\\w+)?", "a=")`, "{0: a=, 1: a, key: a, 2: nil}"},
+		{`regex.replace("(\\w)(\\d)", "a1 b2", "$2$1")`, "1a 2b"},
+		{`regex.replace("\\d+", "a1b22", func(m, groups) { "<" + groups[0] + ">" })`, "a<1>b<22>"},
+		{`regex.replace("(?P<n>\\d)", "a1", func(m) { m + m })`, "a11"},
+		{`regex.split(",\\s*", "a, b,c")`, `["a", "b", "c"]`},
+		{`regex.split(",", "a,b,c", 2)`, `["a", "b,c"]`},
+		{`regex.quote("a.b*")`, "a\\.b\\*"},
+	}
+
+	for _, tt := range tests {
+		result := moduleutils.TestEvalModule(t, tt.input, ModuleName)
+		if ex, ok := result.(*object.Exception); ok {
+			t.Errorf("%s: unexpected exception %q", tt.input, ex.Message)
+			continue
+		}
+		if result.Inspect() != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.input, tt.expected, result.Inspect())
+		}
+	}
+}
+
+func TestRegexErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`regex.match("a(", "a")`, "Invalid regex: error parsing regexp: missing closing ): `a(`"},
+		{`regex.compile(1)`, "Pattern of `compile` must be STRING, got INTEGER"},
+		{`regex.find(1, "a")`, "Pattern of `find` must be a regex or STRING, got INTEGER"},
+		{`regex.findAll("a", "a", "b")`, "Limit of `findAll` must be INTEGER, got STRING"},
+		{`regex.replace("a", "a", 1)`, "Replacement of `replace` must be STRING or a function, got INTEGER"},
+		{`regex.replace("a", "a", func(m) { 1 })`, "Replacement function of `replace` must return STRING, got INTEGER"},
+		{`regex.replace("a", "a", func(m) { throw "oops" })`, "oops"},
+	}
+
+	for _, tt := range tests {
+		moduleutils.TestLiteralErrorObjects(t, moduleutils.TestEvalModule(t, tt.input, ModuleName), tt.expected)
+	}
+
+	exc, ok := moduleutils.TestEvalModule(t, `regex.compile("[")`, ModuleName).(*object.Exception)
+	if !ok || !exc.Is(object.ArgumentErrorClass) {
+		t.Errorf("Expected an ArgumentError for an invalid pattern, got %s", moduleutils.ShowError(exc))
+	}
+}
+
+func TestPatternCache(t *testing.T) {
+	first, _ := compilePattern("first")
+	for i := 0; i < maxCachedPatterns*2; i++ {
+		compilePattern(fmt.Sprintf("p%d", i))
+		// Using the first pattern keeps it cached
+		if re, _ := compilePattern("first"); re != first {
+			t.Fatalf("Recently used pattern was evicted after %d patterns", i)
+		}
+	}
+
+	if n := patternCache.recent.Len(); n != maxCachedPatterns || len(patternCache.patterns) != n {
+		t.Errorf("Expected %d cached patterns, got %d in the list and %d in the map", maxCachedPatterns, n, len(patternCache.patterns))
+	}
+	if _, ok := patternCache.patterns["p0"]; ok {
+		t.Error("Least recently used pattern wasn't evicted")
+	}
+}
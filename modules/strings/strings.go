@@ -2,6 +2,8 @@ package main
 
 import (
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/nitrogen-lang/nitrogen/src/eval"
 	"github.com/nitrogen-lang/nitrogen/src/moduleutils"
@@ -15,6 +17,21 @@ func init() {
 			"splitN":    strSplitN,
 			"trimSpace": strTrim,
 			"dedup":     strDedup,
+			"upper":     strUpper,
+			"lower":     strLower,
+			"title":     strTitle,
+			"contains":  strContains,
+			"index":     strIndex,
+			"lastIndex": strLastIndex,
+			"replace":   strReplace,
+			"split":     strSplit,
+			"join":      strJoin,
+			"repeat":    strRepeat,
+			"padLeft":   strPadLeft,
+			"padRight":  strPadRight,
+			"hasPrefix": strHasPrefix,
+			"hasSuffix": strHasSuffix,
+			"fields":    strFields,
 		},
 		Vars: map[string]object.Object{
 			"name": object.MakeStringObj(ModuleName),
@@ -99,3 +116,229 @@ func dedupString(str string, c byte) string {
 
 	return string(newstr)
 }
+
+// stringArgs checks that the first n arguments are strings and returns their values.
+func stringArgs(name string, n int, args []object.Object) ([]string, *object.Exception) {
+	strs := make([]string, n)
+	for i := 0; i < n; i++ {
+		str, ok := args[i].(*object.String)
+		if !ok {
+			return nil, object.NewTypeError("Argument %d to `%s` must be STRING, got %s", i+1, name, args[i].Type())
+		}
+		strs[i] = str.Value
+	}
+	return strs, nil
+}
+
+// mapString applies fn to its string argument.
+func mapString(name string, fn func(string) string) object.BuiltinFunction {
+	return func(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+		if ac := moduleutils.CheckArgs(name, 1, args...); ac != nil {
+			return ac
+		}
+		strs, err := stringArgs(name, 1, args)
+		if err != nil {
+			return err
+		}
+		return object.MakeStringObj(fn(strs[0]))
+	}
+}
+
+// testStrings applies fn to its two string arguments.
+func testStrings(name string, fn func(string, string) bool) object.BuiltinFunction {
+	return func(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+		if ac := moduleutils.CheckArgs(name, 2, args...); ac != nil {
+			return ac
+		}
+		strs, err := stringArgs(name, 2, args)
+		if err != nil {
+			return err
+		}
+		return object.NativeBoolToBooleanObj(fn(strs[0], strs[1]))
+	}
+}
+
+var (
+	strUpper     = mapString("upper", strings.ToUpper)
+	strLower     = mapString("lower", strings.ToLower)
+	strTitle     = mapString("title", titleCase)
+	strContains  = testStrings("contains", strings.Contains)
+	strHasPrefix = testStrings("hasPrefix", strings.HasPrefix)
+	strHasSuffix = testStrings("hasSuffix", strings.HasSuffix)
+)
+
+// titleCase changes the first letter of each word in str to title case.
+func titleCase(str string) string {
+	inWord := false
+	return strings.Map(func(r rune) rune {
+		letter := unicode.IsLetter(r) || unicode.IsDigit(r) || r == '\''
+		if letter && !inWord {
+			r = unicode.ToTitle(r)
+		}
+		inWord = letter
+		return r
+	}, str)
+}
+
+func strIndex(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("index", 2, args...); ac != nil {
+		return ac
+	}
+	strs, err := stringArgs("index", 2, args)
+	if err != nil {
+		return err
+	}
+	return &object.Integer{Value: runeIndex(strs[0], strings.Index(strs[0], strs[1]))}
+}
+
+func strLastIndex(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("lastIndex", 2, args...); ac != nil {
+		return ac
+	}
+	strs, err := stringArgs("lastIndex", 2, args)
+	if err != nil {
+		return err
+	}
+	return &object.Integer{Value: runeIndex(strs[0], strings.LastIndex(strs[0], strs[1]))}
+}
+
+// runeIndex converts a byte offset in str to the index of a code point like string indexing uses.
+func runeIndex(str string, offset int) int64 {
+	if offset < 0 {
+		return -1
+	}
+	return int64(utf8.RuneCountInString(str[:offset]))
+}
+
+func strReplace(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckMinArgs("replace", 3, args...); ac != nil {
+		return ac
+	}
+	if len(args) > 4 {
+		return object.NewArgumentError("replace expects at most 4 argument(s). Got %d", len(args))
+	}
+	strs, err := stringArgs("replace", 3, args)
+	if err != nil {
+		return err
+	}
+
+	count := int64(-1)
+	if len(args) > 3 {
+		n, ok := args[3].(*object.Integer)
+		if !ok {
+			return object.NewTypeError("Count of `replace` must be INTEGER, got %s", args[3].Type())
+		}
+		count = n.Value
+	}
+	return object.MakeStringObj(strings.Replace(strs[0], strs[1], strs[2], int(count)))
+}
+
+func strSplit(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("split", 2, args...); ac != nil {
+		return ac
+	}
+	strs, err := stringArgs("split", 2, args)
+	if err != nil {
+		return err
+	}
+	return object.MakeStringArray(strings.Split(strs[0], strs[1]))
+}
+
+func strJoin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("join", 2, args...); ac != nil {
+		return ac
+	}
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return object.NewTypeError("Argument 1 to `join` must be ARRAY, got %s", args[0].Type())
+	}
+	sep, ok := args[1].(*object.String)
+	if !ok {
+		return object.NewTypeError("Argument 2 to `join` must be STRING, got %s", args[1].Type())
+	}
+
+	strs := make([]string, len(arr.Elements))
+	for i, e := range arr.Elements {
+		str, ok := e.(*object.String)
+		if !ok {
+			return object.NewTypeError("Elements of `join` must be STRINGs, got %s", e.Type())
+		}
+		strs[i] = str.Value
+	}
+	return object.MakeStringObj(strings.Join(strs, sep.Value))
+}
+
+func strRepeat(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("repeat", 2, args...); ac != nil {
+		return ac
+	}
+	strs, err := stringArgs("repeat", 1, args)
+	if err != nil {
+		return err
+	}
+	count, ok := args[1].(*object.Integer)
+	if !ok {
+		return object.NewTypeError("Count of `repeat` must be INTEGER, got %s", args[1].Type())
+	}
+	// Strings repeat the same way with the * operator
+	return eval.InfixOperation("*", object.MakeStringObj(strs[0]), count)
+}
+
+func strPadLeft(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	return padString("padLeft", true, args)
+}
+
+func strPadRight(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	return padString("padRight", false, args)
+}
+
+// padString pads a string to a width in code points with a pad character, a space by default.
+func padString(name string, left bool, args []object.Object) object.Object {
+	if ac := moduleutils.CheckMinArgs(name, 2, args...); ac != nil {
+		return ac
+	}
+	if len(args) > 3 {
+		return object.NewArgumentError("%s expects at most 3 argument(s). Got %d", name, len(args))
+	}
+	strs, err := stringArgs(name, 1, args)
+	if err != nil {
+		return err
+	}
+	width, ok := args[1].(*object.Integer)
+	if !ok {
+		return object.NewTypeError("Width of `%s` must be INTEGER, got %s", name, args[1].Type())
+	}
+
+	pad := " "
+	if len(args) == 3 {
+		padStr, ok := args[2].(*object.String)
+		if !ok || utf8.RuneCountInString(padStr.Value) != 1 {
+			return object.NewArgumentError("Pad of `%s` must be a single character", name)
+		}
+		pad = padStr.Value
+	}
+
+	length := int64(utf8.RuneCountInString(strs[0]))
+	if width.Value <= length {
+		return args[0]
+	}
+	padding := eval.InfixOperation("*", object.MakeStringObj(pad), &object.Integer{Value: width.Value - length})
+	if eval.IsException(padding) {
+		return padding
+	}
+	if left {
+		return object.MakeStringObj(padding.Inspect() + strs[0])
+	}
+	return object.MakeStringObj(strs[0] + padding.Inspect())
+}
+
+func strFields(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("fields", 1, args...); ac != nil {
+		return ac
+	}
+	strs, err := stringArgs("fields", 1, args)
+	if err != nil {
+		return err
+	}
+	return object.MakeStringArray(strings.Fields(strs[0]))
+}
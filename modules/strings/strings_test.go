@@ -0,0 +1,72 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/nitrogen-lang/nitrogen/src/moduleutils"
+	"github.com/nitrogen-lang/nitrogen/src/object"
+)
+
+func TestStrings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`strings.upper("héllo")`, "HÉLLO"},
+		{`strings.lower("HÉLLO")`, "héllo"},
+		{`strings.title("hello wörld, it's 3rd")`, "Hello Wörld, It's 3rd"},
+		{`strings.contains("héllo", "él")`, "true"},
+		{`strings.contains("héllo", "x")`, "false"},
+		{`strings.index("héllo", "l")`, "2"},
+		{`strings.index("héllo", "x")`, "-1"},
+		{`strings.lastIndex("héllo", "l")`, "3"},
+		{`strings.lastIndex("héllo", "x")`, "-1"},
+		{`strings.replace("a.b.c", ".", "-")`, "a-b-c"},
+		{`strings.replace("a.b.c", ".", "-", 1)`, "a-b.c"},
+		{`strings.replace("a.b.c", ".", "-", -1)`, "a-b-c"},
+		{`strings.split("a,b,c", ",")`, `["a", "b", "c"]`},
+		{`strings.split("hé", "")`, `["h", "é"]`},
+		{`strings.join(["a", "b"], ", ")`, "a, b"},
+		{`strings.join([], ", ")`, ""},
+		{`strings.repeat("é", 3)`, "ééé"},
+		{`strings.padLeft("é", 3)`, "  é"},
+		{`strings.padRight("é", 3, ".")`, "é.."},
+		{`strings.padLeft("long", 2)`, "long"},
+		{`strings.hasPrefix("héllo", "hé")`, "true"},
+		{`strings.hasSuffix("héllo", "hé")`, "false"},
+		{`strings.fields("  a b\t c\n")`, `["a", "b", "c"]`},
+	}
+
+	for _, tt := range tests {
+		result := moduleutils.TestEvalModule(t, tt.input, ModuleName)
+		if ex, ok := result.(*object.Exception); ok {
+			t.Errorf("%s: unexpected exception %q", tt.input, ex.Message)
+			continue
+		}
+		if result.Inspect() != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.input, tt.expected, result.Inspect())
+		}
+	}
+}
+
+func TestStringsErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`strings.replace("a", "a", "b", 1, 2)`, "replace expects at most 4 argument(s). Got 5"},
+		{`strings.replace("a", "a")`, "replace expects 3 argument(s). Got 2"},
+		{`strings.replace("a", "a", "b", "c")`, "Count of `replace` must be INTEGER, got STRING"},
+		{`strings.upper(1)`, "Argument 1 to `upper` must be STRING, got INTEGER"},
+		{`strings.contains("a", 1)`, "Argument 2 to `contains` must be STRING, got INTEGER"},
+		{`strings.join(["a", 1], "")`, "Elements of `join` must be STRINGs, got INTEGER"},
+		{`strings.join("a", "")`, "Argument 1 to `join` must be ARRAY, got STRING"},
+		{`strings.repeat("a", "b")`, "Count of `repeat` must be INTEGER, got STRING"},
+		{`strings.padLeft("a", 3, "ab")`, "Pad of `padLeft` must be a single character"},
+		{`strings.padRight("a", 3, " ", 1)`, "padRight expects at most 3 argument(s). Got 4"},
+	}
+
+	for _, tt := range tests {
+		moduleutils.TestLiteralErrorObjects(t, moduleutils.TestEvalModule(t, tt.input, ModuleName), tt.expected)
+	}
+}
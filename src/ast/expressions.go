@@ -16,6 +16,10 @@ type Expression interface {
 type Identifier struct {
 	Token token.Token // the token.IDENT token
 	Value string
+	// Resolved is set by the resolver when the declaration of the identifier is known,
+	// Depth is then the number of environments between the identifier and the declaration.
+	Resolved bool
+	Depth    int
 }
 
 func (i *Identifier) expressionNode()      {}
@@ -151,10 +155,40 @@ func (i *IndexExpression) String() string {
 	return out.String()
 }
 
+// SliceExpression is left[low:high]. Low and High are nil if they're omitted.
+type SliceExpression struct {
+	Token token.Token // The '[' token
+	Left  Expression
+	Low   Expression
+	High  Expression
+}
+
+func (s *SliceExpression) expressionNode()      {}
+func (s *SliceExpression) TokenLiteral() string { return s.Token.Literal }
+func (s *SliceExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteByte('(')
+	out.WriteString(s.Left.String())
+	out.WriteByte('[')
+	if s.Low != nil {
+		out.WriteString(s.Low.String())
+	}
+	out.WriteByte(':')
+	if s.High != nil {
+		out.WriteString(s.High.String())
+	}
+	out.WriteString("])")
+
+	return out.String()
+}
+
 type TryCatchExpression struct {
-	Try    *BlockStatement
-	Catch  *BlockStatement
-	Symbol *Identifier
+	Token   token.Token // The 'try' token
+	Try     *BlockStatement
+	Catches []*CatchClause
+	// Finally runs however the try expression is exited. It's nil if there's no finally block.
+	Finally *BlockStatement
 }
 
 func (t *TryCatchExpression) expressionNode()      {}
@@ -163,18 +197,47 @@ func (t *TryCatchExpression) String() string {
 	var out bytes.Buffer
 	out.WriteString("try {")
 	out.WriteString(t.Try.String())
-	out.WriteString("} catch ")
-	if t.Symbol != nil {
-		out.WriteString(t.Symbol.String())
+	out.WriteByte('}')
+	for _, c := range t.Catches {
+		out.WriteByte(' ')
+		out.WriteString(c.String())
+	}
+	if t.Finally != nil {
+		out.WriteString(" finally {")
+		out.WriteString(t.Finally.String())
+		out.WriteByte('}')
+	}
+	return out.String()
+}
+
+// CatchClause is a single catch block of a try/catch. A clause with a Class
+// only handles exceptions of that class or its subclasses.
+type CatchClause struct {
+	Token  token.Token // The 'catch' token
+	Symbol *Identifier
+	Class  Expression
+	Body   *BlockStatement
+}
+
+func (c *CatchClause) String() string {
+	var out bytes.Buffer
+	out.WriteString("catch ")
+	if c.Symbol != nil {
+		out.WriteString(c.Symbol.String())
+		if c.Class != nil {
+			out.WriteString(": ")
+			out.WriteString(c.Class.String())
+		}
 		out.WriteByte(' ')
 	}
 	out.WriteByte('{')
-	out.WriteString(t.Catch.String())
+	out.WriteString(c.Body.String())
 	out.WriteByte('}')
 	return out.String()
 }
 
 type MakeInstance struct {
+	Token     token.Token // The 'make' token
 	Class     Expression
 	Arguments []Expression
 }
@@ -3,6 +3,7 @@ package ast
 import (
 	"bytes"
 	"fmt"
+	"math/big"
 	"strings"
 
 	"github.com/nitrogen-lang/nitrogen/src/token"
@@ -19,6 +20,8 @@ func (n *NullLiteral) String() string       { return n.Token.Literal }
 type IntegerLiteral struct {
 	Token token.Token // the token.INT token
 	Value int64
+	// Big is set instead of Value if the literal is too large for an int64
+	Big *big.Int
 }
 
 func (i *IntegerLiteral) expressionNode()      {}
@@ -43,6 +46,34 @@ func (s *StringLiteral) expressionNode()      {}
 func (s *StringLiteral) TokenLiteral() string { return s.Token.Literal }
 func (s *StringLiteral) String() string       { return s.Token.Literal }
 
+// InterpolatedString is a string literal with embedded expressions, "a ${b} c".
+// Parts alternates between string literals and expressions, it starts and ends
+// with a string literal.
+type InterpolatedString struct {
+	Token token.Token // the token.StringStart token
+	Parts []Expression
+}
+
+func (s *InterpolatedString) expressionNode()      {}
+func (s *InterpolatedString) TokenLiteral() string { return s.Token.Literal }
+func (s *InterpolatedString) String() string {
+	var out bytes.Buffer
+
+	out.WriteByte('"')
+	for i, part := range s.Parts {
+		if i%2 == 0 {
+			out.WriteString(part.String())
+			continue
+		}
+		out.WriteString("${")
+		out.WriteString(part.String())
+		out.WriteByte('}')
+	}
+	out.WriteByte('"')
+
+	return out.String()
+}
+
 type Boolean struct {
 	Token token.Token
 	Value bool
@@ -103,7 +134,13 @@ func (a *Array) String() string {
 
 type HashLiteral struct {
 	Token token.Token // the '{' token
-	Pairs map[Expression]Expression
+	Pairs []HashLiteralPair
+}
+
+// HashLiteralPair is a key and value of a hash literal.
+type HashLiteralPair struct {
+	Key   Expression
+	Value Expression
 }
 
 func (h *HashLiteral) expressionNode()      {}
@@ -112,8 +149,8 @@ func (h *HashLiteral) String() string {
 	var out bytes.Buffer
 
 	pairs := []string{}
-	for key, value := range h.Pairs {
-		pairs = append(pairs, key.String()+": "+value.String())
+	for _, pair := range h.Pairs {
+		pairs = append(pairs, pair.Key.String()+": "+pair.Value.String())
 	}
 
 	out.WriteByte('{')
@@ -123,6 +160,7 @@ func (h *HashLiteral) String() string {
 }
 
 type ClassLiteral struct {
+	Token   token.Token // The 'class' token
 	Name    string
 	Parent  string
 	Fields  []*DefStatement
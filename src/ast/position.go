@@ -0,0 +1,82 @@
+package ast
+
+import (
+	"github.com/nitrogen-lang/nitrogen/src/token"
+)
+
+// NodeToken returns the token a node starts at. The token carries the node's
+// filename and position. Nodes generated by the parser may have a zero position.
+func NodeToken(node Node) token.Token {
+	switch node := node.(type) {
+	case *Program:
+		if len(node.Statements) > 0 {
+			return NodeToken(node.Statements[0])
+		}
+		return token.Token{Filename: node.Filename}
+
+	// Statements
+	case *DefStatement:
+		return node.Token
+	case *AssignStatement:
+		return node.Token
+	case *ReturnStatement:
+		return node.Token
+	case *ExpressionStatement:
+		return node.Token
+	case *BlockStatement:
+		return node.Token
+	case *ForLoopStatement:
+		return node.Token
+	case *ContinueStatement:
+		return node.Token
+	case *BreakStatement:
+		return node.Token
+	case *ThrowStatement:
+		return node.Token
+
+	// Literals
+	case *NullLiteral:
+		return node.Token
+	case *IntegerLiteral:
+		return node.Token
+	case *FloatLiteral:
+		return node.Token
+	case *StringLiteral:
+		return node.Token
+	case *InterpolatedString:
+		return node.Token
+	case *Boolean:
+		return node.Token
+	case *FunctionLiteral:
+		return node.Token
+	case *Array:
+		return node.Token
+	case *HashLiteral:
+		return node.Token
+	case *ClassLiteral:
+		return node.Token
+
+	// Expressions
+	case *Identifier:
+		return node.Token
+	case *PrefixExpression:
+		return node.Token
+	case *InfixExpression:
+		return node.Token
+	case *CompareExpression:
+		return node.Token
+	case *IfExpression:
+		return node.Token
+	case *CallExpression:
+		return node.Token
+	case *IndexExpression:
+		return node.Token
+	case *SliceExpression:
+		return node.Token
+	case *TryCatchExpression:
+		return node.Token
+	case *MakeInstance:
+		return node.Token
+	}
+	return token.Token{}
+}
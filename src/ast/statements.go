@@ -144,10 +144,21 @@ func (bs *BlockStatement) String() string {
 }
 
 type ForLoopStatement struct {
+	Token     token.Token // The 'for' token
 	Init      *DefStatement
 	Condition Expression
 	Iter      Expression
 	Body      *BlockStatement
+
+	// For-in loops set Key, Value, and Collection instead of Init, Condition, and Iter.
+	// Key is nil if the loop only has a value variable.
+	Key        *Identifier
+	Value      *Identifier
+	Collection Expression
+
+	// HasClosure is set if a function is defined in the loop. Each iteration
+	// then gets its own copy of the loop variable for the closures to keep.
+	HasClosure bool
 }
 
 func (fl *ForLoopStatement) statementNode()       {}
@@ -155,30 +166,45 @@ func (fl *ForLoopStatement) TokenLiteral() string { return "for" }
 func (fl *ForLoopStatement) String() string {
 	var out bytes.Buffer
 	out.WriteString("for (")
-	out.WriteString(fl.Init.String())
-	out.WriteString("; ")
-	out.WriteString(fl.Condition.String())
-	out.WriteString("; ")
-	out.WriteString(fl.Iter.String())
+	if fl.Collection != nil {
+		if fl.Key != nil {
+			out.WriteString(fl.Key.String())
+			out.WriteString(", ")
+		}
+		out.WriteString(fl.Value.String())
+		out.WriteString(" in ")
+		out.WriteString(fl.Collection.String())
+	} else if fl.Init != nil {
+		out.WriteString(fl.Init.String())
+		out.WriteString("; ")
+		out.WriteString(fl.Condition.String())
+		out.WriteString("; ")
+		out.WriteString(fl.Iter.String())
+	}
 	out.WriteString(") {")
 	out.WriteString(fl.Body.String())
 	out.WriteByte('}')
 	return out.String()
 }
 
-type ContinueStatement struct{}
+type ContinueStatement struct {
+	Token token.Token
+}
 
 func (c *ContinueStatement) statementNode()       {}
 func (c *ContinueStatement) TokenLiteral() string { return "continue" }
 func (c *ContinueStatement) String() string       { return "continue" }
 
-type BreakStatement struct{}
+type BreakStatement struct {
+	Token token.Token
+}
 
 func (b *BreakStatement) statementNode()       {}
 func (b *BreakStatement) TokenLiteral() string { return "break" }
 func (b *BreakStatement) String() string       { return "break" }
 
 type ThrowStatement struct {
+	Token      token.Token
 	Expression Expression
 }
 
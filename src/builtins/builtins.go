@@ -5,7 +5,12 @@ import (
 	// and separation of concerns.
 	_ "github.com/nitrogen-lang/nitrogen/src/builtins/classes"
 	_ "github.com/nitrogen-lang/nitrogen/src/builtins/collections"
+	_ "github.com/nitrogen-lang/nitrogen/src/builtins/crypto"
+	_ "github.com/nitrogen-lang/nitrogen/src/builtins/encoding"
 	_ "github.com/nitrogen-lang/nitrogen/src/builtins/imports"
 	_ "github.com/nitrogen-lang/nitrogen/src/builtins/io"
+	_ "github.com/nitrogen-lang/nitrogen/src/builtins/json"
+	_ "github.com/nitrogen-lang/nitrogen/src/builtins/math"
+	_ "github.com/nitrogen-lang/nitrogen/src/builtins/time"
 	_ "github.com/nitrogen-lang/nitrogen/src/builtins/typing"
 )
@@ -28,7 +28,7 @@ func instanceOf(interpreter object.Interpreter, env *object.Environment, args ..
 		return object.NativeBoolToBooleanObj(object.InstanceOf(class.Name, instance))
 	}
 
-	return object.NewException("is_a expected a class or string for second argument")
+	return object.NewTypeError("is_a expected a class or string for second argument")
 }
 
 func classOf(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
@@ -0,0 +1,241 @@
+package collections
+
+import (
+	"github.com/nitrogen-lang/nitrogen/src/eval"
+	"github.com/nitrogen-lang/nitrogen/src/moduleutils"
+	"github.com/nitrogen-lang/nitrogen/src/object"
+)
+
+func popBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("pop", 1, args...); ac != nil {
+		return ac
+	}
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return object.NewTypeError("Argument to `pop` must be ARRAY, got %s", args[0].Type())
+	}
+
+	length := len(arr.Elements)
+	if length == 0 {
+		return object.NullConst
+	}
+
+	last := arr.Elements[length-1]
+	arr.Elements[length-1] = nil
+	arr.Elements = arr.Elements[:length-1]
+	return last
+}
+
+func shiftBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("shift", 1, args...); ac != nil {
+		return ac
+	}
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return object.NewTypeError("Argument to `shift` must be ARRAY, got %s", args[0].Type())
+	}
+
+	if len(arr.Elements) == 0 {
+		return object.NullConst
+	}
+
+	first := arr.Elements[0]
+	arr.Elements = removeElements(arr.Elements, 0, 1)
+	return first
+}
+
+func unshiftBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("unshift", 2, args...); ac != nil {
+		return ac
+	}
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return object.NewTypeError("Argument to `unshift` must be ARRAY, got %s", args[0].Type())
+	}
+
+	arr.Elements = insertElements(arr.Elements, 0, args[1:])
+	return arr
+}
+
+func insertBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("insert", 3, args...); ac != nil {
+		return ac
+	}
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return object.NewTypeError("Argument to `insert` must be ARRAY, got %s", args[0].Type())
+	}
+	index, ok := args[1].(*object.Integer)
+	if !ok {
+		return object.NewTypeError("Index to `insert` must be INTEGER, got %s", args[1].Type())
+	}
+
+	// Inserting at the length of the array appends the value
+	i := index.Value
+	if i < 0 {
+		i += int64(len(arr.Elements))
+	}
+	if i < 0 || i > int64(len(arr.Elements)) {
+		return object.NewIndexError("Index out of bounds: %d", index.Value)
+	}
+
+	arr.Elements = insertElements(arr.Elements, int(i), args[2:])
+	return arr
+}
+
+func spliceBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckMinArgs("splice", 3, args...); ac != nil {
+		return ac
+	}
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return object.NewTypeError("Argument to `splice` must be ARRAY, got %s", args[0].Type())
+	}
+	start, ok := args[1].(*object.Integer)
+	if !ok {
+		return object.NewTypeError("Start of `splice` must be INTEGER, got %s", args[1].Type())
+	}
+	count, ok := args[2].(*object.Integer)
+	if !ok {
+		return object.NewTypeError("Count of `splice` must be INTEGER, got %s", args[2].Type())
+	}
+	if count.Value < 0 {
+		return object.NewArgumentError("Count of `splice` must not be negative")
+	}
+
+	low, _ := eval.SliceBounds(len(arr.Elements), start.Value, 0)
+	high := len(arr.Elements)
+	if count.Value < int64(high-low) {
+		high = low + int(count.Value)
+	}
+
+	removed := make([]object.Object, high-low)
+	copy(removed, arr.Elements[low:high])
+
+	arr.Elements = removeElements(arr.Elements, low, high-low)
+	arr.Elements = insertElements(arr.Elements, low, args[3:])
+	return &object.Array{Elements: removed}
+}
+
+func sliceBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckMinArgs("slice", 2, args...); ac != nil {
+		return ac
+	}
+	if len(args) > 3 {
+		return object.NewArgumentError("slice expects at most 3 argument(s). Got %d", len(args))
+	}
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return object.NewTypeError("Argument to `slice` must be ARRAY, got %s", args[0].Type())
+	}
+	start, ok := args[1].(*object.Integer)
+	if !ok {
+		return object.NewTypeError("Start of `slice` must be INTEGER, got %s", args[1].Type())
+	}
+
+	end := int64(len(arr.Elements))
+	if len(args) == 3 {
+		endObj, ok := args[2].(*object.Integer)
+		if !ok {
+			return object.NewTypeError("End of `slice` must be INTEGER, got %s", args[2].Type())
+		}
+		end = endObj.Value
+	}
+
+	return eval.SliceOperation(arr, start, &object.Integer{Value: end})
+}
+
+func indexOfBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("indexOf", 2, args...); ac != nil {
+		return ac
+	}
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return object.NewTypeError("Argument to `indexOf` must be ARRAY, got %s", args[0].Type())
+	}
+
+	return &object.Integer{Value: int64(indexOf(arr.Elements, args[1]))}
+}
+
+func containsBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("contains", 2, args...); ac != nil {
+		return ac
+	}
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return object.NewTypeError("Argument to `contains` must be ARRAY, got %s", args[0].Type())
+	}
+
+	return object.NativeBoolToBooleanObj(indexOf(arr.Elements, args[1]) > -1)
+}
+
+func reverseBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("reverse", 1, args...); ac != nil {
+		return ac
+	}
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return object.NewTypeError("Argument to `reverse` must be ARRAY, got %s", args[0].Type())
+	}
+
+	length := len(arr.Elements)
+	newElements := make([]object.Object, length)
+	for i, e := range arr.Elements {
+		newElements[length-1-i] = e
+	}
+	return &object.Array{Elements: newElements}
+}
+
+func uniqueBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("unique", 1, args...); ac != nil {
+		return ac
+	}
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return object.NewTypeError("Argument to `unique` must be ARRAY, got %s", args[0].Type())
+	}
+
+	seen := object.NewHash(len(arr.Elements))
+	newElements := make([]object.Object, 0, len(arr.Elements))
+	for _, e := range arr.Elements {
+		// Hashable elements are checked in constant time, others are compared to each kept element
+		if key, ok := e.(object.Hashable); ok {
+			if seen.Has(key) {
+				continue
+			}
+			seen.Set(key, object.NullConst)
+		} else if indexOf(newElements, e) > -1 {
+			continue
+		}
+		newElements = append(newElements, e)
+	}
+	return &object.Array{Elements: newElements}
+}
+
+func insertElements(elements []object.Object, i int, values []object.Object) []object.Object {
+	if len(values) == 0 {
+		return elements
+	}
+	elements = append(elements, values...)
+	copy(elements[i+len(values):], elements[i:])
+	copy(elements[i:], values)
+	return elements
+}
+
+func removeElements(elements []object.Object, i, count int) []object.Object {
+	length := len(elements)
+	copy(elements[i:], elements[i+count:])
+	for j := length - count; j < length; j++ {
+		elements[j] = nil
+	}
+	return elements[:length-count]
+}
+
+func indexOf(elements []object.Object, val object.Object) int {
+	for i, e := range elements {
+		if eval.ObjectsEqual(e, val) {
+			return i
+		}
+	}
+	return -1
+}
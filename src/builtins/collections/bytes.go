@@ -0,0 +1,93 @@
+package collections
+
+import (
+	"github.com/nitrogen-lang/nitrogen/src/eval"
+	"github.com/nitrogen-lang/nitrogen/src/moduleutils"
+	"github.com/nitrogen-lang/nitrogen/src/object"
+)
+
+// Strings are indexed, sliced, and iterated by code point. These functions
+// work with the bytes of a string instead.
+
+func init() {
+	eval.RegisterBuiltin("bytes", bytesBuiltin)
+	eval.RegisterBuiltin("fromBytes", fromBytesBuiltin)
+	eval.RegisterBuiltin("byteLen", byteLenBuiltin)
+	eval.RegisterBuiltin("byteSlice", byteSliceBuiltin)
+}
+
+func bytesBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("bytes", 1, args...); ac != nil {
+		return ac
+	}
+	str, ok := args[0].(*object.String)
+	if !ok {
+		return object.NewTypeError("Argument to `bytes` must be STRING, got %s", args[0].Type())
+	}
+
+	elements := make([]object.Object, len(str.Value))
+	for i := 0; i < len(str.Value); i++ {
+		elements[i] = &object.Integer{Value: int64(str.Value[i])}
+	}
+	return &object.Array{Elements: elements}
+}
+
+func fromBytesBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("fromBytes", 1, args...); ac != nil {
+		return ac
+	}
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return object.NewTypeError("Argument to `fromBytes` must be ARRAY, got %s", args[0].Type())
+	}
+
+	buf := make([]byte, len(arr.Elements))
+	for i, e := range arr.Elements {
+		b, ok := e.(*object.Integer)
+		if !ok || b.Value < 0 || b.Value > 255 {
+			return object.NewTypeError("Elements of `fromBytes` must be INTEGERs from 0 to 255, got %s", e.Inspect())
+		}
+		buf[i] = byte(b.Value)
+	}
+	return &object.String{Value: string(buf)}
+}
+
+func byteLenBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("byteLen", 1, args...); ac != nil {
+		return ac
+	}
+	str, ok := args[0].(*object.String)
+	if !ok {
+		return object.NewTypeError("Argument to `byteLen` must be STRING, got %s", args[0].Type())
+	}
+	return &object.Integer{Value: int64(len(str.Value))}
+}
+
+func byteSliceBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckMinArgs("byteSlice", 2, args...); ac != nil {
+		return ac
+	}
+	if len(args) > 3 {
+		return object.NewArgumentError("byteSlice expects at most 3 argument(s). Got %d", len(args))
+	}
+	str, ok := args[0].(*object.String)
+	if !ok {
+		return object.NewTypeError("Argument to `byteSlice` must be STRING, got %s", args[0].Type())
+	}
+	start, ok := args[1].(*object.Integer)
+	if !ok {
+		return object.NewTypeError("Start of `byteSlice` must be INTEGER, got %s", args[1].Type())
+	}
+
+	end := int64(len(str.Value))
+	if len(args) == 3 {
+		endObj, ok := args[2].(*object.Integer)
+		if !ok {
+			return object.NewTypeError("End of `byteSlice` must be INTEGER, got %s", args[2].Type())
+		}
+		end = endObj.Value
+	}
+
+	low, high := eval.SliceBounds(len(str.Value), start.Value, end)
+	return &object.String{Value: str.Value[low:high]}
+}
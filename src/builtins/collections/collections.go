@@ -1,7 +1,7 @@
 package collections
 
 import (
-	"sort"
+	"unicode/utf8"
 
 	"github.com/nitrogen-lang/nitrogen/src/eval"
 	"github.com/nitrogen-lang/nitrogen/src/moduleutils"
@@ -15,18 +15,42 @@ func init() {
 	eval.RegisterBuiltin("rest", restBuiltin)
 	eval.RegisterBuiltin("push", pushBuiltin)
 	eval.RegisterBuiltin("sort", sortArrayBuiltin)
+	eval.RegisterBuiltin("sortBy", sortByBuiltin)
 	eval.RegisterBuiltin("hashMerge", hashMergeBuiltin)
 	eval.RegisterBuiltin("hashKeys", hashKeysBuiltin)
+	eval.RegisterBuiltin("hashValues", hashValuesBuiltin)
+	eval.RegisterBuiltin("hashHasKey", hashHasKeyBuiltin)
+	eval.RegisterBuiltin("hashDelete", hashDeleteBuiltin)
+
+	eval.RegisterBuiltin("pop", popBuiltin)
+	eval.RegisterBuiltin("shift", shiftBuiltin)
+	eval.RegisterBuiltin("unshift", unshiftBuiltin)
+	eval.RegisterBuiltin("insert", insertBuiltin)
+	eval.RegisterBuiltin("splice", spliceBuiltin)
+	eval.RegisterBuiltin("slice", sliceBuiltin)
+	eval.RegisterBuiltin("indexOf", indexOfBuiltin)
+	eval.RegisterBuiltin("contains", containsBuiltin)
+	eval.RegisterBuiltin("reverse", reverseBuiltin)
+	eval.RegisterBuiltin("unique", uniqueBuiltin)
+
+	eval.RegisterBuiltin("map", mapBuiltin)
+	eval.RegisterBuiltin("filter", filterBuiltin)
+	eval.RegisterBuiltin("reduce", reduceBuiltin)
+	eval.RegisterBuiltin("any", anyBuiltin)
+	eval.RegisterBuiltin("all", allBuiltin)
+	eval.RegisterBuiltin("zip", zipBuiltin)
+	eval.RegisterBuiltin("enumerate", enumerateBuiltin)
+	eval.RegisterBuiltin("range", rangeBuiltin)
 }
 
 func lenBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
 	if len(args) != 1 {
-		return object.NewException("Incorrect number of arguments. Got %d, expected 1", len(args))
+		return object.NewArgumentError("Incorrect number of arguments. Got %d, expected 1", len(args))
 	}
 
 	switch arg := args[0].(type) {
 	case *object.String:
-		return &object.Integer{Value: int64(len(arg.Value))}
+		return &object.Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
 	case *object.Array:
 		return &object.Integer{Value: int64(len(arg.Elements))}
 	case *object.Null:
@@ -38,10 +62,10 @@ func lenBuiltin(interpreter object.Interpreter, env *object.Environment, args ..
 
 func firstBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
 	if len(args) != 1 {
-		return object.NewException("Incorrect number of arguments. Got %d, expected 1", len(args))
+		return object.NewArgumentError("Incorrect number of arguments. Got %d, expected 1", len(args))
 	}
 	if args[0].Type() != object.ArrayObj {
-		return object.NewException("Argument to `first` must be ARRAY, got %s", args[0].Type())
+		return object.NewTypeError("Argument to `first` must be ARRAY, got %s", args[0].Type())
 	}
 
 	arr := args[0].(*object.Array)
@@ -54,10 +78,10 @@ func firstBuiltin(interpreter object.Interpreter, env *object.Environment, args
 
 func lastBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
 	if len(args) != 1 {
-		return object.NewException("Incorrect number of arguments. Got %d, expected 1", len(args))
+		return object.NewArgumentError("Incorrect number of arguments. Got %d, expected 1", len(args))
 	}
 	if args[0].Type() != object.ArrayObj {
-		return object.NewException("Argument to `last` must be ARRAY, got %s", args[0].Type())
+		return object.NewTypeError("Argument to `last` must be ARRAY, got %s", args[0].Type())
 	}
 
 	arr := args[0].(*object.Array)
@@ -71,10 +95,10 @@ func lastBuiltin(interpreter object.Interpreter, env *object.Environment, args .
 
 func restBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
 	if len(args) != 1 {
-		return object.NewException("Incorrect number of arguments. Got %d, expected 1", len(args))
+		return object.NewArgumentError("Incorrect number of arguments. Got %d, expected 1", len(args))
 	}
 	if args[0].Type() != object.ArrayObj {
-		return object.NewException("Argument to `rest` must be ARRAY, got %s", args[0].Type())
+		return object.NewTypeError("Argument to `rest` must be ARRAY, got %s", args[0].Type())
 	}
 
 	arr := args[0].(*object.Array)
@@ -90,56 +114,25 @@ func restBuiltin(interpreter object.Interpreter, env *object.Environment, args .
 
 func pushBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
 	if len(args) != 2 {
-		return object.NewException("Incorrect number of arguments. Got %d, expected 2", len(args))
+		return object.NewArgumentError("Incorrect number of arguments. Got %d, expected 2", len(args))
 	}
 	if args[0].Type() != object.ArrayObj {
-		return object.NewException("Argument to `push` must be ARRAY, got %s", args[0].Type())
+		return object.NewTypeError("Argument to `push` must be ARRAY, got %s", args[0].Type())
 	}
 
+	// The array is changed in place so building an array with push doesn't copy it each time
 	arr := args[0].(*object.Array)
-	length := len(arr.Elements)
-	newElements := make([]object.Object, length+1, length+1)
-	copy(newElements, arr.Elements)
-	newElements[length] = args[1]
-
-	return &object.Array{Elements: newElements}
-}
-
-func sortArrayBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
-	if ac := moduleutils.CheckMinArgs("sort", 1, args...); ac != nil {
-		return ac
-	}
-
-	arr, ok := args[0].(*object.Array)
-	if !ok {
-		return object.NewException("Argument to `sort` must be ARRAY, got %s", args[0].Type())
-	}
-
-	sorter := &arraySorter{arr.Dup().(*object.Array)}
-	sort.Sort(sorter)
-
-	return sorter.a
-}
-
-type arraySorter struct {
-	a *object.Array
-}
-
-func (s *arraySorter) Len() int { return len(s.a.Elements) }
-func (s *arraySorter) Less(i, j int) bool {
-	return s.a.Elements[i].(*object.String).Value < s.a.Elements[j].(*object.String).Value
-}
-func (s *arraySorter) Swap(i, j int) {
-	s.a.Elements[i], s.a.Elements[j] = s.a.Elements[j], s.a.Elements[i]
+	arr.Elements = append(arr.Elements, args[1])
+	return arr
 }
 
 func hashMergeBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
 	if len(args) < 2 {
-		return object.NewException("hashMerge requires at least 2 arguments. Got %d", len(args))
+		return object.NewArgumentError("hashMerge requires at least 2 arguments. Got %d", len(args))
 	}
 
 	if !object.ObjectsAre(object.HashObj, args[:2]...) {
-		return object.NewException("First two arguments must be maps")
+		return object.NewTypeError("First two arguments must be maps")
 	}
 
 	overwrite := true
@@ -149,17 +142,17 @@ func hashMergeBuiltin(interpreter object.Interpreter, env *object.Environment, a
 		}
 	}
 
-	newMap := &object.Hash{
-		Pairs: make(map[object.HashKey]object.HashPair),
-	}
+	first := args[0].(*object.Hash)
+	newMap := object.NewHash(first.Len())
 
-	for k, v := range args[0].(*object.Hash).Pairs {
-		newMap.Pairs[k] = v
+	for _, pair := range first.Pairs() {
+		newMap.Set(pair.Key.(object.Hashable), pair.Value)
 	}
 
-	for k, v := range args[1].(*object.Hash).Pairs {
-		if _, exists := newMap.Pairs[k]; !exists || (exists && overwrite) {
-			newMap.Pairs[k] = v
+	for _, pair := range args[1].(*object.Hash).Pairs() {
+		key := pair.Key.(object.Hashable)
+		if overwrite || !newMap.Has(key) {
+			newMap.Set(key, pair.Value)
 		}
 	}
 
@@ -176,13 +169,5 @@ func hashKeysBuiltin(interpreter object.Interpreter, env *object.Environment, ar
 		return object.NewException("hashKeys expects a hash map")
 	}
 
-	arr := &object.Array{
-		Elements: make([]object.Object, 0, len(hash.Pairs)),
-	}
-
-	for _, pair := range hash.Pairs {
-		arr.Elements = append(arr.Elements, pair.Key)
-	}
-
-	return arr
+	return &object.Array{Elements: hash.Keys()}
 }
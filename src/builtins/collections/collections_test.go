@@ -15,6 +15,8 @@ func TestBuiltinLenFunction(t *testing.T) {
 		{`len("")`, 0},
 		{`len("four")`, 4},
 		{`len("hello world")`, 11},
+		{`len("héllo")`, 5},
+		{`len("日本")`, 2},
 		{`len(1)`, "Unsupported type INTEGER"},
 		{`len("one", "two")`, "Incorrect number of arguments. Got 2, expected 1"},
 		{`len([1, 2, 3])`, 3},
@@ -109,6 +111,7 @@ func TestBuiltinPushFunction(t *testing.T) {
 		{`push([1], 2)`, `[1, 2]`},
 		{`push([1, 2, 3], 4)`, `[1, 2, 3, 4]`},
 		{`push([], 1)`, `[1]`},
+		{`let a = [1]; push(a, 2); push(a, 3); a`, `[1, 2, 3]`},
 		{`push("four", "five")`, "Argument to `push` must be ARRAY, got STRING"},
 		{`push()`, "Incorrect number of arguments. Got 0, expected 2"},
 		{`push([1])`, "Incorrect number of arguments. Got 1, expected 2"},
@@ -180,20 +183,215 @@ func TestBuiltinHashMergeSpecial(t *testing.T) {
 		t.Fatalf("Got error during hashMerge: %#v", evaled)
 	}
 
-	expected := map[object.HashKey]string{
-		(&object.String{Value: "key"}).HashKey():  "value",
-		(&object.String{Value: "key2"}).HashKey(): "value2",
+	expected := map[string]string{
+		"key":  "value",
+		"key2": "value2",
 	}
 
 	for k, v := range expected {
-		val, exists := hashObj.Pairs[k]
+		val, exists := hashObj.Get(&object.String{Value: k})
 		if !exists {
-			t.Fatalf("Map missing key %q", k)
+			t.Fatalf("Map missing key %v", k)
 		}
 
-		valStr := val.Value.(*object.String).Value
+		valStr := val.(*object.String).Value
 		if valStr != v {
-			t.Fatalf("Incorrect map value for key %q: %q", k, v)
+			t.Fatalf("Incorrect map value for key %v: %q", k, v)
+		}
+	}
+}
+
+func TestBuiltinArrayFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let a = [1, 2, 3]; [pop(a), a]`, `[3, [1, 2]]`},
+		{`pop([])`, `nil`},
+		{`pop("a")`, "Argument to `pop` must be ARRAY, got STRING"},
+		{`let a = [1, 2, 3]; [shift(a), a]`, `[1, [2, 3]]`},
+		{`shift([])`, `nil`},
+		{`let a = [2]; unshift(a, 1); a`, `[1, 2]`},
+		{`unshift([])`, "unshift expects 2 argument(s). Got 1"},
+		{`let a = [1, 3]; insert(a, 1, 2); a`, `[1, 2, 3]`},
+		{`insert([1, 2], 2, 3)`, `[1, 2, 3]`},
+		{`insert([1, 2], -1, 3)`, `[1, 3, 2]`},
+		{`insert([1, 2], 3, 3)`, "Index out of bounds: 3"},
+		{`insert([1, 2], "0", 3)`, "Index to `insert` must be INTEGER, got STRING"},
+		{`let a = [1, 2, 3, 4]; [splice(a, 1, 2), a]`, `[[2, 3], [1, 4]]`},
+		{`let a = [1, 2, 3, 4]; [splice(a, -1, 5, 5, 6), a]`, `[[4], [1, 2, 3, 5, 6]]`},
+		{`let a = [1, 4]; [splice(a, 1, 0, 2, 3), a]`, `[[], [1, 2, 3, 4]]`},
+		{`splice([1], 0, -1)`, "Count of `splice` must not be negative"},
+		{`let a = [1, 2, 3, 4]; [slice(a, 1, 3), a]`, `[[2, 3], [1, 2, 3, 4]]`},
+		{`slice([1, 2, 3, 4], 1)`, `[2, 3, 4]`},
+		{`slice([1, 2, 3, 4], -2)`, `[3, 4]`},
+		{`slice([1, 2, 3, 4], 0, -1)`, `[1, 2, 3]`},
+		{`slice([1, 2, 3, 4], -10, 10)`, `[1, 2, 3, 4]`},
+		{`slice([1, 2, 3, 4], 3, 1)`, `[]`},
+		{`slice([1, 2], 0, 1, 2)`, "slice expects at most 3 argument(s). Got 4"},
+		{`indexOf([1, "2", 3], "2")`, `1`},
+		{`indexOf([1, 2, 3], "2")`, `-1`},
+		{`indexOf([[1], [2]], [2])`, `1`},
+		{`contains([1, 2.5, nil], 2.5)`, `true`},
+		{`contains([1, 2.5, nil], nil)`, `true`},
+		{`contains([1, 2], 3)`, `false`},
+		{`contains({}, 3)`, "Argument to `contains` must be ARRAY, got MAP"},
+		{`let a = [1, 2, 3]; [reverse(a), a]`, `[[3, 2, 1], [1, 2, 3]]`},
+		{`unique([1, 2, 1, "1", [1], [1], 2])`, `[1, 2, "1", [1]]`},
+	}
+
+	for _, tt := range tests {
+		got := moduleutils.TestEval(tt.input)
+		if got.Inspect() != tt.expected {
+			t.Errorf("Incorrect result for %s. Expected=%s, got=%s", tt.input, tt.expected, got.Inspect())
+		}
+	}
+}
+
+func TestBuiltinByteFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`bytes("hé")`, `[104, 195, 169]`},
+		{`bytes("")`, `[]`},
+		{`bytes(1)`, "Argument to `bytes` must be STRING, got INTEGER"},
+		{`fromBytes([104, 195, 169])`, `hé`},
+		{`fromBytes([256])`, "Elements of `fromBytes` must be INTEGERs from 0 to 255, got 256"},
+		{`byteLen("héllo")`, `6`},
+		{`byteSlice("héllo", 1, 3)`, `é`},
+		{`byteSlice("héllo", -3)`, `llo`},
+		{`byteSlice("héllo", 0, 1, 2)`, "byteSlice expects at most 3 argument(s). Got 4"},
+		{`"héllo"[1]`, `é`},
+		{`"héllo"[-4:3]`, `él`},
+	}
+
+	for _, tt := range tests {
+		got := moduleutils.TestEval(tt.input)
+		if got.Inspect() != tt.expected {
+			t.Errorf("Incorrect result for %s. Expected=%s, got=%s", tt.input, tt.expected, got.Inspect())
+		}
+	}
+}
+
+func TestBuiltinMapFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`hashValues({"a": 1, "b": 2})`, `[1, 2]`},
+		{`hashValues([])`, "Argument to `hashValues` must be MAP, got ARRAY"},
+		{`hashHasKey({"a": nil}, "a")`, `true`},
+		{`hashHasKey({"a": nil}, "b")`, `false`},
+		{`hashHasKey({"a": nil}, [])`, `Invalid map key: ARRAY`},
+		{`let m = {"a": 1, "b": 2, "c": 3}; [hashDelete(m, "b"), m]`, `[true, {a: 1, c: 3}]`},
+		{`let m = {"a": 1}; [hashDelete(m, "b"), m]`, `[false, {a: 1}]`},
+		{`hashDelete({"a": 1})`, "hashDelete expects 2 argument(s). Got 1"},
+	}
+
+	for _, tt := range tests {
+		got := moduleutils.TestEval(tt.input)
+		if got.Inspect() != tt.expected {
+			t.Errorf("Incorrect result for %s. Expected=%s, got=%s", tt.input, tt.expected, got.Inspect())
+		}
+	}
+}
+
+func TestBuiltinSortFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`sort(["b", "c", "a"])`, `["a", "b", "c"]`},
+		{`sort([3, 1, 2])`, `[1, 2, 3]`},
+		{`sort([3, 1.5, -2, 0.25])`, `[-2, 0.25, 1.5, 3]`},
+		{`sort([true, false, true])`, `[false, true, true]`},
+		{`sort([[2, 1], [1, 2, 3], [1, 2]])`, `[[1, 2], [1, 2, 3], [2, 1]]`},
+		{`let a = [3, 1, 2]; sort(a); a`, `[3, 1, 2]`},
+		{`sort([])`, `[]`},
+		{`sort([1, 2, 3], func(a, b) { b - a })`, `[3, 2, 1]`},
+		{`sort([[1, "a"], [0, "b"], [1, "c"], [0, "d"]], func(a, b) { a[0] - b[0] })`, `[[0, "b"], [0, "d"], [1, "a"], [1, "c"]]`},
+		{`sort([1, "a"])`, `Can't compare STRING and INTEGER`},
+		{`sort([func() {}, func() {}])`, `Can't compare FUNCTION and FUNCTION`},
+		{`sort([1, 2], func(a, b) { "no" })`, `Comparator must return INTEGER, got STRING`},
+		{`sort([1, 2], func(a, b) { throw "bad" })`, `bad`},
+		{`sort([1, 2], 3)`, "Comparator of `sort` must be a function, got INTEGER"},
+		{`sort("abc")`, "Argument to `sort` must be ARRAY, got STRING"},
+		{`try { sort([1, nil]) } catch e: TypeError { "caught" }`, `caught`},
+		{`
+class Version {
+    let major
+    func init(major) { this.major = major }
+    func compare(other) { major - other.major }
+}
+let vs = sort([make Version(3), make Version(1), make Version(2)])
+[vs[0].major, vs[1].major, vs[2].major]`, `[1, 2, 3]`},
+		{`class A {}; sort([make A(), make A()])`, `Can't compare instance of A, it has no compare method`},
+		{`sortBy(["ccc", "a", "bb"], len)`, `["a", "bb", "ccc"]`},
+		{`sortBy([[1, "a"], [0, "b"], [1, "c"], [0, "d"]], func(p) { p[0] })`, `[[0, "b"], [0, "d"], [1, "a"], [1, "c"]]`},
+		{`sortBy([1, 2], func(x) { throw "bad key" })`, `bad key`},
+		{`sortBy([1, 2])`, `sortBy expects 2 argument(s). Got 1`},
+	}
+
+	for _, tt := range tests {
+		got := moduleutils.TestEval(tt.input)
+		if got.Inspect() != tt.expected {
+			t.Errorf("Incorrect result for %s. Expected=%s, got=%s", tt.input, tt.expected, got.Inspect())
+		}
+	}
+}
+
+func TestBuiltinFunctionalFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`map([1, 2, 3], func(x) { x * 2 })`, `[2, 4, 6]`},
+		{`map(["a", "bc"], len)`, `[1, 2]`},
+		{`map([], func(x) { x })`, `[]`},
+		{`map([1, 2], func(x) { throw "bad" })`, `bad`},
+		{`map([1, 2], 1)`, "Second argument to `map` must be a function, got INTEGER"},
+		{`map({}, len)`, "Argument to `map` must be ARRAY, got MAP"},
+		{`class A { let n = 2; func times(x) { x * n } }; let a = make A(); map([1, 2], a.times)`, `[2, 4]`},
+		{`filter([1, 2, 3, 4], func(x) { x % 2 == 0 })`, `[2, 4]`},
+		{`filter([1, 2], func(x) { nil })`, `[]`},
+		{`reduce([1, 2, 3], func(acc, x) { acc + x })`, `6`},
+		{`reduce([1, 2, 3], func(acc, x) { acc + x }, 10)`, `16`},
+		{`reduce([], func(acc, x) { acc + x }, 10)`, `10`},
+		{`reduce([], func(acc, x) { acc + x })`, `reduce of an empty array needs an initial value`},
+		{`reduce([1, 2], func(acc, x) { throw "bad" })`, `bad`},
+		{`any([0, nil, 3])`, `true`},
+		{`any([])`, `false`},
+		{`any([1, 2, 3], func(x) { x > 2 })`, `true`},
+		{`any([1, 2, 3], func(x) { x > 3 })`, `false`},
+		{`let n = 0; any([1, 2, 3], func(x) { n += 1; x > 1 }); n`, `2`},
+		{`all([1, "a", true])`, `true`},
+		{`all([])`, `true`},
+		{`all([1, 2, 3], func(x) { x < 3 })`, `false`},
+		{`all([1], func(x) { throw "bad" })`, `bad`},
+		{`zip([1, 2, 3], ["a", "b"])`, `[[1, "a"], [2, "b"]]`},
+		{`zip([1, 2])`, `[[1], [2]]`},
+		{`zip([1], "a")`, "Arguments to `zip` must be ARRAY, got STRING"},
+		{`enumerate(["a", "b"])`, `[[0, "a"], [1, "b"]]`},
+		{`range(3)`, `[0, 1, 2]`},
+		{`range(2, 5)`, `[2, 3, 4]`},
+		{`range(0, 10, 3)`, `[0, 3, 6, 9]`},
+		{`range(5, 0, -2)`, `[5, 3, 1]`},
+		{`range(5, 0)`, `[]`},
+		{`range(-2)`, `[]`},
+		{`range(0, 5, 0)`, "Step of `range` must not be 0"},
+		{`range(1.5)`, "Arguments to `range` must be INTEGER, got FLOAT"},
+		{`range(9223372036854775805, 9223372036854775807, 4)`, `[9223372036854775805]`},
+		{`range(-9223372036854775807, -9223372036854775808, -9223372036854775807)`, `[-9223372036854775807]`},
+		{`range(9223372036854775807, -9223372036854775807, -9223372036854775807)`, `[9223372036854775807, 0]`},
+		{`range(0, 9223372036854775807, 2)`, "Range of 4611686018427387904 elements is too large, `range` makes at most 67108864"},
+		{`range(-9223372036854775807, 9223372036854775807)`, "Range of 18446744073709551614 elements is too large, `range` makes at most 67108864"},
+	}
+
+	for _, tt := range tests {
+		got := moduleutils.TestEval(tt.input)
+		if got.Inspect() != tt.expected {
+			t.Errorf("Incorrect result for %s. Expected=%s, got=%s", tt.input, tt.expected, got.Inspect())
 		}
 	}
 }
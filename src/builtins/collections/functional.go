@@ -0,0 +1,225 @@
+package collections
+
+import (
+	"github.com/nitrogen-lang/nitrogen/src/eval"
+	"github.com/nitrogen-lang/nitrogen/src/moduleutils"
+	"github.com/nitrogen-lang/nitrogen/src/object"
+)
+
+// checkArrayAndFunc checks the common arguments of functions that take an array and a callback.
+func checkArrayAndFunc(name string, args []object.Object) (*object.Array, object.Object) {
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return nil, object.NewTypeError("Argument to `%s` must be ARRAY, got %s", name, args[0].Type())
+	}
+	if len(args) > 1 && !isCallable(args[1]) {
+		return nil, object.NewTypeError("Second argument to `%s` must be a function, got %s", name, args[1].Type())
+	}
+	return arr, nil
+}
+
+func mapBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("map", 2, args...); ac != nil {
+		return ac
+	}
+	arr, err := checkArrayAndFunc("map", args)
+	if err != nil {
+		return err
+	}
+
+	newElements := make([]object.Object, len(arr.Elements))
+	for i, e := range arr.Elements {
+		result := interpreter.Call(args[1], []object.Object{e})
+		if isRaised(result) {
+			return result
+		}
+		newElements[i] = result
+	}
+	return &object.Array{Elements: newElements}
+}
+
+func filterBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("filter", 2, args...); ac != nil {
+		return ac
+	}
+	arr, err := checkArrayAndFunc("filter", args)
+	if err != nil {
+		return err
+	}
+
+	newElements := make([]object.Object, 0, len(arr.Elements))
+	for _, e := range arr.Elements {
+		result := interpreter.Call(args[1], []object.Object{e})
+		if isRaised(result) {
+			return result
+		}
+		if eval.IsTruthy(result) {
+			newElements = append(newElements, e)
+		}
+	}
+	return &object.Array{Elements: newElements}
+}
+
+func reduceBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckMinArgs("reduce", 2, args...); ac != nil {
+		return ac
+	}
+	if len(args) > 3 {
+		return object.NewArgumentError("reduce expects at most 3 argument(s). Got %d", len(args))
+	}
+	arr, err := checkArrayAndFunc("reduce", args)
+	if err != nil {
+		return err
+	}
+
+	elements := arr.Elements
+	var acc object.Object
+	if len(args) == 3 {
+		acc = args[2]
+	} else {
+		if len(elements) == 0 {
+			return object.NewArgumentError("reduce of an empty array needs an initial value")
+		}
+		acc = elements[0]
+		elements = elements[1:]
+	}
+
+	for _, e := range elements {
+		acc = interpreter.Call(args[1], []object.Object{acc, e})
+		if isRaised(acc) {
+			return acc
+		}
+	}
+	return acc
+}
+
+func anyBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	return testElements("any", true, interpreter, args)
+}
+
+func allBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	return testElements("all", false, interpreter, args)
+}
+
+// testElements implements any and all. It returns stopOn as soon as an element's test is stopOn.
+// Elements are tested with the function argument if given, otherwise by their own truthiness.
+func testElements(name string, stopOn bool, interpreter object.Interpreter, args []object.Object) object.Object {
+	if ac := moduleutils.CheckMinArgs(name, 1, args...); ac != nil {
+		return ac
+	}
+	if len(args) > 2 {
+		return object.NewArgumentError("%s expects at most 2 argument(s). Got %d", name, len(args))
+	}
+	arr, err := checkArrayAndFunc(name, args)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range arr.Elements {
+		result := e
+		if len(args) == 2 {
+			result = interpreter.Call(args[1], []object.Object{e})
+			if isRaised(result) {
+				return result
+			}
+		}
+		if eval.IsTruthy(result) == stopOn {
+			return object.NativeBoolToBooleanObj(stopOn)
+		}
+	}
+	return object.NativeBoolToBooleanObj(!stopOn)
+}
+
+func zipBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckMinArgs("zip", 1, args...); ac != nil {
+		return ac
+	}
+
+	arrs := make([]*object.Array, len(args))
+	length := -1
+	for i, arg := range args {
+		arr, ok := arg.(*object.Array)
+		if !ok {
+			return object.NewTypeError("Arguments to `zip` must be ARRAY, got %s", arg.Type())
+		}
+		arrs[i] = arr
+		if length == -1 || len(arr.Elements) < length {
+			length = len(arr.Elements)
+		}
+	}
+
+	newElements := make([]object.Object, length)
+	for i := range newElements {
+		group := make([]object.Object, len(arrs))
+		for j, arr := range arrs {
+			group[j] = arr.Elements[i]
+		}
+		newElements[i] = &object.Array{Elements: group}
+	}
+	return &object.Array{Elements: newElements}
+}
+
+func enumerateBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("enumerate", 1, args...); ac != nil {
+		return ac
+	}
+	arr, err := checkArrayAndFunc("enumerate", args)
+	if err != nil {
+		return err
+	}
+
+	newElements := make([]object.Object, len(arr.Elements))
+	for i, e := range arr.Elements {
+		newElements[i] = &object.Array{Elements: []object.Object{&object.Integer{Value: int64(i)}, e}}
+	}
+	return &object.Array{Elements: newElements}
+}
+
+// maxRangeLength is the largest array range will make.
+const maxRangeLength = 1 << 26
+
+func rangeBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckMinArgs("range", 1, args...); ac != nil {
+		return ac
+	}
+	if len(args) > 3 {
+		return object.NewArgumentError("range expects at most 3 argument(s). Got %d", len(args))
+	}
+
+	bounds := make([]int64, len(args))
+	for i, arg := range args {
+		n, ok := arg.(*object.Integer)
+		if !ok {
+			return object.NewTypeError("Arguments to `range` must be INTEGER, got %s", arg.Type())
+		}
+		bounds[i] = n.Value
+	}
+
+	start, stop, step := int64(0), bounds[0], int64(1)
+	if len(bounds) > 1 {
+		start, stop = bounds[0], bounds[1]
+	}
+	if len(bounds) > 2 {
+		step = bounds[2]
+	}
+	if step == 0 {
+		return object.NewArgumentError("Step of `range` must not be 0")
+	}
+
+	// The distance and step are unsigned so extreme bounds can't overflow
+	var length uint64
+	if step > 0 && stop > start {
+		length = (uint64(stop)-uint64(start)-1)/uint64(step) + 1
+	} else if step < 0 && stop < start {
+		length = (uint64(start)-uint64(stop)-1)/-uint64(step) + 1
+	}
+	if length > maxRangeLength {
+		return object.NewArgumentError("Range of %d elements is too large, `range` makes at most %d", length, maxRangeLength)
+	}
+
+	newElements := make([]object.Object, length)
+	for i := range newElements {
+		newElements[i] = &object.Integer{Value: start + int64(i)*step}
+	}
+	return &object.Array{Elements: newElements}
+}
@@ -0,0 +1,50 @@
+package collections
+
+import (
+	"github.com/nitrogen-lang/nitrogen/src/moduleutils"
+	"github.com/nitrogen-lang/nitrogen/src/object"
+)
+
+func hashValuesBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("hashValues", 1, args...); ac != nil {
+		return ac
+	}
+	hash, ok := args[0].(*object.Hash)
+	if !ok {
+		return object.NewTypeError("Argument to `hashValues` must be MAP, got %s", args[0].Type())
+	}
+
+	return &object.Array{Elements: hash.Values()}
+}
+
+func hashHasKeyBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("hashHasKey", 2, args...); ac != nil {
+		return ac
+	}
+	hash, ok := args[0].(*object.Hash)
+	if !ok {
+		return object.NewTypeError("Argument to `hashHasKey` must be MAP, got %s", args[0].Type())
+	}
+	key, ok := args[1].(object.Hashable)
+	if !ok {
+		return object.NewTypeError("Invalid map key: %s", args[1].Type())
+	}
+
+	return object.NativeBoolToBooleanObj(hash.Has(key))
+}
+
+func hashDeleteBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("hashDelete", 2, args...); ac != nil {
+		return ac
+	}
+	hash, ok := args[0].(*object.Hash)
+	if !ok {
+		return object.NewTypeError("Argument to `hashDelete` must be MAP, got %s", args[0].Type())
+	}
+	key, ok := args[1].(object.Hashable)
+	if !ok {
+		return object.NewTypeError("Invalid map key: %s", args[1].Type())
+	}
+
+	return object.NativeBoolToBooleanObj(hash.Delete(key))
+}
@@ -0,0 +1,240 @@
+package collections
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/nitrogen-lang/nitrogen/src/eval"
+	"github.com/nitrogen-lang/nitrogen/src/moduleutils"
+	"github.com/nitrogen-lang/nitrogen/src/object"
+)
+
+func sortArrayBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckMinArgs("sort", 1, args...); ac != nil {
+		return ac
+	}
+	if len(args) > 2 {
+		return object.NewArgumentError("sort expects at most 2 argument(s). Got %d", len(args))
+	}
+
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return object.NewTypeError("Argument to `sort` must be ARRAY, got %s", args[0].Type())
+	}
+
+	s := &sorter{interpreter: interpreter}
+	if len(args) == 2 {
+		if !isCallable(args[1]) {
+			return object.NewTypeError("Comparator of `sort` must be a function, got %s", args[1].Type())
+		}
+		s.cmp = args[1]
+	}
+
+	elements := make([]object.Object, len(arr.Elements))
+	copy(elements, arr.Elements)
+	if err := s.sort(elements, nil); err != nil {
+		return err
+	}
+	return &object.Array{Elements: elements}
+}
+
+func sortByBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("sortBy", 2, args...); ac != nil {
+		return ac
+	}
+
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return object.NewTypeError("Argument to `sortBy` must be ARRAY, got %s", args[0].Type())
+	}
+	if !isCallable(args[1]) {
+		return object.NewTypeError("Key function of `sortBy` must be a function, got %s", args[1].Type())
+	}
+
+	// The key of each element is only computed once
+	keys := make([]object.Object, len(arr.Elements))
+	for i, e := range arr.Elements {
+		key := interpreter.Call(args[1], []object.Object{e})
+		if isRaised(key) {
+			return key
+		}
+		keys[i] = key
+	}
+
+	elements := make([]object.Object, len(arr.Elements))
+	copy(elements, arr.Elements)
+	s := &sorter{interpreter: interpreter}
+	if err := s.sort(keys, elements); err != nil {
+		return err
+	}
+	return &object.Array{Elements: elements}
+}
+
+// sorter stably sorts keys and moves elements along with them. If cmp is nil, keys are compared
+// with compareObjects. The first exception raised while comparing stops the sort.
+type sorter struct {
+	interpreter object.Interpreter
+	cmp         object.Object
+	err         object.Object
+}
+
+func (s *sorter) sort(keys, elements []object.Object) object.Object {
+	sort.Stable(&sortPairs{s: s, keys: keys, elements: elements})
+	return s.err
+}
+
+func (s *sorter) less(a, b object.Object) bool {
+	if s.err != nil {
+		return false
+	}
+
+	var c int
+	if s.cmp == nil {
+		c, s.err = compareObjects(s.interpreter, a, b)
+	} else {
+		c, s.err = callComparator(s.interpreter, s.cmp, a, b)
+	}
+	return c < 0
+}
+
+type sortPairs struct {
+	s        *sorter
+	keys     []object.Object
+	elements []object.Object
+}
+
+func (p *sortPairs) Len() int           { return len(p.keys) }
+func (p *sortPairs) Less(i, j int) bool { return p.s.less(p.keys[i], p.keys[j]) }
+func (p *sortPairs) Swap(i, j int) {
+	p.keys[i], p.keys[j] = p.keys[j], p.keys[i]
+	if p.elements != nil {
+		p.elements[i], p.elements[j] = p.elements[j], p.elements[i]
+	}
+}
+
+// callComparator calls cmp(a, b) which must return an int less than, equal to, or
+// greater than 0 when a is less than, equal to, or greater than b.
+func callComparator(interpreter object.Interpreter, cmp, a, b object.Object) (int, object.Object) {
+	return comparison(interpreter.Call(cmp, []object.Object{a, b}))
+}
+
+func comparison(result object.Object) (int, object.Object) {
+	if isRaised(result) {
+		return 0, result
+	}
+
+	i, ok := result.(*object.Integer)
+	if !ok {
+		return 0, object.NewTypeError("Comparator must return INTEGER, got %s", result.Type())
+	}
+	return compareInts(i.Value, 0), nil
+}
+
+// compareObjects returns -1, 0, or 1 when a is less than, equal to, or greater than b.
+// Ints and floats are compared by value, strings by bytes, times and durations by length
+// of time, and false is less than true. Arrays are compared element by element. Instances
+// are compared with their compare() method. Any other values can't be compared and a
+// TypeError is returned.
+func compareObjects(interpreter object.Interpreter, a, b object.Object) (int, object.Object) {
+	if object.ObjectIs(a, object.BigIntObj, object.DecimalObj) || object.ObjectIs(b, object.BigIntObj, object.DecimalObj) {
+		if c, ok := eval.CompareNumbers(a, b); ok {
+			return c, nil
+		}
+	}
+
+	switch a := a.(type) {
+	case *object.Integer:
+		switch b := b.(type) {
+		case *object.Integer:
+			return compareInts(a.Value, b.Value), nil
+		case *object.Float:
+			return compareFloats(float64(a.Value), b.Value), nil
+		}
+	case *object.Float:
+		switch b := b.(type) {
+		case *object.Integer:
+			return compareFloats(a.Value, float64(b.Value)), nil
+		case *object.Float:
+			return compareFloats(a.Value, b.Value), nil
+		}
+	case *object.String:
+		if b, ok := b.(*object.String); ok {
+			return strings.Compare(a.Value, b.Value), nil
+		}
+	case *object.Boolean:
+		if b, ok := b.(*object.Boolean); ok {
+			switch {
+			case a.Value == b.Value:
+				return 0, nil
+			case b.Value:
+				return -1, nil
+			}
+			return 1, nil
+		}
+	case *object.Time:
+		if b, ok := b.(*object.Time); ok {
+			return a.Value.Compare(b.Value), nil
+		}
+	case *object.Duration:
+		if b, ok := b.(*object.Duration); ok {
+			return compareInts(int64(a.Value), int64(b.Value)), nil
+		}
+	case *object.Null:
+		if b == object.NullConst {
+			return 0, nil
+		}
+	case *object.Array:
+		if b, ok := b.(*object.Array); ok {
+			for i := 0; i < len(a.Elements) && i < len(b.Elements); i++ {
+				c, err := compareObjects(interpreter, a.Elements[i], b.Elements[i])
+				if err != nil || c != 0 {
+					return c, err
+				}
+			}
+			return compareInts(int64(len(a.Elements)), int64(len(b.Elements))), nil
+		}
+	case *object.Instance:
+		if a.GetMethod("compare") != nil {
+			method := eval.IndexOperation(a, &object.String{Value: "compare"}, nil)
+			result := interpreter.Call(method, []object.Object{b})
+			return comparison(result)
+		}
+		return 0, object.NewTypeError("Can't compare instance of %s, it has no compare method", a.Class.Name)
+	}
+
+	return 0, object.NewTypeError("Can't compare %s and %s", a.Type(), b.Type())
+}
+
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// compareFloats orders NaN before all other numbers so sorting is consistent.
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b, math.IsNaN(a) && !math.IsNaN(b):
+		return -1
+	case a > b, !math.IsNaN(a) && math.IsNaN(b):
+		return 1
+	}
+	return 0
+}
+
+func isCallable(fn object.Object) bool {
+	switch fn.(type) {
+	case *object.Function, *object.Builtin, *object.BuiltinMethod:
+		return true
+	}
+	return false
+}
+
+func isRaised(obj object.Object) bool {
+	return eval.IsException(obj) || eval.IsPanic(obj)
+}
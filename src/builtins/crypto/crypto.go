@@ -0,0 +1,141 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"hash"
+
+	"github.com/nitrogen-lang/nitrogen/src/eval"
+	"github.com/nitrogen-lang/nitrogen/src/moduleutils"
+	"github.com/nitrogen-lang/nitrogen/src/object"
+)
+
+// ModuleName is the name the module is registered with, scripts load it with module("crypto").
+var ModuleName = "crypto"
+
+// algorithms are the hash functions that can be used for digests and HMAC.
+var algorithms = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+func init() {
+	methods := map[string]object.BuiltinFunction{
+		"hmac":  hmacBuiltin,
+		"equal": equalBuiltin,
+		"uuid":  uuidBuiltin,
+	}
+	for name, algorithm := range algorithms {
+		methods[name] = makeDigest(name, algorithm)
+	}
+
+	eval.RegisterModule(ModuleName, &object.Module{
+		Name:    ModuleName,
+		Methods: methods,
+		Vars: map[string]object.Object{
+			"name": object.MakeStringObj(ModuleName),
+		},
+	})
+}
+
+// digestResult returns a digest as a hex string, or as a string of the raw bytes if raw is given and true.
+func digestResult(name string, digest []byte, args []object.Object, i int) object.Object {
+	if len(args) > i {
+		raw, ok := args[i].(*object.Boolean)
+		if !ok {
+			return object.NewTypeError("Argument %d to `%s` must be BOOLEAN, got %s", i+1, name, args[i].Type())
+		}
+		if raw.Value {
+			return object.MakeStringObj(string(digest))
+		}
+	}
+	return object.MakeStringObj(hex.EncodeToString(digest))
+}
+
+func makeDigest(name string, algorithm func() hash.Hash) object.BuiltinFunction {
+	return func(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+		if ac := moduleutils.CheckMinArgs(name, 1, args...); ac != nil {
+			return ac
+		}
+		if len(args) > 2 {
+			return object.NewArgumentError("%s expects at most 2 argument(s). Got %d", name, len(args))
+		}
+		data, err := moduleutils.BytesArg(name, args, 0)
+		if err != nil {
+			return err
+		}
+
+		h := algorithm()
+		h.Write(data)
+		return digestResult(name, h.Sum(nil), args, 1)
+	}
+}
+
+func hmacBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckMinArgs("hmac", 3, args...); ac != nil {
+		return ac
+	}
+	if len(args) > 4 {
+		return object.NewArgumentError("hmac expects at most 4 argument(s). Got %d", len(args))
+	}
+
+	name, ok := args[0].(*object.String)
+	if !ok {
+		return object.NewTypeError("Argument 1 to `hmac` must be STRING, got %s", args[0].Type())
+	}
+	algorithm, ok := algorithms[name.Value]
+	if !ok {
+		return object.NewException("Unknown hash algorithm %s", name.Value)
+	}
+	key, err := moduleutils.BytesArg("hmac", args, 1)
+	if err != nil {
+		return err
+	}
+	data, err := moduleutils.BytesArg("hmac", args, 2)
+	if err != nil {
+		return err
+	}
+
+	mac := hmac.New(algorithm, key)
+	mac.Write(data)
+	return digestResult("hmac", mac.Sum(nil), args, 3)
+}
+
+// equalBuiltin compares two values in constant time so secrets such as signatures can't be guessed from timing.
+func equalBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("equal", 2, args...); ac != nil {
+		return ac
+	}
+	a, err := moduleutils.BytesArg("equal", args, 0)
+	if err != nil {
+		return err
+	}
+	b, err := moduleutils.BytesArg("equal", args, 1)
+	if err != nil {
+		return err
+	}
+	return object.NativeBoolToBooleanObj(subtle.ConstantTimeCompare(a, b) == 1)
+}
+
+func uuidBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("uuid", 0, args...); ac != nil {
+		return ac
+	}
+
+	var u [16]byte
+	if _, err := rand.Read(u[:]); err != nil {
+		return object.NewException("Error reading random source: %s", err.Error())
+	}
+	u[6] = u[6]&0x0f | 0x40 // Version 4
+	u[8] = u[8]&0x3f | 0x80 // RFC 4122 variant
+	return object.MakeStringObj(fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16]))
+}
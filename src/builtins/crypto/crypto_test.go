@@ -0,0 +1,63 @@
+package crypto
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/nitrogen-lang/nitrogen/src/moduleutils"
+	"github.com/nitrogen-lang/nitrogen/src/object"
+)
+
+func TestDigests(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`crypto.md5("abc")`, "900150983cd24fb0d6963f7d28e17f72"},
+		{`crypto.sha1("abc")`, "a9993e364706816aba3e25717850c26c9cd0d89d"},
+		{`crypto.sha256("abc")`, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{`crypto.sha512("")`, "cf83e1357eefb8bdf1542850d66d8007d620e4050b5715dc83f4a921d36ce9ce47d0d13c5d85f2b0ff8318d2877eec2f63b931bd47417a81a538327af927da3e"},
+		{`crypto.sha256([97, 98, 99])`, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{`crypto.sha256(crypto.md5("abc", true))`, "46e7e78bfc6972ccb3a94d62b387cd63bad9a94946df9c7caba1948664db0c62"},
+		{`crypto.hmac("sha256", "key", "The quick brown fox jumps over the lazy dog")`, "f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"},
+		{`crypto.equal("signature", "signature")`, "true"},
+		{`crypto.equal("signature", "signaturf")`, "false"},
+		{`crypto.equal("a", "ab")`, "false"},
+	}
+
+	for _, tt := range tests {
+		result := moduleutils.TestEvalModule(t, tt.input, ModuleName)
+		if ex, ok := result.(*object.Exception); ok {
+			t.Errorf("%s: unexpected exception %q", tt.input, ex.Message)
+			continue
+		}
+		if result.Inspect() != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.input, tt.expected, result.Inspect())
+		}
+	}
+}
+
+func TestUUID(t *testing.T) {
+	pattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	uuid := uuidBuiltin(nil, object.NewEnvironment()).Inspect()
+	if !pattern.MatchString(uuid) {
+		t.Errorf("uuid %s isn't a version 4 UUID", uuid)
+	}
+	moduleutils.TestLiteralErrorObjects(t, moduleutils.TestEvalModule(t, `crypto.uuid() != crypto.uuid()`, ModuleName), true)
+}
+
+func TestCryptoErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`crypto.hmac("sha3", "key", "data")`, "Unknown hash algorithm sha3"},
+		{`crypto.sha1(1)`, "Argument 1 to `sha1` must be STRING or an ARRAY of bytes, got INTEGER"},
+		{`crypto.md5("a", 1)`, "Argument 2 to `md5` must be BOOLEAN, got INTEGER"},
+	}
+
+	for _, tt := range tests {
+		moduleutils.TestLiteralErrorObjects(t, moduleutils.TestEvalModule(t, tt.input, ModuleName), tt.expected)
+	}
+}
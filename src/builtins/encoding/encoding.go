@@ -0,0 +1,86 @@
+package encoding
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"net/url"
+	"strings"
+
+	"github.com/nitrogen-lang/nitrogen/src/eval"
+	"github.com/nitrogen-lang/nitrogen/src/moduleutils"
+	"github.com/nitrogen-lang/nitrogen/src/object"
+)
+
+// ModuleName is the name the module is registered with, scripts load it with module("encoding").
+var ModuleName = "encoding"
+
+func init() {
+	eval.RegisterModule(ModuleName, &object.Module{
+		Name: ModuleName,
+		Methods: map[string]object.BuiltinFunction{
+			"base64Encode":    makeEncoder("base64Encode", base64.StdEncoding.EncodeToString),
+			"base64Decode":    makeDecoder("base64Decode", "base64", decodeBase64(base64.RawStdEncoding)),
+			"base64URLEncode": makeEncoder("base64URLEncode", base64.RawURLEncoding.EncodeToString),
+			"base64URLDecode": makeDecoder("base64URLDecode", "base64", decodeBase64(base64.RawURLEncoding)),
+			"hexEncode":       makeEncoder("hexEncode", hex.EncodeToString),
+			"hexDecode":       makeDecoder("hexDecode", "hex", hex.DecodeString),
+			"queryEscape":     makeEncoder("queryEscape", bytesFunc(url.QueryEscape)),
+			"queryUnescape":   makeDecoder("queryUnescape", "query string", stringFunc(url.QueryUnescape)),
+			"percentEncode":   makeEncoder("percentEncode", bytesFunc(url.PathEscape)),
+			"percentDecode":   makeDecoder("percentDecode", "percent-encoding", stringFunc(url.PathUnescape)),
+		},
+		Vars: map[string]object.Object{
+			"name": object.MakeStringObj(ModuleName),
+		},
+	})
+}
+
+func makeEncoder(name string, encode func([]byte) string) object.BuiltinFunction {
+	return func(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+		if ac := moduleutils.CheckArgs(name, 1, args...); ac != nil {
+			return ac
+		}
+		data, err := moduleutils.BytesArg(name, args, 0)
+		if err != nil {
+			return err
+		}
+		return object.MakeStringObj(encode(data))
+	}
+}
+
+// makeDecoder makes a function that decodes a string. The result is a string of the decoded bytes.
+func makeDecoder(name, format string, decode func(string) ([]byte, error)) object.BuiltinFunction {
+	return func(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+		if ac := moduleutils.CheckArgs(name, 1, args...); ac != nil {
+			return ac
+		}
+		str, ok := args[0].(*object.String)
+		if !ok {
+			return object.NewTypeError("Argument to `%s` must be STRING, got %s", name, args[0].Type())
+		}
+
+		data, err := decode(str.Value)
+		if err != nil {
+			return object.NewException("Invalid %s: %s", format, err.Error())
+		}
+		return object.MakeStringObj(string(data))
+	}
+}
+
+// decodeBase64 decodes with or without padding.
+func decodeBase64(encoding *base64.Encoding) func(string) ([]byte, error) {
+	return func(s string) ([]byte, error) {
+		return encoding.DecodeString(strings.TrimRight(s, "="))
+	}
+}
+
+func bytesFunc(fn func(string) string) func([]byte) string {
+	return func(b []byte) string { return fn(string(b)) }
+}
+
+func stringFunc(fn func(string) (string, error)) func(string) ([]byte, error) {
+	return func(s string) ([]byte, error) {
+		decoded, err := fn(s)
+		return []byte(decoded), err
+	}
+}
@@ -0,0 +1,52 @@
+package encoding
+
+import (
+	"testing"
+
+	"github.com/nitrogen-lang/nitrogen/src/moduleutils"
+	"github.com/nitrogen-lang/nitrogen/src/object"
+)
+
+func TestEncoding(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`encoding.base64Encode("hello?>")`, "aGVsbG8/Pg=="},
+		{`encoding.base64URLEncode("hello?>")`, "aGVsbG8_Pg"},
+		{`encoding.base64Decode("aGVsbG8/Pg==")`, "hello?>"},
+		{`encoding.base64Decode("aGVsbG8/Pg")`, "hello?>"},
+		{`encoding.base64URLDecode("aGVsbG8_Pg==")`, "hello?>"},
+		{`encoding.base64Encode([0, 255, 16])`, "AP8Q"},
+		{`encoding.hexEncode("Hi\n")`, "48690a"},
+		{`encoding.hexDecode("48690A")`, "Hi\n"},
+		{`encoding.queryEscape("a b&c=d/é")`, "a+b%26c%3Dd%2F%C3%A9"},
+		{`encoding.queryUnescape("a+b%26c")`, "a b&c"},
+		{`encoding.percentEncode("a b/c")`, "a%20b%2Fc"},
+		{`encoding.percentDecode("a%20b+c")`, "a b+c"},
+	}
+
+	for _, tt := range tests {
+		result := moduleutils.TestEvalModule(t, tt.input, ModuleName)
+		if s, ok := result.(*object.String); !ok || s.Value != tt.expected {
+			t.Errorf("%s: expected %q, got %s", tt.input, tt.expected, moduleutils.ShowError(result))
+		}
+	}
+}
+
+func TestEncodingErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`encoding.base64Decode("a$")`, "Invalid base64: illegal base64 data at input byte 1"},
+		{`encoding.hexDecode("abc")`, "Invalid hex: encoding/hex: odd length hex string"},
+		{`encoding.queryUnescape("%zz")`, `Invalid query string: invalid URL escape "%zz"`},
+		{`encoding.hexEncode([256])`, "Argument 1 to `hexEncode` must be STRING or an ARRAY of bytes, got element 256"},
+		{`encoding.hexDecode(1)`, "Argument to `hexDecode` must be STRING, got INTEGER"},
+	}
+
+	for _, tt := range tests {
+		moduleutils.TestLiteralErrorObjects(t, moduleutils.TestEvalModule(t, tt.input, ModuleName), tt.expected)
+	}
+}
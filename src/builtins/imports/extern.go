@@ -61,7 +61,7 @@ func commonInclude(require bool, save bool, i object.Interpreter, env *object.En
 	if len(args) > 1 {
 		includeOnce, ok := args[1].(*object.Boolean)
 		if !ok {
-			return object.NewException("%s expected a boolean for second argument, got %s", funcName, args[1].Type().String())
+			return object.NewTypeError("%s expected a boolean for second argument, got %s", funcName, args[1].Type().String())
 		}
 		once = includeOnce.Value
 	}
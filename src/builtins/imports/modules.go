@@ -34,7 +34,7 @@ func importModule(i object.Interpreter, env *object.Environment, args ...object.
 	if len(args) > 1 {
 		requiredArg, ok := args[1].(*object.Boolean)
 		if !ok {
-			return object.NewException("module expected a boolean for second argument, got %s", args[1].Type().String())
+			return object.NewTypeError("module expected a boolean for second argument, got %s", args[1].Type().String())
 		}
 		required = requiredArg.Value
 	}
@@ -13,6 +13,14 @@ func init() {
 }
 
 func importModule(i object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	// Modules built into the interpreter are still available
+	if len(args) > 0 {
+		if name, ok := args[0].(*object.String); ok {
+			if module := eval.GetModule(name.Value); module != nil {
+				return module
+			}
+		}
+	}
 	return object.NewException("Shared object modules are not supported in this build")
 }
 
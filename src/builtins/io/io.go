@@ -43,7 +43,7 @@ func printEnvBuiltin(interpreter object.Interpreter, env *object.Environment, ar
 
 func readLineBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
 	if len(args) > 1 {
-		return object.NewException("readline only accepts up to one argument. Got %d", len(args))
+		return object.NewArgumentError("readline only accepts up to one argument. Got %d", len(args))
 	}
 
 	if len(args) == 1 {
@@ -0,0 +1,448 @@
+package json
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nitrogen-lang/nitrogen/src/eval"
+	"github.com/nitrogen-lang/nitrogen/src/moduleutils"
+	"github.com/nitrogen-lang/nitrogen/src/object"
+)
+
+// ModuleName is the name the module is registered with, scripts load it with module("json").
+var ModuleName = "json"
+
+// DecodeErrorClass is the class of exceptions thrown for invalid JSON.
+var DecodeErrorClass = &object.Class{
+	Name:    "DecodeError",
+	Parent:  object.RuntimeErrorClass,
+	Methods: map[string]object.ClassMethod{},
+}
+
+// maxDepth limits how deeply values can be nested. It also stops values that contain themselves.
+const maxDepth = 1000
+
+func init() {
+	eval.RegisterModule(ModuleName, &object.Module{
+		Name: ModuleName,
+		Methods: map[string]object.BuiltinFunction{
+			"encode":  encodeJSON,
+			"decode":  decodeJSON,
+			"decoder": newDecoder,
+			"more":    decoderMore,
+			"next":    decoderNext,
+		},
+		Vars: map[string]object.Object{
+			"name":        object.MakeStringObj(ModuleName),
+			"DecodeError": DecodeErrorClass,
+		},
+	})
+}
+
+type encoder struct {
+	interpreter object.Interpreter
+	buf         bytes.Buffer
+	pretty      bool
+}
+
+func encodeJSON(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckMinArgs("encode", 1, args...); ac != nil {
+		return ac
+	}
+	if len(args) > 2 {
+		return object.NewArgumentError("encode expects at most 2 argument(s). Got %d", len(args))
+	}
+
+	e := &encoder{interpreter: interpreter}
+	if len(args) == 2 {
+		pretty, ok := args[1].(*object.Boolean)
+		if !ok {
+			return object.NewTypeError("Second argument to `encode` must be BOOLEAN, got %s", args[1].Type())
+		}
+		e.pretty = pretty.Value
+	}
+
+	if err := e.encode(args[0], 0); err != nil {
+		return err
+	}
+	return object.MakeStringObj(e.buf.String())
+}
+
+func (e *encoder) newline(depth int) {
+	if e.pretty {
+		e.buf.WriteByte('\n')
+		e.buf.WriteString(strings.Repeat("    ", depth))
+	}
+}
+
+func (e *encoder) encode(val object.Object, depth int) object.Object {
+	if depth > maxDepth {
+		return object.NewException("Value is nested too deeply to encode, it may contain itself")
+	}
+
+	switch val := val.(type) {
+	case *object.Null:
+		e.buf.WriteString("null")
+	case *object.Boolean:
+		e.buf.WriteString(val.Inspect())
+	case *object.Integer, *object.BigInt, *object.Decimal:
+		e.buf.WriteString(val.Inspect())
+	case *object.Float:
+		b, err := json.Marshal(val.Value)
+		if err != nil {
+			return object.NewTypeError("Float %s can't be encoded as JSON", val.Inspect())
+		}
+		e.buf.Write(b)
+	case *object.String:
+		writeString(&e.buf, val.Value)
+	case *object.Array:
+		return e.encodeArray(val, depth)
+	case *object.Hash:
+		return e.encodeHash(val, depth)
+	case *object.Instance:
+		return e.encodeInstance(val, depth)
+	default:
+		return object.NewTypeError("%s can't be encoded as JSON", val.Type())
+	}
+	return nil
+}
+
+func (e *encoder) encodeArray(arr *object.Array, depth int) object.Object {
+	e.buf.WriteByte('[')
+	for i, elem := range arr.Elements {
+		if i > 0 {
+			e.buf.WriteByte(',')
+		}
+		e.newline(depth + 1)
+		if err := e.encode(elem, depth+1); err != nil {
+			return err
+		}
+	}
+	if len(arr.Elements) > 0 {
+		e.newline(depth)
+	}
+	e.buf.WriteByte(']')
+	return nil
+}
+
+func (e *encoder) encodeHash(hash *object.Hash, depth int) object.Object {
+	pairs := hash.Pairs()
+	e.buf.WriteByte('{')
+	for i, pair := range pairs {
+		if i > 0 {
+			e.buf.WriteByte(',')
+		}
+		e.newline(depth + 1)
+
+		switch key := pair.Key.(type) {
+		case *object.String:
+			writeString(&e.buf, key.Value)
+		case *object.Integer, *object.BigInt, *object.Decimal:
+			writeString(&e.buf, key.Inspect())
+		default:
+			return object.NewTypeError("Map key of type %s can't be encoded as JSON", pair.Key.Type())
+		}
+
+		e.buf.WriteByte(':')
+		if e.pretty {
+			e.buf.WriteByte(' ')
+		}
+		if err := e.encode(pair.Value, depth+1); err != nil {
+			return err
+		}
+	}
+	if len(pairs) > 0 {
+		e.newline(depth)
+	}
+	e.buf.WriteByte('}')
+	return nil
+}
+
+// encodeInstance encodes the value returned by an instance's toJSON method.
+func (e *encoder) encodeInstance(instance *object.Instance, depth int) object.Object {
+	if instance.GetMethod("toJSON") == nil {
+		return object.NewTypeError("Instance of %s can't be encoded as JSON, it doesn't have a toJSON method", instance.Class.Name)
+	}
+
+	method := eval.IndexOperation(instance, object.MakeStringObj("toJSON"), nil)
+	val := e.interpreter.Call(method, nil)
+	if eval.IsException(val) || eval.IsPanic(val) {
+		return val
+	}
+	return e.encode(val, depth+1)
+}
+
+// writeString writes str as a JSON string. Bytes that aren't valid UTF-8 are replaced with U+FFFD.
+func writeString(buf *bytes.Buffer, str string) {
+	buf.WriteByte('"')
+	for i := 0; i < len(str); {
+		r, size := utf8.DecodeRuneInString(str[i:])
+		switch {
+		case r == '"' || r == '\\':
+			buf.WriteByte('\\')
+			buf.WriteRune(r)
+		case r == '\n':
+			buf.WriteString(`\n`)
+		case r == '\r':
+			buf.WriteString(`\r`)
+		case r == '\t':
+			buf.WriteString(`\t`)
+		case r < 0x20:
+			fmt.Fprintf(buf, `\u%04x`, r)
+		case r == utf8.RuneError && size == 1:
+			buf.WriteString(`�`)
+		default:
+			buf.WriteString(str[i : i+size])
+		}
+		i += size
+	}
+	buf.WriteByte('"')
+}
+
+type decoderResource struct {
+	dec    *json.Decoder
+	source io.Reader
+	exact  bool
+}
+
+func (d *decoderResource) Inspect() string         { return "JSON decoder resource" }
+func (d *decoderResource) Type() object.ObjectType { return object.ResourceObj }
+func (d *decoderResource) Dup() object.Object      { return object.NullConst } // Duplicating a decoder isn't allowed
+
+// decoderArgs makes a decoder from the source and the optional exact flag of decode and decoder.
+// The source is a string or a resource that can be read from, such as a file.
+func decoderArgs(name string, args []object.Object) (*decoderResource, object.Object) {
+	if ac := moduleutils.CheckMinArgs(name, 1, args...); ac != nil {
+		return nil, ac
+	}
+	if len(args) > 2 {
+		return nil, object.NewArgumentError("%s expects at most 2 argument(s). Got %d", name, len(args))
+	}
+
+	var r io.Reader
+	switch source := args[0].(type) {
+	case *object.String:
+		r = strings.NewReader(source.Value)
+	case io.Reader:
+		r = source
+	default:
+		return nil, object.NewTypeError("Argument to `%s` must be STRING or a readable resource, got %s", name, args[0].Type())
+	}
+
+	d := &decoderResource{dec: json.NewDecoder(r), source: r}
+	d.dec.UseNumber()
+	if len(args) == 2 {
+		exact, ok := args[1].(*object.Boolean)
+		if !ok {
+			return nil, object.NewTypeError("Second argument to `%s` must be BOOLEAN, got %s", name, args[1].Type())
+		}
+		d.exact = exact.Value
+	}
+	return d, nil
+}
+
+func decodeJSON(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	d, err := decoderArgs("decode", args)
+	if err != nil {
+		return err
+	}
+
+	val := d.decode(0)
+	if eval.IsException(val) {
+		return val
+	}
+	if offset := d.trailingData(); offset > -1 {
+		return object.NewExceptionOf(DecodeErrorClass, "Invalid JSON at offset %d: unexpected data after value", offset)
+	}
+	return val
+}
+
+// trailingData returns the offset of the first byte after the decoded value that isn't whitespace, or -1 if there's none.
+func (d *decoderResource) trailingData() int64 {
+	r := bufio.NewReader(io.MultiReader(d.dec.Buffered(), d.source))
+	offset := d.dec.InputOffset()
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return -1
+		}
+		if b != ' ' && b != '\t' && b != '\r' && b != '\n' {
+			return offset
+		}
+		offset++
+	}
+}
+
+func newDecoder(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	d, err := decoderArgs("decoder", args)
+	if err != nil {
+		return err
+	}
+	return d
+}
+
+func decoderMore(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("more", 1, args...); ac != nil {
+		return ac
+	}
+	d, ok := args[0].(*decoderResource)
+	if !ok {
+		return object.NewTypeError("Argument to `more` must be a JSON decoder, got %s", args[0].Type())
+	}
+	return object.NativeBoolToBooleanObj(d.dec.More())
+}
+
+func decoderNext(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("next", 1, args...); ac != nil {
+		return ac
+	}
+	d, ok := args[0].(*decoderResource)
+	if !ok {
+		return object.NewTypeError("Argument to `next` must be a JSON decoder, got %s", args[0].Type())
+	}
+	return d.decode(0)
+}
+
+// error makes a DecodeError with the byte offset of the problem.
+func (d *decoderResource) error(err error, msg string) *object.Exception {
+	offset := d.dec.InputOffset()
+	switch err := err.(type) {
+	case *json.SyntaxError:
+		// The error's offset is just after the invalid byte
+		offset, msg = err.Offset-1, err.Error()
+		if strings.HasPrefix(msg, "unexpected end") {
+			offset, msg = err.Offset, "unexpected end of input"
+		} else if strings.HasPrefix(msg, "invalid character ','") {
+			offset, msg = d.trailingComma(offset, msg)
+		}
+	case nil:
+	default:
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			msg = "unexpected end of input"
+		} else {
+			msg = err.Error()
+		}
+	}
+	return object.NewExceptionOf(DecodeErrorClass, "Invalid JSON at offset %d: %s", offset, msg)
+}
+
+// trailingComma finds the closing bracket after a trailing comma. The decoder
+// blames the comma before it, the bracket is where a value was expected.
+func (d *decoderResource) trailingComma(offset int64, msg string) (int64, string) {
+	rest, _ := io.ReadAll(d.dec.Buffered())
+	if len(rest) == 0 || rest[0] != ',' {
+		return offset, msg
+	}
+	i := 1 + len(rest[1:]) - len(bytes.TrimLeft(rest[1:], " \t\r\n"))
+	if i < len(rest) && (rest[i] == ']' || rest[i] == '}') {
+		return d.dec.InputOffset() + int64(i), fmt.Sprintf("invalid character '%c' looking for beginning of value", rest[i])
+	}
+	return offset, msg
+}
+
+func (d *decoderResource) decode(depth int) object.Object {
+	if depth > maxDepth {
+		return d.error(nil, "value is nested too deeply")
+	}
+
+	tok, err := d.dec.Token()
+	if err != nil {
+		return d.error(err, "")
+	}
+
+	switch tok := tok.(type) {
+	case nil:
+		return object.NullConst
+	case bool:
+		return object.NativeBoolToBooleanObj(tok)
+	case string:
+		return object.MakeStringObj(tok)
+	case json.Number:
+		return d.decodeNumber(string(tok))
+	case json.Delim:
+		if tok == '[' {
+			return d.decodeArray(depth)
+		}
+		if tok == '{' {
+			return d.decodeObject(depth)
+		}
+	}
+	return d.error(nil, fmt.Sprintf("unexpected %v", tok))
+}
+
+func (d *decoderResource) decodeArray(depth int) object.Object {
+	elements := []object.Object{}
+	for d.dec.More() {
+		val := d.decode(depth + 1)
+		if eval.IsException(val) {
+			return val
+		}
+		elements = append(elements, val)
+	}
+	if _, err := d.dec.Token(); err != nil {
+		return d.error(err, "")
+	}
+	return &object.Array{Elements: elements}
+}
+
+func (d *decoderResource) decodeObject(depth int) object.Object {
+	hash := object.NewHash(0)
+	for d.dec.More() {
+		key, err := d.dec.Token()
+		if err != nil {
+			return d.error(err, "")
+		}
+		val := d.decode(depth + 1)
+		if eval.IsException(val) {
+			return val
+		}
+		hash.Set(object.MakeStringObj(key.(string)), val)
+	}
+	if _, err := d.dec.Token(); err != nil {
+		return d.error(err, "")
+	}
+	return hash
+}
+
+// decodeNumber decodes integers exactly, as a BIGINT if needed. Other numbers are
+// floats or, in exact mode, decimals.
+func (d *decoderResource) decodeNumber(num string) object.Object {
+	if !strings.ContainsAny(num, ".eE") {
+		i, _ := new(big.Int).SetString(num, 10)
+		return object.NewInt(i)
+	}
+
+	if !d.exact {
+		f, err := json.Number(num).Float64()
+		if err != nil {
+			return d.error(nil, fmt.Sprintf("number %s is out of range", num))
+		}
+		return &object.Float{Value: f}
+	}
+
+	mantissa, exp := num, int64(0)
+	if i := strings.IndexAny(num, "eE"); i > -1 {
+		mantissa = num[:i]
+		e, ok := new(big.Int).SetString(strings.TrimPrefix(num[i+1:], "+"), 10)
+		if !ok || !e.IsInt64() || e.Int64() > 1000 || e.Int64() < -1000 {
+			return d.error(nil, fmt.Sprintf("exponent of number %s is out of range", num))
+		}
+		exp = e.Int64()
+	}
+
+	dec, err := object.ParseDecimal(mantissa, object.RoundHalfEven)
+	if err != nil {
+		return d.error(nil, err.Error())
+	}
+	dec.Scale -= int(exp)
+	if dec.Scale < 0 {
+		dec = dec.Rescale(0)
+	}
+	return dec
+}
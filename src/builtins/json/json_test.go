@@ -0,0 +1,125 @@
+package json
+
+import (
+	"testing"
+
+	"github.com/nitrogen-lang/nitrogen/src/moduleutils"
+	"github.com/nitrogen-lang/nitrogen/src/object"
+)
+
+func testString(t *testing.T, input string, obj object.Object, expected string) {
+	if ex, ok := obj.(*object.Exception); ok {
+		t.Errorf("%s: unexpected exception %q", input, ex.Message)
+		return
+	}
+	if obj.Inspect() != expected {
+		t.Errorf("%s: expected %s, got %s", input, expected, obj.Inspect())
+	}
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`json.encode(nil)`, `null`},
+		{`json.encode([true, false, 1, -2.5, "a\"b\n"])`, `[true,false,1,-2.5,"a\"b\n"]`},
+		{`json.encode("<&>")`, `"<&>"`},
+		{`json.encode({"b": 1, "a": [], 3: {}})`, `{"b":1,"a":[],"3":{}}`},
+		{`json.encode(9223372036854775807 + 1)`, `9223372036854775808`},
+		{`json.encode(json.decode("1.50", true))`, `1.50`},
+		{`json.encode({"a": [1, 2], "b": {}}, true)`, "{\n    \"a\": [\n        1,\n        2\n    ],\n    \"b\": {}\n}"},
+		{`
+		let point = class {
+			let x
+			let y
+			func init(x, y) {
+				this.x = x
+				this.y = y
+			}
+			func toJSON() {
+				return {"x": x, "y": y}
+			}
+		}
+		json.encode([make point(1, 2)])`, `[{"x":1,"y":2}]`},
+	}
+
+	for _, tt := range tests {
+		result := moduleutils.TestEvalModule(t, tt.input, ModuleName)
+		if s, ok := result.(*object.String); ok {
+			if s.Value != tt.expected {
+				t.Errorf("%s: expected %s, got %s", tt.input, tt.expected, s.Value)
+			}
+			continue
+		}
+		t.Errorf("%s: expected STRING, got %s", tt.input, moduleutils.ShowError(result))
+	}
+}
+
+func TestEncodeErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`json.encode(func() {})`, "FUNCTION can't be encoded as JSON"},
+		{`json.encode(1, 2)`, "Second argument to `encode` must be BOOLEAN, got INTEGER"},
+		{`json.encode(make (class {})())`, "Instance of  can't be encoded as JSON, it doesn't have a toJSON method"},
+		{`let a = {}; a["a"] = a; json.encode(a)`, "Value is nested too deeply to encode, it may contain itself"},
+	}
+
+	for _, tt := range tests {
+		moduleutils.TestLiteralErrorObjects(t, moduleutils.TestEvalModule(t, tt.input, ModuleName), tt.expected)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`json.decode("null")`, `nil`},
+		{`json.decode(" [true, 1, 2.5, \"a\\u00e9\"] ")`, `[true, 1, 2.5, "aé"]`},
+		{`json.decode("{\"b\": {\"c\": []}, \"a\": 1}")`, `{b: {c: []}, a: 1}`},
+		{`json.decode("123456789012345678901234567890")`, `123456789012345678901234567890`},
+		{`json.decode("[0.1, 1.5e2, 25e-3]", true)`, `[0.1, 150, 0.025]`},
+	}
+
+	for _, tt := range tests {
+		testString(t, tt.input, moduleutils.TestEvalModule(t, tt.input, ModuleName), tt.expected)
+	}
+
+	moduleutils.TestLiteralErrorObjects(t, moduleutils.TestEvalModule(t, `json.decode("1.5e2")`, ModuleName), 150.0)
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`json.decode("")`, "Invalid JSON at offset 0: unexpected end of input"},
+		{`json.decode("[1, 2")`, "Invalid JSON at offset 5: unexpected end of input"},
+		{`json.decode("[1 2]")`, "Invalid JSON at offset 3: invalid character '2' after array element"},
+		{`json.decode("{\"a\": tru}")`, "Invalid JSON at offset 9: invalid character '}' in literal true (expecting 'e')"},
+		{`json.decode("1 2")`, "Invalid JSON at offset 2: unexpected data after value"},
+		{`json.decode("[1, ]")`, "Invalid JSON at offset 4: invalid character ']' looking for beginning of value"},
+		{`json.decode("{\"a\": 1,}")`, "Invalid JSON at offset 8: invalid character '}' looking for beginning of value"},
+		{`json.decode("[1,,2]")`, "Invalid JSON at offset 3: invalid character ',' looking for beginning of value"},
+		{`json.decode(1)`, "Argument to `decode` must be STRING or a readable resource, got INTEGER"},
+	}
+
+	for _, tt := range tests {
+		result := moduleutils.TestEvalModule(t, tt.input, ModuleName)
+		moduleutils.TestLiteralErrorObjects(t, result, tt.expected)
+		if ex, ok := result.(*object.Exception); ok && tt.input != `json.decode(1)` && ex.Class != DecodeErrorClass {
+			t.Errorf("%s: expected a DecodeError, got %s", tt.input, ex.Class.Name)
+		}
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	input := `
+	let d = json.decoder("{\"a\": 1} [2] 3")
+	[json.more(d), json.next(d), json.next(d), json.next(d), json.more(d)]`
+
+	testString(t, input, moduleutils.TestEvalModule(t, input, ModuleName), `[true, {a: 1}, [2], 3, false]`)
+}
@@ -0,0 +1,295 @@
+package math
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/nitrogen-lang/nitrogen/src/eval"
+	"github.com/nitrogen-lang/nitrogen/src/moduleutils"
+	"github.com/nitrogen-lang/nitrogen/src/object"
+)
+
+// ModuleName is the name the module is registered with, scripts load it with module("math").
+var ModuleName = "math"
+
+// floatFuncs are functions of one number that always return a float.
+var floatFuncs = map[string]func(float64) float64{
+	"sin":   math.Sin,
+	"cos":   math.Cos,
+	"tan":   math.Tan,
+	"asin":  math.Asin,
+	"acos":  math.Acos,
+	"atan":  math.Atan,
+	"sinh":  math.Sinh,
+	"cosh":  math.Cosh,
+	"tanh":  math.Tanh,
+	"asinh": math.Asinh,
+	"acosh": math.Acosh,
+	"atanh": math.Atanh,
+	"sqrt":  math.Sqrt,
+	"cbrt":  math.Cbrt,
+	"exp":   math.Exp,
+	"log2":  math.Log2,
+	"log10": math.Log10,
+}
+
+func init() {
+	methods := map[string]object.BuiltinFunction{
+		"atan2": makeFloatFunc2("atan2", math.Atan2),
+		"hypot": makeFloatFunc2("hypot", math.Hypot),
+		"log":   logBuiltin,
+		"pow":   powBuiltin,
+		"abs":   absBuiltin,
+		"floor": makeRoundingFunc("floor", math.Floor, object.RoundFloor),
+		"ceil":  makeRoundingFunc("ceil", math.Ceil, object.RoundCeiling),
+		"round": makeRoundingFunc("round", math.Round, object.RoundHalfUp),
+		"trunc": makeRoundingFunc("trunc", math.Trunc, object.RoundDown),
+		"min":   makeExtremeFunc("min", -1),
+		"max":   makeExtremeFunc("max", 1),
+		"isNaN": isNaNBuiltin,
+		"isInf": isInfBuiltin,
+	}
+	for name, fn := range floatFuncs {
+		methods[name] = makeFloatFunc(name, fn)
+	}
+
+	eval.RegisterModule(ModuleName, &object.Module{
+		Name:    ModuleName,
+		Methods: methods,
+		Vars: map[string]object.Object{
+			"name":   object.MakeStringObj(ModuleName),
+			"pi":     &object.Float{Value: math.Pi},
+			"e":      &object.Float{Value: math.E},
+			"maxInt": &object.Integer{Value: math.MaxInt64},
+			"minInt": &object.Integer{Value: math.MinInt64},
+			"inf":    &object.Float{Value: math.Inf(1)},
+			"nan":    &object.Float{Value: math.NaN()},
+			"rand":   randModule,
+		},
+	})
+}
+
+// floatArg returns any number as a float.
+func floatArg(name string, args []object.Object, i int) (float64, object.Object) {
+	switch arg := args[i].(type) {
+	case *object.Integer:
+		return float64(arg.Value), nil
+	case *object.BigInt:
+		f, _ := new(big.Float).SetInt(arg.Value).Float64()
+		return f, nil
+	case *object.Float:
+		return arg.Value, nil
+	case *object.Decimal:
+		return arg.Float64(), nil
+	}
+	return 0, object.NewTypeError("Argument %d to `%s` must be a number, got %s", i+1, name, args[i].Type())
+}
+
+func makeFloatFunc(name string, fn func(float64) float64) object.BuiltinFunction {
+	return func(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+		if ac := moduleutils.CheckArgs(name, 1, args...); ac != nil {
+			return ac
+		}
+		x, err := floatArg(name, args, 0)
+		if err != nil {
+			return err
+		}
+		return &object.Float{Value: fn(x)}
+	}
+}
+
+func makeFloatFunc2(name string, fn func(float64, float64) float64) object.BuiltinFunction {
+	return func(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+		if ac := moduleutils.CheckArgs(name, 2, args...); ac != nil {
+			return ac
+		}
+		x, err := floatArg(name, args, 0)
+		if err != nil {
+			return err
+		}
+		y, err := floatArg(name, args, 1)
+		if err != nil {
+			return err
+		}
+		return &object.Float{Value: fn(x, y)}
+	}
+}
+
+func logBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckMinArgs("log", 1, args...); ac != nil {
+		return ac
+	}
+	if len(args) > 2 {
+		return object.NewArgumentError("log expects at most 2 argument(s). Got %d", len(args))
+	}
+	x, err := floatArg("log", args, 0)
+	if err != nil {
+		return err
+	}
+	if len(args) == 1 {
+		return &object.Float{Value: math.Log(x)}
+	}
+
+	base, err := floatArg("log", args, 1)
+	if err != nil {
+		return err
+	}
+	return &object.Float{Value: math.Log(x) / math.Log(base)}
+}
+
+// maxPowResultBits limits the size of exact integer powers.
+const maxPowResultBits = 1 << 20
+
+// powBuiltin is exact when both arguments are integers and the exponent isn't negative.
+func powBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("pow", 2, args...); ac != nil {
+		return ac
+	}
+
+	if object.ObjectIs(args[0], object.IntergerObj, object.BigIntObj) && args[1].Type() == object.IntergerObj {
+		base := bigValue(args[0])
+		exp := args[1].(*object.Integer).Value
+		if exp >= 0 {
+			if base.BitLen() > 1 && exp > maxPowResultBits/int64(base.BitLen()-1) {
+				return object.NewException("Result of `pow` is too large")
+			}
+			return object.NewInt(new(big.Int).Exp(base, big.NewInt(exp), nil))
+		}
+	}
+
+	x, err := floatArg("pow", args, 0)
+	if err != nil {
+		return err
+	}
+	y, err := floatArg("pow", args, 1)
+	if err != nil {
+		return err
+	}
+	return &object.Float{Value: math.Pow(x, y)}
+}
+
+func bigValue(obj object.Object) *big.Int {
+	if i, ok := obj.(*object.Integer); ok {
+		return big.NewInt(i.Value)
+	}
+	return obj.(*object.BigInt).Value
+}
+
+func absBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("abs", 1, args...); ac != nil {
+		return ac
+	}
+
+	switch arg := args[0].(type) {
+	case *object.Integer:
+		if arg.Value >= 0 {
+			return arg
+		}
+		return object.NewInt(new(big.Int).Neg(big.NewInt(arg.Value)))
+	case *object.BigInt:
+		return object.NewInt(new(big.Int).Abs(arg.Value))
+	case *object.Float:
+		return &object.Float{Value: math.Abs(arg.Value)}
+	case *object.Decimal:
+		d := arg.Dup().(*object.Decimal)
+		d.Unscaled.Abs(d.Unscaled)
+		return d
+	}
+	return object.NewTypeError("Argument 1 to `abs` must be a number, got %s", args[0].Type())
+}
+
+// makeRoundingFunc makes a function that rounds floats with fn and decimals with mode.
+// Integers are returned as is.
+func makeRoundingFunc(name string, fn func(float64) float64, mode object.RoundingMode) object.BuiltinFunction {
+	return func(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+		if ac := moduleutils.CheckArgs(name, 1, args...); ac != nil {
+			return ac
+		}
+
+		switch arg := args[0].(type) {
+		case *object.Integer, *object.BigInt:
+			return arg
+		case *object.Float:
+			return &object.Float{Value: fn(arg.Value)}
+		case *object.Decimal:
+			d := &object.Decimal{Unscaled: arg.Unscaled, Scale: arg.Scale, Rounding: mode}
+			d = d.Rescale(0)
+			d.Rounding = arg.Rounding
+			return d
+		}
+		return object.NewTypeError("Argument 1 to `%s` must be a number, got %s", name, args[0].Type())
+	}
+}
+
+// makeExtremeFunc makes min, or max when want is 1. They take an array or several numbers.
+func makeExtremeFunc(name string, want int) object.BuiltinFunction {
+	return func(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+		if ac := moduleutils.CheckMinArgs(name, 1, args...); ac != nil {
+			return ac
+		}
+
+		values := args
+		if len(args) == 1 {
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return object.NewTypeError("Argument to `%s` must be ARRAY when given one argument, got %s", name, args[0].Type())
+			}
+			if len(arr.Elements) == 0 {
+				return object.NewException("%s of an empty array", name)
+			}
+			values = arr.Elements
+		}
+
+		result := values[0]
+		for _, v := range values {
+			cmp, ok := eval.CompareNumbers(v, result)
+			if !ok {
+				return object.NewTypeError("Values given to `%s` must be numbers, got %s", name, v.Type())
+			}
+			if cmp == want {
+				result = v
+			}
+		}
+		return result
+	}
+}
+
+func isNaNBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("isNaN", 1, args...); ac != nil {
+		return ac
+	}
+	x, err := floatArg("isNaN", args, 0)
+	if err != nil {
+		return err
+	}
+	return object.NativeBoolToBooleanObj(math.IsNaN(x))
+}
+
+// isInfBuiltin checks for either infinity, or only positive or negative infinity if sign is given.
+func isInfBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckMinArgs("isInf", 1, args...); ac != nil {
+		return ac
+	}
+	if len(args) > 2 {
+		return object.NewArgumentError("isInf expects at most 2 argument(s). Got %d", len(args))
+	}
+
+	// Integers and decimals are never infinite, even if they're too large for a float
+	if args[0].Type() != object.FloatObj {
+		if _, err := floatArg("isInf", args, 0); err != nil {
+			return err
+		}
+		return object.FalseConst
+	}
+	x := args[0].(*object.Float).Value
+
+	sign := int64(0)
+	if len(args) == 2 {
+		s, ok := args[1].(*object.Integer)
+		if !ok {
+			return object.NewTypeError("Argument 2 to `isInf` must be INTEGER, got %s", args[1].Type())
+		}
+		sign = s.Value
+	}
+	return object.NativeBoolToBooleanObj(math.IsInf(x, int(sign)))
+}
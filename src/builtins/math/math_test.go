@@ -0,0 +1,96 @@
+package math
+
+import (
+	"testing"
+
+	"github.com/nitrogen-lang/nitrogen/src/moduleutils"
+	"github.com/nitrogen-lang/nitrogen/src/object"
+)
+
+func TestMathFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`math.sqrt(16)`, "4"},
+		{`math.sin(math.pi / 2)`, "1"},
+		{`math.cosh(0)`, "1"},
+		{`math.atan2(1, 1) == math.pi / 4`, "true"},
+		{`math.log(math.e)`, "1"},
+		{`math.log(8, 2)`, "3"},
+		{`math.exp(0)`, "1"},
+		{`math.pow(2, 10)`, "1024"},
+		{`math.pow(2, 100)`, "1267650600228229401496703205376"},
+		{`math.pow(2, -1)`, "0.5"},
+		{`math.pow(4, 0.5)`, "2"},
+		{`math.abs(-5)`, "5"},
+		{`math.abs(math.minInt)`, "9223372036854775808"},
+		{`math.abs(-2.5)`, "2.5"},
+		{`math.floor(-2.5)`, "-3"},
+		{`math.ceil(2.1)`, "3"},
+		{`math.round(2.5)`, "3"},
+		{`math.trunc(-2.7)`, "-2"},
+		{`math.floor(7)`, "7"},
+		{`math.max(1, 2.5, 2)`, "2.5"},
+		{`math.min([3, -1, 2])`, "-1"},
+		{`math.max([1, math.maxInt + 1])`, "9223372036854775808"},
+		{`math.isNaN(math.sqrt(-1))`, "true"},
+		{`math.isNaN(1)`, "false"},
+		{`math.isInf(math.inf)`, "true"},
+		{`math.isInf(-math.inf, 1)`, "false"},
+		{`math.maxInt`, "9223372036854775807"},
+	}
+
+	for _, tt := range tests {
+		result := moduleutils.TestEvalModule(t, tt.input, ModuleName)
+		if ex, ok := result.(*object.Exception); ok {
+			t.Errorf("%s: unexpected exception %q", tt.input, ex.Message)
+			continue
+		}
+		if result.Inspect() != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.input, tt.expected, result.Inspect())
+		}
+	}
+}
+
+func TestMathErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`math.sqrt("4")`, "Argument 1 to `sqrt` must be a number, got STRING"},
+		{`math.max([])`, "max of an empty array"},
+		{`math.min(1, "2")`, "Values given to `min` must be numbers, got STRING"},
+		{`math.pow(3, 10000000)`, "Result of `pow` is too large"},
+		{`math.rand.int(5, 5)`, "Empty range given to `int`"},
+		{`math.rand.choice([])`, "choice from an empty array"},
+		{`math.rand.secureBytes(-1)`, "Length of `secureBytes` must be from 0 to 1048576, got -1"},
+	}
+
+	for _, tt := range tests {
+		moduleutils.TestLiteralErrorObjects(t, moduleutils.TestEvalModule(t, tt.input, ModuleName), tt.expected)
+	}
+}
+
+func TestRand(t *testing.T) {
+	seeded := `
+	math.rand.seed(42)
+	[math.rand.int(1000), math.rand.float(), math.rand.shuffle([1, 2, 3, 4, 5]), math.rand.choice(["a", "b", "c"])]`
+
+	first := moduleutils.TestEvalModule(t, seeded, ModuleName).Inspect()
+	if second := moduleutils.TestEvalModule(t, seeded, ModuleName).Inspect(); first != second {
+		t.Errorf("seeded generator isn't repeatable: %s != %s", first, second)
+	}
+
+	tests := []string{
+		`let n = math.rand.int(10); n >= 0 and n < 10`,
+		`let n = math.rand.int(-5, -3); n == -5 or n == -4`,
+		`let n = math.rand.int(math.maxInt, math.maxInt * 4); n >= math.maxInt and n < math.maxInt * 4`,
+		`let f = math.rand.float(); f >= 0.0 and f < 1.0`,
+		`let n = math.rand.secureInt(1, 7); n >= 1 and n < 7`,
+		`let b = math.rand.secureBytes(16); b[15] >= 0 and b[15] < 256`,
+	}
+	for _, input := range tests {
+		moduleutils.TestLiteralErrorObjects(t, moduleutils.TestEvalModule(t, input, ModuleName), true)
+	}
+}
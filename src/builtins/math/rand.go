@@ -0,0 +1,192 @@
+package math
+
+import (
+	crand "crypto/rand"
+	"math/big"
+	"math/bits"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/nitrogen-lang/nitrogen/src/moduleutils"
+	"github.com/nitrogen-lang/nitrogen/src/object"
+)
+
+// randModule is math.rand. Its pseudo-random generator is seeded randomly
+// unless a script calls seed. The secure functions use the system's
+// cryptographic random source and can't be seeded.
+var randModule = &object.Module{
+	Name: "rand",
+	Methods: map[string]object.BuiltinFunction{
+		"seed":        seedRand,
+		"int":         randInt,
+		"float":       randFloat,
+		"shuffle":     shuffleRand,
+		"choice":      choiceRand,
+		"secureInt":   secureInt,
+		"secureBytes": secureBytes,
+	},
+	Vars: map[string]object.Object{
+		"name": object.MakeStringObj("rand"),
+	},
+}
+
+// generator is the pseudo-random generator of math.rand. A rand.Rand isn't safe
+// for concurrent use so it's guarded by the mutex.
+var generator = struct {
+	sync.Mutex
+	*rand.Rand
+}{Rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+
+func seedRand(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("seed", 1, args...); ac != nil {
+		return ac
+	}
+	seed, ok := args[0].(*object.Integer)
+	if !ok {
+		return object.NewTypeError("Argument to `seed` must be INTEGER, got %s", args[0].Type())
+	}
+
+	generator.Lock()
+	generator.Rand = rand.New(rand.NewSource(seed.Value))
+	generator.Unlock()
+	return object.NullConst
+}
+
+// randBounds returns the range [low, high) of randInt and secureInt, given as (high) or (low, high).
+func randBounds(name string, args []object.Object) (low, high *big.Int, err object.Object) {
+	if ac := moduleutils.CheckMinArgs(name, 1, args...); ac != nil {
+		return nil, nil, ac
+	}
+	if len(args) > 2 {
+		return nil, nil, object.NewArgumentError("%s expects at most 2 argument(s). Got %d", name, len(args))
+	}
+
+	bounds := []*big.Int{big.NewInt(0)}
+	for i, arg := range args {
+		if !object.ObjectIs(arg, object.IntergerObj, object.BigIntObj) {
+			return nil, nil, object.NewTypeError("Argument %d to `%s` must be INTEGER, got %s", i+1, name, arg.Type())
+		}
+		bounds = append(bounds, bigValue(arg))
+	}
+	low, high = bounds[len(bounds)-2], bounds[len(bounds)-1]
+
+	if low.Cmp(high) >= 0 {
+		return nil, nil, object.NewException("Empty range given to `%s`", name)
+	}
+	return low, high, nil
+}
+
+func randInt(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	low, high, err := randBounds("int", args)
+	if err != nil {
+		return err
+	}
+	n := new(big.Int).Sub(high, low)
+
+	generator.Lock()
+	defer generator.Unlock()
+
+	if n.IsInt64() {
+		return object.NewInt(new(big.Int).Add(low, big.NewInt(generator.Int63n(n.Int64()))))
+	}
+
+	// Build a number with enough random bits and reject it until it's in the range
+	words := make([]big.Word, len(n.Bits()))
+	for {
+		for i := range words {
+			words[i] = big.Word(generator.Uint64())
+		}
+		r := new(big.Int).SetBits(words)
+		r.Rsh(r, uint(len(words)*bits.UintSize-n.BitLen()))
+		if r.Cmp(n) < 0 {
+			return object.NewInt(r.Add(r, low))
+		}
+	}
+}
+
+func randFloat(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("float", 0, args...); ac != nil {
+		return ac
+	}
+	generator.Lock()
+	defer generator.Unlock()
+	return &object.Float{Value: generator.Float64()}
+}
+
+func shuffleRand(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("shuffle", 1, args...); ac != nil {
+		return ac
+	}
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return object.NewTypeError("Argument to `shuffle` must be ARRAY, got %s", args[0].Type())
+	}
+
+	elements := make([]object.Object, len(arr.Elements))
+	copy(elements, arr.Elements)
+
+	generator.Lock()
+	generator.Shuffle(len(elements), func(i, j int) {
+		elements[i], elements[j] = elements[j], elements[i]
+	})
+	generator.Unlock()
+	return &object.Array{Elements: elements}
+}
+
+func choiceRand(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("choice", 1, args...); ac != nil {
+		return ac
+	}
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return object.NewTypeError("Argument to `choice` must be ARRAY, got %s", args[0].Type())
+	}
+	if len(arr.Elements) == 0 {
+		return object.NewException("choice from an empty array")
+	}
+
+	generator.Lock()
+	defer generator.Unlock()
+	return arr.Elements[generator.Intn(len(arr.Elements))]
+}
+
+func secureInt(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	low, high, err := randBounds("secureInt", args)
+	if err != nil {
+		return err
+	}
+
+	r, rerr := crand.Int(crand.Reader, new(big.Int).Sub(high, low))
+	if rerr != nil {
+		return object.NewException("Error reading random source: %s", rerr.Error())
+	}
+	return object.NewInt(r.Add(r, low))
+}
+
+// maxSecureBytes limits the size of the array returned by secureBytes.
+const maxSecureBytes = 1 << 20
+
+func secureBytes(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("secureBytes", 1, args...); ac != nil {
+		return ac
+	}
+	n, ok := args[0].(*object.Integer)
+	if !ok {
+		return object.NewTypeError("Argument to `secureBytes` must be INTEGER, got %s", args[0].Type())
+	}
+	if n.Value < 0 || n.Value > maxSecureBytes {
+		return object.NewException("Length of `secureBytes` must be from 0 to %d, got %d", maxSecureBytes, n.Value)
+	}
+
+	buf := make([]byte, n.Value)
+	if _, err := crand.Read(buf); err != nil {
+		return object.NewException("Error reading random source: %s", err.Error())
+	}
+
+	elements := make([]object.Object, len(buf))
+	for i, b := range buf {
+		elements[i] = &object.Integer{Value: int64(b)}
+	}
+	return &object.Array{Elements: elements}
+}
@@ -0,0 +1,451 @@
+package time
+
+import (
+	"math"
+	"math/big"
+	"time"
+	_ "time/tzdata" // Time zones work without the system's database
+
+	"github.com/nitrogen-lang/nitrogen/src/eval"
+	"github.com/nitrogen-lang/nitrogen/src/moduleutils"
+	"github.com/nitrogen-lang/nitrogen/src/object"
+)
+
+// ModuleName is the name the module is registered with, scripts load it with module("time").
+var ModuleName = "time"
+
+// start is the reference point of the monotonic clock.
+var start = time.Now()
+
+func init() {
+	eval.RegisterModule(ModuleName, &object.Module{
+		Name: ModuleName,
+		Methods: map[string]object.BuiltinFunction{
+			"now":           nowTime,
+			"monotonic":     monotonicClock,
+			"sleep":         sleepTime,
+			"date":          makeDate,
+			"parse":         parseTime,
+			"format":        formatTime,
+			"inZone":        inZone,
+			"zone":          zoneName,
+			"offset":        zoneOffset,
+			"parts":         timeParts,
+			"addDate":       addDate,
+			"since":         sinceTime,
+			"duration":      makeDuration,
+			"seconds":       durationSeconds,
+			"milliseconds":  durationMilliseconds,
+			"nanoseconds":   durationNanoseconds,
+			"unix":          unixTime,
+			"unixMilli":     unixMilliTime,
+			"unixNano":      unixNanoTime,
+			"fromUnix":      fromUnix,
+			"fromUnixMilli": fromUnixMilli,
+		},
+		Vars: map[string]object.Object{
+			"name": object.MakeStringObj(ModuleName),
+
+			"nanosecond":  &object.Duration{Value: time.Nanosecond},
+			"microsecond": &object.Duration{Value: time.Microsecond},
+			"millisecond": &object.Duration{Value: time.Millisecond},
+			"second":      &object.Duration{Value: time.Second},
+			"minute":      &object.Duration{Value: time.Minute},
+			"hour":        &object.Duration{Value: time.Hour},
+
+			"ANSIC":       object.MakeStringObj(time.ANSIC),
+			"RFC822":      object.MakeStringObj(time.RFC822),
+			"RFC1123":     object.MakeStringObj(time.RFC1123),
+			"RFC3339":     object.MakeStringObj(time.RFC3339),
+			"RFC3339Nano": object.MakeStringObj(time.RFC3339Nano),
+			"Kitchen":     object.MakeStringObj(time.Kitchen),
+			"DateTime":    object.MakeStringObj(time.DateTime),
+			"DateOnly":    object.MakeStringObj(time.DateOnly),
+			"TimeOnly":    object.MakeStringObj(time.TimeOnly),
+		},
+	})
+}
+
+func timeArg(name string, args []object.Object, i int) (time.Time, object.Object) {
+	t, ok := args[i].(*object.Time)
+	if !ok {
+		return time.Time{}, object.NewTypeError("Argument %d to `%s` must be TIME, got %s", i+1, name, args[i].Type())
+	}
+	return t.Value, nil
+}
+
+func durationArg(name string, args []object.Object, i int) (time.Duration, object.Object) {
+	d, ok := args[i].(*object.Duration)
+	if !ok {
+		return 0, object.NewTypeError("Argument %d to `%s` must be DURATION, got %s", i+1, name, args[i].Type())
+	}
+	return d.Value, nil
+}
+
+func stringArg(name string, args []object.Object, i int) (string, object.Object) {
+	s, ok := args[i].(*object.String)
+	if !ok {
+		return "", object.NewTypeError("Argument %d to `%s` must be STRING, got %s", i+1, name, args[i].Type())
+	}
+	return s.Value, nil
+}
+
+func intArg(name string, args []object.Object, i int) (int64, object.Object) {
+	n, ok := args[i].(*object.Integer)
+	if !ok {
+		return 0, object.NewTypeError("Argument %d to `%s` must be INTEGER, got %s", i+1, name, args[i].Type())
+	}
+	return n.Value, nil
+}
+
+// secondsArg accepts a duration or a number of seconds.
+func secondsArg(name string, args []object.Object, i int) (time.Duration, object.Object) {
+	switch arg := args[i].(type) {
+	case *object.Duration:
+		return arg.Value, nil
+	case *object.Integer:
+		if arg.Value > math.MaxInt64/int64(time.Second) || arg.Value < math.MinInt64/int64(time.Second) {
+			return 0, object.NewException("Duration overflow")
+		}
+		return time.Duration(arg.Value) * time.Second, nil
+	case *object.Float:
+		ns := math.Round(arg.Value * float64(time.Second))
+		if math.IsNaN(ns) || ns >= math.MaxInt64 || ns < math.MinInt64 {
+			return 0, object.NewException("Duration overflow")
+		}
+		return time.Duration(ns), nil
+	}
+	return 0, object.NewTypeError("Argument %d to `%s` must be DURATION or a number of seconds, got %s", i+1, name, args[i].Type())
+}
+
+// locationArg loads a time zone by its IANA name such as "Europe/Paris", "UTC", or "Local".
+func locationArg(name string, args []object.Object, i int) (*time.Location, object.Object) {
+	zone, err := stringArg(name, args, i)
+	if err != nil {
+		return nil, err
+	}
+	loc, lerr := time.LoadLocation(zone)
+	if lerr != nil {
+		return nil, object.NewException("Unknown time zone %s", zone)
+	}
+	return loc, nil
+}
+
+func nowTime(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("now", 0, args...); ac != nil {
+		return ac
+	}
+	return &object.Time{Value: time.Now()}
+}
+
+func monotonicClock(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("monotonic", 0, args...); ac != nil {
+		return ac
+	}
+	return &object.Duration{Value: time.Since(start)}
+}
+
+func sleepTime(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("sleep", 1, args...); ac != nil {
+		return ac
+	}
+	d, err := secondsArg("sleep", args, 0)
+	if err != nil {
+		return err
+	}
+	time.Sleep(d)
+	return object.NullConst
+}
+
+func makeDate(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckMinArgs("date", 3, args...); ac != nil {
+		return ac
+	}
+	if len(args) > 8 {
+		return object.NewArgumentError("date expects at most 8 argument(s). Got %d", len(args))
+	}
+
+	// year, month, day, hour, minute, second, nanosecond
+	var fields [7]int
+	for i := 0; i < len(args) && i < len(fields); i++ {
+		n, err := intArg("date", args, i)
+		if err != nil {
+			return err
+		}
+		fields[i] = int(n)
+	}
+
+	loc := time.Local
+	if len(args) == 8 {
+		var err object.Object
+		if loc, err = locationArg("date", args, 7); err != nil {
+			return err
+		}
+	}
+
+	return &object.Time{Value: time.Date(
+		fields[0], time.Month(fields[1]), fields[2], fields[3], fields[4], fields[5], fields[6], loc,
+	)}
+}
+
+func parseTime(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckMinArgs("parse", 2, args...); ac != nil {
+		return ac
+	}
+	if len(args) > 3 {
+		return object.NewArgumentError("parse expects at most 3 argument(s). Got %d", len(args))
+	}
+	layout, err := stringArg("parse", args, 0)
+	if err != nil {
+		return err
+	}
+	value, err := stringArg("parse", args, 1)
+	if err != nil {
+		return err
+	}
+
+	loc := time.UTC
+	if len(args) == 3 {
+		if loc, err = locationArg("parse", args, 2); err != nil {
+			return err
+		}
+	}
+
+	t, perr := time.ParseInLocation(layout, value, loc)
+	if perr != nil {
+		return object.NewException("Invalid time: %s", perr.Error())
+	}
+	return &object.Time{Value: t}
+}
+
+func formatTime(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("format", 2, args...); ac != nil {
+		return ac
+	}
+	t, err := timeArg("format", args, 0)
+	if err != nil {
+		return err
+	}
+	layout, err := stringArg("format", args, 1)
+	if err != nil {
+		return err
+	}
+	return object.MakeStringObj(t.Format(layout))
+}
+
+func inZone(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("inZone", 2, args...); ac != nil {
+		return ac
+	}
+	t, err := timeArg("inZone", args, 0)
+	if err != nil {
+		return err
+	}
+	loc, err := locationArg("inZone", args, 1)
+	if err != nil {
+		return err
+	}
+	return &object.Time{Value: t.In(loc)}
+}
+
+func zoneName(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("zone", 1, args...); ac != nil {
+		return ac
+	}
+	t, err := timeArg("zone", args, 0)
+	if err != nil {
+		return err
+	}
+	return object.MakeStringObj(t.Location().String())
+}
+
+func zoneOffset(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("offset", 1, args...); ac != nil {
+		return ac
+	}
+	t, err := timeArg("offset", args, 0)
+	if err != nil {
+		return err
+	}
+	_, offset := t.Zone()
+	return &object.Duration{Value: time.Duration(offset) * time.Second}
+}
+
+func timeParts(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("parts", 1, args...); ac != nil {
+		return ac
+	}
+	t, err := timeArg("parts", args, 0)
+	if err != nil {
+		return err
+	}
+
+	abbreviation, _ := t.Zone()
+	parts := object.NewHash(10)
+	set := func(key string, val int) {
+		parts.Set(object.MakeStringObj(key), &object.Integer{Value: int64(val)})
+	}
+	set("year", t.Year())
+	set("month", int(t.Month()))
+	set("day", t.Day())
+	set("hour", t.Hour())
+	set("minute", t.Minute())
+	set("second", t.Second())
+	set("nanosecond", t.Nanosecond())
+	set("weekday", int(t.Weekday()))
+	set("yearDay", t.YearDay())
+	parts.Set(object.MakeStringObj("zone"), object.MakeStringObj(abbreviation))
+	return parts
+}
+
+func addDate(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("addDate", 4, args...); ac != nil {
+		return ac
+	}
+	t, err := timeArg("addDate", args, 0)
+	if err != nil {
+		return err
+	}
+
+	var fields [3]int
+	for i := range fields {
+		n, err := intArg("addDate", args, i+1)
+		if err != nil {
+			return err
+		}
+		fields[i] = int(n)
+	}
+	return &object.Time{Value: t.AddDate(fields[0], fields[1], fields[2])}
+}
+
+func sinceTime(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("since", 1, args...); ac != nil {
+		return ac
+	}
+	t, err := timeArg("since", args, 0)
+	if err != nil {
+		return err
+	}
+	return &object.Duration{Value: time.Since(t)}
+}
+
+func makeDuration(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("duration", 1, args...); ac != nil {
+		return ac
+	}
+
+	if s, ok := args[0].(*object.String); ok {
+		d, err := time.ParseDuration(s.Value)
+		if err != nil {
+			return object.NewException("Invalid duration: %s", s.Value)
+		}
+		return &object.Duration{Value: d}
+	}
+
+	d, err := secondsArg("duration", args, 0)
+	if err != nil {
+		return err
+	}
+	return &object.Duration{Value: d}
+}
+
+func durationSeconds(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("seconds", 1, args...); ac != nil {
+		return ac
+	}
+	d, err := durationArg("seconds", args, 0)
+	if err != nil {
+		return err
+	}
+	return &object.Float{Value: d.Seconds()}
+}
+
+func durationMilliseconds(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("milliseconds", 1, args...); ac != nil {
+		return ac
+	}
+	d, err := durationArg("milliseconds", args, 0)
+	if err != nil {
+		return err
+	}
+	return &object.Integer{Value: d.Milliseconds()}
+}
+
+func durationNanoseconds(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("nanoseconds", 1, args...); ac != nil {
+		return ac
+	}
+	d, err := durationArg("nanoseconds", args, 0)
+	if err != nil {
+		return err
+	}
+	return &object.Integer{Value: d.Nanoseconds()}
+}
+
+func unixTime(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("unix", 1, args...); ac != nil {
+		return ac
+	}
+	t, err := timeArg("unix", args, 0)
+	if err != nil {
+		return err
+	}
+	return &object.Integer{Value: t.Unix()}
+}
+
+func unixMilliTime(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("unixMilli", 1, args...); ac != nil {
+		return ac
+	}
+	t, err := timeArg("unixMilli", args, 0)
+	if err != nil {
+		return err
+	}
+	return &object.Integer{Value: t.UnixMilli()}
+}
+
+// unixNanoTime returns a BIGINT for times that don't fit in an int64 of nanoseconds.
+func unixNanoTime(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("unixNano", 1, args...); ac != nil {
+		return ac
+	}
+	t, err := timeArg("unixNano", args, 0)
+	if err != nil {
+		return err
+	}
+
+	ns := new(big.Int).Mul(big.NewInt(t.Unix()), big.NewInt(int64(time.Second)))
+	return object.NewInt(ns.Add(ns, big.NewInt(int64(t.Nanosecond()))))
+}
+
+func fromUnix(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckMinArgs("fromUnix", 1, args...); ac != nil {
+		return ac
+	}
+	if len(args) > 2 {
+		return object.NewArgumentError("fromUnix expects at most 2 argument(s). Got %d", len(args))
+	}
+	sec, err := intArg("fromUnix", args, 0)
+	if err != nil {
+		return err
+	}
+
+	var nsec int64
+	if len(args) == 2 {
+		if nsec, err = intArg("fromUnix", args, 1); err != nil {
+			return err
+		}
+	}
+	return &object.Time{Value: time.Unix(sec, nsec)}
+}
+
+func fromUnixMilli(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("fromUnixMilli", 1, args...); ac != nil {
+		return ac
+	}
+	ms, err := intArg("fromUnixMilli", args, 0)
+	if err != nil {
+		return err
+	}
+	return &object.Time{Value: time.UnixMilli(ms)}
+}
@@ -0,0 +1,69 @@
+package time
+
+import (
+	"testing"
+
+	_ "github.com/nitrogen-lang/nitrogen/src/builtins/collections"
+	"github.com/nitrogen-lang/nitrogen/src/moduleutils"
+	"github.com/nitrogen-lang/nitrogen/src/object"
+)
+
+func TestTimeValues(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`time.date(2024, 2, 29, 13, 4, 5, 0, "UTC")`, "2024-02-29T13:04:05Z"},
+		{`time.inZone(time.date(2024, 7, 1, 12, 0, 0, 0, "UTC"), "America/New_York")`, "2024-07-01T08:00:00-04:00"},
+		{`time.parse(time.DateTime, "2024-01-02 03:04:05", "Asia/Tokyo")`, "2024-01-02T03:04:05+09:00"},
+		{`time.format(time.date(2024, 1, 2, 15, 4, 0, 0, "UTC"), "Jan 2, 2006 at 3:04pm")`, "Jan 2, 2024 at 3:04pm"},
+		{`time.date(2024, 1, 31, 0, 0, 0, 0, "UTC") + 36 * time.hour`, "2024-02-01T12:00:00Z"},
+		{`time.addDate(time.date(2024, 1, 31, 0, 0, 0, 0, "UTC"), 0, 1, 0)`, "2024-03-02T00:00:00Z"},
+		{`time.date(2024, 1, 2, 0, 0, 0, 0, "UTC") - time.date(2024, 1, 1, 0, 0, 0, 0, "UTC")`, "24h0m0s"},
+		{`time.duration("1h30m") / time.hour`, "1.5"},
+		{`time.duration(90) - time.minute * 0.5`, "1m0s"},
+		{`-time.duration("1s") % time.duration("400ms")`, "-200ms"},
+		{`time.fromUnix(1700000000, 5) == time.inZone(time.fromUnix(1700000000, 5), "UTC")`, "true"},
+		{`time.date(2024, 1, 1, 0, 0, 0, 0, "UTC") < time.date(2024, 1, 1, 0, 0, 0, 1, "UTC")`, "true"},
+		{`time.unix(time.date(1970, 1, 2, 0, 0, 0, 0, "UTC"))`, "86400"},
+		{`time.unixNano(time.date(2300, 1, 1, 0, 0, 0, 0, "UTC"))`, "10413792000000000000"},
+		{`time.inZone(time.fromUnixMilli(1500), "UTC")`, "1970-01-01T00:00:01.5Z"},
+		{`time.milliseconds(time.duration(1.5))`, "1500"},
+		{`time.offset(time.inZone(time.fromUnix(0), "Asia/Kolkata"))`, "5h30m0s"},
+		{`time.parts(time.date(2024, 3, 10, 1, 2, 3, 4, "UTC"))`, "{year: 2024, month: 3, day: 10, hour: 1, minute: 2, second: 3, nanosecond: 4, weekday: 0, yearDay: 70, zone: UTC}"},
+		{`sort([time.minute, time.second, time.hour])`, "[1s, 1m0s, 1h0m0s]"},
+		{`let d = time.date(2024, 1, 1, 0, 0, 0, 0, "UTC"); {d: 1}[time.inZone(d, "Europe/Paris")]`, "1"},
+		{`let start = time.monotonic(); time.sleep(time.millisecond); time.monotonic() - start >= time.millisecond`, "true"},
+	}
+
+	for _, tt := range tests {
+		result := moduleutils.TestEvalModule(t, tt.input, ModuleName)
+		if ex, ok := result.(*object.Exception); ok {
+			t.Errorf("%s: unexpected exception %q", tt.input, ex.Message)
+			continue
+		}
+		if result.Inspect() != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.input, tt.expected, result.Inspect())
+		}
+	}
+}
+
+func TestTimeErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`time.inZone(time.now(), "Mars/Olympus")`, "Unknown time zone Mars/Olympus"},
+		{`time.parse(time.DateOnly, "2024-13-01")`, `Invalid time: parsing time "2024-13-01": month out of range`},
+		{`time.duration("soon")`, "Invalid duration: soon"},
+		{`time.format(1, time.RFC3339)`, "Argument 1 to `format` must be TIME, got INTEGER"},
+		{`time.sleep("1s")`, "Argument 1 to `sleep` must be DURATION or a number of seconds, got STRING"},
+		{`time.now() + time.now()`, "unknown operator: TIME + TIME"},
+		{`time.second / 0`, "Duration division by zero"},
+		{`time.hour * 9223372036854775807`, "Duration overflow"},
+	}
+
+	for _, tt := range tests {
+		moduleutils.TestLiteralErrorObjects(t, moduleutils.TestEvalModule(t, tt.input, ModuleName), tt.expected)
+	}
+}
@@ -1,6 +1,8 @@
 package typing
 
 import (
+	"math"
+	"math/big"
 	"strconv"
 
 	"github.com/nitrogen-lang/nitrogen/src/eval"
@@ -8,10 +10,13 @@ import (
 	"github.com/nitrogen-lang/nitrogen/src/object"
 )
 
+const maxDecimalScale = 1000
+
 func init() {
 	eval.RegisterBuiltin("toInt", toIntBuiltin)
 	eval.RegisterBuiltin("toFloat", toFloatBuiltin)
 	eval.RegisterBuiltin("toString", toStringBuiltin)
+	eval.RegisterBuiltin("decimal", decimalBuiltin)
 
 	eval.RegisterBuiltin("parseInt", parseIntBuiltin)
 	eval.RegisterBuiltin("parseFloat", parseFloatBuiltin)
@@ -19,7 +24,8 @@ func init() {
 	eval.RegisterBuiltin("varType", varTypeBuiltin)
 	eval.RegisterBuiltin("isDefined", isDefinedBuiltin)
 	eval.RegisterBuiltin("isFloat", makeIsTypeBuiltin(object.FloatObj))
-	eval.RegisterBuiltin("isInt", makeIsTypeBuiltin(object.IntergerObj))
+	eval.RegisterBuiltin("isInt", makeIsTypeBuiltin(object.IntergerObj, object.BigIntObj))
+	eval.RegisterBuiltin("isDecimal", makeIsTypeBuiltin(object.DecimalObj))
 	eval.RegisterBuiltin("isBool", makeIsTypeBuiltin(object.BooleanObj))
 	eval.RegisterBuiltin("isNull", makeIsTypeBuiltin(object.NullObj))
 	eval.RegisterBuiltin("isFunc", makeIsTypeBuiltin(object.FunctionObj))
@@ -31,45 +37,60 @@ func init() {
 	eval.RegisterBuiltin("isInstance", makeIsTypeBuiltin(object.InstanceObj))
 
 	eval.RegisterBuiltin("errorVal", getErrorVal)
+	eval.RegisterBuiltin("errorTrace", getErrorTrace)
 }
 
 func toIntBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
 	if len(args) != 1 {
-		return object.NewException("Incorrect number of arguments. Got %d, expected 1", len(args))
+		return object.NewArgumentError("Incorrect number of arguments. Got %d, expected 1", len(args))
 	}
 
 	switch arg := args[0].(type) {
-	case *object.Integer:
+	case *object.Integer, *object.BigInt:
 		return arg
 	case *object.Float:
-		return &object.Integer{Value: int64(arg.Value)}
+		if math.IsInf(arg.Value, 0) || math.IsNaN(arg.Value) {
+			return object.NewArgumentError("Can't convert %s to an int", arg.Inspect())
+		}
+		if arg.Value >= math.MinInt64 && arg.Value < math.MaxInt64 {
+			return &object.Integer{Value: int64(arg.Value)}
+		}
+		i, _ := big.NewFloat(arg.Value).Int(nil)
+		return &object.BigInt{Value: i}
+	case *object.Decimal:
+		return object.NewInt(arg.Int())
 	}
 
-	return object.NewException("Argument to `toInt` must be FLOAT or INT, got %s", args[0].Type())
+	return object.NewTypeError("Argument to `toInt` must be FLOAT or INT, got %s", args[0].Type())
 }
 
 func toFloatBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
 	if len(args) != 1 {
-		return object.NewException("Incorrect number of arguments. Got %d, expected 1", len(args))
+		return object.NewArgumentError("Incorrect number of arguments. Got %d, expected 1", len(args))
 	}
 
 	switch arg := args[0].(type) {
 	case *object.Integer:
 		return &object.Float{Value: float64(arg.Value)}
+	case *object.BigInt:
+		f, _ := new(big.Float).SetInt(arg.Value).Float64()
+		return &object.Float{Value: f}
+	case *object.Decimal:
+		return &object.Float{Value: arg.Float64()}
 	case *object.Float:
 		return arg
 	}
 
-	return object.NewException("Argument to `toFloat` must be FLOAT or INT, got %s", args[0].Type())
+	return object.NewTypeError("Argument to `toFloat` must be FLOAT or INT, got %s", args[0].Type())
 }
 
-func makeIsTypeBuiltin(t object.ObjectType) object.BuiltinFunction {
+func makeIsTypeBuiltin(t ...object.ObjectType) object.BuiltinFunction {
 	return func(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
 		if len(args) != 1 {
-			return object.NewException("Type check requires one argument. Got %d", len(args))
+			return object.NewArgumentError("Type check requires one argument. Got %d", len(args))
 		}
 
-		return object.NativeBoolToBooleanObj(args[0].Type() == t)
+		return object.NativeBoolToBooleanObj(object.ObjectIs(args[0], t...))
 	}
 }
 
@@ -92,9 +113,20 @@ func getErrorVal(interpreter object.Interpreter, env *object.Environment, args .
 	return &object.String{Value: ""}
 }
 
+func getErrorTrace(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckArgs("errorTrace", 1, args...); ac != nil {
+		return ac
+	}
+
+	if exc, ok := args[0].(*object.Exception); ok {
+		return &object.String{Value: exc.StackTrace()}
+	}
+	return &object.String{Value: ""}
+}
+
 func toStringBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
 	if len(args) != 1 {
-		return object.NewException("toString expects 1 argument. Got %d", len(args))
+		return object.NewArgumentError("toString expects 1 argument. Got %d", len(args))
 	}
 
 	converted := ""
@@ -106,6 +138,8 @@ func toStringBuiltin(interpreter object.Interpreter, env *object.Environment, ar
 		converted = strconv.FormatFloat(arg.Value, 'G', -1, 64)
 	case *object.Integer:
 		converted = strconv.FormatInt(arg.Value, 10)
+	case *object.BigInt, *object.Decimal:
+		converted = arg.Inspect()
 	case *object.Boolean:
 		converted = strconv.FormatBool(arg.Value)
 	case *object.Null:
@@ -115,9 +149,66 @@ func toStringBuiltin(interpreter object.Interpreter, env *object.Environment, ar
 	return &object.String{Value: converted}
 }
 
+// decimalBuiltin makes a DECIMAL from a string, integer, float, or decimal. The scale is
+// the number of digits after the decimal point, by default it's the number of digits the
+// value has. rounding is the name of the rounding mode used when digits are dropped.
+func decimalBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
+	if ac := moduleutils.CheckMinArgs("decimal", 1, args...); ac != nil {
+		return ac
+	}
+	if len(args) > 3 {
+		return object.NewArgumentError("decimal expects at most 3 argument(s). Got %d", len(args))
+	}
+
+	rounding := object.RoundHalfEven
+	if len(args) == 3 {
+		name, ok := args[2].(*object.String)
+		if !ok {
+			return object.NewTypeError("Rounding mode of `decimal` must be STRING, got %s", args[2].Type())
+		}
+		if rounding, ok = object.ParseRoundingMode(name.Value); !ok {
+			return object.NewException("Unknown rounding mode: %s", name.Value)
+		}
+	}
+
+	var dec *object.Decimal
+	switch arg := args[0].(type) {
+	case *object.String:
+		var err error
+		if dec, err = object.ParseDecimal(arg.Value, rounding); err != nil {
+			return object.NewException("%s", err)
+		}
+	case *object.Float:
+		if math.IsInf(arg.Value, 0) || math.IsNaN(arg.Value) {
+			return object.NewException("Invalid decimal: %s", arg.Inspect())
+		}
+		dec, _ = object.ParseDecimal(strconv.FormatFloat(arg.Value, 'f', -1, 64), rounding)
+	case *object.Integer:
+		dec = object.NewDecimalFromInt(big.NewInt(arg.Value), rounding)
+	case *object.BigInt:
+		dec = object.NewDecimalFromInt(arg.Value, rounding)
+	case *object.Decimal:
+		dec = &object.Decimal{Unscaled: arg.Unscaled, Scale: arg.Scale, Rounding: arg.Rounding}
+		if len(args) == 3 {
+			dec.Rounding = rounding
+		}
+	default:
+		return object.NewTypeError("Argument to `decimal` must be STRING, INT, FLOAT, or DECIMAL, got %s", args[0].Type())
+	}
+
+	if len(args) > 1 {
+		scale, ok := args[1].(*object.Integer)
+		if !ok || scale.Value < 0 || scale.Value > maxDecimalScale {
+			return object.NewException("Scale of `decimal` must be an INTEGER from 0 to %d", maxDecimalScale)
+		}
+		dec = dec.Rescale(int(scale.Value))
+	}
+	return dec
+}
+
 func parseIntBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
 	if len(args) != 1 {
-		return object.NewException("parseInt expects 1 argument. Got %d", len(args))
+		return object.NewArgumentError("parseInt expects 1 argument. Got %d", len(args))
 	}
 
 	str, ok := args[0].(*object.String)
@@ -125,17 +216,17 @@ func parseIntBuiltin(interpreter object.Interpreter, env *object.Environment, ar
 		return object.NewException("parseInt expected a string, got %s", args[0].Type().String())
 	}
 
-	i, err := strconv.ParseInt(str.Value, 10, 64)
-	if err != nil {
+	i, ok := new(big.Int).SetString(str.Value, 10)
+	if !ok {
 		return object.NullConst
 	}
 
-	return &object.Integer{Value: i}
+	return object.NewInt(i)
 }
 
 func parseFloatBuiltin(interpreter object.Interpreter, env *object.Environment, args ...object.Object) object.Object {
 	if len(args) != 1 {
-		return object.NewException("parseFloat expects 1 argument. Got %d", len(args))
+		return object.NewArgumentError("parseFloat expects 1 argument. Got %d", len(args))
 	}
 
 	str, ok := args[0].(*object.String)
@@ -1,6 +1,7 @@
 package typing
 
 import (
+	"math"
 	"testing"
 
 	"github.com/nitrogen-lang/nitrogen/src/moduleutils"
@@ -16,6 +17,7 @@ func TestBuiltinIntConvFunction(t *testing.T) {
 		{`toInt(1)`, 1},
 		{`toInt("hello world")`, "Argument to `toInt` must be FLOAT or INT, got STRING"},
 		{`toInt([])`, "Argument to `toInt` must be FLOAT or INT, got ARRAY"},
+		{`toInt(-23.5)`, -23},
 	}
 
 	for _, tt := range tests {
@@ -23,6 +25,38 @@ func TestBuiltinIntConvFunction(t *testing.T) {
 	}
 }
 
+func TestIntConvOutOfRange(t *testing.T) {
+	tests := []struct {
+		input    float64
+		expected string
+	}{
+		{1e30, "1000000000000000019884624838656"},
+		{-1e30, "-1000000000000000019884624838656"},
+		{9223372036854775808, "9223372036854775808"},
+		{-9223372036854775808, "-9223372036854775808"},
+		{math.NaN(), "Can't convert NaN to an int"},
+		{math.Inf(1), "Can't convert +Inf to an int"},
+		{math.Inf(-1), "Can't convert -Inf to an int"},
+	}
+
+	for _, tt := range tests {
+		result := toIntBuiltin(nil, nil, &object.Float{Value: tt.input})
+		if exc, ok := result.(*object.Exception); ok {
+			if exc.Message != tt.expected || !exc.Is(object.ArgumentErrorClass) {
+				t.Errorf("toInt(%g): expected %s, got %s", tt.input, tt.expected, moduleutils.ShowError(exc))
+			}
+			continue
+		}
+		if result.Inspect() != tt.expected {
+			t.Errorf("toInt(%g): expected %s, got %s", tt.input, tt.expected, result.Inspect())
+		}
+	}
+
+	if result := moduleutils.TestEval(`varType(toInt(1000000000000000000000000000000.0))`); result.Inspect() != "BIGINT" {
+		t.Errorf("Expected a BIGINT, got %s", result.Inspect())
+	}
+}
+
 func TestBuiltinFloatConvFunction(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -175,3 +209,75 @@ func TestParseFloatBuiltin(t *testing.T) {
 		}
 	}
 }
+
+func TestDecimalBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`decimal("12.50")`, "12.50"},
+		{`decimal("-0.05")`, "-0.05"},
+		{`decimal(3)`, "3"},
+		{`decimal(2.25)`, "2.25"},
+		{`decimal("2.5", 3)`, "2.500"},
+		{`decimal("2.345", 2)`, "2.34"},
+		{`decimal("2.345", 2, "half-up")`, "2.35"},
+		{`decimal("-2.345", 2, "half-up")`, "-2.35"},
+		{`decimal("2.341", 2, "up")`, "2.35"},
+		{`decimal("-2.349", 2, "down")`, "-2.34"},
+		{`decimal("-2.341", 2, "floor")`, "-2.35"},
+		{`decimal("2.341", 2, "ceiling")`, "2.35"},
+		// Arithmetic keeps the larger scale and the left rounding mode
+		{`decimal("19.99") * 3`, "59.97"},
+		{`decimal("0.10") + decimal("0.2")`, "0.30"},
+		{`decimal("1.00") / 3`, "0.33"},
+		{`decimal("2.00") / 3`, "0.67"},
+		{`decimal("1.00", 2, "down") / decimal("0.3")`, "3.33"},
+		{`decimal("1.25") * decimal("1.5")`, "1.88"},
+		{`decimal("10.5") % 4`, "2.5"},
+		{`2 - decimal("0.5")`, "1.5"},
+		{`-decimal("1.5")`, "-1.5"},
+		{`decimal("2.50") == decimal("2.5")`, "true"},
+		{`decimal("0.1") < 1`, "true"},
+		{`let m = {}; m[decimal("1.50")] = 1; m[decimal("1.5")]`, "1"},
+		{`toString(decimal("1.10"))`, "1.10"},
+		{`toInt(decimal("-7.9"))`, "-7"},
+		{`toFloat(decimal("7.25"))`, "7.25"},
+		{`isDecimal(decimal(1))`, "true"},
+		{`decimal("1.5") + 1.5`, "type mismatch: DECIMAL + FLOAT"},
+		{`decimal("1.5") / 0`, "Decimal division by zero"},
+		{`decimal("1.2.3")`, `Invalid decimal: "1.2.3"`},
+		{`decimal("1", 2, "sideways")`, "Unknown rounding mode: sideways"},
+		{`decimal("1", -1)`, "Scale of `decimal` must be an INTEGER from 0 to 1000"},
+		{`decimal([])`, "Argument to `decimal` must be STRING, INT, FLOAT, or DECIMAL, got ARRAY"},
+	}
+
+	for _, tt := range tests {
+		evaled := moduleutils.TestEval(tt.input)
+		if evaled.Inspect() != tt.expected {
+			t.Errorf("wrong result for %s. expected=%s, got=%s", tt.input, tt.expected, evaled.Inspect())
+		}
+	}
+}
+
+func TestBigIntConversions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let a = 9223372036854775807 + 1; varType(a)`, "BIGINT"},
+		{`toString(9223372036854775807 + 1)`, "9223372036854775808"},
+		{`toInt(9223372036854775807 + 1)`, "9223372036854775808"},
+		{`toFloat(9223372036854775807 + 1)`, "9.223372036854776E+18"},
+		{`isInt(9223372036854775807 * 2)`, "true"},
+		{`parseInt("123456789012345678901234567890")`, "123456789012345678901234567890"},
+		{`decimal(9223372036854775807 + 1) + decimal("0.5")`, "9223372036854775808.5"},
+	}
+
+	for _, tt := range tests {
+		evaled := moduleutils.TestEval(tt.input)
+		if evaled.Inspect() != tt.expected {
+			t.Errorf("wrong result for %s. expected=%s, got=%s", tt.input, tt.expected, evaled.Inspect())
+		}
+	}
+}
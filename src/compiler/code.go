@@ -0,0 +1,284 @@
+package compiler
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// Instructions is a sequence of encoded opcodes and their operands.
+type Instructions []byte
+
+// Opcode is a single byte VM instruction.
+type Opcode byte
+
+// All opcodes understood by the VM. Operand widths are listed in the definitions table below.
+const (
+	OpConstant Opcode = iota
+	OpNull
+	OpTrue
+	OpFalse
+	OpPop
+
+	// Binary operators. The left operand is on top of the stack because
+	// operands are evaluated right to left like the tree walking interpreter.
+	OpAdd
+	OpSub
+	OpMul
+	OpDiv
+	OpMod
+	OpShiftLeft
+	OpShiftRight
+	OpBitAnd
+	OpBitOr
+	OpBitXor
+	OpBitAndNot
+	OpEqual
+	OpNotEqual
+	OpLessThan
+	OpGreaterThan
+	OpLessThanEq
+	OpGreaterThanEq
+	OpIn
+
+	// Prefix operators
+	OpMinus
+	OpBang
+
+	// Conditionals
+	OpTruthy
+	OpJump
+	OpJumpNotTruthy
+	OpJumpTrueOrPop
+	OpJumpFalseOrPop
+
+	// Loops
+	// OpIter replaces the collection on the stack with an iterator over it
+	OpIter
+	// OpIterNext pushes the next key and value of the iterator on the stack or jumps if it's finished
+	OpIterNext
+
+	// Variables
+	OpGetName
+	OpGetFunc
+	OpSetName
+	OpDefineName
+	OpDefineConstName
+	OpGetLocal
+	OpSetLocal
+	OpDefineLocal
+	OpClearLocal
+	OpGetCell
+	OpSetCell
+	OpDefineCell
+	OpClearCell
+	OpGetFree
+	OpSetFree
+	OpCheckConst
+	OpCheckName
+
+	// Collections
+	OpArray
+	OpHash
+	OpIndex
+	OpSetIndex
+	OpSlice
+	// OpInterpolate concatenates the parts of an interpolated string, the first part is deepest in the stack
+	OpInterpolate
+
+	// Functions and classes
+	OpClosure
+	OpCall
+	OpReturn
+	OpClass
+	OpMake
+
+	// Exceptions
+	OpSetupTry
+	OpPopTry
+	OpThrow
+	OpError
+	// OpMatchException pops a class and jumps if the exception on the stack isn't an instance of it
+	OpMatchException
+)
+
+// Definition describes an opcode for encoding and disassembly.
+type Definition struct {
+	Name          string
+	OperandWidths []int
+}
+
+var definitions = map[Opcode]*Definition{
+	OpConstant: {"OpConstant", []int{4}},
+	OpNull:     {"OpNull", []int{}},
+	OpTrue:     {"OpTrue", []int{}},
+	OpFalse:    {"OpFalse", []int{}},
+	OpPop:      {"OpPop", []int{}},
+
+	OpAdd:           {"OpAdd", []int{}},
+	OpSub:           {"OpSub", []int{}},
+	OpMul:           {"OpMul", []int{}},
+	OpDiv:           {"OpDiv", []int{}},
+	OpMod:           {"OpMod", []int{}},
+	OpShiftLeft:     {"OpShiftLeft", []int{}},
+	OpShiftRight:    {"OpShiftRight", []int{}},
+	OpBitAnd:        {"OpBitAnd", []int{}},
+	OpBitOr:         {"OpBitOr", []int{}},
+	OpBitXor:        {"OpBitXor", []int{}},
+	OpBitAndNot:     {"OpBitAndNot", []int{}},
+	OpEqual:         {"OpEqual", []int{}},
+	OpNotEqual:      {"OpNotEqual", []int{}},
+	OpLessThan:      {"OpLessThan", []int{}},
+	OpGreaterThan:   {"OpGreaterThan", []int{}},
+	OpLessThanEq:    {"OpLessThanEq", []int{}},
+	OpGreaterThanEq: {"OpGreaterThanEq", []int{}},
+	OpIn:            {"OpIn", []int{}},
+
+	OpMinus: {"OpMinus", []int{}},
+	OpBang:  {"OpBang", []int{}},
+
+	OpTruthy:         {"OpTruthy", []int{1}},
+	OpJump:           {"OpJump", []int{4}},
+	OpJumpNotTruthy:  {"OpJumpNotTruthy", []int{4}},
+	OpJumpTrueOrPop:  {"OpJumpTrueOrPop", []int{4}},
+	OpJumpFalseOrPop: {"OpJumpFalseOrPop", []int{4}},
+
+	OpIter:     {"OpIter", []int{}},
+	OpIterNext: {"OpIterNext", []int{4}},
+
+	OpGetName:         {"OpGetName", []int{4}},
+	OpGetFunc:         {"OpGetFunc", []int{4}},
+	OpSetName:         {"OpSetName", []int{4}},
+	OpDefineName:      {"OpDefineName", []int{4}},
+	OpDefineConstName: {"OpDefineConstName", []int{4}},
+	OpGetLocal:        {"OpGetLocal", []int{4}},
+	OpSetLocal:        {"OpSetLocal", []int{4}},
+	OpDefineLocal:     {"OpDefineLocal", []int{4}},
+	OpClearLocal:      {"OpClearLocal", []int{4}},
+	OpGetCell:         {"OpGetCell", []int{4}},
+	OpSetCell:         {"OpSetCell", []int{4}},
+	OpDefineCell:      {"OpDefineCell", []int{4}},
+	OpClearCell:       {"OpClearCell", []int{4}},
+	OpGetFree:         {"OpGetFree", []int{4}},
+	OpSetFree:         {"OpSetFree", []int{4}},
+	OpCheckConst:      {"OpCheckConst", []int{}},
+	OpCheckName:       {"OpCheckName", []int{4, 1}},
+
+	OpArray:    {"OpArray", []int{4}},
+	OpHash:     {"OpHash", []int{4}},
+	OpIndex:    {"OpIndex", []int{}},
+	OpSetIndex: {"OpSetIndex", []int{}},
+	OpSlice:    {"OpSlice", []int{}},
+
+	OpInterpolate: {"OpInterpolate", []int{4}},
+
+	OpClosure: {"OpClosure", []int{4}},
+	OpCall:    {"OpCall", []int{2}},
+	OpReturn:  {"OpReturn", []int{}},
+	OpClass:   {"OpClass", []int{4, 1}},
+	OpMake:    {"OpMake", []int{4, 2}},
+
+	OpSetupTry: {"OpSetupTry", []int{4}},
+	OpPopTry:   {"OpPopTry", []int{}},
+	OpThrow:    {"OpThrow", []int{}},
+	OpError:    {"OpError", []int{4}},
+
+	OpMatchException: {"OpMatchException", []int{4}},
+}
+
+// Lookup returns the definition of an opcode.
+func Lookup(op byte) (*Definition, error) {
+	def, ok := definitions[Opcode(op)]
+	if !ok {
+		return nil, fmt.Errorf("opcode %d undefined", op)
+	}
+	return def, nil
+}
+
+// Make encodes an instruction with its operands.
+func Make(op Opcode, operands ...int) []byte {
+	def, ok := definitions[op]
+	if !ok {
+		return []byte{}
+	}
+
+	length := 1
+	for _, w := range def.OperandWidths {
+		length += w
+	}
+
+	instruction := make([]byte, length)
+	instruction[0] = byte(op)
+
+	offset := 1
+	for i, o := range operands {
+		width := def.OperandWidths[i]
+		switch width {
+		case 4:
+			binary.BigEndian.PutUint32(instruction[offset:], uint32(o))
+		case 2:
+			binary.BigEndian.PutUint16(instruction[offset:], uint16(o))
+		case 1:
+			instruction[offset] = byte(o)
+		}
+		offset += width
+	}
+
+	return instruction
+}
+
+// ReadOperands decodes the operands of an instruction and returns them with the number of bytes read.
+func ReadOperands(def *Definition, ins Instructions) ([]int, int) {
+	operands := make([]int, len(def.OperandWidths))
+	offset := 0
+
+	for i, width := range def.OperandWidths {
+		switch width {
+		case 4:
+			operands[i] = int(ReadUint32(ins[offset:]))
+		case 2:
+			operands[i] = int(ReadUint16(ins[offset:]))
+		case 1:
+			operands[i] = int(ins[offset])
+		}
+		offset += width
+	}
+
+	return operands, offset
+}
+
+// ReadUint32 decodes a four byte operand.
+func ReadUint32(ins Instructions) uint32 {
+	return binary.BigEndian.Uint32(ins)
+}
+
+// ReadUint16 decodes a two byte operand.
+func ReadUint16(ins Instructions) uint16 {
+	return binary.BigEndian.Uint16(ins)
+}
+
+// String disassembles the instructions, one per line.
+func (ins Instructions) String() string {
+	var out bytes.Buffer
+
+	i := 0
+	for i < len(ins) {
+		def, err := Lookup(ins[i])
+		if err != nil {
+			fmt.Fprintf(&out, "ERROR: %s\n", err)
+			i++
+			continue
+		}
+
+		operands, read := ReadOperands(def, ins[i+1:])
+		fmt.Fprintf(&out, "%04d %s", i, def.Name)
+		for _, o := range operands {
+			fmt.Fprintf(&out, " %d", o)
+		}
+		out.WriteByte('\n')
+
+		i += 1 + read
+	}
+
+	return out.String()
+}
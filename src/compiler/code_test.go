@@ -0,0 +1,90 @@
+package compiler
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		op       Opcode
+		operands []int
+		expected []byte
+	}{
+		{OpConstant, []int{65534}, []byte{byte(OpConstant), 0, 0, 255, 254}},
+		{OpConstant, []int{70000}, []byte{byte(OpConstant), 0, 1, 17, 112}},
+		{OpAdd, []int{}, []byte{byte(OpAdd)}},
+		{OpCall, []int{300}, []byte{byte(OpCall), 1, 44}},
+		{OpMake, []int{258, 2}, []byte{byte(OpMake), 0, 0, 1, 2, 0, 2}},
+	}
+
+	for _, tt := range tests {
+		instruction := Make(tt.op, tt.operands...)
+
+		if len(instruction) != len(tt.expected) {
+			t.Fatalf("instruction has wrong length. want=%d, got=%d", len(tt.expected), len(instruction))
+		}
+
+		for i, b := range tt.expected {
+			if instruction[i] != b {
+				t.Errorf("wrong byte at pos %d. want=%d, got=%d", i, b, instruction[i])
+			}
+		}
+	}
+}
+
+func TestInstructionsString(t *testing.T) {
+	var ins Instructions
+	ins = append(ins, Make(OpConstant, 1)...)
+	ins = append(ins, Make(OpGetLocal, 2)...)
+	ins = append(ins, Make(OpClass, 3, 1)...)
+	ins = append(ins, Make(OpReturn)...)
+
+	expected := `0000 OpConstant 1
+0005 OpGetLocal 2
+0010 OpClass 3 1
+0016 OpReturn
+`
+
+	if ins.String() != expected {
+		t.Errorf("instructions wrongly formatted.\nwant=%q\ngot=%q", expected, ins.String())
+	}
+}
+
+func TestCapturedLocalsUseCells(t *testing.T) {
+	input := `func(x) { let y = 1; func() { x + y } }`
+	code := compileTestFunction(t, input)
+
+	if !code.HasCells {
+		t.Fatal("function should have cells")
+	}
+	for i, name := range []string{"x", "args", "y"} {
+		if code.Locals[i] != name {
+			t.Errorf("local %d wrong. want=%s, got=%s", i, name, code.Locals[i])
+		}
+	}
+	if !code.Captured[0] || code.Captured[1] || !code.Captured[2] {
+		t.Errorf("wrong captured locals: %v", code.Captured)
+	}
+
+	// Infix operands are compiled right to left so y is captured first
+	inner := code.Functions[0]
+	if len(inner.Free) != 2 || inner.Free[0].Name != "y" || !inner.Free[0].Local || inner.Free[0].Index != 2 {
+		t.Errorf("wrong free variables: %+v", inner.Free)
+	}
+}
+
+func TestLoopControlOutsideLoop(t *testing.T) {
+	_, err := Compile(parseTestProgram(t, `break`))
+	if err == nil || err.Error() != "break statement outside of a loop" {
+		t.Errorf("expected loop control error, got %v", err)
+	}
+}
+
+func TestTooManyArguments(t *testing.T) {
+	input := `f(0` + strings.Repeat(", 0", maxArguments) + `)`
+	_, err := Compile(parseTestProgram(t, input))
+	if err == nil || err.Error() != "too many arguments in call, at most 65535 are allowed" {
+		t.Errorf("expected argument count error, got %v", err)
+	}
+}
@@ -0,0 +1,956 @@
+package compiler
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/nitrogen-lang/nitrogen/src/ast"
+	"github.com/nitrogen-lang/nitrogen/src/eval"
+	"github.com/nitrogen-lang/nitrogen/src/object"
+	"github.com/nitrogen-lang/nitrogen/src/token"
+)
+
+// CodeBlock is a compiled unit of code. Either top level code or a function body.
+type CodeBlock struct {
+	Name         string
+	Instructions Instructions
+	Constants    []object.Object
+	// Names holds identifiers used by instructions that resolve a name at runtime.
+	Names []string
+	// Locals holds the name of each local slot, NumParams slots are parameters followed by "args".
+	Locals    []string
+	NumParams int
+	// Captured marks local slots that are referenced by a closure and stored in a cell.
+	Captured []bool
+	HasCells bool
+	// Free describes the variables a closure over this block captures when created.
+	Free      []FreeVar
+	Functions []*CodeBlock
+	Classes   []*ast.ClassLiteral
+	// Literal is the source of a compiled function literal. It's nil for top level code.
+	Literal *ast.FunctionLiteral
+	// Positions maps instructions to the source they were compiled from, sorted by offset.
+	Positions []SourcePos
+}
+
+// SourcePos records the source position of the instructions starting at Offset.
+type SourcePos struct {
+	Offset   int
+	Filename string
+	Pos      token.Position
+}
+
+// PositionOf returns the source position of the instruction at offset.
+func (c *CodeBlock) PositionOf(offset int) (SourcePos, bool) {
+	i := sort.Search(len(c.Positions), func(i int) bool { return c.Positions[i].Offset > offset })
+	if i == 0 {
+		return SourcePos{}, false
+	}
+	return c.Positions[i-1], true
+}
+
+// NumLocals is the number of slots the block needs in its call frame.
+func (c *CodeBlock) NumLocals() int {
+	return len(c.Locals)
+}
+
+type loop struct {
+	tryDepth       int
+	breakJumps     []int
+	continueJumps  []int
+	continueTarget int
+}
+
+// tryBlock is an active exception handler. finally is nil if the handler doesn't have a finally block.
+type tryBlock struct {
+	finally *ast.BlockStatement
+}
+
+// declaration is how a definition resolved the first time it was compiled.
+type declaration struct {
+	existing   *Symbol
+	redeclared bool
+}
+
+type unit struct {
+	code      *CodeBlock
+	scope     *funcScope
+	outer     *unit
+	names     map[string]int
+	localRefs [][]int
+	loops     []*loop
+	tries     []*tryBlock
+	// Finally blocks are compiled once for every way they're entered. Their declarations
+	// must compile the same each time.
+	declarations map[*ast.DefStatement]declaration
+	// pos is the position of the node being compiled
+	pos token.Token
+}
+
+// Compiler lowers an AST into bytecode for the VM.
+type Compiler struct {
+	unit *unit
+}
+
+var infixOps = map[string]Opcode{
+	"+":  OpAdd,
+	"-":  OpSub,
+	"*":  OpMul,
+	"/":  OpDiv,
+	"%":  OpMod,
+	"<<": OpShiftLeft,
+	">>": OpShiftRight,
+	"&":  OpBitAnd,
+	"|":  OpBitOr,
+	"^":  OpBitXor,
+	"&^": OpBitAndNot,
+	"==": OpEqual,
+	"!=": OpNotEqual,
+	"<":  OpLessThan,
+	">":  OpGreaterThan,
+	"<=": OpLessThanEq,
+	">=": OpGreaterThanEq,
+	"in": OpIn,
+}
+
+// InfixOperator returns the operator symbol of a binary operator opcode.
+func InfixOperator(op Opcode) string {
+	for sym, code := range infixOps {
+		if code == op {
+			return sym
+		}
+	}
+	return ""
+}
+
+type compileError struct {
+	msg string
+}
+
+func (e *compileError) Error() string { return e.msg }
+
+// Compile compiles top level code. Declarations at the top level are stored in the
+// environment the code is executed with.
+func Compile(node ast.Node) (code *CodeBlock, err error) {
+	c := &Compiler{}
+	c.enterUnit("", newFuncScope(nil, true), nil)
+
+	defer func() {
+		if r := recover(); r != nil {
+			if ce, ok := r.(*compileError); ok {
+				code, err = nil, ce
+				return
+			}
+			panic(r)
+		}
+	}()
+
+	switch node := node.(type) {
+	case *ast.Program:
+		c.compileStatements(node.Statements, true)
+	case *ast.BlockStatement:
+		c.compileStatements(node.Statements, true)
+	case ast.Statement:
+		c.compileStatement(node, true)
+	case ast.Expression:
+		c.compileExpression(node)
+	default:
+		return nil, fmt.Errorf("can't compile node %T", node)
+	}
+	c.emit(OpReturn)
+
+	return c.leaveUnit(), nil
+}
+
+// CompileFunction compiles a function body that isn't nested in other compiled code,
+// such as a class method. Identifiers that aren't parameters or locals are resolved at
+// runtime through the function's environment.
+func CompileFunction(name string, params []*ast.Identifier, body *ast.BlockStatement) (code *CodeBlock, err error) {
+	c := &Compiler{}
+
+	defer func() {
+		if r := recover(); r != nil {
+			if ce, ok := r.(*compileError); ok {
+				code, err = nil, ce
+				return
+			}
+			panic(r)
+		}
+	}()
+
+	c.compileFunctionBody(name, params, body, nil)
+	return c.leaveUnit(), nil
+}
+
+func (c *Compiler) errorf(format string, args ...interface{}) {
+	panic(&compileError{msg: fmt.Sprintf(format, args...)})
+}
+
+func (c *Compiler) enterUnit(name string, scope *funcScope, lit *ast.FunctionLiteral) {
+	c.unit = &unit{
+		code:         &CodeBlock{Name: name, Literal: lit},
+		scope:        scope,
+		outer:        c.unit,
+		names:        make(map[string]int),
+		declarations: make(map[*ast.DefStatement]declaration),
+	}
+}
+
+func (c *Compiler) leaveUnit() *CodeBlock {
+	u := c.unit
+	code := u.code
+	code.Locals = u.scope.locals
+	code.Captured = u.scope.captured
+	code.Free = u.scope.free
+
+	// Locals referenced by a closure are stored in cells so all closures
+	// share the variable, rewrite their instructions now that it's known.
+	for slot, captured := range code.Captured {
+		if !captured {
+			continue
+		}
+		code.HasCells = true
+		if slot >= len(u.localRefs) {
+			continue
+		}
+		for _, pos := range u.localRefs[slot] {
+			switch Opcode(code.Instructions[pos]) {
+			case OpGetLocal:
+				code.Instructions[pos] = byte(OpGetCell)
+			case OpSetLocal:
+				code.Instructions[pos] = byte(OpSetCell)
+			case OpDefineLocal:
+				code.Instructions[pos] = byte(OpDefineCell)
+			case OpClearLocal:
+				code.Instructions[pos] = byte(OpClearCell)
+			}
+		}
+	}
+
+	c.unit = u.outer
+	return code
+}
+
+func (c *Compiler) compileFunctionBody(name string, params []*ast.Identifier, body *ast.BlockStatement, lit *ast.FunctionLiteral) {
+	var outer *funcScope
+	if c.unit != nil {
+		outer = c.unit.scope
+	}
+	c.enterUnit(name, newFuncScope(outer, false), lit)
+
+	for _, p := range params {
+		c.unit.scope.define(p.Value, false)
+	}
+	c.unit.scope.define("args", true)
+	c.unit.code.NumParams = len(params)
+
+	c.compileStatements(body.Statements, true)
+	c.emit(OpReturn)
+}
+
+// setPosition makes tok the source position of emitted instructions and returns the previous position.
+func (c *Compiler) setPosition(node ast.Node) token.Token {
+	prev := c.unit.pos
+	if tok := ast.NodeToken(node); tok.Pos.Line > 0 {
+		c.unit.pos = tok
+	}
+	return prev
+}
+
+func (c *Compiler) restorePosition(tok token.Token) {
+	c.unit.pos = tok
+}
+
+func (c *Compiler) emit(op Opcode, operands ...int) int {
+	def := definitions[op]
+	for i, o := range operands {
+		if width := def.OperandWidths[i]; o >= 1<<uint(8*width) {
+			c.errorf("%s operand %d doesn't fit in %d bytes", def.Name, o, width)
+		}
+	}
+
+	pos := len(c.unit.code.Instructions)
+	c.recordPosition(pos)
+	c.unit.code.Instructions = append(c.unit.code.Instructions, Make(op, operands...)...)
+
+	switch op {
+	case OpGetLocal, OpSetLocal, OpDefineLocal, OpClearLocal:
+		slot := operands[0]
+		for len(c.unit.localRefs) <= slot {
+			c.unit.localRefs = append(c.unit.localRefs, nil)
+		}
+		c.unit.localRefs[slot] = append(c.unit.localRefs[slot], pos)
+	}
+	return pos
+}
+
+func (c *Compiler) recordPosition(offset int) {
+	code := c.unit.code
+	tok := c.unit.pos
+	if tok.Pos.Line == 0 {
+		return
+	}
+	if n := len(code.Positions); n > 0 {
+		last := code.Positions[n-1]
+		if last.Pos == tok.Pos && last.Filename == tok.Filename {
+			return
+		}
+		if last.Offset == offset {
+			code.Positions = code.Positions[:n-1]
+		}
+	}
+	code.Positions = append(code.Positions, SourcePos{Offset: offset, Filename: tok.Filename, Pos: tok.Pos})
+}
+
+func (c *Compiler) currentPos() int {
+	return len(c.unit.code.Instructions)
+}
+
+// patchJump sets the target of the jump instruction at pos to the current position.
+func (c *Compiler) patchJump(pos int) {
+	c.patchJumpTo(pos, c.currentPos())
+}
+
+func (c *Compiler) patchJumpTo(pos, target int) {
+	ins := Make(Opcode(c.unit.code.Instructions[pos]), target)
+	copy(c.unit.code.Instructions[pos:], ins)
+}
+
+func (c *Compiler) addConstant(obj object.Object) int {
+	c.unit.code.Constants = append(c.unit.code.Constants, obj)
+	return len(c.unit.code.Constants) - 1
+}
+
+func (c *Compiler) addName(name string) int {
+	if idx, ok := c.unit.names[name]; ok {
+		return idx
+	}
+	c.unit.code.Names = append(c.unit.code.Names, name)
+	idx := len(c.unit.code.Names) - 1
+	c.unit.names[name] = idx
+	return idx
+}
+
+// emitError emits an instruction that throws an exception of class with the given message when executed.
+func (c *Compiler) emitError(class *object.Class, format string, args ...interface{}) {
+	c.emit(OpError, c.addConstant(object.NewExceptionOf(class, format, args...)))
+}
+
+// compileStatements compiles a list of statements. If keep is true, the value of the
+// last statement is left on the stack, or nil if there are no statements.
+func (c *Compiler) compileStatements(stmts []ast.Statement, keep bool) {
+	if len(stmts) == 0 {
+		if keep {
+			c.emit(OpNull)
+		}
+		return
+	}
+
+	last := len(stmts) - 1
+	for i, s := range stmts {
+		c.compileStatement(s, keep && i == last)
+	}
+}
+
+func (c *Compiler) compileStatement(node ast.Statement, keep bool) {
+	defer c.restorePosition(c.setPosition(node))
+
+	switch node := node.(type) {
+	case *ast.ExpressionStatement:
+		if assign, ok := node.Expression.(*ast.AssignStatement); ok {
+			c.compileAssignment(assign, keep)
+			return
+		}
+		c.compileExpression(node.Expression)
+		if !keep {
+			c.emit(OpPop)
+		}
+		return
+
+	case *ast.BlockStatement:
+		c.compileStatements(node.Statements, keep)
+		return
+
+	case *ast.DefStatement:
+		c.compileDefinition(node)
+
+	case *ast.ReturnStatement:
+		c.compileExpression(node.Value)
+		if c.hasFinally() {
+			// The return value is kept out of the way while the finally blocks run
+			result := c.unit.scope.defineTemp()
+			c.emit(OpDefineLocal, result)
+			c.unwindTries(0)
+			c.emit(OpGetLocal, result)
+		}
+		c.emit(OpReturn)
+		return
+
+	case *ast.ThrowStatement:
+		c.compileExpression(node.Expression)
+		c.emit(OpThrow)
+		return
+
+	case *ast.ForLoopStatement:
+		c.compileForLoop(node)
+
+	case *ast.ContinueStatement:
+		c.compileLoopControl(true)
+		return
+
+	case *ast.BreakStatement:
+		c.compileLoopControl(false)
+		return
+
+	default:
+		c.errorf("unsupported statement %T", node)
+	}
+
+	if keep {
+		c.emit(OpNull)
+	}
+}
+
+func (c *Compiler) compileExpression(node ast.Expression) {
+	defer c.restorePosition(c.setPosition(node))
+
+	switch node := node.(type) {
+	// Literals
+	case *ast.NullLiteral:
+		c.emit(OpNull)
+	case *ast.IntegerLiteral:
+		if node.Big != nil {
+			c.emit(OpConstant, c.addConstant(&object.BigInt{Value: node.Big}))
+			break
+		}
+		c.emit(OpConstant, c.addConstant(&object.Integer{Value: node.Value}))
+	case *ast.FloatLiteral:
+		c.emit(OpConstant, c.addConstant(&object.Float{Value: node.Value}))
+	case *ast.StringLiteral:
+		c.emit(OpConstant, c.addConstant(&object.String{Value: node.Value}))
+	case *ast.InterpolatedString:
+		for _, part := range node.Parts {
+			c.compileExpression(part)
+		}
+		c.emit(OpInterpolate, len(node.Parts))
+	case *ast.Boolean:
+		if node.Value {
+			c.emit(OpTrue)
+		} else {
+			c.emit(OpFalse)
+		}
+	case *ast.Array:
+		for _, e := range node.Elements {
+			c.compileExpression(e)
+		}
+		c.emit(OpArray, len(node.Elements))
+	case *ast.HashLiteral:
+		for _, pair := range node.Pairs {
+			c.compileExpression(pair.Key)
+			c.compileExpression(pair.Value)
+		}
+		c.emit(OpHash, len(node.Pairs))
+
+	// Expressions
+	case *ast.Identifier:
+		c.compileIdentifier(node.Value, OpGetName)
+	case *ast.PrefixExpression:
+		c.compileExpression(node.Right)
+		switch node.Operator {
+		case "-":
+			c.emit(OpMinus)
+		case "!":
+			c.emit(OpBang)
+		default:
+			c.errorf("unknown prefix operator %s", node.Operator)
+		}
+	case *ast.InfixExpression:
+		op, ok := infixOps[node.Operator]
+		if !ok {
+			c.errorf("unknown infix operator %s", node.Operator)
+		}
+		c.compileExpression(node.Right)
+		c.compileExpression(node.Left)
+		c.emit(op)
+	case *ast.IndexExpression:
+		c.compileExpression(node.Left)
+		c.compileExpression(node.Index)
+		c.emit(OpIndex)
+	case *ast.SliceExpression:
+		c.compileExpression(node.Left)
+		for _, bound := range []ast.Expression{node.Low, node.High} {
+			if bound == nil {
+				c.emit(OpNull)
+			} else {
+				c.compileExpression(bound)
+			}
+		}
+		c.emit(OpSlice)
+	case *ast.AssignStatement:
+		c.compileAssignment(node, true)
+
+	// Conditionals
+	case *ast.IfExpression:
+		c.compileExpression(node.Condition)
+		jumpElse := c.emit(OpJumpNotTruthy, 0)
+		c.compileStatements(node.Consequence.Statements, true)
+		jumpEnd := c.emit(OpJump, 0)
+		c.patchJump(jumpElse)
+		if node.Alternative != nil {
+			c.compileStatements(node.Alternative.Statements, true)
+		} else {
+			c.emit(OpNull)
+		}
+		c.patchJump(jumpEnd)
+	case *ast.CompareExpression:
+		c.compileExpression(node.Left)
+		c.emit(OpTruthy, 0)
+		var jump int
+		if node.Token.Type == token.LOr {
+			jump = c.emit(OpJumpTrueOrPop, 0)
+		} else {
+			jump = c.emit(OpJumpFalseOrPop, 0)
+		}
+		c.compileExpression(node.Right)
+		c.emit(OpTruthy, 1)
+		c.patchJump(jump)
+	case *ast.TryCatchExpression:
+		c.compileTryCatch(node)
+
+	// Functions
+	case *ast.FunctionLiteral:
+		c.compileFunctionLiteral(node)
+	case *ast.CallExpression:
+		if ident, ok := node.Function.(*ast.Identifier); ok {
+			c.compileIdentifier(ident.Value, OpGetFunc)
+		} else {
+			c.compileExpression(node.Function)
+		}
+		c.compileArguments(node.Arguments)
+		c.emit(OpCall, len(node.Arguments))
+
+	// Classes
+	case *ast.ClassLiteral:
+		c.compileClassLiteral(node)
+	case *ast.MakeInstance:
+		c.compileExpression(node.Class)
+		c.compileArguments(node.Arguments)
+		c.emit(OpMake, c.addName(node.Class.String()), len(node.Arguments))
+
+	default:
+		c.errorf("unsupported expression %T", node)
+	}
+}
+
+// maxArguments is the largest argument count the call operands can hold.
+const maxArguments = 1<<16 - 1
+
+func (c *Compiler) compileArguments(args []ast.Expression) {
+	if len(args) > maxArguments {
+		c.errorf("too many arguments in call, at most %d are allowed", maxArguments)
+	}
+	for _, a := range args {
+		c.compileExpression(a)
+	}
+}
+
+// compileIdentifier loads the value of an identifier. nameOp is the instruction
+// used if the identifier needs to be looked up at runtime.
+func (c *Compiler) compileIdentifier(name string, nameOp Opcode) {
+	sym := c.unit.scope.resolve(name)
+	switch sym.Scope {
+	case LocalScope:
+		c.emit(OpGetLocal, sym.Index)
+	case FreeScope:
+		c.emit(OpGetFree, sym.Index)
+	default:
+		c.emit(nameOp, c.addName(name))
+	}
+}
+
+func (c *Compiler) compileDefinition(node *ast.DefStatement) {
+	name := node.Name.Value
+	scope := c.unit.scope
+
+	if scope.declarationBlock().global {
+		if node.Value == nil {
+			c.emit(OpNull)
+		} else {
+			c.compileExpression(node.Value)
+		}
+		if node.Const {
+			c.emit(OpDefineConstName, c.addName(name))
+		} else {
+			c.emit(OpDefineName, c.addName(name))
+		}
+		return
+	}
+
+	if eval.GetBuiltin(name) != nil {
+		c.emitError(object.NameErrorClass, "Attempted redeclaration of builtin function '%s'", name)
+		return
+	}
+
+	// Declarations are checked against every visible variable like the environment would
+	decl, compiled := c.unit.declarations[node]
+	if !compiled {
+		decl = declaration{
+			existing:   scope.resolve(name),
+			redeclared: scope.lookupDeclared(name) != nil,
+		}
+		c.unit.declarations[node] = decl
+	}
+
+	existing := decl.existing
+	if existing.Scope == NameScope {
+		constant := 0
+		if node.Const {
+			constant = 1
+		}
+		c.emit(OpCheckName, c.addName(name), constant)
+	} else if node.Const {
+		c.emitError(object.ConstantErrorClass, "Can't assign constant to variable `%s`", name)
+		return
+	} else if existing.Const {
+		c.emitError(object.ConstantErrorClass, "Assignment to declared constant %s", name)
+		return
+	}
+
+	// Redeclaring a variable in the same scope keeps its original value
+	if decl.redeclared {
+		if node.Value != nil {
+			c.compileExpression(node.Value)
+			c.emit(OpPop)
+		}
+		return
+	}
+
+	// Functions are declared before their body is compiled so they can call themselves
+	if fn, ok := node.Value.(*ast.FunctionLiteral); ok {
+		sym := scope.define(name, node.Const)
+		c.emit(OpClearLocal, sym.Index)
+		c.compileFunctionLiteral(fn)
+		if node.Const {
+			c.emit(OpCheckConst)
+		}
+		c.emit(OpDefineLocal, sym.Index)
+		return
+	}
+
+	if node.Value == nil {
+		c.emit(OpNull)
+	} else {
+		c.compileExpression(node.Value)
+	}
+	if node.Const {
+		c.emit(OpCheckConst)
+	}
+	sym := scope.define(name, node.Const)
+	c.emit(OpDefineLocal, sym.Index)
+}
+
+func (c *Compiler) compileAssignment(node *ast.AssignStatement, keep bool) {
+	switch left := node.Left.(type) {
+	case *ast.IndexExpression:
+		c.compileExpression(left.Left)
+		c.compileExpression(left.Index)
+		c.compileExpression(node.Value)
+		c.emit(OpSetIndex)
+
+	case *ast.Identifier:
+		sym := c.unit.scope.resolve(left.Value)
+		if sym.Scope != NameScope && sym.Const {
+			c.emitError(object.ConstantErrorClass, "Assignment to declared constant %s", left.Value)
+			break
+		}
+
+		c.compileExpression(node.Value)
+		switch sym.Scope {
+		case LocalScope:
+			c.emit(OpSetLocal, sym.Index)
+		case FreeScope:
+			c.emit(OpSetFree, sym.Index)
+		default:
+			c.emit(OpSetName, c.addName(left.Value))
+		}
+
+	default:
+		c.emitError(object.RuntimeErrorClass, "Invalid variable name, expected identifier, got %s", node.Left.String())
+	}
+
+	if keep {
+		c.emit(OpNull)
+	}
+}
+
+func (c *Compiler) compileFunctionLiteral(node *ast.FunctionLiteral) {
+	c.compileFunctionBody(node.Name, node.Parameters, node.Body, node)
+	code := c.leaveUnit()
+
+	c.unit.code.Functions = append(c.unit.code.Functions, code)
+	c.emit(OpClosure, len(c.unit.code.Functions)-1)
+}
+
+func (c *Compiler) compileClassLiteral(node *ast.ClassLiteral) {
+	c.unit.code.Classes = append(c.unit.code.Classes, node)
+	idx := len(c.unit.code.Classes) - 1
+
+	// Methods are pushed in the order of MethodNames so the VM can pair them up
+	for _, name := range MethodNames(node) {
+		c.compileFunctionLiteral(node.Methods[name])
+	}
+
+	// The parent class is pushed on the stack if it's a local variable, otherwise
+	// the VM will look it up by name.
+	if node.Parent != "" {
+		if sym := c.unit.scope.resolve(node.Parent); sym.Scope != NameScope {
+			c.compileIdentifier(node.Parent, OpGetName)
+			c.emit(OpClass, idx, 1)
+			return
+		}
+	}
+	c.emit(OpClass, idx, 0)
+}
+
+// MethodNames returns the method names of a class in the order they're compiled.
+func MethodNames(node *ast.ClassLiteral) []string {
+	names := make([]string, 0, len(node.Methods))
+	for name := range node.Methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c *Compiler) compileForLoop(node *ast.ForLoopStatement) {
+	if node.Collection != nil {
+		c.compileForInLoop(node)
+		return
+	}
+	scope := c.unit.scope
+	scope.openBlock(false)
+	defer scope.closeBlock()
+
+	if node.Init != nil {
+		c.compileDefinition(node.Init)
+	}
+
+	l := &loop{tryDepth: len(c.unit.tries)}
+	c.unit.loops = append(c.unit.loops, l)
+
+	start := c.currentPos()
+	exitJump := -1
+	if node.Condition != nil {
+		c.compileExpression(node.Condition)
+		exitJump = c.emit(OpJumpNotTruthy, 0)
+	}
+
+	scope.openBlock(false)
+	c.compileStatements(node.Body.Statements, false)
+	scope.closeBlock()
+
+	l.continueTarget = c.currentPos()
+	if node.Init != nil {
+		// Redefine the loop variable so each iteration captured by a closure gets a new cell
+		if sym := scope.resolve(node.Init.Name.Value); sym.Scope == LocalScope {
+			c.emit(OpGetLocal, sym.Index)
+			c.emit(OpDefineLocal, sym.Index)
+		}
+	}
+	if node.Iter != nil {
+		iter, ok := node.Iter.(*ast.AssignStatement)
+		if !ok && node.Init != nil {
+			// If the iterator is not an assignment, assign its value to the initialized identifier
+			iter = &ast.AssignStatement{
+				Left:  node.Init.Name,
+				Value: node.Iter,
+			}
+		}
+
+		if iter != nil {
+			c.compileAssignment(iter, false)
+		} else {
+			c.compileExpression(node.Iter)
+			c.emit(OpPop)
+		}
+	}
+	c.emit(OpJump, start)
+
+	if exitJump > -1 {
+		c.patchJump(exitJump)
+	}
+	for _, pos := range l.breakJumps {
+		c.patchJump(pos)
+	}
+	for _, pos := range l.continueJumps {
+		c.patchJumpTo(pos, l.continueTarget)
+	}
+
+	c.unit.loops = c.unit.loops[:len(c.unit.loops)-1]
+}
+
+// compileForInLoop compiles a for-in loop. The iterator stays on the stack while the loop runs.
+func (c *Compiler) compileForInLoop(node *ast.ForLoopStatement) {
+	c.compileExpression(node.Collection)
+	c.emit(OpIter)
+
+	l := &loop{tryDepth: len(c.unit.tries)}
+	c.unit.loops = append(c.unit.loops, l)
+
+	l.continueTarget = c.currentPos()
+	next := c.emit(OpIterNext, 0)
+
+	// The loop variables are declared in the body's scope so each iteration gets new variables
+	scope := c.unit.scope
+	scope.openBlock(false)
+	value := scope.define(node.Value.Value, false)
+	c.emit(OpDefineLocal, value.Index)
+	if node.Key != nil {
+		key := scope.define(node.Key.Value, false)
+		c.emit(OpDefineLocal, key.Index)
+	} else {
+		c.emit(OpPop)
+	}
+	c.compileStatements(node.Body.Statements, false)
+	scope.closeBlock()
+	c.emit(OpJump, l.continueTarget)
+
+	c.patchJump(next)
+	for _, pos := range l.breakJumps {
+		c.patchJump(pos)
+	}
+	for _, pos := range l.continueJumps {
+		c.patchJumpTo(pos, l.continueTarget)
+	}
+	c.emit(OpPop)
+
+	c.unit.loops = c.unit.loops[:len(c.unit.loops)-1]
+}
+
+func (c *Compiler) compileLoopControl(isContinue bool) {
+	if len(c.unit.loops) == 0 {
+		if isContinue {
+			c.errorf("continue statement outside of a loop")
+		}
+		c.errorf("break statement outside of a loop")
+	}
+
+	l := c.unit.loops[len(c.unit.loops)-1]
+	c.unwindTries(l.tryDepth)
+
+	pos := c.emit(OpJump, 0)
+	if isContinue {
+		l.continueJumps = append(l.continueJumps, pos)
+	} else {
+		l.breakJumps = append(l.breakJumps, pos)
+	}
+}
+
+func (c *Compiler) compileTryCatch(node *ast.TryCatchExpression) {
+	if node.Finally == nil {
+		c.compileCatchClauses(node)
+		return
+	}
+
+	setup := c.emit(OpSetupTry, 0)
+	c.unit.tries = append(c.unit.tries, &tryBlock{finally: node.Finally})
+	if len(node.Catches) > 0 {
+		c.compileCatchClauses(node)
+	} else {
+		c.compileStatements(node.Try.Statements, true)
+	}
+	c.unit.tries = c.unit.tries[:len(c.unit.tries)-1]
+	c.emit(OpPopTry)
+
+	// The value of the try is kept out of the way while the finally block runs
+	result := c.unit.scope.defineTemp()
+	c.emit(OpDefineLocal, result)
+	c.compileStatements(node.Finally.Statements, false)
+	c.emit(OpGetLocal, result)
+	jumpEnd := c.emit(OpJump, 0)
+
+	// An exception left the try or catch blocks, it's rethrown after the finally block
+	c.patchJump(setup)
+	c.emit(OpDefineLocal, result)
+	c.compileStatements(node.Finally.Statements, false)
+	c.emit(OpGetLocal, result)
+	c.emit(OpThrow)
+
+	c.patchJump(jumpEnd)
+}
+
+func (c *Compiler) compileCatchClauses(node *ast.TryCatchExpression) {
+	setup := c.emit(OpSetupTry, 0)
+	c.unit.tries = append(c.unit.tries, &tryBlock{})
+	c.compileStatements(node.Try.Statements, true)
+	c.unit.tries = c.unit.tries[:len(c.unit.tries)-1]
+	c.emit(OpPopTry)
+	jumpEnd := c.emit(OpJump, 0)
+
+	// The VM pushes the exception before jumping to the catch block
+	c.patchJump(setup)
+	var endJumps []int
+	for _, clause := range node.Catches {
+		next := -1
+		if clause.Class != nil {
+			c.compileExpression(clause.Class)
+			next = c.emit(OpMatchException, 0)
+		}
+
+		scope := c.unit.scope
+		scope.openBlock(true)
+		if clause.Symbol != nil {
+			sym := scope.defineInBlock(clause.Symbol.Value, true)
+			c.emit(OpDefineLocal, sym.Index)
+		} else {
+			c.emit(OpPop)
+		}
+		c.compileStatements(clause.Body.Statements, true)
+		scope.closeBlock()
+		endJumps = append(endJumps, c.emit(OpJump, 0))
+
+		if next >= 0 {
+			c.patchJump(next)
+		}
+	}
+
+	// No clause matched, the exception is still on the stack
+	c.emit(OpThrow)
+
+	c.patchJump(jumpEnd)
+	for _, pos := range endJumps {
+		c.patchJump(pos)
+	}
+}
+
+// hasFinally returns if any active exception handler has a finally block.
+func (c *Compiler) hasFinally() bool {
+	for _, t := range c.unit.tries {
+		if t.finally != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// unwindTries removes the exception handlers above depth before jumping out of them.
+// The finally blocks of the handlers are run as they're removed.
+func (c *Compiler) unwindTries(depth int) {
+	tries, loops := c.unit.tries, c.unit.loops
+	defer func() { c.unit.tries, c.unit.loops = tries, loops }()
+
+	for i := len(tries) - 1; i >= depth; i-- {
+		c.emit(OpPopTry)
+		if tries[i].finally == nil {
+			continue
+		}
+
+		// The finally block is compiled outside of its handler and the loops inside it
+		c.unit.tries = tries[:i]
+		c.unit.loops = loops
+		for len(c.unit.loops) > 0 && c.unit.loops[len(c.unit.loops)-1].tryDepth > i {
+			c.unit.loops = c.unit.loops[:len(c.unit.loops)-1]
+		}
+		c.compileStatements(tries[i].finally.Statements, false)
+	}
+}
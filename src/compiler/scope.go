@@ -0,0 +1,157 @@
+package compiler
+
+// SymbolScope denotes where the value of a resolved identifier is stored.
+type SymbolScope int
+
+const (
+	// NameScope symbols are looked up by name in the object.Environment
+	// of the executing code at runtime. Globals, builtins, and anything the
+	// compiler can't statically resolve use this scope.
+	NameScope SymbolScope = iota
+	// LocalScope symbols live in a slot of the current call frame.
+	LocalScope
+	// FreeScope symbols are locals of an enclosing function captured by a closure.
+	FreeScope
+)
+
+// Symbol is a resolved identifier.
+type Symbol struct {
+	Name  string
+	Scope SymbolScope
+	Index int
+	Const bool
+}
+
+// FreeVar describes where a closure captures a free variable from when it's created.
+// If Local is true, Index is a local slot of the enclosing frame, otherwise it's
+// an index into the enclosing closure's free variables.
+type FreeVar struct {
+	Name  string
+	Local bool
+	Index int
+}
+
+type block struct {
+	symbols map[string]*Symbol
+	// Names declared in a transparent block are declared in the enclosing block instead.
+	// Catch blocks are transparent since they share the scope of the surrounding code.
+	transparent bool
+	// Declarations in a global block are stored in the environment.
+	global bool
+}
+
+// funcScope tracks the symbols of a single compilation unit, a function
+// body or top level code.
+type funcScope struct {
+	outer     *funcScope
+	blocks    []*block
+	locals    []string
+	captured  []bool
+	free      []FreeVar
+	freeNames map[string]*Symbol
+}
+
+func newFuncScope(outer *funcScope, global bool) *funcScope {
+	s := &funcScope{
+		outer:     outer,
+		freeNames: make(map[string]*Symbol),
+	}
+	s.blocks = []*block{{symbols: make(map[string]*Symbol), global: global}}
+	return s
+}
+
+func (s *funcScope) openBlock(transparent bool) {
+	s.blocks = append(s.blocks, &block{
+		symbols:     make(map[string]*Symbol),
+		transparent: transparent,
+	})
+}
+
+func (s *funcScope) closeBlock() {
+	s.blocks = s.blocks[:len(s.blocks)-1]
+}
+
+// declarationBlock returns the block new declarations are stored in.
+func (s *funcScope) declarationBlock() *block {
+	for i := len(s.blocks) - 1; i > 0; i-- {
+		if !s.blocks[i].transparent {
+			return s.blocks[i]
+		}
+	}
+	return s.blocks[0]
+}
+
+// lookupDeclared returns a symbol already declared in the declaration block.
+func (s *funcScope) lookupDeclared(name string) *Symbol {
+	return s.declarationBlock().symbols[name]
+}
+
+// define declares name in the current declaration block. A name
+// declared twice in the same block reuses its storage.
+func (s *funcScope) define(name string, constant bool) *Symbol {
+	return s.defineIn(s.declarationBlock(), name, constant)
+}
+
+// defineInBlock declares name in the innermost block, even if it's transparent.
+func (s *funcScope) defineInBlock(name string, constant bool) *Symbol {
+	return s.defineIn(s.blocks[len(s.blocks)-1], name, constant)
+}
+
+func (s *funcScope) defineIn(b *block, name string, constant bool) *Symbol {
+	if sym, exists := b.symbols[name]; exists {
+		sym.Const = constant
+		return sym
+	}
+
+	var sym *Symbol
+	if b.global {
+		sym = &Symbol{Name: name, Scope: NameScope, Const: constant}
+	} else {
+		sym = &Symbol{Name: name, Scope: LocalScope, Index: len(s.locals), Const: constant}
+		s.locals = append(s.locals, name)
+		s.captured = append(s.captured, false)
+	}
+	b.symbols[name] = sym
+	return sym
+}
+
+// defineTemp allocates a local slot that can't be referenced by name.
+func (s *funcScope) defineTemp() int {
+	s.locals = append(s.locals, "<temp>")
+	s.captured = append(s.captured, false)
+	return len(s.locals) - 1
+}
+
+// resolve finds the storage of name from the perspective of this scope.
+func (s *funcScope) resolve(name string) *Symbol {
+	for i := len(s.blocks) - 1; i >= 0; i-- {
+		if sym, ok := s.blocks[i].symbols[name]; ok {
+			return sym
+		}
+	}
+
+	if sym, ok := s.freeNames[name]; ok {
+		return sym
+	}
+
+	if s.outer == nil {
+		return &Symbol{Name: name, Scope: NameScope}
+	}
+
+	outer := s.outer.resolve(name)
+	switch outer.Scope {
+	case LocalScope:
+		s.outer.captured[outer.Index] = true
+		return s.defineFree(name, FreeVar{Name: name, Local: true, Index: outer.Index}, outer.Const)
+	case FreeScope:
+		return s.defineFree(name, FreeVar{Name: name, Index: outer.Index}, outer.Const)
+	}
+	return outer
+}
+
+func (s *funcScope) defineFree(name string, v FreeVar, constant bool) *Symbol {
+	sym := &Symbol{Name: name, Scope: FreeScope, Index: len(s.free), Const: constant}
+	s.free = append(s.free, v)
+	s.freeNames[name] = sym
+	return sym
+}
@@ -0,0 +1,31 @@
+package compiler
+
+import (
+	"testing"
+
+	"github.com/nitrogen-lang/nitrogen/src/ast"
+	"github.com/nitrogen-lang/nitrogen/src/lexer"
+	"github.com/nitrogen-lang/nitrogen/src/parser"
+)
+
+func parseTestProgram(t *testing.T, input string) *ast.Program {
+	l := lexer.NewString(input)
+	p := parser.New(l, nil)
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatal(p.Errors()[0])
+	}
+	return program
+}
+
+// compileTestFunction compiles the function literal in the first statement of input.
+func compileTestFunction(t *testing.T, input string) *CodeBlock {
+	code, err := Compile(parseTestProgram(t, input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(code.Functions) == 0 {
+		t.Fatal("no functions compiled")
+	}
+	return code.Functions[0]
+}
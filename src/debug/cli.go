@@ -0,0 +1,277 @@
+package debug
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/nitrogen-lang/nitrogen/src/object"
+)
+
+const (
+	cliPrompt = "(debug) "
+
+	// maxValueLength is the length values are cut to when listing variables.
+	maxValueLength = 100
+)
+
+var cliCommands = []struct {
+	names, args, help string
+}{
+	{"break, b", "[file:]line [if cond]", "Set a breakpoint, optionally only when cond is true"},
+	{"delete, d", "id", "Remove a breakpoint"},
+	{"breakpoints, bl", "", "List the breakpoints"},
+	{"continue, c", "", "Run until a breakpoint"},
+	{"step, s", "", "Run the next statement, stepping into function calls"},
+	{"next, n", "", "Run the next statement, stepping over function calls"},
+	{"out, o", "", "Run until the current function returns"},
+	{"backtrace, bt", "", "Print the call stack"},
+	{"frame, f", "n", "Select a frame of the call stack to inspect"},
+	{"print, p", "expr", "Print the value of an expression in the selected frame"},
+	{"set", "name = expr", "Assign a variable in the selected frame"},
+	{"locals", "", "List the variables of the selected frame and its enclosing blocks"},
+	{"globals", "", "List the variables of the script"},
+	{"list, l", "", "Show the source around the current line"},
+	{"help, h", "", "Show this help"},
+	{"quit, q", "", "Stop the script and exit"},
+}
+
+// CLI is a command line front end for a debugger.
+type CLI struct {
+	d   *Debugger
+	in  *bufio.Reader
+	out io.Writer
+
+	stop *Stop
+	// frame is the index of the selected frame in stop.Frames
+	frame       int
+	lastCommand string
+	sources     map[string][]string
+}
+
+// NewCLI returns a front end reading commands from in and writing to out.
+func NewCLI(d *Debugger, in io.Reader, out io.Writer) *CLI {
+	return &CLI{
+		d:       d,
+		in:      bufio.NewReader(in),
+		out:     out,
+		sources: make(map[string][]string),
+	}
+}
+
+// Stopped is a Handler that reads commands until one continues the script.
+// The end of input quits.
+func (c *CLI) Stopped(stop *Stop) Action {
+	c.stop = stop
+	c.frame = 0
+	c.printStop()
+
+	for {
+		fmt.Fprint(c.out, cliPrompt)
+		line, err := c.in.ReadString('\n')
+		if err != nil && line == "" {
+			fmt.Fprintln(c.out)
+			return Quit
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			line = c.lastCommand
+		}
+		c.lastCommand = line
+		if line == "" {
+			continue
+		}
+
+		if action, resume := c.command(line); resume {
+			return action
+		}
+	}
+}
+
+// command runs a command line and returns the action if it continues the script.
+func (c *CLI) command(line string) (Action, bool) {
+	name, arg := line, ""
+	if i := strings.IndexAny(line, " \t"); i > -1 {
+		name, arg = line[:i], strings.TrimSpace(line[i+1:])
+	}
+
+	switch name {
+	case "continue", "c":
+		return Continue, true
+	case "step", "s":
+		return StepIn, true
+	case "next", "n":
+		return StepOver, true
+	case "out", "o":
+		return StepOut, true
+	case "quit", "q":
+		return Quit, true
+	case "break", "b":
+		c.setBreakpoint(arg)
+	case "delete", "d":
+		id, err := strconv.Atoi(arg)
+		if err != nil {
+			fmt.Fprintln(c.out, "Usage: delete id")
+		} else if !c.d.ClearBreakpoint(id) {
+			fmt.Fprintf(c.out, "No breakpoint %d\n", id)
+		}
+	case "breakpoints", "bl":
+		bps := c.d.Breakpoints()
+		if len(bps) == 0 {
+			fmt.Fprintln(c.out, "No breakpoints")
+		}
+		for _, bp := range bps {
+			fmt.Fprintf(c.out, "%d: %s (hits %d)\n", bp.ID, bp, bp.Hits)
+		}
+	case "backtrace", "bt":
+		for i, f := range c.stop.Frames {
+			marker := " "
+			if i == c.frame {
+				marker = "*"
+			}
+			fmt.Fprintf(c.out, "%s%d %s\n", marker, i, f)
+		}
+	case "frame", "f":
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 0 || n >= len(c.stop.Frames) {
+			fmt.Fprintf(c.out, "Frame must be 0 to %d\n", len(c.stop.Frames)-1)
+			break
+		}
+		c.frame = n
+		c.printFrame()
+	case "print", "p":
+		val, err := c.d.Evaluate(c.stop.Frames[c.frame], arg)
+		if err != nil {
+			fmt.Fprintf(c.out, "Error: %s\n", err)
+			break
+		}
+		fmt.Fprintln(c.out, val.Inspect())
+	case "set":
+		c.assign(arg)
+	case "locals":
+		scopes := Scopes(c.stop.Frames[c.frame])
+		if len(scopes) > 1 {
+			scopes = scopes[:len(scopes)-1]
+		}
+		for _, scope := range scopes {
+			c.printVariables(scope.Env)
+		}
+	case "globals":
+		scopes := Scopes(c.stop.Frames[c.frame])
+		c.printVariables(scopes[len(scopes)-1].Env)
+	case "list", "l":
+		c.printSource(c.stop.Frames[c.frame], 5)
+	case "help", "h":
+		for _, cmd := range cliCommands {
+			fmt.Fprintf(c.out, "%-40s %s\n", strings.TrimSpace(cmd.names+" "+cmd.args), cmd.help)
+		}
+	default:
+		fmt.Fprintf(c.out, "Unknown command %s, type help for a list of commands\n", name)
+	}
+	return Continue, false
+}
+
+// setBreakpoint parses "[file:]line [if cond]". The file defaults to the file of the selected frame.
+func (c *CLI) setBreakpoint(arg string) {
+	loc, cond := arg, ""
+	if i := strings.Index(arg, " if "); i > -1 {
+		loc, cond = strings.TrimSpace(arg[:i]), strings.TrimSpace(arg[i+4:])
+	}
+
+	file := c.stop.Frames[c.frame].File
+	if i := strings.LastIndexByte(loc, ':'); i > -1 {
+		file, loc = loc[:i], loc[i+1:]
+	}
+	line, err := strconv.Atoi(loc)
+	if err != nil || line < 1 || file == "" {
+		fmt.Fprintln(c.out, "Usage: break [file:]line [if cond]")
+		return
+	}
+
+	bp, err := c.d.SetBreakpoint(file, line, cond)
+	if err != nil {
+		fmt.Fprintf(c.out, "Error: %s\n", err)
+		return
+	}
+	fmt.Fprintf(c.out, "Breakpoint %d at %s\n", bp.ID, bp)
+}
+
+func (c *CLI) assign(arg string) {
+	i := strings.IndexByte(arg, '=')
+	if i == -1 {
+		fmt.Fprintln(c.out, "Usage: set name = expr")
+		return
+	}
+	name := strings.TrimSpace(arg[:i])
+	val, err := c.d.Assign(c.stop.Frames[c.frame], name, arg[i+1:])
+	if err != nil {
+		fmt.Fprintf(c.out, "Error: %s\n", err)
+		return
+	}
+	fmt.Fprintf(c.out, "%s = %s\n", name, val.Inspect())
+}
+
+func (c *CLI) printStop() {
+	frame := c.stop.Frames[0]
+	switch c.stop.Reason {
+	case ReasonBreakpoint:
+		fmt.Fprintf(c.out, "Breakpoint %d, %s\n", c.stop.Breakpoint.ID, frame)
+		if c.stop.ConditionErr != nil {
+			fmt.Fprintf(c.out, "Error in breakpoint condition: %s\n", c.stop.ConditionErr)
+		}
+	case ReasonEntry:
+		fmt.Fprintf(c.out, "Stopped at entry, %s\n", frame)
+	case ReasonPause:
+		fmt.Fprintf(c.out, "Paused, %s\n", frame)
+	default:
+		fmt.Fprintln(c.out, frame)
+	}
+	c.printSource(frame, 0)
+}
+
+func (c *CLI) printFrame() {
+	frame := c.stop.Frames[c.frame]
+	fmt.Fprintf(c.out, "#%d %s\n", c.frame, frame)
+	c.printSource(frame, 0)
+}
+
+// printSource prints the line of frame and the lines around it.
+func (c *CLI) printSource(frame *Frame, around int) {
+	lines, ok := c.sources[frame.File]
+	if !ok {
+		src, err := ioutil.ReadFile(frame.File)
+		if err == nil {
+			lines = strings.Split(string(src), "\n")
+		}
+		c.sources[frame.File] = lines
+	}
+
+	for n := frame.Line - around; n <= frame.Line+around; n++ {
+		if n < 1 || n > len(lines) {
+			continue
+		}
+		marker := " "
+		if n == frame.Line {
+			marker = ">"
+		}
+		fmt.Fprintf(c.out, "%s%4d  %s\n", marker, n, strings.TrimRight(lines[n-1], "\r"))
+	}
+}
+
+func (c *CLI) printVariables(env *object.Environment) {
+	for _, v := range Variables(env) {
+		kind := "let"
+		if v.Const {
+			kind = "always"
+		}
+		val := v.Value.Inspect()
+		if len(val) > maxValueLength {
+			val = val[:maxValueLength] + "..."
+		}
+		fmt.Fprintf(c.out, "%s %s = %s\n", kind, v.Name, val)
+	}
+}
@@ -0,0 +1,590 @@
+package debug
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nitrogen-lang/nitrogen/src/eval"
+	"github.com/nitrogen-lang/nitrogen/src/moduleutils"
+	"github.com/nitrogen-lang/nitrogen/src/object"
+)
+
+// threadID is the ID of the only thread scripts run in.
+const threadID = 1
+
+// DAPServer is a Debug Adapter Protocol server that launches a script and
+// debugs it for an editor.
+type DAPServer struct {
+	// NewEnv returns the environment a script is run in. By default it's an empty environment.
+	NewEnv func(program string, args []string) *object.Environment
+
+	in *bufio.Reader
+
+	outMu sync.Mutex
+	out   io.Writer
+	seq   int
+
+	interp  *eval.Interpreter
+	d       *Debugger
+	launch  *dapLaunchArgs
+	running bool
+	// resume receives the action of a stopped script.
+	resume chan Action
+	done   chan struct{}
+
+	mu       sync.Mutex
+	stop     *Stop
+	quitting bool
+	// refs are the environments and values variables requests can expand.
+	// They're only valid while the script is stopped.
+	refs []interface{}
+}
+
+type dapMessage struct {
+	Seq       int             `json:"seq"`
+	Type      string          `json:"type"`
+	Command   string          `json:"command"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+type dapResponse struct {
+	Seq        int         `json:"seq"`
+	Type       string      `json:"type"`
+	RequestSeq int         `json:"request_seq"`
+	Success    bool        `json:"success"`
+	Command    string      `json:"command"`
+	Message    string      `json:"message,omitempty"`
+	Body       interface{} `json:"body,omitempty"`
+}
+
+type dapEvent struct {
+	Seq   int         `json:"seq"`
+	Type  string      `json:"type"`
+	Event string      `json:"event"`
+	Body  interface{} `json:"body,omitempty"`
+}
+
+type dapLaunchArgs struct {
+	Program     string   `json:"program"`
+	Args        []string `json:"args"`
+	StopOnEntry bool     `json:"stopOnEntry"`
+}
+
+type dapSource struct {
+	Name string `json:"name,omitempty"`
+	Path string `json:"path,omitempty"`
+}
+
+type dapBreakpoint struct {
+	ID       int       `json:"id,omitempty"`
+	Verified bool      `json:"verified"`
+	Message  string    `json:"message,omitempty"`
+	Line     int       `json:"line,omitempty"`
+	Source   dapSource `json:"source"`
+}
+
+type dapVariable struct {
+	Name               string `json:"name"`
+	Value              string `json:"value"`
+	Type               string `json:"type,omitempty"`
+	VariablesReference int    `json:"variablesReference"`
+}
+
+// NewDAPServer returns a server reading requests from in and writing to out.
+func NewDAPServer(in io.Reader, out io.Writer) *DAPServer {
+	return &DAPServer{
+		in:     bufio.NewReader(in),
+		out:    out,
+		resume: make(chan Action),
+		done:   make(chan struct{}),
+	}
+}
+
+// Run handles requests until the client disconnects or the input ends.
+func (s *DAPServer) Run() error {
+	for {
+		content, err := readDAPMessage(s.in)
+		if err == io.EOF {
+			s.terminate()
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var req dapMessage
+		if err := json.Unmarshal(content, &req); err != nil {
+			return err
+		}
+		if req.Type != "request" {
+			continue
+		}
+
+		body, err := s.handle(&req)
+		resp := &dapResponse{Type: "response", RequestSeq: req.Seq, Success: err == nil, Command: req.Command, Body: body}
+		if err != nil {
+			resp.Message = err.Error()
+		}
+		if err := s.send(resp); err != nil {
+			return err
+		}
+
+		switch req.Command {
+		case "initialize":
+			s.event("initialized", nil)
+		case "configurationDone":
+			s.start()
+		case "disconnect":
+			s.terminate()
+			return nil
+		}
+	}
+}
+
+func (s *DAPServer) handle(req *dapMessage) (interface{}, error) {
+	switch req.Command {
+	case "initialize":
+		return map[string]bool{
+			"supportsConfigurationDoneRequest": true,
+			"supportsConditionalBreakpoints":   true,
+			"supportsSetVariable":              true,
+			"supportsEvaluateForHovers":        true,
+			"supportsTerminateRequest":         true,
+		}, nil
+	case "launch":
+		return nil, s.launchScript(req.Arguments)
+	case "setBreakpoints":
+		return s.setBreakpoints(req.Arguments)
+	case "setExceptionBreakpoints", "configurationDone", "disconnect":
+		return nil, nil
+	case "threads":
+		return map[string]interface{}{
+			"threads": []map[string]interface{}{{"id": threadID, "name": "main"}},
+		}, nil
+	case "stackTrace":
+		return s.stackTrace()
+	case "scopes":
+		return s.scopes(req.Arguments)
+	case "variables":
+		return s.variables(req.Arguments)
+	case "setVariable":
+		return s.setVariable(req.Arguments)
+	case "evaluate":
+		return s.evaluate(req.Arguments)
+	case "continue":
+		return map[string]bool{"allThreadsContinued": true}, s.resumeScript(Continue)
+	case "next":
+		return nil, s.resumeScript(StepOver)
+	case "stepIn":
+		return nil, s.resumeScript(StepIn)
+	case "stepOut":
+		return nil, s.resumeScript(StepOut)
+	case "pause":
+		if s.d != nil {
+			s.d.Pause()
+		}
+		return nil, nil
+	case "terminate":
+		s.terminate()
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unsupported request %s", req.Command)
+}
+
+func (s *DAPServer) send(msg interface{}) error {
+	s.outMu.Lock()
+	defer s.outMu.Unlock()
+
+	s.seq++
+	switch msg := msg.(type) {
+	case *dapResponse:
+		msg.Seq = s.seq
+	case *dapEvent:
+		msg.Seq = s.seq
+	}
+
+	content, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n", len(content)); err != nil {
+		return err
+	}
+	_, err = s.out.Write(content)
+	return err
+}
+
+func (s *DAPServer) event(name string, body interface{}) {
+	s.send(&dapEvent{Type: "event", Event: name, Body: body})
+}
+
+// outputWriter sends what a script writes as output events.
+type outputWriter struct {
+	s        *DAPServer
+	category string
+}
+
+func (w *outputWriter) Write(p []byte) (int, error) {
+	w.s.event("output", map[string]string{"category": w.category, "output": string(p)})
+	return len(p), nil
+}
+
+func (s *DAPServer) launchScript(args json.RawMessage) error {
+	var launch dapLaunchArgs
+	if err := json.Unmarshal(args, &launch); err != nil {
+		return err
+	}
+	if launch.Program == "" {
+		return errors.New("launch needs a program")
+	}
+	if s.d != nil {
+		return errors.New("a script was already launched")
+	}
+
+	s.launch = &launch
+	s.interp = eval.NewInterpreter()
+	s.interp.Stdin = strings.NewReader("")
+	s.interp.Stdout = &outputWriter{s: s, category: "stdout"}
+	s.interp.Stderr = &outputWriter{s: s, category: "stderr"}
+	s.d = New(s.interp)
+	s.d.StopOnEntry = launch.StopOnEntry
+	return nil
+}
+
+// start runs the launched script until it ends.
+func (s *DAPServer) start() {
+	if s.d == nil || s.running {
+		return
+	}
+	s.running = true
+
+	go func() {
+		defer close(s.done)
+		exitCode := 0
+		defer func() {
+			s.event("exited", map[string]int{"exitCode": exitCode})
+			s.event("terminated", nil)
+		}()
+
+		program, err := moduleutils.ASTCache.GetTree(s.launch.Program)
+		if err != nil {
+			s.event("output", map[string]string{"category": "stderr", "output": err.Error() + "\n"})
+			exitCode = 1
+			return
+		}
+
+		env := object.NewEnvironment()
+		if s.NewEnv != nil {
+			env = s.NewEnv(s.launch.Program, s.launch.Args)
+		}
+		result, err := s.d.Run(program, env, s.stopped)
+		if err != nil {
+			exitCode = 1
+			return
+		}
+		if exc, ok := result.(*object.Exception); ok {
+			s.event("output", map[string]string{
+				"category": "stderr",
+				"output":   "Uncaught Exception: " + exc.Message + "\n" + exc.StackTrace(),
+			})
+			exitCode = 1
+		}
+	}()
+}
+
+// stopped is the script's stop handler. It waits for the client to resume the script.
+func (s *DAPServer) stopped(stop *Stop) Action {
+	s.mu.Lock()
+	if s.quitting {
+		s.mu.Unlock()
+		return Quit
+	}
+	s.stop = stop
+	s.refs = nil
+	s.mu.Unlock()
+
+	body := map[string]interface{}{
+		"reason":            stop.Reason,
+		"threadId":          threadID,
+		"allThreadsStopped": true,
+	}
+	if stop.Breakpoint != nil {
+		body["hitBreakpointIds"] = []int{stop.Breakpoint.ID}
+	}
+	if stop.ConditionErr != nil {
+		body["text"] = "Error in breakpoint condition: " + stop.ConditionErr.Error()
+	}
+	s.event("stopped", body)
+
+	action := <-s.resume
+	s.mu.Lock()
+	s.stop = nil
+	s.refs = nil
+	s.mu.Unlock()
+	return action
+}
+
+func (s *DAPServer) currentStop() (*Stop, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop == nil {
+		return nil, errors.New("the script isn't stopped")
+	}
+	return s.stop, nil
+}
+
+func (s *DAPServer) resumeScript(action Action) error {
+	if _, err := s.currentStop(); err != nil {
+		return err
+	}
+	s.resume <- action
+	return nil
+}
+
+// terminate stops the script if it's running and waits for it to end.
+func (s *DAPServer) terminate() {
+	if !s.running {
+		return
+	}
+	s.mu.Lock()
+	s.quitting = true
+	stopped := s.stop != nil
+	s.mu.Unlock()
+
+	if stopped {
+		s.resume <- Quit
+	} else {
+		s.d.Pause()
+	}
+	<-s.done
+	s.running = false
+}
+
+func (s *DAPServer) setBreakpoints(args json.RawMessage) (interface{}, error) {
+	var p struct {
+		Source      dapSource `json:"source"`
+		Breakpoints []struct {
+			Line      int    `json:"line"`
+			Condition string `json:"condition"`
+		} `json:"breakpoints"`
+	}
+	if err := json.Unmarshal(args, &p); err != nil {
+		return nil, err
+	}
+	if s.d == nil {
+		return nil, errors.New("breakpoints must be set after launch")
+	}
+
+	s.d.ClearFileBreakpoints(p.Source.Path)
+	bps := []dapBreakpoint{}
+	for _, b := range p.Breakpoints {
+		bp, err := s.d.SetBreakpoint(p.Source.Path, b.Line, b.Condition)
+		if err != nil {
+			bps = append(bps, dapBreakpoint{Verified: false, Message: err.Error(), Line: b.Line, Source: p.Source})
+			continue
+		}
+		bps = append(bps, dapBreakpoint{ID: bp.ID, Verified: true, Line: bp.Line, Source: p.Source})
+	}
+	return map[string]interface{}{"breakpoints": bps}, nil
+}
+
+func (s *DAPServer) stackTrace() (interface{}, error) {
+	stop, err := s.currentStop()
+	if err != nil {
+		return nil, err
+	}
+
+	frames := make([]map[string]interface{}, len(stop.Frames))
+	for i, f := range stop.Frames {
+		frames[i] = map[string]interface{}{
+			"id":     i,
+			"name":   f.Function,
+			"source": dapSource{Name: filepath.Base(f.File), Path: f.File},
+			"line":   f.Line,
+			"column": f.Col,
+		}
+	}
+	return map[string]interface{}{"stackFrames": frames, "totalFrames": len(frames)}, nil
+}
+
+// frame returns the frame with an ID from a stackTrace response.
+func (s *DAPServer) frame(id int) (*Frame, error) {
+	stop, err := s.currentStop()
+	if err != nil {
+		return nil, err
+	}
+	if id < 0 || id >= len(stop.Frames) {
+		return nil, fmt.Errorf("no frame %d", id)
+	}
+	return stop.Frames[id], nil
+}
+
+// ref returns a variables reference for an environment or value.
+func (s *DAPServer) ref(v interface{}) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refs = append(s.refs, v)
+	return len(s.refs)
+}
+
+func (s *DAPServer) deref(ref int) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ref < 1 || ref > len(s.refs) {
+		return nil, fmt.Errorf("no variables reference %d", ref)
+	}
+	return s.refs[ref-1], nil
+}
+
+func (s *DAPServer) scopes(args json.RawMessage) (interface{}, error) {
+	var p struct {
+		FrameID int `json:"frameId"`
+	}
+	if err := json.Unmarshal(args, &p); err != nil {
+		return nil, err
+	}
+	frame, err := s.frame(p.FrameID)
+	if err != nil {
+		return nil, err
+	}
+
+	var scopes []map[string]interface{}
+	for _, scope := range Scopes(frame) {
+		scopes = append(scopes, map[string]interface{}{
+			"name":               scope.Name,
+			"variablesReference": s.ref(scope.Env),
+			"expensive":          false,
+		})
+	}
+	return map[string]interface{}{"scopes": scopes}, nil
+}
+
+func (s *DAPServer) variables(args json.RawMessage) (interface{}, error) {
+	var p struct {
+		VariablesReference int `json:"variablesReference"`
+	}
+	if err := json.Unmarshal(args, &p); err != nil {
+		return nil, err
+	}
+	v, err := s.deref(p.VariablesReference)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := []dapVariable{}
+	switch v := v.(type) {
+	case *object.Environment:
+		for _, variable := range Variables(v) {
+			vars = append(vars, s.variable(variable.Name, variable.Value))
+		}
+	case *object.Array:
+		for i, elem := range v.Elements {
+			vars = append(vars, s.variable(strconv.Itoa(i), elem))
+		}
+	case *object.Hash:
+		for _, pair := range v.Pairs() {
+			vars = append(vars, s.variable(pair.Key.Inspect(), pair.Value))
+		}
+	}
+	return map[string]interface{}{"variables": vars}, nil
+}
+
+// variable describes a value, arrays and maps can be expanded.
+func (s *DAPServer) variable(name string, val object.Object) dapVariable {
+	v := dapVariable{Name: name, Value: val.Inspect(), Type: val.Type().String()}
+	switch val := val.(type) {
+	case *object.Array:
+		if len(val.Elements) > 0 {
+			v.VariablesReference = s.ref(val)
+		}
+	case *object.Hash:
+		if len(val.Pairs()) > 0 {
+			v.VariablesReference = s.ref(val)
+		}
+	}
+	return v
+}
+
+func (s *DAPServer) setVariable(args json.RawMessage) (interface{}, error) {
+	var p struct {
+		VariablesReference int    `json:"variablesReference"`
+		Name               string `json:"name"`
+		Value              string `json:"value"`
+	}
+	if err := json.Unmarshal(args, &p); err != nil {
+		return nil, err
+	}
+	v, err := s.deref(p.VariablesReference)
+	if err != nil {
+		return nil, err
+	}
+	env, ok := v.(*object.Environment)
+	if !ok {
+		return nil, errors.New("only variables in a scope can be set")
+	}
+
+	val, err := s.d.Assign(&Frame{Env: env}, p.Name, p.Value)
+	if err != nil {
+		return nil, err
+	}
+	variable := s.variable(p.Name, val)
+	return map[string]interface{}{"value": variable.Value, "type": variable.Type, "variablesReference": variable.VariablesReference}, nil
+}
+
+func (s *DAPServer) evaluate(args json.RawMessage) (interface{}, error) {
+	var p struct {
+		Expression string `json:"expression"`
+		FrameID    int    `json:"frameId"`
+	}
+	if err := json.Unmarshal(args, &p); err != nil {
+		return nil, err
+	}
+	frame, err := s.frame(p.FrameID)
+	if err != nil {
+		return nil, err
+	}
+
+	val, err := s.d.Evaluate(frame, p.Expression)
+	if err != nil {
+		return nil, err
+	}
+	variable := s.variable("", val)
+	return map[string]interface{}{"result": variable.Value, "type": variable.Type, "variablesReference": variable.VariablesReference}, nil
+}
+
+// readDAPMessage reads the content of a message with its Content-Length header.
+func readDAPMessage(r *bufio.Reader) ([]byte, error) {
+	length := -1
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		if i := strings.IndexByte(line, ':'); i > -1 && strings.EqualFold(line[:i], "Content-Length") {
+			length, err = strconv.Atoi(strings.TrimSpace(line[i+1:]))
+			if err != nil {
+				return nil, fmt.Errorf("invalid Content-Length %q", line[i+1:])
+			}
+		}
+	}
+	if length < 0 {
+		return nil, errors.New("missing Content-Length header")
+	}
+
+	content := make([]byte, length)
+	if _, err := io.ReadFull(r, content); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
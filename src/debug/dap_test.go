@@ -0,0 +1,341 @@
+package debug
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// dapClient sends requests to a server over pipes like an editor would.
+type dapClient struct {
+	t  *testing.T
+	in *io.PipeWriter
+	// messages are read from the server as they're sent so neither side blocks writing.
+	messages chan map[string]json.RawMessage
+	// pending are messages read while waiting for another one.
+	pending []map[string]json.RawMessage
+	seq     int
+	done    chan error
+	output  strings.Builder
+}
+
+func newDAPClient(t *testing.T) *dapClient {
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+
+	c := &dapClient{
+		t:        t,
+		in:       clientOut,
+		messages: make(chan map[string]json.RawMessage, 100),
+		done:     make(chan error, 1),
+	}
+	go func() {
+		err := NewDAPServer(serverIn, serverOut).Run()
+		serverOut.Close()
+		c.done <- err
+	}()
+	go func() {
+		out := bufio.NewReader(clientIn)
+		for {
+			content, err := readDAPMessage(out)
+			if err != nil {
+				close(c.messages)
+				return
+			}
+			var msg map[string]json.RawMessage
+			json.Unmarshal(content, &msg)
+			c.messages <- msg
+		}
+	}()
+	return c
+}
+
+// next returns the first message match accepts, keeping the others for later.
+func (c *dapClient) next(match func(msg map[string]json.RawMessage) bool) map[string]json.RawMessage {
+	c.t.Helper()
+	for i, msg := range c.pending {
+		if match(msg) {
+			c.pending = append(c.pending[:i], c.pending[i+1:]...)
+			return msg
+		}
+	}
+	for {
+		select {
+		case msg, ok := <-c.messages:
+			if !ok {
+				c.t.Fatal("server closed its output")
+			}
+			if string(msg["event"]) == `"output"` {
+				var body struct{ Output string }
+				json.Unmarshal(msg["body"], &body)
+				c.output.WriteString(body.Output)
+			}
+			if match(msg) {
+				return msg
+			}
+			c.pending = append(c.pending, msg)
+		case <-time.After(5 * time.Second):
+			c.t.Fatal("timed out waiting for a message")
+		}
+	}
+}
+
+// request sends a request and decodes the body of its response into body.
+// It returns the message of a failed response.
+func (c *dapClient) request(command string, args, body interface{}) string {
+	c.t.Helper()
+	c.seq++
+	msg, _ := json.Marshal(map[string]interface{}{"seq": c.seq, "type": "request", "command": command, "arguments": args})
+	if _, err := fmt.Fprintf(c.in, "Content-Length: %d\r\n\r\n%s", len(msg), msg); err != nil {
+		c.t.Fatal(err)
+	}
+
+	seq := fmt.Sprint(c.seq)
+	resp := c.next(func(msg map[string]json.RawMessage) bool {
+		return string(msg["type"]) == `"response"` && string(msg["request_seq"]) == seq
+	})
+	if string(resp["success"]) != "true" {
+		var message string
+		json.Unmarshal(resp["message"], &message)
+		return message
+	}
+	if body != nil {
+		if err := json.Unmarshal(resp["body"], body); err != nil {
+			c.t.Fatal(err)
+		}
+	}
+	return ""
+}
+
+// mustRequest sends a request that must succeed.
+func (c *dapClient) mustRequest(command string, args, body interface{}) {
+	c.t.Helper()
+	if msg := c.request(command, args, body); msg != "" {
+		c.t.Fatalf("%s failed: %s", command, msg)
+	}
+}
+
+// event waits for an event and decodes its body into body.
+func (c *dapClient) event(name string, body interface{}) {
+	c.t.Helper()
+	msg := c.next(func(msg map[string]json.RawMessage) bool {
+		return string(msg["event"]) == `"`+name+`"`
+	})
+	if body != nil {
+		json.Unmarshal(msg["body"], body)
+	}
+}
+
+type stoppedBody struct {
+	Reason           string
+	HitBreakpointIds []int
+}
+
+type stackTraceBody struct {
+	StackFrames []struct {
+		ID, Line int
+		Name     string
+	}
+}
+
+type variablesBody struct {
+	Variables []dapVariable
+}
+
+func (c *dapClient) stackTrace() stackTraceBody {
+	c.t.Helper()
+	var body stackTraceBody
+	c.mustRequest("stackTrace", map[string]int{"threadId": threadID}, &body)
+	return body
+}
+
+func (c *dapClient) variables(ref int) map[string]dapVariable {
+	c.t.Helper()
+	var body variablesBody
+	c.mustRequest("variables", map[string]int{"variablesReference": ref}, &body)
+	vars := make(map[string]dapVariable, len(body.Variables))
+	for _, v := range body.Variables {
+		vars[v.Name] = v
+	}
+	return vars
+}
+
+// launch starts the test script with breakpoints at lines of it.
+func (c *dapClient) launch(stopOnEntry bool, breakpoints ...map[string]interface{}) string {
+	c.t.Helper()
+	path, _ := filepath.Abs(filepath.Join("testdata", "script.ni"))
+
+	var capabilities map[string]bool
+	c.mustRequest("initialize", map[string]string{"adapterID": "nitrogen"}, &capabilities)
+	if !capabilities["supportsConditionalBreakpoints"] {
+		c.t.Errorf("Expected conditional breakpoint support, got %v", capabilities)
+	}
+	c.event("initialized", nil)
+
+	c.mustRequest("launch", map[string]interface{}{"program": path, "stopOnEntry": stopOnEntry}, nil)
+	if len(breakpoints) > 0 {
+		var body struct{ Breakpoints []dapBreakpoint }
+		c.mustRequest("setBreakpoints", map[string]interface{}{
+			"source":      dapSource{Path: path},
+			"breakpoints": breakpoints,
+		}, &body)
+		for _, bp := range body.Breakpoints {
+			if !bp.Verified {
+				c.t.Errorf("Breakpoint not verified: %+v", bp)
+			}
+		}
+	}
+	c.mustRequest("configurationDone", nil, nil)
+	return path
+}
+
+// finish waits for the script to end and disconnects.
+func (c *dapClient) finish() int {
+	c.t.Helper()
+	var exited struct{ ExitCode int }
+	c.event("exited", &exited)
+	c.event("terminated", nil)
+	c.mustRequest("disconnect", nil, nil)
+	if err := <-c.done; err != nil {
+		c.t.Fatal(err)
+	}
+	return exited.ExitCode
+}
+
+func TestDAPBreakpoint(t *testing.T) {
+	c := newDAPClient(t)
+	c.launch(false, map[string]interface{}{"line": lineReturn, "condition": "b == 2"})
+
+	var stopped stoppedBody
+	c.event("stopped", &stopped)
+	if stopped.Reason != ReasonBreakpoint || len(stopped.HitBreakpointIds) != 1 {
+		t.Errorf("Expected a breakpoint stop, got %+v", stopped)
+	}
+
+	trace := c.stackTrace()
+	if len(trace.StackFrames) != 2 {
+		t.Fatalf("Expected 2 frames, got %+v", trace.StackFrames)
+	}
+	if top := trace.StackFrames[0]; top.Name != "add" || top.Line != lineReturn {
+		t.Errorf("Expected add at line %d, got %+v", lineReturn, top)
+	}
+
+	var scopes struct {
+		Scopes []struct {
+			Name               string
+			VariablesReference int
+		}
+	}
+	c.mustRequest("scopes", map[string]int{"frameId": 0}, &scopes)
+	if len(scopes.Scopes) != 2 || scopes.Scopes[0].Name != "Local" || scopes.Scopes[1].Name != "Global" {
+		t.Fatalf("Wrong scopes %+v", scopes.Scopes)
+	}
+	locals := c.variables(scopes.Scopes[0].VariablesReference)
+	if locals["a"].Value != "1" || locals["sum"].Value != "3" {
+		t.Errorf("Wrong locals %+v", locals)
+	}
+	if locals["args"].VariablesReference != 0 {
+		t.Errorf("Expected an empty array to have no children, got %+v", locals["args"])
+	}
+
+	var evaluated struct{ Result string }
+	c.mustRequest("evaluate", map[string]interface{}{"expression": "[a, b]", "frameId": 0}, &evaluated)
+	if evaluated.Result != "[1, 2]" {
+		t.Errorf("Expected [1, 2], got %q", evaluated.Result)
+	}
+
+	var set struct{ Value string }
+	c.mustRequest("setVariable", map[string]interface{}{
+		"variablesReference": scopes.Scopes[0].VariablesReference,
+		"name":               "sum",
+		"value":              "sum * 10",
+	}, &set)
+	if set.Value != "30" {
+		t.Errorf("Expected sum to be set to 30, got %q", set.Value)
+	}
+	msg := c.request("setVariable", map[string]interface{}{
+		"variablesReference": scopes.Scopes[1].VariablesReference,
+		"name":               "limit",
+		"value":              "1",
+	}, nil)
+	if msg != "limit is a constant" {
+		t.Errorf("Expected a constant error, got %q", msg)
+	}
+
+	c.mustRequest("continue", map[string]int{"threadId": threadID}, nil)
+	if code := c.finish(); code != 0 {
+		t.Errorf("Expected exit code 0, got %d", code)
+	}
+	if c.output.String() != "30\n" {
+		t.Errorf("Expected output 30, got %q", c.output.String())
+	}
+}
+
+func TestDAPStepping(t *testing.T) {
+	c := newDAPClient(t)
+	c.launch(true)
+
+	steps := []struct {
+		command string
+		name    string
+		line    int
+	}{
+		{"", "<main>", lineLimit},
+		{"next", "<main>", lineSum - 1},
+		{"next", "<main>", lineTotal},
+		{"next", "<main>", lineFor},
+		{"stepIn", "<main>", lineCallAdd},
+		{"stepIn", "add", lineSum},
+		{"stepOut", "<main>", lineCallAdd},
+	}
+	for _, step := range steps {
+		if step.command != "" {
+			c.mustRequest(step.command, map[string]int{"threadId": threadID}, nil)
+		}
+		var stopped stoppedBody
+		c.event("stopped", &stopped)
+
+		top := c.stackTrace().StackFrames[0]
+		if top.Name != step.name || top.Line != step.line {
+			t.Fatalf("After %s expected %s at line %d, got %+v", step.command, step.name, step.line, top)
+		}
+	}
+
+	var evaluated struct{ Result string }
+	c.mustRequest("evaluate", map[string]interface{}{"expression": "i", "frameId": 0}, &evaluated)
+	if evaluated.Result != "1" {
+		t.Errorf("Expected i to be 1, got %q", evaluated.Result)
+	}
+	if msg := c.request("evaluate", map[string]interface{}{"expression": "i +", "frameId": 0}, nil); msg == "" {
+		t.Error("Expected an error evaluating an invalid expression")
+	}
+
+	// Disconnecting while stopped ends the script
+	c.mustRequest("disconnect", nil, nil)
+	if err := <-c.done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDAPNotStopped(t *testing.T) {
+	c := newDAPClient(t)
+	if msg := c.request("stackTrace", map[string]int{"threadId": threadID}, nil); msg != "the script isn't stopped" {
+		t.Errorf("Expected an error, got %q", msg)
+	}
+	if msg := c.request("bogus", nil, nil); msg != "unsupported request bogus" {
+		t.Errorf("Expected an error, got %q", msg)
+	}
+
+	c.launch(false)
+	if code := c.finish(); code != 0 {
+		t.Errorf("Expected exit code 0, got %d", code)
+	}
+	if c.output.String() != "3\n" {
+		t.Errorf("Expected output 3, got %q", c.output.String())
+	}
+}
@@ -0,0 +1,441 @@
+// Package debug implements a step debugger for the tree walking interpreter.
+package debug
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/nitrogen-lang/nitrogen/src/ast"
+	"github.com/nitrogen-lang/nitrogen/src/eval"
+	"github.com/nitrogen-lang/nitrogen/src/lexer"
+	"github.com/nitrogen-lang/nitrogen/src/object"
+	"github.com/nitrogen-lang/nitrogen/src/parser"
+	"github.com/nitrogen-lang/nitrogen/src/token"
+)
+
+// Action is how a stopped script continues.
+type Action int
+
+const (
+	// Continue runs until a breakpoint.
+	Continue Action = iota
+	// StepIn stops at the next statement, including in called functions.
+	StepIn
+	// StepOver stops at the next statement in the current function or a caller.
+	StepOver
+	// StepOut stops at the next statement in a caller of the current function.
+	StepOut
+	// Quit stops running the script.
+	Quit
+)
+
+// Reasons a script stops
+const (
+	ReasonEntry      = "entry"
+	ReasonBreakpoint = "breakpoint"
+	ReasonStep       = "step"
+	ReasonPause      = "pause"
+)
+
+// ErrQuit is returned by Run when the script was stopped with Quit.
+var ErrQuit = errors.New("script stopped by the debugger")
+
+// Breakpoint stops the script before a statement on a line is run.
+type Breakpoint struct {
+	ID   int
+	File string
+	Line int
+	// Condition is an expression that must be true for the breakpoint to stop the script.
+	Condition string
+	// Hits is the number of times the breakpoint stopped the script.
+	Hits int
+
+	absFile   string
+	condition ast.Node
+}
+
+func (b *Breakpoint) String() string {
+	s := fmt.Sprintf("%s:%d", b.File, b.Line)
+	if b.Condition != "" {
+		s += " if " + b.Condition
+	}
+	return s
+}
+
+// Frame is a function or script in a stopped script.
+type Frame struct {
+	Function string
+	File     string
+	Line     int
+	Col      int
+	Env      *object.Environment
+}
+
+func (f *Frame) String() string {
+	return fmt.Sprintf("%s at %s:%d:%d", f.Function, f.File, f.Line, f.Col)
+}
+
+// Stop describes where a script stopped.
+type Stop struct {
+	Reason string
+	// Breakpoint is the breakpoint the script stopped at, if any.
+	Breakpoint *Breakpoint
+	// ConditionErr is the error evaluating the condition of the breakpoint.
+	// Breakpoints stop the script when their condition fails.
+	ConditionErr error
+	// Frames are the frames being run, the innermost first.
+	Frames []*Frame
+}
+
+// Handler is called when the script stops and returns how it continues. The
+// script doesn't run until the handler returns.
+type Handler func(stop *Stop) Action
+
+// Debugger runs scripts with an interpreter and stops them at breakpoints and steps.
+type Debugger struct {
+	// StopOnEntry stops the script before its first statement.
+	StopOnEntry bool
+
+	interp  *eval.Interpreter
+	handler Handler
+
+	mu          sync.Mutex
+	breakpoints []*Breakpoint
+	nextID      int
+	pause       bool
+
+	action Action
+	// depth is the number of frames when the step action was chosen.
+	depth   int
+	started bool
+	// evaluating is set while the debugger runs code for a front end.
+	evaluating bool
+	// last and lastStmt are the previous statement and where it was. A
+	// breakpoint doesn't stop at the following statements on the same line.
+	last     location
+	lastStmt ast.Statement
+	absPaths map[string]string
+}
+
+type location struct {
+	file  string
+	line  int
+	depth int
+}
+
+// New returns a debugger for scripts run by interp.
+func New(interp *eval.Interpreter) *Debugger {
+	return &Debugger{
+		interp:   interp,
+		absPaths: make(map[string]string),
+	}
+}
+
+// Run runs program in env and calls handler each time it stops. It returns
+// ErrQuit if the handler stopped the script.
+func (d *Debugger) Run(program *ast.Program, env *object.Environment, handler Handler) (result object.Object, err error) {
+	d.handler = handler
+	d.interp.SetStatementHook(d.statement)
+	defer d.interp.SetStatementHook(nil)
+
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(quit); !ok {
+				panic(r)
+			}
+			err = ErrQuit
+		}
+	}()
+	return d.interp.Eval(program, env), nil
+}
+
+// quit unwinds the interpreter when the handler returns Quit.
+type quit struct{}
+
+// SetBreakpoint adds a breakpoint at a line of file. The condition is optional.
+func (d *Debugger) SetBreakpoint(file string, line int, condition string) (*Breakpoint, error) {
+	bp := &Breakpoint{File: file, Line: line, Condition: condition, absFile: absPath(file)}
+	if condition != "" {
+		node, err := parse(condition)
+		if err != nil {
+			return nil, err
+		}
+		bp.condition = node
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.nextID++
+	bp.ID = d.nextID
+	d.breakpoints = append(d.breakpoints, bp)
+	return bp, nil
+}
+
+// ClearBreakpoint removes the breakpoint with an ID and reports if it existed.
+func (d *Debugger) ClearBreakpoint(id int) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i, bp := range d.breakpoints {
+		if bp.ID == id {
+			d.breakpoints = append(d.breakpoints[:i], d.breakpoints[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// ClearFileBreakpoints removes the breakpoints in file.
+func (d *Debugger) ClearFileBreakpoints(file string) {
+	abs := absPath(file)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	kept := d.breakpoints[:0]
+	for _, bp := range d.breakpoints {
+		if bp.absFile != abs {
+			kept = append(kept, bp)
+		}
+	}
+	d.breakpoints = kept
+}
+
+// Breakpoints returns the breakpoints ordered by ID.
+func (d *Debugger) Breakpoints() []*Breakpoint {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]*Breakpoint(nil), d.breakpoints...)
+}
+
+// Pause stops the script before its next statement.
+func (d *Debugger) Pause() {
+	d.mu.Lock()
+	d.pause = true
+	d.mu.Unlock()
+}
+
+// Evaluate runs src in the environment of frame and returns its value.
+// Exceptions are returned as errors.
+func (d *Debugger) Evaluate(frame *Frame, src string) (object.Object, error) {
+	node, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+	return d.eval(node, frame.Env)
+}
+
+// Assign sets the variable name visible in frame to the value of src.
+func (d *Debugger) Assign(frame *Frame, name, src string) (object.Object, error) {
+	val, err := d.Evaluate(frame, src)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := frame.Env.Set(name, val); err != nil {
+		if object.IsConstErr(err) {
+			return nil, fmt.Errorf("%s is a constant", name)
+		}
+		return nil, fmt.Errorf("%s is not defined", name)
+	}
+	return val, nil
+}
+
+// parse parses src as the statements of a script.
+func parse(src string) (ast.Node, error) {
+	p := parser.New(lexer.NewString(src), nil)
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		return nil, errors.New(strings.Join(p.Errors(), "\n"))
+	}
+	if len(program.Statements) == 0 {
+		return nil, errors.New("no expression")
+	}
+	if len(program.Statements) == 1 {
+		if exp, ok := program.Statements[0].(*ast.ExpressionStatement); ok {
+			return exp.Expression, nil
+		}
+	}
+	return &ast.BlockStatement{Statements: program.Statements}, nil
+}
+
+// eval runs node without stopping at breakpoints in code it calls.
+func (d *Debugger) eval(node ast.Node, env *object.Environment) (object.Object, error) {
+	d.evaluating = true
+	defer func() { d.evaluating = false }()
+
+	result := d.interp.Eval(node, env)
+	switch r := result.(type) {
+	case nil:
+		return object.NullConst, nil
+	case *object.Exception:
+		return nil, errors.New(r.Message)
+	case *object.ReturnValue:
+		return r.Value, nil
+	case *object.LoopControl:
+		return nil, errors.New("break and continue can't be used outside a loop")
+	}
+	return result, nil
+}
+
+// statement is the interpreter's statement hook.
+func (d *Debugger) statement(stmt ast.Statement, frames []*eval.Frame) {
+	if d.evaluating {
+		return
+	}
+
+	tok := statementToken(stmt)
+	here := location{file: tok.Filename, line: tok.Pos.Line, depth: len(frames)}
+	sameLine := here == d.last && stmt != d.lastStmt
+	d.last, d.lastStmt = here, stmt
+
+	d.mu.Lock()
+	paused := d.pause
+	d.pause = false
+	d.mu.Unlock()
+
+	entry := !d.started
+	d.started = true
+
+	stop := &Stop{}
+	switch {
+	case entry && d.StopOnEntry:
+		stop.Reason = ReasonEntry
+	case paused:
+		stop.Reason = ReasonPause
+	case d.action == StepIn,
+		d.action == StepOver && len(frames) <= d.depth,
+		d.action == StepOut && len(frames) < d.depth:
+		stop.Reason = ReasonStep
+	case !sameLine:
+		d.checkBreakpoint(stop, tok, frames)
+	}
+	if stop.Reason == "" {
+		return
+	}
+
+	stop.Frames = stackFrames(tok, frames)
+	d.action = d.handler(stop)
+	d.depth = len(frames)
+	if d.action == Quit {
+		panic(quit{})
+	}
+}
+
+// checkBreakpoint sets stop to the first breakpoint at tok whose condition is true.
+func (d *Debugger) checkBreakpoint(stop *Stop, tok token.Token, frames []*eval.Frame) {
+	if tok.Filename == "" {
+		return
+	}
+	abs, ok := d.absPaths[tok.Filename]
+	if !ok {
+		abs = absPath(tok.Filename)
+		d.absPaths[tok.Filename] = abs
+	}
+
+	for _, bp := range d.Breakpoints() {
+		if bp.Line != tok.Pos.Line || !sameFile(bp, tok.Filename, abs) {
+			continue
+		}
+		if bp.condition != nil {
+			val, err := d.eval(bp.condition, frames[len(frames)-1].Env)
+			if err == nil && !eval.IsTruthy(val) {
+				continue
+			}
+			stop.ConditionErr = err
+		}
+
+		d.mu.Lock()
+		bp.Hits++
+		d.mu.Unlock()
+		stop.Reason = ReasonBreakpoint
+		stop.Breakpoint = bp
+		return
+	}
+}
+
+// sameFile reports if bp is in file. A breakpoint file without a directory
+// matches files with that name in any directory.
+func sameFile(bp *Breakpoint, file, abs string) bool {
+	if bp.absFile == abs {
+		return true
+	}
+	return !strings.ContainsAny(bp.File, `/\`) && filepath.Base(file) == bp.File
+}
+
+func absPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	return abs
+}
+
+// statementToken returns a token with the position of stmt. Functions and
+// classes declared without let start at their name.
+func statementToken(stmt ast.Statement) token.Token {
+	tok := ast.NodeToken(stmt)
+	if def, ok := stmt.(*ast.DefStatement); ok && tok.Pos.Line == 0 {
+		tok = def.Name.Token
+	}
+	return tok
+}
+
+// stackFrames converts the interpreter's frames to frames with positions, the innermost first.
+func stackFrames(tok token.Token, frames []*eval.Frame) []*Frame {
+	stack := make([]*Frame, 0, len(frames))
+	for i := len(frames) - 1; i >= 0; i-- {
+		f := frames[i]
+		if i < len(frames)-1 {
+			tok = statementToken(f.Statement)
+		}
+		stack = append(stack, &Frame{
+			Function: f.Function,
+			File:     tok.Filename,
+			Line:     tok.Pos.Line,
+			Col:      tok.Pos.Col,
+			Env:      f.Env,
+		})
+	}
+	return stack
+}
+
+// Scope is an environment in the chain of a frame's environment.
+type Scope struct {
+	// Name is "Local" for the frame's environment, "Global" for the outermost, and "Enclosing" for the others.
+	Name string
+	Env  *object.Environment
+}
+
+// Scopes returns the environments that variables in frame are found in, the innermost first.
+func Scopes(frame *Frame) []Scope {
+	var scopes []Scope
+	for env := frame.Env; env != nil; env = env.Parent() {
+		name := "Enclosing"
+		if env == frame.Env {
+			name = "Local"
+		}
+		if env.Parent() == nil {
+			name = "Global"
+		}
+		scopes = append(scopes, Scope{Name: name, Env: env})
+	}
+	return scopes
+}
+
+// Variable is a variable in an environment.
+type Variable struct {
+	Name  string
+	Value object.Object
+	Const bool
+}
+
+// Variables returns the variables declared in env sorted by name.
+func Variables(env *object.Environment) []Variable {
+	names := env.LocalNames()
+	vars := make([]Variable, 0, len(names))
+	for _, name := range names {
+		val, _ := env.GetLocal(name)
+		vars = append(vars, Variable{Name: name, Value: val, Const: env.IsConstLocal(name)})
+	}
+	return vars
+}
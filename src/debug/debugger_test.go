@@ -0,0 +1,313 @@
+package debug
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nitrogen-lang/nitrogen/src/ast"
+	"github.com/nitrogen-lang/nitrogen/src/eval"
+	"github.com/nitrogen-lang/nitrogen/src/moduleutils"
+	"github.com/nitrogen-lang/nitrogen/src/object"
+
+	_ "github.com/nitrogen-lang/nitrogen/src/builtins/io"
+)
+
+// Lines of testdata/script.ni
+const (
+	lineLimit   = 1
+	lineSum     = 4
+	lineReturn  = 5
+	lineTotal   = 8
+	lineFor     = 9
+	lineCallAdd = 10
+	linePrint   = 12
+)
+
+func testScript(t *testing.T) (*ast.Program, string) {
+	t.Helper()
+	path, _ := filepath.Abs(filepath.Join("testdata", "script.ni"))
+	program, err := moduleutils.ASTCache.GetTree(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return program, path
+}
+
+func testDebugger(out *bytes.Buffer) *Debugger {
+	interp := eval.NewInterpreter()
+	interp.Stdout = out
+	return New(interp)
+}
+
+// run runs the test script with handler and returns what it printed.
+func run(t *testing.T, d *Debugger, handler Handler) (string, error) {
+	t.Helper()
+	program, _ := testScript(t)
+	_, err := d.Run(program, object.NewEnvironment(), handler)
+	return d.interp.Stdout.(*bytes.Buffer).String(), err
+}
+
+func TestStepOver(t *testing.T) {
+	d := testDebugger(&bytes.Buffer{})
+	d.StopOnEntry = true
+
+	var lines []int
+	out, err := run(t, d, func(stop *Stop) Action {
+		if len(stop.Frames) != 1 {
+			t.Errorf("Step over stopped in %s", stop.Frames[0])
+		}
+		lines = append(lines, stop.Frames[0].Line)
+		return StepOver
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "3\n" {
+		t.Errorf("Expected output 3, got %q", out)
+	}
+
+	expected := []int{lineLimit, lineSum - 1, lineTotal, lineFor, lineCallAdd, lineCallAdd, lineCallAdd, linePrint}
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected stops at lines %v, got %v", expected, lines)
+	}
+	for i, line := range expected {
+		if lines[i] != line {
+			t.Fatalf("Expected stops at lines %v, got %v", expected, lines)
+		}
+	}
+}
+
+func TestStepInAndOut(t *testing.T) {
+	d := testDebugger(&bytes.Buffer{})
+	_, path := testScript(t)
+	if _, err := d.SetBreakpoint(path, lineCallAdd, "i == 1"); err != nil {
+		t.Fatal(err)
+	}
+
+	var stops []*Stop
+	actions := []Action{StepIn, StepIn, StepOut, Continue}
+	_, err := run(t, d, func(stop *Stop) Action {
+		stops = append(stops, stop)
+		if len(stops) > len(actions) {
+			t.Fatalf("Unexpected stop at %s", stop.Frames[0])
+		}
+		return actions[len(stops)-1]
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stops) != len(actions) {
+		t.Fatalf("Expected %d stops, got %d", len(actions), len(stops))
+	}
+
+	tests := []struct {
+		reason   string
+		function string
+		line     int
+		depth    int
+	}{
+		{ReasonBreakpoint, "<main>", lineCallAdd, 1},
+		{ReasonStep, "add", lineSum, 2},
+		{ReasonStep, "add", lineReturn, 2},
+		{ReasonStep, "<main>", lineCallAdd, 1},
+	}
+	for i, tt := range tests {
+		stop := stops[i]
+		frame := stop.Frames[0]
+		if stop.Reason != tt.reason || frame.Function != tt.function || frame.Line != tt.line || len(stop.Frames) != tt.depth {
+			t.Errorf("Stop %d: expected %s in %s at line %d with %d frames, got %s at %s with %d frames",
+				i, tt.reason, tt.function, tt.line, tt.depth, stop.Reason, frame, len(stop.Frames))
+		}
+	}
+
+	if stops[0].Breakpoint == nil || stops[0].Breakpoint.Hits != 1 {
+		t.Errorf("Expected the breakpoint to be hit once, got %+v", stops[0].Breakpoint)
+	}
+}
+
+func TestBreakpointByBasename(t *testing.T) {
+	d := testDebugger(&bytes.Buffer{})
+	if _, err := d.SetBreakpoint("script.ni", lineSum, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	hits := 0
+	if _, err := run(t, d, func(stop *Stop) Action {
+		hits++
+		return Continue
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if hits != 3 {
+		t.Errorf("Expected 3 hits, got %d", hits)
+	}
+}
+
+func TestBreakpointConditionError(t *testing.T) {
+	d := testDebugger(&bytes.Buffer{})
+	if _, err := d.SetBreakpoint("script.ni", lineSum, "missing > 1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.SetBreakpoint("script.ni", lineSum, "a +"); err == nil {
+		t.Error("Expected an error for an invalid condition")
+	}
+
+	var stop *Stop
+	_, err := run(t, d, func(s *Stop) Action {
+		stop = s
+		return Quit
+	})
+	if err != ErrQuit {
+		t.Errorf("Expected ErrQuit, got %v", err)
+	}
+	if stop == nil || stop.ConditionErr == nil {
+		t.Fatalf("Expected a stop with a condition error, got %+v", stop)
+	}
+}
+
+func TestEvaluateAndAssign(t *testing.T) {
+	out := &bytes.Buffer{}
+	d := testDebugger(out)
+	d.SetBreakpoint("script.ni", lineReturn, "")
+
+	first := true
+	_, err := run(t, d, func(stop *Stop) Action {
+		if !first {
+			return Continue
+		}
+		first = false
+		frame := stop.Frames[0]
+
+		if val, err := d.Evaluate(frame, "a + b + limit"); err != nil || val.Inspect() != "3" {
+			t.Errorf("Expected a + b + limit to be 3, got %v, %v", val, err)
+		}
+		if _, err := d.Evaluate(frame, "missing"); err == nil {
+			t.Error("Expected an error evaluating an undefined variable")
+		}
+		if _, err := d.Assign(frame, "limit", "1"); err == nil || err.Error() != "limit is a constant" {
+			t.Errorf("Expected a constant error, got %v", err)
+		}
+		if _, err := d.Assign(frame, "missing", "1"); err == nil || err.Error() != "missing is not defined" {
+			t.Errorf("Expected an undefined error, got %v", err)
+		}
+		if _, err := d.Assign(frame, "sum", "10"); err != nil {
+			t.Error(err)
+		}
+
+		scopes := Scopes(frame)
+		if len(scopes) < 2 || scopes[0].Name != "Local" || scopes[len(scopes)-1].Name != "Global" {
+			t.Fatalf("Wrong scopes %+v", scopes)
+		}
+		var names []string
+		for _, v := range Variables(scopes[0].Env) {
+			names = append(names, v.Name)
+		}
+		if strings.Join(names, ",") != "a,args,b,sum" {
+			t.Errorf("Expected locals a,args,b,sum, got %v", names)
+		}
+		return Continue
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The first call returns 10 instead of 0, the others add 1 and 2 to it
+	if out.String() != "13\n" {
+		t.Errorf("Expected output 13, got %q", out.String())
+	}
+}
+
+func TestPause(t *testing.T) {
+	d := testDebugger(&bytes.Buffer{})
+	d.Pause()
+
+	var stop *Stop
+	if _, err := run(t, d, func(s *Stop) Action {
+		if stop == nil {
+			stop = s
+		}
+		return Continue
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// The pause is seen at the first statement
+	if stop == nil || stop.Reason != ReasonPause || stop.Frames[0].Line != lineLimit {
+		t.Errorf("Expected a pause at line %d, got %+v", lineLimit, stop)
+	}
+}
+
+func TestCLI(t *testing.T) {
+	commands := []string{
+		"break 4 if b == 1",
+		"bl",
+		"continue",
+		"bt",
+		"p a + b",
+		"set b = 10",
+		"set limit = 1",
+		"frame 1",
+		"locals",
+		"globals",
+		"frame 0",
+		"next",
+		"",
+		"p total",
+		"bogus",
+		"c",
+	}
+	in := strings.NewReader(strings.Join(commands, "\n") + "\n")
+	out := &bytes.Buffer{}
+	scriptOut := &bytes.Buffer{}
+
+	d := testDebugger(scriptOut)
+	d.StopOnEntry = true
+	cli := NewCLI(d, in, out)
+	if _, err := run(t, d, cli.Stopped); err != nil {
+		t.Fatal(err)
+	}
+
+	if scriptOut.String() != "12\n" {
+		t.Errorf("Expected script output 12, got %q", scriptOut.String())
+	}
+
+	expected := []string{
+		"Stopped at entry, <main> at ",
+		">   1  always limit = 3",
+		"Breakpoint 1 at ",
+		"script.ni:4 if b == 1 (hits 0)",
+		"Breakpoint 1, add at ",
+		"*0 add at ",
+		" 1 <main> at ",
+		"(debug) 1\n",
+		"b = 10",
+		"Error: limit is a constant",
+		"#1 <main> at ",
+		"let i = 1",
+		"let total = 0",
+		"always limit = 3",
+		">   5      return sum",
+		"(debug) 10\n",
+		"Unknown command bogus",
+	}
+	output := out.String()
+	for _, s := range expected {
+		if !strings.Contains(output, s) {
+			t.Errorf("Expected %q in output:\n%s", s, output)
+		}
+	}
+	// The empty line repeats next, which returns from add
+	if !strings.Contains(output, ">  10      total = add(total, i)") {
+		t.Errorf("Expected the repeated next to return to line 10:\n%s", output)
+	}
+}
+
+func TestCLIEndOfInput(t *testing.T) {
+	d := testDebugger(&bytes.Buffer{})
+	d.StopOnEntry = true
+	cli := NewCLI(d, strings.NewReader("next\n"), &bytes.Buffer{})
+
+	if _, err := run(t, d, cli.Stopped); err != ErrQuit {
+		t.Errorf("Expected ErrQuit, got %v", err)
+	}
+}
@@ -24,22 +24,27 @@ func (i *Interpreter) assignIdentValue(
 	val ast.Expression,
 	new bool,
 	env *object.Environment) object.Object {
-	// Protect builtin functions
-	if builtin := getBuiltin(name.Value); builtin != nil {
-		return object.NewException(
-			"Attempted redeclaration of builtin function '%s'",
-			name.Value,
-		)
+	scope, exists := env, false
+	if !new {
+		scope, exists = declaringEnv(name, env)
+	}
+
+	// Protect builtin functions, a variable that exists can't be one since they can't be declared
+	if !exists {
+		if builtin := getBuiltin(name.Value); builtin != nil {
+			return object.NewNameError(
+				"Attempted redeclaration of builtin function '%s'",
+				name.Value,
+			)
+		}
 	}
 
-	if !new { // Variables must be declared before use
-		if _, exists := env.Get(name.Value); !exists {
-			return object.NewException("Assignment to uninitialized variable %s", name.Value)
-		}
+	if !new && !exists { // Variables must be declared before use
+		return object.NewNameError("Assignment to uninitialized variable %s", name.Value)
 	}
 
-	if env.IsConst(name.Value) {
-		return object.NewException("Assignment to declared constant %s", name.Value)
+	if scope.IsConst(name.Value) {
+		return object.NewConstantError("Assignment to declared constant %s", name.Value)
 	}
 
 	var evaled object.Object = object.NullConst
@@ -54,25 +59,39 @@ func (i *Interpreter) assignIdentValue(
 	if new {
 		env.Create(name.Value, evaled)
 	} else {
-		env.Set(name.Value, evaled)
+		scope.Set(name.Value, evaled)
 	}
 	return object.NullConst
 }
 
+// declaringEnv returns the environment name is declared in if the resolver bound it,
+// otherwise it returns env. The bool reports if name is declared at all.
+func declaringEnv(name *ast.Identifier, env *object.Environment) (*object.Environment, bool) {
+	if name.Resolved {
+		if scope := env.Ancestor(name.Depth); scope != nil {
+			if _, ok := scope.GetLocal(name.Value); ok {
+				return scope, true
+			}
+		}
+	}
+	_, ok := env.Get(name.Value)
+	return env, ok
+}
+
 func (i *Interpreter) assignConstIdentValue(
 	name *ast.Identifier,
 	val ast.Expression,
 	env *object.Environment) object.Object {
 	// Protect builtin functions
 	if builtin := getBuiltin(name.Value); builtin != nil {
-		return object.NewException(
+		return object.NewNameError(
 			"Attempted redeclaration of builtin function '%s'",
 			name.Value,
 		)
 	}
 
 	if _, exists := env.Get(name.Value); exists { // Constants can't redeclare an existing var
-		return object.NewException("Can't assign constant to variable `%s`", name.Value)
+		return object.NewConstantError("Can't assign constant to variable `%s`", name.Value)
 	}
 
 	evaled := i.Eval(val, env)
@@ -81,7 +100,7 @@ func (i *Interpreter) assignConstIdentValue(
 	}
 
 	if !object.ObjectIs(evaled, object.IntergerObj, object.FloatObj, object.StringObj, object.NullObj, object.BooleanObj, object.ModuleObj) {
-		return object.NewException("Constants must be int, float, string, bool or null")
+		return object.NewTypeError("Constants must be int, float, string, bool or null")
 	}
 
 	// Ignore error since we check above
@@ -99,116 +118,82 @@ func (i *Interpreter) assignIndexedValue(
 	}
 
 	index := i.Eval(e.Index, env)
-	if isException(indexed) {
-		return indexed
+	if isException(index) {
+		return index
 	}
 
+	value := i.Eval(val, env)
+	if isException(value) {
+		return value
+	}
+
+	return assignIndex(indexed, index, value)
+}
+
+func assignIndex(indexed, index, value object.Object) object.Object {
 	switch indexed.Type() {
 	case object.ArrayObj:
-		return i.assignArrayIndex(indexed.(*object.Array), index, val, env)
+		return assignArrayIndex(indexed.(*object.Array), index, value)
 	case object.HashObj:
-		return i.assignHashMapIndex(indexed.(*object.Hash), index, val, env)
+		return assignHashMapIndex(indexed.(*object.Hash), index, value)
 	case object.ModuleObj:
-		return i.assignModuleVariable(indexed.(*object.Module), index, val, env)
+		return assignModuleVariable(indexed.(*object.Module), index, value)
 	case object.InstanceObj:
-		return i.assignInstanceVariable(indexed.(*object.Instance), index, val, env)
+		return assignInstanceVariable(indexed.(*object.Instance), index, value)
 	}
 	return object.NullConst
 }
 
-func (i *Interpreter) assignArrayIndex(
-	array *object.Array,
-	index object.Object,
-	val ast.Expression,
-	env *object.Environment) object.Object {
-
+func assignArrayIndex(array *object.Array, index, value object.Object) object.Object {
 	in, ok := index.(*object.Integer)
 	if !ok {
-		return object.NewException("Invalid array index type %s", index.(object.Object).Type())
-	}
-
-	value := i.Eval(val, env)
-	if isException(value) {
-		return value
+		return object.NewTypeError("Invalid array index type %s", index.Type())
 	}
 
 	if in.Value < 0 || in.Value > int64(len(array.Elements)-1) {
-		return object.NewException("Index out of bounds: %s", index.Inspect())
+		return object.NewIndexError("Index out of bounds: %s", index.Inspect())
 	}
 
 	array.Elements[in.Value] = value
 	return object.NullConst
 }
 
-func (i *Interpreter) assignHashMapIndex(
-	hashmap *object.Hash,
-	index object.Object,
-	val ast.Expression,
-	env *object.Environment) object.Object {
-
+func assignHashMapIndex(hashmap *object.Hash, index, value object.Object) object.Object {
 	hashable, ok := index.(object.Hashable)
 	if !ok {
-		return object.NewException("Invalid index type %s", index.Type())
+		return object.NewTypeError("Invalid index type %s", index.Type())
 	}
 
-	value := i.Eval(val, env)
-	if isException(value) {
-		return value
-	}
-
-	hashmap.Pairs[hashable.HashKey()] = object.HashPair{
-		Key:   index,
-		Value: value,
-	}
+	hashmap.Set(hashable, value)
 	return object.NullConst
 }
 
-func (i *Interpreter) assignModuleVariable(
-	module *object.Module,
-	index object.Object,
-	val ast.Expression,
-	env *object.Environment) object.Object {
-
+func assignModuleVariable(module *object.Module, index, value object.Object) object.Object {
 	hashable, ok := index.(*object.String)
 	if !ok {
-		return object.NewException("Invalid index type %s", index.Type())
+		return object.NewTypeError("Invalid index type %s", index.Type())
 	}
 
 	if _, exists := module.Vars[hashable.Value]; !exists {
-		return object.NewException("Module %s has no assignable variable %s", module.Name, hashable.Value)
-	}
-
-	value := i.Eval(val, env)
-	if isException(value) {
-		return value
+		return object.NewNameError("Module %s has no assignable variable %s", module.Name, hashable.Value)
 	}
 
 	module.Vars[hashable.Value] = value
 	return object.NullConst
 }
 
-func (i *Interpreter) assignInstanceVariable(
-	instance *object.Instance,
-	index object.Object,
-	val ast.Expression,
-	env *object.Environment) object.Object {
-
+func assignInstanceVariable(instance *object.Instance, index, value object.Object) object.Object {
 	hashable, ok := index.(*object.String)
 	if !ok {
-		return object.NewException("Invalid index type %s", index.Type())
+		return object.NewTypeError("Invalid index type %s", index.Type())
 	}
 
 	if _, ok := instance.Fields.Get(hashable.Value); !ok {
-		return object.NewException("Instance has no field %s", hashable.Value)
+		return object.NewNameError("Instance has no field %s", hashable.Value)
 	}
 
 	if instance.Fields.IsConst(hashable.Value) {
-		return object.NewException("Assignment to constant field %s", hashable.Value)
-	}
-
-	value := i.Eval(val, env)
-	if isException(value) {
-		return value
+		return object.NewConstantError("Assignment to constant field %s", hashable.Value)
 	}
 
 	instance.Fields.SetForce(hashable.Value, value, false)
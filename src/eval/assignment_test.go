@@ -1,4 +1,4 @@
-package eval
+package eval_test
 
 import (
 	"testing"
@@ -100,3 +100,39 @@ func TestHashAssignEval(t *testing.T) {
 		}
 	}
 }
+
+func TestDeclarationChecks(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`let a = b; let b = 1`, "Use of variable b before declaration"},
+		{`func f() { let a = x + 1; let x = 2; a }; 1`, "Use of variable x before declaration"},
+		{`func f() { x = 1 }; 1`, "Assignment to uninitialized variable x"},
+		{`for x in [1] { y = x }`, "Assignment to uninitialized variable y"},
+		// Functions can use variables declared after them
+		{`func f() { x }; let x = 5; f()`, 5},
+		{`let x = 1; func f() { let a = x; let x = 2; a + x }; f()`, 3},
+		{`if true { let a = 4 }; a`, 4},
+		{`try { throw "e" } catch e { let a = 6 }; a`, 6},
+		// Class bodies are resolved when an instance is made
+		{`class A { func get() { return v } }; let v = 7; let a = make A(); a.get()`, 7},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input, t)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			exc, ok := evaluated.(*object.Exception)
+			if !ok {
+				t.Fatalf("Expected exception for %q, got %s", tt.input, evaluated.Inspect())
+			}
+			if exc.Message != expected {
+				t.Errorf("Incorrect error, expected %s, got %s", expected, exc.Message)
+			}
+		}
+	}
+}
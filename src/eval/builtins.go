@@ -2,12 +2,14 @@ package eval
 
 import (
 	"regexp"
+	"sort"
 
 	"github.com/nitrogen-lang/nitrogen/src/object"
 )
 
 var (
-	builtins   = map[string]*object.Builtin{}
+	// builtins holds the builtin functions and classes
+	builtins   = map[string]object.Object{}
 	modules    = map[string]*object.Module{}
 	identRegex = regexp.MustCompile(`[a-zA-Z_][a-zA-Z0-9_]*`)
 )
@@ -26,6 +28,20 @@ func RegisterBuiltin(name string, fn object.BuiltinFunction) {
 	builtins[name] = &object.Builtin{Fn: fn}
 }
 
+// RegisterBuiltinClass allows other packages to register a class for availability in user code
+func RegisterBuiltinClass(c *object.Class) {
+	if !validBuiltinIdent(c.Name) {
+		panic("Invalid builtin class name " + c.Name)
+	}
+
+	if getBuiltin(c.Name) != nil {
+		// Panic because this should NEVER happen when built
+		panic("Builtin " + c.Name + " already defined")
+	}
+
+	builtins[c.Name] = c
+}
+
 // RegisterModule allows other packages to register a Module object for availability in user code
 func RegisterModule(name string, m *object.Module) {
 	for k := range m.Methods {
@@ -46,11 +62,33 @@ func validBuiltinIdent(ident string) bool {
 	return identRegex.Match([]byte(ident))
 }
 
-func getBuiltin(name string) object.Object {
-	if builtin, defined := builtins[name]; defined {
-		return builtin
+// BuiltinNames returns the sorted names of all builtin functions and classes.
+func BuiltinNames() []string {
+	names := make([]string, 0, len(builtins))
+	for name := range builtins {
+		names = append(names, name)
 	}
-	return nil
+	sort.Strings(names)
+	return names
+}
+
+// ModuleNames returns the sorted names of all registered modules.
+func ModuleNames() []string {
+	names := make([]string, 0, len(modules))
+	for name := range modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetBuiltin returns the builtin function or class registered with name, otherwise nil.
+func GetBuiltin(name string) object.Object {
+	return getBuiltin(name)
+}
+
+func getBuiltin(name string) object.Object {
+	return builtins[name]
 }
 
 // GetModule returns a Module object is a module with the given name is registered, otherwise nil.
@@ -60,3 +98,9 @@ func GetModule(name string) *object.Module {
 	}
 	return nil
 }
+
+func init() {
+	for _, c := range object.ExceptionClasses {
+		RegisterBuiltinClass(c)
+	}
+}
@@ -8,12 +8,12 @@ import (
 func (i *Interpreter) evalMakeInstance(ms *ast.MakeInstance, env *object.Environment) object.Object {
 	class := i.Eval(ms.Class, env)
 	if class == object.NullConst {
-		return object.NewException("Class %s not defined", ms.Class)
+		return object.NewNameError("Class %s not defined", ms.Class)
 	}
 
 	classObj, ok := class.(*object.Class)
 	if !ok {
-		return object.NewException("%s is not a class", ms.Class)
+		return object.NewTypeError("%s is not a class", ms.Class)
 	}
 
 	cClass := classObj
@@ -57,7 +57,16 @@ func (i *Interpreter) evalMakeInstance(ms *ast.MakeInstance, env *object.Environ
 		return args[0]
 	}
 
-	if ret := i.applyFunctionDirect(init, args, initEnv); isException(ret) || isPanic(ret) {
+	var ret object.Object
+	if initFn, ok := init.(*object.Function); ok {
+		i.pushCall(classObj.Name+"."+initFn.Name, ms.Token)
+		ret = i.applyFunctionDirect(init, args, initEnv)
+		i.popCall()
+	} else {
+		ret = i.applyFunctionDirect(init, args, initEnv)
+	}
+	if isException(ret) || isPanic(ret) {
+		i.recordTrace(ret, ms.Token)
 		return ret
 	}
 	return instance
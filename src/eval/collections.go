@@ -1,29 +1,99 @@
 package eval
 
 import (
+	"unicode/utf8"
+
 	"github.com/nitrogen-lang/nitrogen/src/ast"
 	"github.com/nitrogen-lang/nitrogen/src/object"
 )
 
-func (i *Interpreter) evalIndexExpression(left, index object.Object) object.Object {
+func evalIndexExpression(left, index object.Object, current *object.Instance) object.Object {
 	switch {
 	case left.Type() == object.ArrayObj && index.Type() == object.IntergerObj:
-		return i.evalArrayIndexExpression(left, index)
+		return evalArrayIndexExpression(left, index)
 	case left.Type() == object.HashObj:
-		return i.evalHashIndexExpression(left, index)
+		return evalHashIndexExpression(left, index)
 	case left.Type() == object.StringObj && index.Type() == object.IntergerObj:
-		return i.evalStringIndexExpression(left, index)
+		return evalStringIndexExpression(left, index)
 	case left.Type() == object.ModuleObj && index.Type() == object.StringObj:
-		return i.evalModuleLookupExpression(left, index)
+		return evalModuleLookupExpression(left, index)
 	case left.Type() == object.InstanceObj && index.Type() == object.StringObj:
-		return i.evalInstanceLookupExpression(left, index)
+		return evalInstanceLookupExpression(left, index)
 	case left.Type() == object.ClassObj && index.Type() == object.StringObj:
-		return i.evalClassLookupExpression(left, index)
+		return evalClassLookupExpression(left, index, current)
+	case left.Type() == object.ExceptionObj && index.Type() == object.StringObj:
+		return evalExceptionLookupExpression(left, index)
+	}
+	return object.NewTypeError("Index operator not allowed: %s", left.Type())
+}
+
+// evalSliceExpression evaluates left[low:high]. low and high are nil if they were omitted.
+// Strings are sliced by code point.
+func evalSliceExpression(left, low, high object.Object) object.Object {
+	var length int
+	switch left := left.(type) {
+	case *object.Array:
+		length = len(left.Elements)
+	case *object.String:
+		length = utf8.RuneCountInString(left.Value)
+	default:
+		return object.NewTypeError("Slice operator not allowed: %s", left.Type())
+	}
+
+	start, end := int64(0), int64(length)
+	if low != nil && low != object.NullConst {
+		lowInt, ok := low.(*object.Integer)
+		if !ok {
+			return object.NewTypeError("Slice index must be INTEGER, got %s", low.Type())
+		}
+		start = lowInt.Value
+	}
+	if high != nil && high != object.NullConst {
+		highInt, ok := high.(*object.Integer)
+		if !ok {
+			return object.NewTypeError("Slice index must be INTEGER, got %s", high.Type())
+		}
+		end = highInt.Value
+	}
+
+	i, j := sliceBounds(length, start, end)
+	switch left := left.(type) {
+	case *object.Array:
+		newElements := make([]object.Object, j-i)
+		copy(newElements, left.Elements[i:j])
+		return &object.Array{Elements: newElements}
+	case *object.String:
+		start := runeOffset(left.Value, i)
+		return &object.String{Value: left.Value[start : start+runeOffset(left.Value[start:], j-i)]}
+	}
+	return object.NullConst
+}
+
+// sliceBounds converts the start and end of a slice of a sequence of length elements
+// to indexes. Negative values count from the end of the sequence and values outside the
+// sequence are clamped to it. An end before the start results in an empty slice.
+func sliceBounds(length int, start, end int64) (int, int) {
+	clamp := func(i int64) int {
+		if i < 0 {
+			i += int64(length)
+		}
+		if i < 0 {
+			return 0
+		}
+		if i > int64(length) {
+			return length
+		}
+		return int(i)
+	}
+
+	low, high := clamp(start), clamp(end)
+	if high < low {
+		high = low
 	}
-	return object.NewException("Index operator not allowed: %s", left.Type())
+	return low, high
 }
 
-func (i *Interpreter) evalArrayIndexExpression(array, index object.Object) object.Object {
+func evalArrayIndexExpression(array, index object.Object) object.Object {
 	arrObj := array.(*object.Array)
 	idx := index.(*object.Integer).Value
 	max := int64(len(arrObj.Elements))
@@ -44,23 +114,23 @@ func (i *Interpreter) evalArrayIndexExpression(array, index object.Object) objec
 	return arrObj.Elements[idx]
 }
 
-func (i *Interpreter) evalHashIndexExpression(hash, index object.Object) object.Object {
+func evalHashIndexExpression(hash, index object.Object) object.Object {
 	hashObj := hash.(*object.Hash)
 
 	key, ok := index.(object.Hashable)
 	if !ok {
-		return object.NewException("Invalid map key: %s", index.Type())
+		return object.NewTypeError("Invalid map key: %s", index.Type())
 	}
 
-	pair, ok := hashObj.Pairs[key.HashKey()]
+	value, ok := hashObj.Get(key)
 	if !ok {
 		return object.NullConst
 	}
 
-	return pair.Value
+	return value
 }
 
-func (i *Interpreter) evalModuleLookupExpression(module, index object.Object) object.Object {
+func evalModuleLookupExpression(module, index object.Object) object.Object {
 	moduleObj := module.(*object.Module)
 	key := index.(*object.String)
 
@@ -78,35 +148,35 @@ func (i *Interpreter) evalModuleLookupExpression(module, index object.Object) ob
 }
 
 func (i *Interpreter) evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
-	pairs := make(map[object.HashKey]object.HashPair)
+	hash := object.NewHash(len(node.Pairs))
 
-	for keyNode, valueNode := range node.Pairs {
-		key := i.Eval(keyNode, env)
+	for _, pairNode := range node.Pairs {
+		key := i.Eval(pairNode.Key, env)
 		if isException(key) {
 			return key
 		}
 
 		hashKey, ok := key.(object.Hashable)
 		if !ok {
-			return object.NewException("Invalid map key: %s", key.Type())
+			return object.NewTypeError("Invalid map key: %s", key.Type())
 		}
 
-		value := i.Eval(valueNode, env)
+		value := i.Eval(pairNode.Value, env)
 		if isException(value) {
 			return value
 		}
 
-		hashed := hashKey.HashKey()
-		pairs[hashed] = object.HashPair{Key: key, Value: value}
+		hash.Set(hashKey, value)
 	}
 
-	return &object.Hash{Pairs: pairs}
+	return hash
 }
 
-func (i *Interpreter) evalStringIndexExpression(array, index object.Object) object.Object {
+// evalStringIndexExpression returns the code point at index as a string.
+func evalStringIndexExpression(array, index object.Object) object.Object {
 	strObj := array.(*object.String)
 	idx := index.(*object.Integer).Value
-	max := int64(len(strObj.Value))
+	max := int64(utf8.RuneCountInString(strObj.Value))
 
 	if idx > max-1 { // Check upper bound
 		return object.NullConst
@@ -121,10 +191,24 @@ func (i *Interpreter) evalStringIndexExpression(array, index object.Object) obje
 		}
 	}
 
-	return &object.String{Value: string(strObj.Value[idx])}
+	start := runeOffset(strObj.Value, int(idx))
+	_, size := utf8.DecodeRuneInString(strObj.Value[start:])
+	return &object.String{Value: strObj.Value[start : start+size]}
+}
+
+// runeOffset returns the byte offset of code point i in str, or len(str) if str has i code points.
+// An invalid UTF-8 byte counts as one code point.
+func runeOffset(str string, i int) int {
+	for offset := range str {
+		if i == 0 {
+			return offset
+		}
+		i--
+	}
+	return len(str)
 }
 
-func (i *Interpreter) evalInstanceLookupExpression(instance, index object.Object) object.Object {
+func evalInstanceLookupExpression(instance, index object.Object) object.Object {
 	instanceObj := instance.(*object.Instance)
 	key := index.(*object.String)
 
@@ -138,6 +222,7 @@ func (i *Interpreter) evalInstanceLookupExpression(instance, index object.Object
 				Body:       m.Body,
 				Env:        object.NewEnclosedEnv(instanceObj.Fields),
 				Instance:   instanceObj,
+				Compiled:   m.Compiled,
 			}
 			if instanceObj.Class.Parent != nil {
 				fn.Env.CreateConst("parent", instanceObj.Class.Parent)
@@ -158,9 +243,33 @@ func (i *Interpreter) evalInstanceLookupExpression(instance, index object.Object
 	return object.NullConst
 }
 
-func (i *Interpreter) evalClassLookupExpression(class, index object.Object) object.Object {
+// evalExceptionLookupExpression looks up the message, payload, or class (type) of an exception.
+// Other fields and methods come from the thrown instance, if any.
+func evalExceptionLookupExpression(exception, index object.Object) object.Object {
+	exceptionObj := exception.(*object.Exception)
+
+	switch index.(*object.String).Value {
+	case "message":
+		return &object.String{Value: exceptionObj.Message}
+	case "payload":
+		if exceptionObj.Payload != nil {
+			return exceptionObj.Payload
+		}
+	case "type":
+		if exceptionObj.Class != nil {
+			return exceptionObj.Class
+		}
+	default:
+		if exceptionObj.Payload != nil && exceptionObj.Payload.Type() == object.InstanceObj {
+			return evalInstanceLookupExpression(exceptionObj.Payload, index)
+		}
+	}
+	return object.NullConst
+}
+
+func evalClassLookupExpression(class, index object.Object, current *object.Instance) object.Object {
 	classObj := class.(*object.Class)
-	if !object.InstanceOf(classObj.Name, i.currentInstance) {
+	if !object.InstanceOf(classObj.Name, current) {
 		return object.NullConst
 	}
 	key := index.(*object.String)
@@ -173,8 +282,9 @@ func (i *Interpreter) evalClassLookupExpression(class, index object.Object) obje
 				Name:       m.Name,
 				Parameters: m.Parameters,
 				Body:       m.Body,
-				Env:        i.currentInstance.Fields,
-				Instance:   i.currentInstance,
+				Env:        current.Fields,
+				Instance:   current,
+				Compiled:   m.Compiled,
 			}
 			if classObj.Parent != nil {
 				fn.Env.CreateConst("parent", classObj.Parent)
@@ -183,7 +293,7 @@ func (i *Interpreter) evalClassLookupExpression(class, index object.Object) obje
 		case *object.BuiltinMethod:
 			return &object.BuiltinMethod{
 				Fn:       m.Fn,
-				Instance: i.currentInstance,
+				Instance: current,
 			}
 		}
 	}
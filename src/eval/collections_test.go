@@ -1,4 +1,4 @@
-package eval
+package eval_test
 
 import (
 	"testing"
@@ -117,6 +117,39 @@ func TestArrayConcatenation(t *testing.T) {
 	}
 }
 
+func TestSliceExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`[1, 2, 3, 4][1:3]`, `[2, 3]`},
+		{`[1, 2, 3, 4][:2]`, `[1, 2]`},
+		{`[1, 2, 3, 4][2:]`, `[3, 4]`},
+		{`[1, 2, 3, 4][:]`, `[1, 2, 3, 4]`},
+		{`[1, 2, 3, 4][:-1]`, `[1, 2, 3]`},
+		{`[1, 2, 3, 4][-3:-1]`, `[2, 3]`},
+		{`[1, 2, 3, 4][-10:10]`, `[1, 2, 3, 4]`},
+		{`[1, 2, 3, 4][3:1]`, `[]`},
+		{`[1, 2, 3, 4][5:]`, `[]`},
+		{`let a = [1, 2, 3]; let b = a[:]; b[0] = 5; a`, `[1, 2, 3]`},
+		{`let a = nil; [1, 2, 3][a:2]`, `[1, 2]`},
+		{`"Hello, world"[7:]`, `world`},
+		{`"Hello, world"[:-7]`, `Hello`},
+		{`"Hello"[2:2]`, ``},
+		{`[1, 2]["a":]`, `Slice index must be INTEGER, got STRING`},
+		{`[1, 2][:1.5]`, `Slice index must be INTEGER, got FLOAT`},
+		{`{"a": 1}[0:1]`, `Slice operator not allowed: MAP`},
+		{`let a = [1]; a[0:1] = 2`, `Invalid variable name, expected identifier, got (a[0:1])`},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input, t)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %s. expected=%s, got=%s", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
 func TestHashLiterals(t *testing.T) {
 	input := `let two = "two";
 		{
@@ -132,23 +165,33 @@ func TestHashLiterals(t *testing.T) {
 		t.Fatalf("Eval didn't return Hash. got=%T (%+v)", evaluated, showError(evaluated))
 	}
 
-	expected := map[object.HashKey]int64{
-		(&object.String{Value: "one"}).HashKey():   1,
-		(&object.String{Value: "two"}).HashKey():   2,
-		(&object.String{Value: "three"}).HashKey(): 3,
-		(&object.Integer{Value: 4}).HashKey():      4,
+	expected := []struct {
+		key   object.Hashable
+		value int64
+	}{
+		{&object.String{Value: "one"}, 1},
+		{&object.String{Value: "two"}, 2},
+		{&object.String{Value: "three"}, 3},
+		{&object.Integer{Value: 4}, 4},
 	}
 
-	if len(result.Pairs) != len(expected) {
-		t.Fatalf("Hash has wrong num of pairs. got=%d", len(result.Pairs))
+	if result.Len() != len(expected) {
+		t.Fatalf("Hash has wrong num of pairs. got=%d", result.Len())
+	}
+
+	for i, pair := range result.Pairs() {
+		if pair.Key.Inspect() != expected[i].key.Inspect() {
+			t.Errorf("pair %d has wrong key. expected=%s, got=%s", i, expected[i].key.Inspect(), pair.Key.Inspect())
+		}
 	}
 
-	for expectedKey, expectedValue := range expected {
-		pair, ok := result.Pairs[expectedKey]
+	for _, expected := range expected {
+		value, ok := result.Get(expected.key)
 		if !ok {
-			t.Errorf("no pair for given key in Pairs")
+			t.Errorf("no pair for key %s", expected.key.Inspect())
+			continue
 		}
-		testIntegerObject(t, pair.Value, expectedValue)
+		testIntegerObject(t, value, expected.value)
 	}
 }
 
@@ -240,6 +283,26 @@ func TestStringIndexExpressions(t *testing.T) {
 			`"Hello, world"[-13]`,
 			nil,
 		},
+		{
+			`"héllo"[1]`,
+			"é",
+		},
+		{
+			`"日本語"[-1]`,
+			"語",
+		},
+		{
+			`"日本語"[3]`,
+			nil,
+		},
+		{
+			`"héllo wörld"[1:8]`,
+			"éllo wö",
+		},
+		{
+			`let s = ""; for i, c in "añb" { s = "${s}${i}${c}" }; s`,
+			"0a1ñ2b",
+		},
 	}
 
 	for _, tt := range tests {
@@ -252,3 +315,27 @@ func TestStringIndexExpressions(t *testing.T) {
 		}
 	}
 }
+
+func TestHashOrder(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{"z": 1, "a": 2, 5: 3}`, `["z", "a", 5]`},
+		{`let m = {"z": 1}; m["a"] = 2; m["z"] = 3; m["b"] = 4; m`, `["z", "a", "b"]`},
+		{`let m = {}; for x in [3, 1, 2] { m[x] = x }; m`, `[3, 1, 2]`},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input, t)
+		hash, ok := evaluated.(*object.Hash)
+		if !ok {
+			t.Fatalf("Eval didn't return Hash. got=%T (%+v)", evaluated, showError(evaluated))
+		}
+
+		keys := (&object.Array{Elements: hash.Keys()}).Inspect()
+		if keys != tt.expected {
+			t.Errorf("wrong key order. expected=%s, got=%s", tt.expected, keys)
+		}
+	}
+}
@@ -22,6 +22,9 @@ func (i *Interpreter) evalIfExpression(ie *ast.IfExpression, env *object.Environ
 }
 
 func (i *Interpreter) evalForLoop(loop *ast.ForLoopStatement, env *object.Environment) object.Object {
+	if loop.Collection != nil {
+		return i.evalForInLoop(loop, env)
+	}
 	outterScope := object.NewEnclosedEnv(env)
 
 	if loop.Init != nil {
@@ -70,6 +73,11 @@ func (i *Interpreter) evalForLoop(loop *ast.ForLoopStatement, env *object.Enviro
 			}
 		}
 
+		// Each iteration gets its own copy of the loop variable so closures keep the value they saw
+		if loop.HasClosure {
+			outterScope = outterScope.Clone()
+		}
+
 		// Execute iterator
 		if loop.Iter != nil {
 			iter := i.Eval(loop.Iter, outterScope)
@@ -81,6 +89,51 @@ func (i *Interpreter) evalForLoop(loop *ast.ForLoopStatement, env *object.Enviro
 	return object.NullConst
 }
 
+func (i *Interpreter) evalForInLoop(loop *ast.ForLoopStatement, env *object.Environment) object.Object {
+	collection := i.Eval(loop.Collection, env)
+	if isException(collection) {
+		return collection
+	}
+
+	iter, err := NewIterator(collection, func(fn object.Object, args []object.Object) object.Object {
+		return i.callAt(fn, args, env, loop.Token)
+	})
+	if err != nil {
+		return err
+	}
+
+	for {
+		key, value, ok, err := iter.Next()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+
+		// Each iteration has its own variables so closures keep the values they saw
+		bodyEnv := object.NewEnclosedEnv(env)
+		if loop.Key != nil {
+			bodyEnv.Create(loop.Key.Value, key)
+		}
+		bodyEnv.Create(loop.Value.Value, value)
+
+		body := i.Eval(loop.Body, bodyEnv)
+		if isException(body) || isPanic(body) {
+			return body
+		}
+
+		rt := body.Type()
+		if rt == object.ReturnObj {
+			return body
+		}
+		if rt == object.LoopControlObj && !body.(*object.LoopControl).Continue {
+			break
+		}
+	}
+	return object.NullConst
+}
+
 func (i *Interpreter) evalCompareExpression(node *ast.CompareExpression, env *object.Environment) object.Object {
 	left := i.Eval(node.Left, env)
 	if isException(left) {
@@ -89,7 +142,7 @@ func (i *Interpreter) evalCompareExpression(node *ast.CompareExpression, env *ob
 
 	lBool, valid := convertToBoolean(left)
 	if !valid {
-		return object.NewException("Left side of conditional must be truthy or falsey")
+		return object.NewTypeError("Left side of conditional must be truthy or falsey")
 	}
 
 	// Short circuit if possible
@@ -107,45 +160,97 @@ func (i *Interpreter) evalCompareExpression(node *ast.CompareExpression, env *ob
 
 	rBool, valid := convertToBoolean(right)
 	if !valid {
-		return object.NewException("Right side of condition must be truthy or falsey")
+		return object.NewTypeError("Right side of condition must be truthy or falsey")
 	}
 
 	return object.NativeBoolToBooleanObj(rBool)
 }
 
 func (i *Interpreter) evalTryCatch(t *ast.TryCatchExpression, env *object.Environment) object.Object {
+	result := i.evalTryCatchClauses(t, env)
+	if t.Finally == nil || isPanic(result) {
+		return result
+	}
+
+	// The finally block's value is discarded unless it leaves the try itself. An exception,
+	// return, break, or continue from the finally block replaces the result of the try.
+	finally := i.Eval(t.Finally, env)
+	if isException(finally) || isPanic(finally) {
+		return finally
+	}
+	switch finally.Type() {
+	case object.ReturnObj, object.LoopControlObj:
+		return finally
+	}
+	return result
+}
+
+func (i *Interpreter) evalTryCatchClauses(t *ast.TryCatchExpression, env *object.Environment) object.Object {
 	try := i.Eval(t.Try, env)
 	if try.Type() != object.ExceptionObj {
-		return unwrapReturnValue(try)
+		// A return from the try block leaves the enclosing function
+		return try
 	}
 
 	exception := try.(*object.Exception)
 	if !exception.Catchable {
 		return exception
 	}
-	exception.Caught = true
 
+	for _, clause := range t.Catches {
+		if clause.Class != nil {
+			matches, err := i.exceptionMatches(exception, clause.Class, env)
+			if err != nil {
+				return err
+			}
+			if !matches {
+				continue
+			}
+		}
+		exception.Caught = true
+		return i.evalCatchClause(clause, exception, env)
+	}
+
+	// No clause handled the exception so it continues up the stack
+	return exception
+}
+
+// exceptionMatches returns if exception is an instance of the class classExp evaluates to.
+func (i *Interpreter) exceptionMatches(exception *object.Exception, classExp ast.Expression, env *object.Environment) (bool, object.Object) {
+	class := i.Eval(classExp, env)
+	if isException(class) {
+		return false, class
+	}
+
+	classObj, ok := class.(*object.Class)
+	if !ok {
+		return false, object.NewTypeError("Catch type must be a class, got %s", class.Type())
+	}
+	return exception.Is(classObj), nil
+}
+
+func (i *Interpreter) evalCatchClause(clause *ast.CatchClause, exception *object.Exception, env *object.Environment) object.Object {
 	var orig object.Object
 	var origConst bool
 
-	if t.Symbol != nil {
+	if clause.Symbol != nil {
 		// Get original value is set
-		orig, _ = env.GetLocal(t.Symbol.Value)
-		origConst = env.IsConstLocal(t.Symbol.Value)
+		orig, _ = env.GetLocal(clause.Symbol.Value)
+		origConst = env.IsConstLocal(clause.Symbol.Value)
 
 		// Clobber value
-		env.SetForce(t.Symbol.Value, try, true)
+		env.SetForce(clause.Symbol.Value, exception, true)
 	}
 
-	catch := i.Eval(t.Catch, env)
+	catch := i.Eval(clause.Body, env)
 
-	if t.Symbol != nil {
+	if clause.Symbol != nil {
 		if orig == nil { // The exception ident never existed
-			env.UnsetLocal(t.Symbol.Value)
+			env.UnsetLocal(clause.Symbol.Value)
 		} else {
-			env.SetForce(t.Symbol.Value, orig, origConst)
+			env.SetForce(clause.Symbol.Value, orig, origConst)
 		}
 	}
 
-	return unwrapReturnValue(catch)
+	return catch
 }
@@ -1,7 +1,10 @@
-package eval
+package eval_test
 
 import (
+	"strings"
 	"testing"
+
+	"github.com/nitrogen-lang/nitrogen/src/object"
 )
 
 func TestEvalBooleanExpression(t *testing.T) {
@@ -31,6 +34,12 @@ func TestEvalBooleanExpression(t *testing.T) {
 		{"1 == 2 or 2 == 2", true},
 		{"1 == 2 and 2 == 2", false},
 		{"1 == 2 or 2 == 3 or 3 == 4", false},
+		{"1 < 1.5", true},
+		{"2.5 > 3", false},
+		{"2 == 2.0", true},
+		{"2 != 2.0", false},
+		{"3.0 >= 3", true},
+		{"-0.5 <= 0", true},
 	}
 
 	for _, tt := range tests {
@@ -91,3 +100,189 @@ func TestIfElseExpressions(t *testing.T) {
 		}
 	}
 }
+
+func TestLargeIfBody(t *testing.T) {
+	// The body compiles to more than 64 KiB of bytecode
+	input := `let x = 0; if true {` + strings.Repeat(" x = x + 1;", 12000) + ` } x`
+	testIntegerObject(t, testEval(input, t), 12000)
+}
+
+func TestTypedCatch(t *testing.T) {
+	classes := `
+class NotFound ^ Exception {
+    let path
+    func init(path) {
+        parent("not found: " + path)
+        this.path = path
+    }
+}
+class Denied ^ RuntimeError {}
+`
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`try { throw make NotFound("a") } catch e: NotFound { e.path }`, "a"},
+		{`try { throw make NotFound("a") } catch e: NotFound { e.message }`, "not found: a"},
+		{`try { throw make NotFound("a") } catch e: Denied { 1 } catch e: Exception { 2 }`, 2},
+		{`try { throw make Denied("x") } catch e: NotFound { 1 } catch e: RuntimeError { 2 } catch e { 3 }`, 2},
+		{`try { throw make Denied("x") } catch e: NotFound { 1 } catch e { e.message }`, "x"},
+		{`try { undefinedVar } catch e: NameError { e.message }`, "identifier not found: undefinedVar"},
+		{`try { 1 + "a" } catch e: NameError { 1 } catch e: TypeError { 2 }`, 2},
+		{`try { throw 5 } catch e: RuntimeError { 1 } catch e: Exception { e.payload }`, 5},
+		{`try { try { throw make Denied("x") } catch e: NotFound { 1 } } catch e: Denied { 2 }`, 2},
+		{`try { throw {"a": 1} } catch e { e.payload["a"] }`, 1},
+		{`try { throw make NotFound("a") } catch e { try { throw make NotFound("b") } catch e2: e.type { 1 } }`, 1},
+		{`try { throw make NotFound("a") } catch e { try { throw make Denied("b") } catch e2: e.type { 1 } catch e2 { 2 } }`, 2},
+		{`try { throw 1 } catch e: 5 { 1 }`, "Catch type must be a class, got INTEGER"},
+		{`try { throw make NotFound("a") } catch e: Denied { 1 }`, "not found: a"},
+		// Classes are matched by identity, not by name
+		{`func f() { class NotFound ^ Exception {}; throw make NotFound("b") }; try { f() } catch e: NotFound { 1 } catch e { 2 }`, 2},
+		{`class ValueError ^ Exception {}; func f() { class ValueError ^ Exception {}; return ValueError }; let Other = f(); try { throw make Other("x") } catch e: ValueError { 1 } catch e: Exception { 2 }`, 2},
+		{`func f() { class ValueError ^ Exception {}; return ValueError }; let V = f(); try { throw make V("x") } catch e: V { 1 }`, 1},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(classes+tt.input, t)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			if exc, ok := evaluated.(*object.Exception); ok {
+				if exc.Message != expected {
+					t.Errorf("wrong exception message. expected=%q, got=%q", expected, exc.Message)
+				}
+				continue
+			}
+			testStringObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestTryFinally(t *testing.T) {
+	// log records the order blocks ran in
+	setup := `
+let log = ""
+func note(s) { log = log + s }
+`
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		// Normal completion keeps the value of the try block
+		{`let r = try { note("t"); "v" } finally { note("f") }; log + r`, "tfv"},
+		// Caught exception
+		{`let r = try { throw "x" } catch e { note("c"); "v" } finally { note("f") }; log + r`, "cfv"},
+		// Uncaught exception is rethrown after finally
+		{`let r = try { try { throw "x" } finally { note("f") } } catch e { e.message }; log + r`, "fx"},
+		{`let r = try { try { throw "x" } catch e: NameError { 1 } finally { note("f") } } catch e { e.message }; log + r`, "fx"},
+		// Exception thrown by a catch block
+		{`let r = try { try { throw "x" } catch e { throw "y" } finally { note("f") } } catch e { e.message }; log + r`, "fy"},
+		// Return from the try and catch blocks
+		{`func f() { try { return "r" } finally { note("f") }; "n" }; let r = f(); log + r`, "fr"},
+		{`func f() { try { throw "x" } catch e { return "r" } finally { note("f") }; "n" }; let r = f(); log + r`, "fr"},
+		{`func f() { try { try { return "r" } finally { note("1") } } finally { note("2") } }; let r = f(); log + r`, "12r"},
+		// Break and continue
+		{`for (i = 0; i < 3; i + 1) {
+			try { if (i == 1) { break }; note("i") } finally { note("f") }
+		}
+		log`, "iff"},
+		{`for (i = 0; i < 2; i + 1) {
+			try { if (i == 0) { continue }; note("i") } finally { note("f") }
+		}
+		log`, "fif"},
+		// The finally block replaces the result when it leaves the try
+		{`let r = try { try { throw "x" } finally { throw "y" } } catch e { e.message }; r`, "y"},
+		{`func f() { try { return "a" } finally { return "b" } }; f()`, "b"},
+		{`for (i = 0; i < 2; i + 1) {
+			try { throw "x" } finally { note("f"); continue }
+		}
+		log`, "ff"},
+		// Declarations in a finally block work on every path
+		{`func f() { try { throw "x" } finally { let q = "q"; note(q) } }; try { f() } catch e {}; log`, "q"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(setup+tt.input, t)
+		testStringObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestForInLoops(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`let s = 0; for x in [1, 2, 3] { s += x }; s`, 6},
+		{`let s = 0; for i, x in [1, 2, 3] { s += i * x }; s`, 8},
+		{`let s = 0; for k, v in {1: 10, 2: 20} { s += k * v }; s`, 50},
+		{`let s = 0; for v in {"a": 1, "b": 2} { s += v }; s`, 3},
+		{`let s = ""; for c in "héllo" { s = c + s }; s`, "olléh"},
+		{`let n = 0; for i, c in "héllo" { n = i }; n`, 4},
+		{`let s = 0; for x in [] { s += 1 }; s`, 0},
+		// break and continue
+		{`let s = 0; for x in [1, 2, 3, 4] { if (x == 2) { continue }; if (x == 4) { break }; s += x }; s`, 4},
+		{`func f() { for x in [1, 2, 3] { if (x == 2) { return x } } }; f()`, 2},
+		{`let s = 0; for x in [1, 2] { for y in [10, 20] { if (y == 20) { break }; s += x * y } }; s`, 30},
+		// Each iteration has its own variables
+		{`let fns = []; for x in [1, 2] { fns = fns + [func() { x }] }; fns[0]() + fns[1]() * 10`, 21},
+		// Iterator protocol
+		{`
+class Counter {
+    let n = 0
+    let max
+    func init(max) { this.max = max }
+    func next() {
+        if (n >= max) { return nil }
+        n += 1
+        return n
+    }
+}
+let s = 0
+for i, x in make Counter(3) { s += x * 10 + i }
+s`, 63},
+		{`
+class Bag {
+    let items = [4, 5]
+    func iter() { return items }
+}
+let s = 0
+for x in make Bag() { s += x }
+s`, 9},
+		{`for x in 5 { x }`, "INTEGER is not iterable"},
+		{`class A {}; for x in make A() { x }`, "instance of A is not iterable"},
+		{`class A { func next() { throw "stop" } }; for x in make A() { x }`, "stop"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input, t)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			if exc, ok := evaluated.(*object.Exception); ok {
+				if exc.Message != expected {
+					t.Errorf("wrong exception message. expected=%q, got=%q", expected, exc.Message)
+				}
+				continue
+			}
+			testStringObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestForLoopClosures(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{`let fns = []; for (i = 0; i < 3; i + 1) { fns = fns + [func() { i }] }; fns[0]() + fns[1]() * 10 + fns[2]() * 100`, 210},
+		{`let fns = []; for (i = 0; i < 3; i += 1) { if i == 1 { continue }; fns = fns + [func() { i }] }; fns[0]() + fns[1]() * 10`, 20},
+		{`let s = 0; for (i = 0; i < 4; i + 1) { let f = func() { i }; s += f() }; s`, 6},
+		{`func f() { let fns = []; for (i = 0; i < 2; i + 1) { fns = fns + [func() { i }] }; return fns }; f()[0]() + f()[1]() * 10`, 10},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input, t), tt.expected)
+	}
+}
@@ -0,0 +1,50 @@
+package eval
+
+import (
+	"github.com/nitrogen-lang/nitrogen/src/ast"
+	"github.com/nitrogen-lang/nitrogen/src/object"
+)
+
+// Frame is a script or function being run. Frames are only kept while a
+// statement hook is set.
+type Frame struct {
+	// Function is the name of the function, "<main>" for a script.
+	Function string
+	// Statement is the statement being run in the frame.
+	Statement ast.Statement
+	// Env is the environment the statement is run in.
+	Env *object.Environment
+}
+
+// StatementHook is called before each statement of a script or block is run.
+// frames are the frames being run with the innermost last.
+type StatementHook func(stmt ast.Statement, frames []*Frame)
+
+// SetStatementHook sets the function called before each statement is run. A
+// nil hook removes it.
+func (i *Interpreter) SetStatementHook(hook StatementHook) {
+	i.statementHook = hook
+}
+
+// pushFrame adds a frame if a statement hook is set and reports if it did.
+func (i *Interpreter) pushFrame(function string, env *object.Environment) bool {
+	if i.statementHook == nil {
+		return false
+	}
+	i.frames = append(i.frames, &Frame{Function: function, Env: env})
+	return true
+}
+
+func (i *Interpreter) popFrame() {
+	i.frames = i.frames[:len(i.frames)-1]
+}
+
+func (i *Interpreter) beforeStatement(stmt ast.Statement, env *object.Environment) {
+	if len(i.frames) == 0 {
+		return
+	}
+	frame := i.frames[len(i.frames)-1]
+	frame.Statement = stmt
+	frame.Env = env
+	i.statementHook(stmt, i.frames)
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/nitrogen-lang/nitrogen/src/ast"
 	"github.com/nitrogen-lang/nitrogen/src/object"
+	"github.com/nitrogen-lang/nitrogen/src/resolver"
+	"github.com/nitrogen-lang/nitrogen/src/token"
 )
 
 type Interpreter struct {
@@ -15,6 +17,12 @@ type Interpreter struct {
 
 	scriptNameStack *stringStack
 	currentInstance *object.Instance
+	callStack       []callFrame
+	// callSite is the call of the builtin being executed
+	callSite token.Token
+
+	statementHook StatementHook
+	frames        []*Frame
 }
 
 func NewInterpreter() *Interpreter {
@@ -54,15 +62,26 @@ func (i *Interpreter) Eval(node ast.Node, env *object.Environment) object.Object
 			exc.Caught = false // Reset since exception is being rethrown.
 			return exc
 		}
-		return object.NewException(ret.Inspect())
+		exc := object.NewThrownException(ret)
+		i.recordTrace(exc, node.Token)
+		return exc
 
 	// Literals
 	case *ast.NullLiteral:
 		return object.NullConst
 	case *ast.IntegerLiteral:
+		if node.Big != nil {
+			return &object.BigInt{Value: node.Big}
+		}
 		return &object.Integer{Value: node.Value}
 	case *ast.StringLiteral:
 		return &object.String{Value: node.Value}
+	case *ast.InterpolatedString:
+		parts := i.evalExpressions(node.Parts, env)
+		if len(parts) == 1 && isException(parts[0]) {
+			return parts[0]
+		}
+		return interpolate(parts)
 	case *ast.FloatLiteral:
 		return &object.Float{Value: node.Value}
 	case *ast.Array:
@@ -84,7 +103,7 @@ func (i *Interpreter) Eval(node ast.Node, env *object.Environment) object.Object
 		if isException(right) {
 			return right
 		}
-		return i.evalPrefixExpression(node.Operator, right)
+		return evalPrefixExpression(node.Operator, right)
 	case *ast.InfixExpression:
 		right := i.Eval(node.Right, env)
 		if isException(right) {
@@ -96,7 +115,7 @@ func (i *Interpreter) Eval(node ast.Node, env *object.Environment) object.Object
 			return left
 		}
 
-		return i.evalInfixExpression(node.Operator, left, right)
+		return evalInfixExpression(node.Operator, left, right)
 	case *ast.IndexExpression:
 		left := i.Eval(node.Left, env)
 		if isException(left) {
@@ -107,7 +126,27 @@ func (i *Interpreter) Eval(node ast.Node, env *object.Environment) object.Object
 		if isException(index) {
 			return index
 		}
-		return i.evalIndexExpression(left, index)
+		return evalIndexExpression(left, index, i.currentInstance)
+	case *ast.SliceExpression:
+		left := i.Eval(node.Left, env)
+		if isException(left) {
+			return left
+		}
+
+		var low, high object.Object
+		if node.Low != nil {
+			low = i.Eval(node.Low, env)
+			if isException(low) {
+				return low
+			}
+		}
+		if node.High != nil {
+			high = i.Eval(node.High, env)
+			if isException(high) {
+				return high
+			}
+		}
+		return evalSliceExpression(left, low, high)
 
 	// Conditionals
 	case *ast.IfExpression:
@@ -135,7 +174,7 @@ func (i *Interpreter) Eval(node ast.Node, env *object.Environment) object.Object
 		function := i.Eval(node.Function, env)
 		if isException(function) {
 			if ident, ok := node.Function.(*ast.Identifier); ok {
-				return object.NewException("function not found: %s", ident.Value)
+				return object.NewNameError("function not found: %s", ident.Value)
 			}
 			return function
 		}
@@ -145,7 +184,10 @@ func (i *Interpreter) Eval(node ast.Node, env *object.Environment) object.Object
 			return args[0]
 		}
 
-		return i.applyFunction(function, args, env)
+		result := i.callAt(function, args, env, node.Token)
+		// Exceptions from builtins are raised at the call
+		i.recordTrace(result, node.Token)
+		return result
 
 	// Classes
 	case *ast.ClassLiteral:
@@ -153,11 +195,14 @@ func (i *Interpreter) Eval(node ast.Node, env *object.Environment) object.Object
 		if node.Parent != "" {
 			parent, ok := env.Get(node.Parent)
 			if !ok {
-				return object.NewException("Parent class %s not declared", node.Parent)
+				parent = getBuiltin(node.Parent)
+			}
+			if parent == nil {
+				return object.NewNameError("Parent class %s not declared", node.Parent)
 			}
 			parentClass, ok = parent.(*object.Class)
 			if !ok {
-				return object.NewException("Ident %s is not a class", node.Parent)
+				return object.NewTypeError("Ident %s is not a class", node.Parent)
 			}
 		}
 
@@ -194,19 +239,40 @@ func (i *Interpreter) GetStdin() io.Reader {
 	return i.Stdin
 }
 
+// Call calls fn with args and returns its result.
+func (i *Interpreter) Call(fn object.Object, args []object.Object) object.Object {
+	if _, ok := fn.(*object.Class); ok {
+		return object.NewTypeError("%s is not a function", fn.Type())
+	}
+	// Functions called by builtins are called from where the builtin was
+	return i.callAt(fn, args, object.NewEnvironment(), i.callSite)
+}
+
 func (i *Interpreter) evalProgram(p *ast.Program, env *object.Environment) object.Object {
 	var result object.Object = object.NullConst
 	i.scriptNameStack.push(p.Filename)
 	defer i.scriptNameStack.pop()
 	env.CreateConst("_FILE", &object.String{Value: p.Filename})
 
+	if exc := i.resolve(p, env); exc != nil {
+		return exc
+	}
+
+	if i.pushFrame("<main>", env) {
+		defer i.popFrame()
+	}
+
 	for _, statement := range p.Statements {
+		if i.statementHook != nil {
+			i.beforeStatement(statement, env)
+		}
 		result = i.Eval(statement, env)
 
 		switch result := result.(type) {
 		case *object.ReturnValue:
 			return result.Value
 		case *object.Exception:
+			i.recordTrace(result, ast.NodeToken(statement))
 			return result
 		}
 	}
@@ -214,15 +280,37 @@ func (i *Interpreter) evalProgram(p *ast.Program, env *object.Environment) objec
 	return result
 }
 
+// resolve statically checks the variable declarations of p.
+func (i *Interpreter) resolve(p *ast.Program, env *object.Environment) *object.Exception {
+	res := resolver.Resolve(p, func(name string) bool {
+		_, ok := env.Get(name)
+		return ok || getBuiltin(name) != nil
+	})
+	if len(res.Errors) > 0 {
+		err := res.Errors[0]
+		exc := object.NewNameError("%s", err.Message)
+		exc.Trace = i.stackTrace(err.Token)
+		return exc
+	}
+	return nil
+}
+
 func (i *Interpreter) evalBlockStatements(block *ast.BlockStatement, env *object.Environment) object.Object {
 	var result object.Object = object.NullConst
 
 	for _, statement := range block.Statements {
+		if i.statementHook != nil {
+			i.beforeStatement(statement, env)
+		}
 		result = i.Eval(statement, env)
 
 		if result != nil {
 			rt := result.Type()
-			if rt == object.ReturnObj || rt == object.ExceptionObj || rt == object.LoopControlObj {
+			if rt == object.ExceptionObj {
+				i.recordTrace(result, ast.NodeToken(statement))
+				return result
+			}
+			if rt == object.ReturnObj || rt == object.LoopControlObj {
 				return result
 			}
 		}
@@ -246,11 +334,20 @@ func (i *Interpreter) evalExpressions(exps []ast.Expression, env *object.Environ
 }
 
 func (i *Interpreter) evalIdent(node *ast.Identifier, env *object.Environment) object.Object {
+	if node.Resolved {
+		if scope := env.Ancestor(node.Depth); scope != nil {
+			if val, ok := scope.GetLocal(node.Value); ok {
+				return val
+			}
+		}
+	}
 	if val, ok := env.Get(node.Value); ok {
 		return val
 	}
 	if builtin := getBuiltin(node.Value); builtin != nil {
 		return builtin
 	}
-	return object.NewException("identifier not found: %s", node.Value)
+	exc := object.NewNameError("identifier not found: %s", node.Value)
+	i.recordTrace(exc, node.Token)
+	return exc
 }
@@ -1,9 +1,13 @@
-package eval
+package eval_test
 
 import (
 	"testing"
 
+	"github.com/nitrogen-lang/nitrogen/src/eval"
 	"github.com/nitrogen-lang/nitrogen/src/object"
+	"github.com/nitrogen-lang/nitrogen/src/vm"
+
+	_ "github.com/nitrogen-lang/nitrogen/src/builtins/collections"
 )
 
 func TestNullEval(t *testing.T) {
@@ -30,6 +34,14 @@ func TestEvalFloatExpression(t *testing.T) {
 		{"-50.2 + 100.0 + -50.1", -0.30000000000000426},
 		{"5.2 * 2.3 + 10.1", 22.06},
 		{"50.2 / 2.1 * 2.3 + 10.5", 65.48095238095237},
+		// Integers are promoted when mixed with floats
+		{"1 + 2.5", 3.5},
+		{"2.5 - 1", 1.5},
+		{"3 * 0.5", 1.5},
+		{"7 / 2.0", 3.5},
+		{"7 % 2.5", 2},
+		{"1e3 + 2.5e-1", 1000.25},
+		{"let a = 1; a += 0.5; a", 1.5},
 	}
 
 	for _, tt := range tests {
@@ -76,6 +88,41 @@ func TestEvalIntegerExpression(t *testing.T) {
 	}
 }
 
+func TestBigIntExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"9223372036854775807 + 1", "9223372036854775808"},
+		{"-9223372036854775807 - 2", "-9223372036854775809"},
+		{"9223372036854775807 * 4", "36893488147419103228"},
+		{"let a = -9223372036854775807 - 1; -a", "9223372036854775808"},
+		{"let a = -9223372036854775807 - 1; a / -1", "9223372036854775808"},
+		{"1 << 64", "18446744073709551616"},
+		{"(1 << 64) >> 60", "16"},
+		{"(9223372036854775807 + 1) - 1", "9223372036854775807"},
+		{"(9223372036854775807 * 3) / 3", "9223372036854775807"},
+		{"(9223372036854775807 * 3) % 10", "1"},
+		{"((1 << 70) | 1) & 3", "1"},
+		{"(1 << 64) > 9223372036854775807", "true"},
+		{"(1 << 64) == (1 << 64)", "true"},
+		{"(1 << 64) * 0.5", "9.223372036854776E+18"},
+		{"let s = 9223372036854775800; for (i = 0; i < 10; i + 1) { s += 1 }; s", "9223372036854775810"},
+		{"(1 << 64) / 0", "Integer division by zero"},
+		{"123456789012345678901234567890", "123456789012345678901234567890"},
+		{"123456789012345678901234567890 - 123456789012345678901234567889", "1"},
+		{"0x10000000000000000", "18446744073709551616"},
+		{"-9223372036854775808 == -9223372036854775807 - 1", "true"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input, t)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %s. expected=%s, got=%s", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
 func TestReturnStatements(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -104,30 +151,37 @@ func TestErrorHandling(t *testing.T) {
 	tests := []struct {
 		input           string
 		expectedMessage string
+		expectedClass   string
 	}{
 		{
 			"5 + true;",
 			"type mismatch: INTEGER + BOOLEAN",
+			"TypeError",
 		},
 		{
 			"5 + true; 5;",
 			"type mismatch: INTEGER + BOOLEAN",
+			"TypeError",
 		},
 		{
 			"-true",
 			"unknown operator: -BOOLEAN",
+			"TypeError",
 		},
 		{
 			"true + false;",
 			"unknown operator: BOOLEAN + BOOLEAN",
+			"TypeError",
 		},
 		{
 			"5; true + false; 5",
 			"unknown operator: BOOLEAN + BOOLEAN",
+			"TypeError",
 		},
 		{
 			"if (10 > 1) { true + false; }",
 			"unknown operator: BOOLEAN + BOOLEAN",
+			"TypeError",
 		},
 		{
 			`if (10 > 1) {
@@ -138,18 +192,72 @@ func TestErrorHandling(t *testing.T) {
             }
             `,
 			"unknown operator: BOOLEAN + BOOLEAN",
+			"TypeError",
 		},
 		{
 			"foobar",
 			"identifier not found: foobar",
+			"NameError",
 		},
 		{
 			`"Hello" - "World"`,
 			"unknown operator: STRING - STRING",
+			"TypeError",
 		},
 		{
 			`{"name": "Monkey"}[func(x) { x }];`,
 			"Invalid map key: FUNCTION",
+			"TypeError",
+		},
+		{
+			"let a = [1]; a[3] = 2",
+			"Index out of bounds: 3",
+			"IndexError",
+		},
+		{
+			"always a = 1; a = 2",
+			"Assignment to declared constant a",
+			"ConstantError",
+		},
+		{
+			"func f(a) { a }; f()",
+			"Not enough parameters to call function f",
+			"ArgumentError",
+		},
+		{
+			"5 / 0",
+			"Integer division by zero",
+			"ZeroDivisionError",
+		},
+		{
+			"5 % 0",
+			"Integer modulo by zero",
+			"ZeroDivisionError",
+		},
+		{
+			"1 << 2.0",
+			"unknown operator: INTEGER << FLOAT",
+			"TypeError",
+		},
+		{
+			`"a" * -1`,
+			"String repeat count must be non-negative",
+			"RuntimeError",
+		},
+		{
+			`1 in "abc"`,
+			"unknown operator: INTEGER in STRING",
+			"TypeError",
+		},
+		{
+			`[1] in {"a": 1}`,
+			"Invalid map key: ARRAY",
+			"TypeError",
+		},
+		{
+			`throw "plain"`,
+			"plain",
+			"Exception",
 		},
 	}
 
@@ -167,6 +275,10 @@ func TestErrorHandling(t *testing.T) {
 			t.Errorf("wrong error message. expected=%q, got=%q",
 				tt.expectedMessage, errObj.Message)
 		}
+
+		if errObj.Class == nil || errObj.Class.Name != tt.expectedClass {
+			t.Errorf("wrong exception class. expected=%s, got=%v", tt.expectedClass, errObj.Class)
+		}
 	}
 }
 
@@ -207,9 +319,133 @@ func TestStringEquality(t *testing.T) {
 		{`"foobar" == "foo bar"`, false},
 		{`"foobar" != "foo bar"`, true},
 		{`"foobar" != "foobar"`, false},
+		{`"abc" < "abd"`, true},
+		{`"abc" < "ab"`, false},
+		{`"b" > "abc"`, true},
+		{`"a" <= "a"`, true},
+		{`"a" >= "b"`, false},
 	}
 
 	for _, tt := range tests {
 		testBooleanObject(t, testEval(tt.input, t), tt.expected)
 	}
 }
+
+func TestStringRepetition(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"ab" * 3`, "ababab"},
+		{`2 * "xy"`, "xyxy"},
+		{`"ab" * 0`, ""},
+	}
+
+	for _, tt := range tests {
+		testStringObject(t, testEval(tt.input, t), tt.expected)
+	}
+}
+
+func TestInOperator(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{`"ell" in "hello"`, true},
+		{`"" in "hello"`, true},
+		{`"x" in "hello"`, false},
+		{`2 in [1, 2, 3]`, true},
+		{`4 in [1, 2, 3]`, false},
+		{`[1, 2] in [[1, 2], 3]`, true},
+		{`1 in [1.0]`, false},
+		{`"a" in {"a": 1}`, true},
+		{`1 in {"1": 1}`, false},
+		{`let x = 1; if x in [1] { true } else { false }`, true},
+		{`1 + 1 in [2]`, true},
+	}
+
+	for _, tt := range tests {
+		testBooleanObject(t, testEval(tt.input, t), tt.expected)
+	}
+}
+
+func TestStringInterpolation(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let name = "World"; "Hello ${name}!"`, "Hello World!"},
+		{`"${1 + 2} ${[1, "a"]} ${nil}"`, "3 [1, \"a\"] nil"},
+		{`let a = "x"; "${"${a}${a}"}"`, "xx"},
+		{`"${ {"k": 1}["k"] }"`, "1"},
+		{`"\${name}"`, "${name}"},
+		{`let fns = []; for (i = 0; i < 2; i + 1) { fns = fns + [func() { "i=${i}" }] }; fns[0]()`, "i=0"},
+	}
+
+	for _, tt := range tests {
+		testStringObject(t, testEval(tt.input, t), tt.expected)
+	}
+}
+
+func TestExceptionTrace(t *testing.T) {
+	type frame struct {
+		function string
+		line     int
+	}
+	tests := []struct {
+		input    string
+		expected []frame
+	}{
+		{`func thrower() {
+    throw "oops"
+}
+func mid() {
+    thrower()
+}
+mid()`, []frame{{"thrower", 2}, {"mid", 5}, {"<main>", 7}}},
+		// Exceptions raised by builtins are at the call
+		{`func check(a) {
+    let b = 1
+    let n = len(a, b)
+}
+check([])`, []frame{{"check", 3}, {"<main>", 5}}},
+		{`func f() {
+    if true {
+        return missing
+    }
+}
+let x = f()`, []frame{{"f", 3}, {"<main>", 6}}},
+		// Functions called by builtins are called from the builtin's call
+		{`func cb(x) {
+    return x + missing
+}
+func run() {
+    return map([1], cb)
+}
+run()`, []frame{{"cb", 2}, {"run", 5}, {"<main>", 7}}},
+	}
+
+	for _, tt := range tests {
+		backends := map[string]object.Interpreter{
+			"eval": eval.NewInterpreter(),
+			"vm":   vm.New(),
+		}
+		for name, interpreter := range backends {
+			result := interpreter.Eval(parseTestProgram(tt.input, t), object.NewEnvironment())
+			exc, ok := result.(*object.Exception)
+			if !ok {
+				t.Fatalf("%s: expected exception, got %s", name, showError(result))
+			}
+
+			if len(exc.Trace) != len(tt.expected) {
+				t.Fatalf("%s: wrong trace length. expected=%d, got=%d\n%s", name, len(tt.expected), len(exc.Trace), exc.StackTrace())
+			}
+
+			for i, frame := range tt.expected {
+				if exc.Trace[i].Function != frame.function || exc.Trace[i].Pos.Line != frame.line {
+					t.Errorf("%s: wrong trace frame %d. expected=%s:%d, got=%s", name, i, frame.function, frame.line, exc.Trace[i])
+				}
+			}
+		}
+	}
+}
@@ -8,11 +8,14 @@ func (i *Interpreter) applyFunction(fn object.Object, args []object.Object, env
 	switch fn := fn.(type) {
 	case *object.Function:
 		if len(args) < len(fn.Parameters) {
-			return object.NewException("Not enough parameters to call function %s", fn.Name)
+			return object.NewArgumentError("Not enough parameters to call function %s", fn.Name)
 		}
 		extendedEnv := i.extendFunctionEnv(fn, fn.Env, args)
 		oldInstance := i.currentInstance
 		i.currentInstance = fn.Instance
+		if i.pushFrame(FunctionName(fn), extendedEnv) {
+			defer i.popFrame()
+		}
 		evaled := i.Eval(fn.Body, extendedEnv)
 		i.currentInstance = oldInstance
 		return unwrapReturnValue(evaled)
@@ -37,27 +40,33 @@ func (i *Interpreter) applyFunction(fn object.Object, args []object.Object, env
 		initFn := init.(*object.Function)
 
 		if len(args) < len(initFn.Parameters) {
-			return object.NewException("Not enough parameters to call class initializer %s", fn.Name)
+			return object.NewArgumentError("Not enough parameters to call class initializer %s", fn.Name)
 		}
 		extendedEnv := i.extendFunctionEnv(initFn, initFn.Env, args)
 		extendedEnv.SetParent(env)
 		if fn.Parent != nil {
 			extendedEnv.CreateConst("parent", fn.Parent)
 		}
+		if i.pushFrame(fn.Name+".init", extendedEnv) {
+			defer i.popFrame()
+		}
 		evaled := i.Eval(initFn.Body, extendedEnv)
 		return unwrapReturnValue(evaled)
 	}
 
-	return object.NewException("%s is not a function", fn.Type())
+	return object.NewTypeError("%s is not a function", fn.Type())
 }
 
 func (i *Interpreter) applyFunctionDirect(fn object.Object, args []object.Object, env *object.Environment) object.Object {
 	switch fn := fn.(type) {
 	case *object.Function:
 		if len(args) < len(fn.Parameters) {
-			return object.NewException("Not enough parameters to call function %s", fn.Name)
+			return object.NewArgumentError("Not enough parameters to call function %s", fn.Name)
 		}
 		extendedEnv := i.extendFunctionEnv(fn, env, args)
+		if i.pushFrame(FunctionName(fn), extendedEnv) {
+			defer i.popFrame()
+		}
 		evaled := i.Eval(fn.Body, extendedEnv)
 		return unwrapReturnValue(evaled)
 	case *object.Builtin:
@@ -66,7 +75,7 @@ func (i *Interpreter) applyFunctionDirect(fn object.Object, args []object.Object
 		return fn.Fn(i, i.currentInstance, env, args...)
 	}
 
-	return object.NewException("%s is not a function", fn.Type())
+	return object.NewTypeError("%s is not a function", fn.Type())
 }
 
 func (i *Interpreter) extendFunctionEnv(fn *object.Function, outer *object.Environment, args []object.Object) *object.Environment {
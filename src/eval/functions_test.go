@@ -1,6 +1,7 @@
-package eval
+package eval_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/nitrogen-lang/nitrogen/src/object"
@@ -64,6 +65,11 @@ func TestExtraArgs(t *testing.T) {
 	testIntegerObject(t, testEval(input, t), 2)
 }
 
+func TestManyArgs(t *testing.T) {
+	input := `func count(a) { len(args) } count(0` + strings.Repeat(", 0", 299) + `)`
+	testIntegerObject(t, testEval(input, t), 299)
+}
+
 func TestExtraArgsError(t *testing.T) {
 	input := `func extra(a) { args = 5; } extra(1, 2)`
 	evaled := testEval(input, t)
@@ -76,3 +82,35 @@ func TestExtraArgsError(t *testing.T) {
 		t.Fatalf("Incorrect error message. Got '%s'", errObj.Message)
 	}
 }
+
+func TestInterpreterCall(t *testing.T) {
+	tests := []struct {
+		input    string
+		args     []object.Object
+		expected string
+	}{
+		{`func(x, y) { x * y }`, []object.Object{&object.Integer{Value: 3}, &object.Integer{Value: 4}}, "12"},
+		{`func(x) { args }`, []object.Object{object.NullConst, object.TrueConst}, "[true]"},
+		{`func(x) { throw "bad" }`, []object.Object{object.NullConst}, "bad"},
+		{`func(x) { x }`, nil, "Not enough parameters to call function "},
+		{`class A { let n = 2; func times(x) { x * n } }; let a = make A(); a.times`, []object.Object{&object.Integer{Value: 3}}, "6"},
+		{`let counter = 0; func() { counter += 1; counter }`, nil, "1"},
+		{`class A {}; A`, nil, "CLASS is not a function"},
+		{`5`, nil, "INTEGER is not a function"},
+	}
+
+	for _, tt := range tests {
+		fn := testInterpreter.Eval(parseTestProgram(tt.input, t), object.NewEnvironment())
+		evaled := testInterpreter.Call(fn, tt.args)
+
+		fn = testVM.Eval(parseTestProgram(tt.input, t), object.NewEnvironment())
+		vmResult := testVM.Call(fn, tt.args)
+
+		if !sameResult(evaled, vmResult) {
+			t.Errorf("backends disagree on %q: eval=%s, vm=%s", tt.input, showError(evaled), showError(vmResult))
+		}
+		if evaled.Inspect() != tt.expected {
+			t.Errorf("wrong result calling %q. expected=%q, got=%q", tt.input, tt.expected, evaled.Inspect())
+		}
+	}
+}
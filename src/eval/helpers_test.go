@@ -0,0 +1,180 @@
+package eval_test
+
+import (
+	"testing"
+
+	"github.com/nitrogen-lang/nitrogen/src/ast"
+	"github.com/nitrogen-lang/nitrogen/src/eval"
+	"github.com/nitrogen-lang/nitrogen/src/lexer"
+	"github.com/nitrogen-lang/nitrogen/src/object"
+	"github.com/nitrogen-lang/nitrogen/src/parser"
+	"github.com/nitrogen-lang/nitrogen/src/vm"
+)
+
+var (
+	testInterpreter = eval.NewInterpreter()
+	testVM          = vm.New()
+)
+
+// testEval evaluates input with the tree walking interpreter and the bytecode VM.
+// Both backends must produce the same result.
+func testEval(input string, t *testing.T) object.Object {
+	program := parseTestProgram(input, t)
+	evaled := testInterpreter.Eval(program, object.NewEnvironment())
+
+	program = parseTestProgram(input, t)
+	vmResult := testVM.Eval(program, object.NewEnvironment())
+
+	if !sameResult(evaled, vmResult) {
+		t.Errorf("backends disagree on %q: eval=%s, vm=%s", input, showError(evaled), showError(vmResult))
+	}
+	return evaled
+}
+
+func parseTestProgram(input string, t *testing.T) *ast.Program {
+	l := lexer.NewString(input)
+	p := parser.New(l, nil)
+	program := p.ParseProgram()
+	if len(p.Errors()) > 1 {
+		t.Fatal(p.Errors()[0])
+	}
+	return program
+}
+
+func sameResult(a, b object.Object) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Type() != b.Type() {
+		return false
+	}
+
+	switch a := a.(type) {
+	case *object.Exception:
+		bExc := b.(*object.Exception)
+		return a.Message == bExc.Message && exceptionClassName(a) == exceptionClassName(bExc)
+	case *object.Class:
+		return a.Name == b.(*object.Class).Name
+	case *object.Function:
+		return true
+	case *object.Array:
+		bArr := b.(*object.Array)
+		if len(a.Elements) != len(bArr.Elements) {
+			return false
+		}
+		for i := range a.Elements {
+			if !sameResult(a.Elements[i], bArr.Elements[i]) {
+				return false
+			}
+		}
+		return true
+	case *object.Hash:
+		bHash := b.(*object.Hash)
+		if a.Len() != bHash.Len() {
+			return false
+		}
+		bPairs := bHash.Pairs()
+		for i, pair := range a.Pairs() {
+			if !sameResult(pair.Key, bPairs[i].Key) || !sameResult(pair.Value, bPairs[i].Value) {
+				return false
+			}
+		}
+		return true
+	}
+	return a.Inspect() == b.Inspect()
+}
+
+func exceptionClassName(e *object.Exception) string {
+	if e.Class == nil {
+		return ""
+	}
+	return e.Class.Name
+}
+
+// Verification functions
+func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
+	result, ok := obj.(*object.Integer)
+	if !ok {
+		t.Errorf("object is not Integer. expected=%d, got=%T (%+v)",
+			expected,
+			obj,
+			showError(obj),
+		)
+		return false
+	}
+
+	if result.Value != expected {
+		t.Errorf("object has wrong value. got=%d, want=%d",
+			result.Value, expected)
+		return false
+	}
+	return true
+}
+
+func testFloatObject(t *testing.T, obj object.Object, expected float64) bool {
+	result, ok := obj.(*object.Float)
+	if !ok {
+		t.Errorf("object is not Float. expected=%g, got=%T (%+v)",
+			expected,
+			obj,
+			showError(obj),
+		)
+		return false
+	}
+
+	if result.Value != expected {
+		t.Errorf("object has wrong value. got=%g, want=%g",
+			result.Value, expected)
+		return false
+	}
+	return true
+}
+
+func testBooleanObject(t *testing.T, obj object.Object, expected bool) bool {
+	result, ok := obj.(*object.Boolean)
+	if !ok {
+		t.Errorf("object is not Boolean. expected=%t, got=%T (%+v)",
+			expected,
+			obj,
+			showError(obj),
+		)
+		return false
+	}
+
+	if result.Value != expected {
+		t.Errorf("object has wrong value. got=%t, want=%t",
+			result.Value, expected)
+		return false
+	}
+	return true
+}
+
+func testNullObject(t *testing.T, obj object.Object) bool {
+	if obj.Type() != object.NullObj {
+		t.Errorf("object is not Null. got=%T (%+v)", obj, showError(obj))
+		return false
+	}
+	return true
+}
+
+func testStringObject(t *testing.T, got object.Object, expected string) {
+	str, ok := got.(*object.String)
+	if !ok {
+		t.Fatalf("object is not String. got=%T (%+v)", got, showError(got))
+	}
+
+	if str.Value != expected {
+		t.Errorf("String has wrong value. got=%q", str.Value)
+	}
+}
+
+func showError(obj object.Object) string {
+	if obj == nil {
+		return "nil"
+	}
+
+	if obj, ok := obj.(*object.Exception); ok {
+		return obj.Inspect()
+	}
+	return obj.Type().String()
+}
@@ -2,33 +2,53 @@ package eval
 
 import (
 	"math"
+	"strings"
 
 	"github.com/nitrogen-lang/nitrogen/src/object"
 )
 
-func (i *Interpreter) evalInfixExpression(op string, left, right object.Object) object.Object {
+func evalInfixExpression(op string, left, right object.Object) object.Object {
 	switch {
-	case left.Type() != right.Type():
-		return object.NewException("type mismatch: %s %s %s", left.Type(), op, right.Type())
+	case op == "in":
+		return evalInExpression(left, right)
 	case object.ObjectsAre(object.IntergerObj, left, right):
-		return i.evalIntegerInfixExpression(op, left, right)
-	case object.ObjectsAre(object.FloatObj, left, right):
-		return i.evalFloatInfixExpression(op, left, right)
+		return evalIntegerInfixExpression(op, left, right)
+	case left.Type() == object.DecimalObj && (isInteger(right) || right.Type() == object.DecimalObj),
+		right.Type() == object.DecimalObj && isInteger(left):
+		return evalDecimalInfixExpression(op, left, right)
+	case isInteger(left) && isInteger(right):
+		return evalBigIntInfixExpression(op, left, right)
+	case isNumber(left) && isNumber(right):
+		// Integers are promoted to floats when mixed with a float
+		return evalFloatInfixExpression(op, left, right)
+	case op == "*" && left.Type() == object.StringObj && right.Type() == object.IntergerObj:
+		return repeatString(left.(*object.String).Value, right.(*object.Integer).Value)
+	case op == "*" && left.Type() == object.IntergerObj && right.Type() == object.StringObj:
+		return repeatString(right.(*object.String).Value, left.(*object.Integer).Value)
+	case isTimeOperand(left) || isTimeOperand(right):
+		return evalTimeInfixExpression(op, left, right)
+	case left.Type() != right.Type():
+		return object.NewTypeError("type mismatch: %s %s %s", left.Type(), op, right.Type())
 	case object.ObjectsAre(object.StringObj, left, right):
-		return i.evalStringInfixExpression(op, left, right)
+		return evalStringInfixExpression(op, left, right)
 	case object.ObjectsAre(object.ArrayObj, left, right):
-		return i.evalArrayInfixExpression(op, left, right)
+		return evalArrayInfixExpression(op, left, right)
 	case object.ObjectsAre(object.BooleanObj, left, right):
-		return i.evalBoolInfixExpression(op, left, right)
+		return evalBoolInfixExpression(op, left, right)
 	}
 
-	return object.NewException("unknown operator: %s %s %s", left.Type(), op, right.Type())
+	return object.NewTypeError("unknown operator: %s %s %s", left.Type(), op, right.Type())
 }
 
-func (i *Interpreter) evalIntegerInfixExpression(op string, left, right object.Object) object.Object {
+func evalIntegerInfixExpression(op string, left, right object.Object) object.Object {
 	leftVal := left.(*object.Integer).Value
 	rightVal := right.(*object.Integer).Value
 
+	// Results that don't fit in an int64 are promoted to a BIGINT
+	if integerOverflows(op, leftVal, rightVal) {
+		return evalBigIntInfixExpression(op, left, right)
+	}
+
 	switch op {
 	case "+":
 		return &object.Integer{Value: leftVal + rightVal}
@@ -37,8 +57,14 @@ func (i *Interpreter) evalIntegerInfixExpression(op string, left, right object.O
 	case "*":
 		return &object.Integer{Value: leftVal * rightVal}
 	case "/":
+		if rightVal == 0 {
+			return object.NewZeroDivisionError("Integer division by zero")
+		}
 		return &object.Integer{Value: leftVal / rightVal}
 	case "%":
+		if rightVal == 0 {
+			return object.NewZeroDivisionError("Integer modulo by zero")
+		}
 		return &object.Integer{Value: leftVal % rightVal}
 	case "<":
 		return object.NativeBoolToBooleanObj(leftVal < rightVal)
@@ -72,12 +98,12 @@ func (i *Interpreter) evalIntegerInfixExpression(op string, left, right object.O
 		return &object.Integer{Value: leftVal ^ rightVal}
 	}
 
-	return object.NewException("unknown operator: %s %s %s", left.Type(), op, right.Type())
+	return object.NewTypeError("unknown operator: %s %s %s", left.Type(), op, right.Type())
 }
 
-func (i *Interpreter) evalFloatInfixExpression(op string, left, right object.Object) object.Object {
-	leftVal := left.(*object.Float).Value
-	rightVal := right.(*object.Float).Value
+func evalFloatInfixExpression(op string, left, right object.Object) object.Object {
+	leftVal := floatValue(left)
+	rightVal := floatValue(right)
 
 	switch op {
 	case "+":
@@ -104,10 +130,10 @@ func (i *Interpreter) evalFloatInfixExpression(op string, left, right object.Obj
 		return object.NativeBoolToBooleanObj(leftVal >= rightVal)
 	}
 
-	return object.NewException("unknown operator: %s %s %s", left.Type(), op, right.Type())
+	return object.NewTypeError("unknown operator: %s %s %s", left.Type(), op, right.Type())
 }
 
-func (i *Interpreter) evalStringInfixExpression(op string, left, right object.Object) object.Object {
+func evalStringInfixExpression(op string, left, right object.Object) object.Object {
 	leftVal := left.(*object.String).Value
 	rightVal := right.(*object.String).Value
 
@@ -118,12 +144,93 @@ func (i *Interpreter) evalStringInfixExpression(op string, left, right object.Ob
 		return object.NativeBoolToBooleanObj(leftVal == rightVal)
 	case "!=":
 		return object.NativeBoolToBooleanObj(leftVal != rightVal)
+	case "<", ">", "<=", ">=":
+		return compareResult(op, strings.Compare(leftVal, rightVal))
+	}
+
+	return object.NewTypeError("unknown operator: %s %s %s", left.Type(), op, right.Type())
+}
+
+func repeatString(str string, count int64) object.Object {
+	if count < 0 {
+		return object.NewException("String repeat count must be non-negative")
+	}
+	if count > 0 && int64(len(str)) > math.MaxInt32/count {
+		return object.NewException("String repeat count too large")
+	}
+	return &object.String{Value: strings.Repeat(str, int(count))}
+}
+
+// evalInExpression checks if left is a substring of a string, an element of an array, or a key of a map.
+func evalInExpression(left, right object.Object) object.Object {
+	switch right := right.(type) {
+	case *object.String:
+		if str, ok := left.(*object.String); ok {
+			return object.NativeBoolToBooleanObj(strings.Contains(right.Value, str.Value))
+		}
+	case *object.Array:
+		for _, e := range right.Elements {
+			if objectsEqual(left, e) {
+				return object.TrueConst
+			}
+		}
+		return object.FalseConst
+	case *object.Hash:
+		key, ok := left.(object.Hashable)
+		if !ok {
+			return object.NewTypeError("Invalid map key: %s", left.Type())
+		}
+		_, exists := right.Get(key)
+		return object.NativeBoolToBooleanObj(exists)
 	}
 
-	return object.NewException("unknown operator: %s %s %s", left.Type(), op, right.Type())
+	return object.NewTypeError("unknown operator: %s in %s", left.Type(), right.Type())
+}
+
+// objectsEqual returns if a and b have the same type and value. Arrays and maps
+// are compared by their contents, other objects by identity.
+func objectsEqual(a, b object.Object) bool {
+	if a.Type() != b.Type() {
+		return false
+	}
+
+	switch a := a.(type) {
+	case object.Hashable:
+		return a.HashKey() == b.(object.Hashable).HashKey()
+	case *object.Float:
+		return a.Value == b.(*object.Float).Value
+	case *object.Boolean:
+		return a.Value == b.(*object.Boolean).Value
+	case *object.Null:
+		return true
+	case *object.Array:
+		bArr := b.(*object.Array)
+		if len(a.Elements) != len(bArr.Elements) {
+			return false
+		}
+		for i := range a.Elements {
+			if !objectsEqual(a.Elements[i], bArr.Elements[i]) {
+				return false
+			}
+		}
+		return true
+	case *object.Hash:
+		bHash := b.(*object.Hash)
+		if a.Len() != bHash.Len() {
+			return false
+		}
+		for _, pair := range a.Pairs() {
+			other, ok := bHash.Get(pair.Key.(object.Hashable))
+			if !ok || !objectsEqual(pair.Value, other) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
 }
 
-func (i *Interpreter) evalArrayInfixExpression(op string, left, right object.Object) object.Object {
+func evalArrayInfixExpression(op string, left, right object.Object) object.Object {
 	leftVal := left.(*object.Array)
 	rightVal := right.(*object.Array)
 
@@ -137,10 +244,10 @@ func (i *Interpreter) evalArrayInfixExpression(op string, left, right object.Obj
 		return &object.Array{Elements: newElements}
 	}
 
-	return object.NewException("unknown operator: %s %s %s", left.Type(), op, right.Type())
+	return object.NewTypeError("unknown operator: %s %s %s", left.Type(), op, right.Type())
 }
 
-func (i *Interpreter) evalBoolInfixExpression(op string, left, right object.Object) object.Object {
+func evalBoolInfixExpression(op string, left, right object.Object) object.Object {
 	leftVal := left.(*object.Boolean).Value
 	rightVal := right.(*object.Boolean).Value
 
@@ -155,5 +262,5 @@ func (i *Interpreter) evalBoolInfixExpression(op string, left, right object.Obje
 		return object.NativeBoolToBooleanObj(leftVal && rightVal)
 	}
 
-	return object.NewException("unknown operator: %s %s %s", left.Type(), op, right.Type())
+	return object.NewTypeError("unknown operator: %s %s %s", left.Type(), op, right.Type())
 }
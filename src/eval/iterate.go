@@ -0,0 +1,119 @@
+package eval
+
+import (
+	"unicode/utf8"
+
+	"github.com/nitrogen-lang/nitrogen/src/object"
+)
+
+// CallFunc calls a function object with arguments and returns its result.
+type CallFunc func(fn object.Object, args []object.Object) object.Object
+
+// Iterator produces the keys and values of a for-in loop.
+type Iterator interface {
+	// Next returns the next key and value. ok is false when there are no more items.
+	// err is an exception raised while getting the item.
+	Next() (key, value object.Object, ok bool, err object.Object)
+}
+
+// NewIterator returns an iterator over obj. Arrays are iterated by index and element,
+// maps by key and value, and strings by the index and value of each character. Instances
+// with an iter() method are iterated over the value it returns. Instances with a next()
+// method are iterators, next() is called for each value until it returns nil.
+// call is used to call the methods of instances.
+func NewIterator(obj object.Object, call CallFunc) (Iterator, object.Object) {
+	switch obj := obj.(type) {
+	case *object.Array:
+		return &arrayIterator{elements: obj.Elements}, nil
+	case *object.Hash:
+		return &hashIterator{pairs: obj.Pairs()}, nil
+	case *object.String:
+		return &stringIterator{str: obj.Value}, nil
+	case *object.Instance:
+		return newInstanceIterator(obj, call)
+	}
+	return nil, object.NewTypeError("%s is not iterable", obj.Type())
+}
+
+type arrayIterator struct {
+	elements []object.Object
+	i        int
+}
+
+func (it *arrayIterator) Next() (object.Object, object.Object, bool, object.Object) {
+	if it.i >= len(it.elements) {
+		return nil, nil, false, nil
+	}
+	it.i++
+	return &object.Integer{Value: int64(it.i - 1)}, it.elements[it.i-1], true, nil
+}
+
+type hashIterator struct {
+	pairs []object.HashPair
+	i     int
+}
+
+func (it *hashIterator) Next() (object.Object, object.Object, bool, object.Object) {
+	if it.i >= len(it.pairs) {
+		return nil, nil, false, nil
+	}
+	it.i++
+	pair := it.pairs[it.i-1]
+	return pair.Key, pair.Value, true, nil
+}
+
+// stringIterator produces the code points of a string like indexing it does.
+type stringIterator struct {
+	str    string
+	offset int
+	i      int
+}
+
+func (it *stringIterator) Next() (object.Object, object.Object, bool, object.Object) {
+	if it.offset >= len(it.str) {
+		return nil, nil, false, nil
+	}
+	_, size := utf8.DecodeRuneInString(it.str[it.offset:])
+	char := it.str[it.offset : it.offset+size]
+	it.offset += size
+	it.i++
+	return &object.Integer{Value: int64(it.i - 1)}, &object.String{Value: char}, true, nil
+}
+
+type instanceIterator struct {
+	next object.Object
+	call CallFunc
+	i    int
+}
+
+func newInstanceIterator(instance *object.Instance, call CallFunc) (Iterator, object.Object) {
+	if instance.GetMethod("iter") != nil {
+		iter := call(evalInstanceLookupExpression(instance, &object.String{Value: "iter"}), nil)
+		if isException(iter) || isPanic(iter) {
+			return nil, iter
+		}
+		if iter != instance {
+			return NewIterator(iter, call)
+		}
+	}
+
+	if instance.GetMethod("next") == nil {
+		return nil, object.NewTypeError("instance of %s is not iterable", instance.Class.Name)
+	}
+	return &instanceIterator{
+		next: evalInstanceLookupExpression(instance, &object.String{Value: "next"}),
+		call: call,
+	}, nil
+}
+
+func (it *instanceIterator) Next() (object.Object, object.Object, bool, object.Object) {
+	val := it.call(it.next, nil)
+	if isException(val) || isPanic(val) {
+		return nil, nil, false, val
+	}
+	if val == object.NullConst {
+		return nil, nil, false, nil
+	}
+	it.i++
+	return &object.Integer{Value: int64(it.i - 1)}, val, true, nil
+}
@@ -0,0 +1,200 @@
+package eval
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/nitrogen-lang/nitrogen/src/object"
+)
+
+// Numbers are promoted in the order INTEGER, BIGINT, DECIMAL. Floats are
+// inexact and only mix with integers.
+
+func isNumber(obj object.Object) bool {
+	return object.ObjectIs(obj, object.IntergerObj, object.BigIntObj, object.FloatObj)
+}
+
+func isInteger(obj object.Object) bool {
+	return object.ObjectIs(obj, object.IntergerObj, object.BigIntObj)
+}
+
+// floatValue returns the value of an integer or float as a float.
+func floatValue(obj object.Object) float64 {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return float64(obj.Value)
+	case *object.BigInt:
+		f, _ := new(big.Float).SetInt(obj.Value).Float64()
+		return f
+	}
+	return obj.(*object.Float).Value
+}
+
+// bigValue returns the value of an INTEGER or BIGINT as a big.Int.
+func bigValue(obj object.Object) *big.Int {
+	if i, ok := obj.(*object.Integer); ok {
+		return big.NewInt(i.Value)
+	}
+	return obj.(*object.BigInt).Value
+}
+
+// integerOverflows reports if op applied to left and right doesn't fit in an int64.
+func integerOverflows(op string, left, right int64) bool {
+	switch op {
+	case "+":
+		sum := left + right
+		return (left > 0 && right > 0 && sum < 0) || (left < 0 && right < 0 && sum >= 0)
+	case "-":
+		diff := left - right
+		return (left >= 0 && right < 0 && diff < 0) || (left < 0 && right > 0 && diff >= 0)
+	case "*":
+		if left == 0 || right == 0 {
+			return false
+		}
+		if (left == -1 && right == math.MinInt64) || (right == -1 && left == math.MinInt64) {
+			return true
+		}
+		return (left*right)/right != left
+	case "/":
+		return left == math.MinInt64 && right == -1
+	case "<<":
+		return right >= 63 || (left<<uint64(right))>>uint64(right) != left
+	}
+	return false
+}
+
+func evalBigIntInfixExpression(op string, left, right object.Object) object.Object {
+	leftVal := bigValue(left)
+	rightVal := bigValue(right)
+
+	switch op {
+	case "+":
+		return object.NewInt(new(big.Int).Add(leftVal, rightVal))
+	case "-":
+		return object.NewInt(new(big.Int).Sub(leftVal, rightVal))
+	case "*":
+		return object.NewInt(new(big.Int).Mul(leftVal, rightVal))
+	case "/":
+		if rightVal.Sign() == 0 {
+			return object.NewZeroDivisionError("Integer division by zero")
+		}
+		return object.NewInt(new(big.Int).Quo(leftVal, rightVal))
+	case "%":
+		if rightVal.Sign() == 0 {
+			return object.NewZeroDivisionError("Integer modulo by zero")
+		}
+		return object.NewInt(new(big.Int).Rem(leftVal, rightVal))
+	case "<", ">", "==", "!=", "<=", ">=":
+		return compareResult(op, leftVal.Cmp(rightVal))
+	case "<<", ">>":
+		if rightVal.Sign() < 0 {
+			return object.NewException("Shift value must be non-negative")
+		}
+		if !rightVal.IsInt64() || rightVal.Int64() > math.MaxInt32 {
+			return object.NewException("Shift value too large")
+		}
+		if op == "<<" {
+			return object.NewInt(new(big.Int).Lsh(leftVal, uint(rightVal.Int64())))
+		}
+		return object.NewInt(new(big.Int).Rsh(leftVal, uint(rightVal.Int64())))
+	case "&":
+		return object.NewInt(new(big.Int).And(leftVal, rightVal))
+	case "&^":
+		return object.NewInt(new(big.Int).AndNot(leftVal, rightVal))
+	case "|":
+		return object.NewInt(new(big.Int).Or(leftVal, rightVal))
+	case "^":
+		return object.NewInt(new(big.Int).Xor(leftVal, rightVal))
+	}
+
+	return object.NewTypeError("unknown operator: %s %s %s", left.Type(), op, right.Type())
+}
+
+// decimalValue returns an INTEGER, BIGINT, or DECIMAL as a decimal.
+func decimalValue(obj object.Object, rounding object.RoundingMode) *object.Decimal {
+	if d, ok := obj.(*object.Decimal); ok {
+		return d
+	}
+	return object.NewDecimalFromInt(bigValue(obj), rounding)
+}
+
+// evalDecimalInfixExpression applies op to two decimals, or a decimal and an integer.
+// The result has the larger scale of the two and the rounding mode of the left decimal.
+func evalDecimalInfixExpression(op string, left, right object.Object) object.Object {
+	rounding := object.RoundHalfEven
+	if d, ok := left.(*object.Decimal); ok {
+		rounding = d.Rounding
+	} else {
+		rounding = right.(*object.Decimal).Rounding
+	}
+
+	leftDec := decimalValue(left, rounding)
+	rightDec := decimalValue(right, rounding)
+	scale := leftDec.Scale
+	if rightDec.Scale > scale {
+		scale = rightDec.Scale
+	}
+	leftVal := leftDec.Rescale(scale).Unscaled
+	rightVal := rightDec.Rescale(scale).Unscaled
+
+	result := &object.Decimal{Scale: scale, Rounding: rounding}
+	switch op {
+	case "+":
+		result.Unscaled = new(big.Int).Add(leftVal, rightVal)
+	case "-":
+		result.Unscaled = new(big.Int).Sub(leftVal, rightVal)
+	case "*":
+		product := new(big.Int).Mul(leftDec.Unscaled, rightDec.Unscaled)
+		result.Unscaled = object.RoundQuo(product, pow10(leftDec.Scale+rightDec.Scale-scale), rounding)
+	case "/":
+		if rightVal.Sign() == 0 {
+			return object.NewZeroDivisionError("Decimal division by zero")
+		}
+		result.Unscaled = object.RoundQuo(new(big.Int).Mul(leftVal, pow10(scale)), rightVal, rounding)
+	case "%":
+		if rightVal.Sign() == 0 {
+			return object.NewZeroDivisionError("Decimal modulo by zero")
+		}
+		result.Unscaled = new(big.Int).Rem(leftVal, rightVal)
+	case "<", ">", "==", "!=", "<=", ">=":
+		return compareResult(op, leftVal.Cmp(rightVal))
+	default:
+		return object.NewTypeError("unknown operator: %s %s %s", left.Type(), op, right.Type())
+	}
+	return result
+}
+
+func compareResult(op string, cmp int) object.Object {
+	switch op {
+	case "<":
+		return object.NativeBoolToBooleanObj(cmp < 0)
+	case ">":
+		return object.NativeBoolToBooleanObj(cmp > 0)
+	case "==":
+		return object.NativeBoolToBooleanObj(cmp == 0)
+	case "!=":
+		return object.NativeBoolToBooleanObj(cmp != 0)
+	case "<=":
+		return object.NativeBoolToBooleanObj(cmp <= 0)
+	}
+	return object.NativeBoolToBooleanObj(cmp >= 0)
+}
+
+// compareNumbers compares two numbers. ok is false if they can't be compared.
+func compareNumbers(left, right object.Object) (cmp int, ok bool) {
+	result := evalInfixExpression("<", left, right)
+	if result == object.TrueConst {
+		return -1, true
+	}
+	if result != object.FalseConst {
+		return 0, false
+	}
+	if evalInfixExpression("==", left, right) == object.TrueConst {
+		return 0, true
+	}
+	return 1, true
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
@@ -0,0 +1,80 @@
+package eval
+
+import (
+	"github.com/nitrogen-lang/nitrogen/src/object"
+)
+
+// The functions in this file expose the runtime semantics of the language's
+// operators so alternative execution backends, such as the bytecode VM, behave
+// exactly like the tree walking interpreter.
+
+// InfixOperation applies the binary operator op to left and right.
+func InfixOperation(op string, left, right object.Object) object.Object {
+	return evalInfixExpression(op, left, right)
+}
+
+// CompareNumbers returns -1, 0, or 1 if left is less than, equal to, or greater than right.
+// ok is false if either isn't a number or they can't be compared.
+func CompareNumbers(left, right object.Object) (cmp int, ok bool) {
+	if !isNumber(left) && left.Type() != object.DecimalObj || !isNumber(right) && right.Type() != object.DecimalObj {
+		return 0, false
+	}
+	return compareNumbers(left, right)
+}
+
+// Interpolate concatenates the evaluated parts of an interpolated string.
+func Interpolate(parts []object.Object) object.Object {
+	return interpolate(parts)
+}
+
+// ObjectsEqual compares a and b the way the in operator compares array elements.
+func ObjectsEqual(a, b object.Object) bool {
+	return objectsEqual(a, b)
+}
+
+// PrefixOperation applies the unary operator op to right.
+func PrefixOperation(op string, right object.Object) object.Object {
+	return evalPrefixExpression(op, right)
+}
+
+// IndexOperation evaluates left[index]. current is the instance whose method is
+// executing, it's used to resolve parent class lookups.
+func IndexOperation(left, index object.Object, current *object.Instance) object.Object {
+	return evalIndexExpression(left, index, current)
+}
+
+// SliceOperation evaluates left[low:high]. low and high are nil or null if they were omitted.
+func SliceOperation(left, low, high object.Object) object.Object {
+	return evalSliceExpression(left, low, high)
+}
+
+// SliceBounds returns the indexes of the slice [start:end] of a sequence of length elements.
+// Negative values count from the end and values outside the sequence are clamped to it.
+func SliceBounds(length int, start, end int64) (int, int) {
+	return sliceBounds(length, start, end)
+}
+
+// AssignIndex evaluates the assignment indexed[index] = value.
+func AssignIndex(indexed, index, value object.Object) object.Object {
+	return assignIndex(indexed, index, value)
+}
+
+// IsTruthy returns if obj is considered true in a conditional.
+func IsTruthy(obj object.Object) bool {
+	return isTruthy(obj)
+}
+
+// ConvertToBoolean returns obj expressed as a boolean and if obj is a valid bool-like object.
+func ConvertToBoolean(obj object.Object) (bool, bool) {
+	return convertToBoolean(obj)
+}
+
+// IsException returns if obj is a catchable exception that hasn't been caught.
+func IsException(obj object.Object) bool {
+	return isException(obj)
+}
+
+// IsPanic returns if obj is an uncatchable exception.
+func IsPanic(obj object.Object) bool {
+	return isPanic(obj)
+}
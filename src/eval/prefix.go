@@ -1,21 +1,24 @@
 package eval
 
 import (
+	"math"
+	"math/big"
+
 	"github.com/nitrogen-lang/nitrogen/src/object"
 )
 
-func (i *Interpreter) evalPrefixExpression(op string, right object.Object) object.Object {
+func evalPrefixExpression(op string, right object.Object) object.Object {
 	switch op {
 	case "!":
-		return i.evalBangOpExpression(right)
+		return evalBangOpExpression(right)
 	case "-":
-		return i.evalMinusPreOpExpression(right)
+		return evalMinusPreOpExpression(right)
 	}
 
-	return object.NewException("unknown operator: %s%s", op, right.Type())
+	return object.NewTypeError("unknown operator: %s%s", op, right.Type())
 }
 
-func (i *Interpreter) evalBangOpExpression(right object.Object) object.Object {
+func evalBangOpExpression(right object.Object) object.Object {
 	if right == object.FalseConst || right == object.NullConst {
 		return object.TrueConst
 	}
@@ -27,15 +30,30 @@ func (i *Interpreter) evalBangOpExpression(right object.Object) object.Object {
 	return object.FalseConst
 }
 
-func (i *Interpreter) evalMinusPreOpExpression(right object.Object) object.Object {
+func evalMinusPreOpExpression(right object.Object) object.Object {
 	switch right.Type() {
 	case object.IntergerObj:
 		value := right.(*object.Integer).Value
+		if value == math.MinInt64 {
+			return object.NewInt(new(big.Int).Neg(big.NewInt(value)))
+		}
 		return &object.Integer{Value: -value}
+	case object.BigIntObj:
+		return object.NewInt(new(big.Int).Neg(right.(*object.BigInt).Value))
+	case object.DecimalObj:
+		d := right.Dup().(*object.Decimal)
+		d.Unscaled.Neg(d.Unscaled)
+		return d
 	case object.FloatObj:
 		value := right.(*object.Float).Value
 		return &object.Float{Value: -value}
+	case object.DurationObj:
+		value := right.(*object.Duration).Value
+		if value == math.MinInt64 {
+			return object.NewException("Duration overflow")
+		}
+		return &object.Duration{Value: -value}
 	}
 
-	return object.NewException("unknown operator: -%s", right.Type())
+	return object.NewTypeError("unknown operator: -%s", right.Type())
 }
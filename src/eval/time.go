@@ -0,0 +1,130 @@
+package eval
+
+import (
+	"math"
+	"time"
+
+	"github.com/nitrogen-lang/nitrogen/src/object"
+)
+
+// Times can be compared and subtracted to get a duration. Durations can be
+// added to times and to each other, and scaled by numbers.
+
+func isTimeOperand(obj object.Object) bool {
+	return object.ObjectIs(obj, object.TimeObj, object.DurationObj)
+}
+
+func evalTimeInfixExpression(op string, left, right object.Object) object.Object {
+	switch left := left.(type) {
+	case *object.Time:
+		switch right := right.(type) {
+		case *object.Time:
+			switch op {
+			case "-":
+				return &object.Duration{Value: left.Value.Sub(right.Value)}
+			case "<", ">", "==", "!=", "<=", ">=":
+				return compareResult(op, left.Value.Compare(right.Value))
+			}
+		case *object.Duration:
+			switch op {
+			case "+":
+				return &object.Time{Value: left.Value.Add(right.Value)}
+			case "-":
+				return &object.Time{Value: left.Value.Add(-right.Value)}
+			}
+		}
+	case *object.Duration:
+		switch right := right.(type) {
+		case *object.Duration:
+			return evalDurationInfixExpression(op, left.Value, right.Value)
+		case *object.Time:
+			if op == "+" {
+				return &object.Time{Value: right.Value.Add(left.Value)}
+			}
+		case *object.Integer:
+			if op == "*" || op == "/" {
+				return scaleDuration(op, left.Value, right.Value)
+			}
+		case *object.Float:
+			switch op {
+			case "*":
+				return durationFromFloat(float64(left.Value) * right.Value)
+			case "/":
+				if right.Value == 0 {
+					return object.NewZeroDivisionError("Duration division by zero")
+				}
+				return durationFromFloat(float64(left.Value) / right.Value)
+			}
+		}
+	case *object.Integer:
+		if d, ok := right.(*object.Duration); ok && op == "*" {
+			return scaleDuration(op, d.Value, left.Value)
+		}
+	case *object.Float:
+		if d, ok := right.(*object.Duration); ok && op == "*" {
+			return durationFromFloat(float64(d.Value) * left.Value)
+		}
+	}
+
+	return object.NewTypeError("unknown operator: %s %s %s", left.Type(), op, right.Type())
+}
+
+func evalDurationInfixExpression(op string, left, right time.Duration) object.Object {
+	switch op {
+	case "+", "-":
+		if integerOverflows(op, int64(left), int64(right)) {
+			return object.NewException("Duration overflow")
+		}
+		if op == "+" {
+			return &object.Duration{Value: left + right}
+		}
+		return &object.Duration{Value: left - right}
+	case "/":
+		// Dividing durations gives their ratio, e.g. the number of hours in a duration
+		if right == 0 {
+			return object.NewZeroDivisionError("Duration division by zero")
+		}
+		return &object.Float{Value: float64(left) / float64(right)}
+	case "%":
+		if right == 0 {
+			return object.NewZeroDivisionError("Duration modulo by zero")
+		}
+		return &object.Duration{Value: left % right}
+	case "<", ">", "==", "!=", "<=", ">=":
+		return compareResult(op, compareInts(int64(left), int64(right)))
+	}
+
+	return object.NewTypeError("unknown operator: DURATION %s DURATION", op)
+}
+
+func scaleDuration(op string, d time.Duration, n int64) object.Object {
+	if op == "/" {
+		if n == 0 {
+			return object.NewZeroDivisionError("Duration division by zero")
+		}
+		return &object.Duration{Value: d / time.Duration(n)}
+	}
+	if integerOverflows(op, int64(d), n) {
+		return object.NewException("Duration overflow")
+	}
+	return &object.Duration{Value: d * time.Duration(n)}
+}
+
+// durationFromFloat rounds nanoseconds to a duration.
+func durationFromFloat(ns float64) object.Object {
+	ns = math.Round(ns)
+	if math.IsNaN(ns) || ns >= math.MaxInt64 || ns < math.MinInt64 {
+		return object.NewException("Duration overflow")
+	}
+	return &object.Duration{Value: time.Duration(ns)}
+}
+
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
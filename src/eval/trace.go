@@ -0,0 +1,70 @@
+package eval
+
+import (
+	"github.com/nitrogen-lang/nitrogen/src/object"
+	"github.com/nitrogen-lang/nitrogen/src/token"
+)
+
+// callFrame is a function call currently being executed.
+type callFrame struct {
+	function string
+	// callSite is where the function was called from in the calling frame
+	callSite token.Token
+}
+
+// FunctionName returns the name of fn used in stack traces.
+func FunctionName(fn *object.Function) string {
+	name := fn.Name
+	if name == "" {
+		name = "<anonymous>"
+	}
+	if fn.Instance != nil {
+		name = fn.Instance.Class.Name + "." + name
+	}
+	return name
+}
+
+func (i *Interpreter) pushCall(function string, callSite token.Token) {
+	i.callStack = append(i.callStack, callFrame{function: function, callSite: callSite})
+}
+
+func (i *Interpreter) popCall() {
+	i.callStack = i.callStack[:len(i.callStack)-1]
+}
+
+// callAt applies fn called at callSite. Functions get a frame in the call stack,
+// functions called by builtins are called from callSite.
+func (i *Interpreter) callAt(fn object.Object, args []object.Object, env *object.Environment, callSite token.Token) object.Object {
+	f, ok := fn.(*object.Function)
+	if !ok {
+		outer := i.callSite
+		i.callSite = callSite
+		result := i.applyFunction(fn, args, env)
+		i.callSite = outer
+		return result
+	}
+
+	i.pushCall(FunctionName(f), callSite)
+	result := i.applyFunction(fn, args, env)
+	i.popCall()
+	return result
+}
+
+// recordTrace sets the trace of result to where it was raised at tok if it's an
+// exception without one. Traces are recorded where exceptions are created, at
+// the statement that returns them, or at the call of a builtin that raised them.
+func (i *Interpreter) recordTrace(result object.Object, tok token.Token) {
+	if exc, ok := result.(*object.Exception); ok && exc.Trace == nil && tok.Pos.Line > 0 {
+		exc.Trace = i.stackTrace(tok)
+	}
+}
+
+// stackTrace builds the trace of an exception raised at tok in the current function.
+func (i *Interpreter) stackTrace(tok token.Token) []object.TraceFrame {
+	trace := make([]object.TraceFrame, 0, len(i.callStack)+1)
+	for c := len(i.callStack) - 1; c >= 0; c-- {
+		trace = append(trace, object.NewTraceFrame(i.callStack[c].function, tok))
+		tok = i.callStack[c].callSite
+	}
+	return append(trace, object.NewTraceFrame("<main>", tok))
+}
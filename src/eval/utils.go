@@ -1,6 +1,8 @@
 package eval
 
 import (
+	"bytes"
+
 	"github.com/nitrogen-lang/nitrogen/src/object"
 )
 
@@ -32,6 +34,13 @@ func isTruthy(obj object.Object) bool {
 		return (obj.(*object.Float).Value != 0.0)
 	}
 
+	switch obj := obj.(type) {
+	case *object.BigInt:
+		return obj.Value.Sign() != 0
+	case *object.Decimal:
+		return obj.Unscaled.Sign() != 0
+	}
+
 	// Empty string is false, non-empty is true
 	if obj.Type() == object.StringObj {
 		return (obj.(*object.String).Value != "")
@@ -49,6 +58,8 @@ func convertToBoolean(obj object.Object) (bool, bool) {
 		object.BooleanObj,
 		object.IntergerObj,
 		object.FloatObj,
+		object.BigIntObj,
+		object.DecimalObj,
 		object.StringObj,
 		object.NullObj,
 	)
@@ -56,6 +67,15 @@ func convertToBoolean(obj object.Object) (bool, bool) {
 	return isTruthy(obj), isValid
 }
 
+// interpolate concatenates parts, strings are added as is and other objects as they're printed.
+func interpolate(parts []object.Object) object.Object {
+	var str bytes.Buffer
+	for _, part := range parts {
+		str.WriteString(part.Inspect())
+	}
+	return &object.String{Value: str.String()}
+}
+
 func isException(obj object.Object) bool {
 	if obj == nil {
 		return false
@@ -0,0 +1,278 @@
+package format
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/nitrogen-lang/nitrogen/src/ast"
+	"github.com/nitrogen-lang/nitrogen/src/token"
+)
+
+// Precedence levels of expressions, they match the parser's.
+const (
+	precLowest = iota
+	precCompare
+	precEquals
+	precLessGreater
+	precSum
+	precProduct
+	precPrefix
+	precPostfix // Calls and indexing
+	precAtom
+)
+
+var operatorPrecedence = map[string]int{
+	"==": precEquals,
+	"!=": precEquals,
+	"<=": precEquals,
+	">=": precEquals,
+	"<":  precLessGreater,
+	">":  precLessGreater,
+	"in": precLessGreater,
+	"+":  precSum,
+	"-":  precSum,
+	"|":  precSum,
+	"^":  precSum,
+	"*":  precProduct,
+	"/":  precProduct,
+	"%":  precProduct,
+	"<<": precProduct,
+	">>": precProduct,
+	"&":  precProduct,
+	"&^": precProduct,
+}
+
+var compoundOperators = map[token.TokenType]string{
+	token.PlusAssign:  "+=",
+	token.MinusAssign: "-=",
+	token.TimesAssign: "*=",
+	token.SlashAssign: "/=",
+	token.ModAssign:   "%=",
+}
+
+func precedence(e ast.Expression) int {
+	switch e := e.(type) {
+	case *ast.CompareExpression:
+		return precCompare
+	case *ast.InfixExpression:
+		return operatorPrecedence[e.Operator]
+	case *ast.PrefixExpression:
+		return precPrefix
+	case *ast.CallExpression, *ast.IndexExpression, *ast.SliceExpression:
+		return precPostfix
+	case *ast.AssignStatement, *ast.MakeInstance:
+		// Both take everything after them so they're grouped anywhere but at the end
+		return precLowest
+	}
+	return precAtom
+}
+
+// expr prints e in parentheses if it binds looser than min.
+func (p *printer) expr(e ast.Expression, min int) {
+	if precedence(e) < min {
+		p.write("(")
+		p.expr(e, precLowest)
+		p.write(")")
+		return
+	}
+
+	switch e := e.(type) {
+	case *ast.Identifier:
+		p.write(e.Value)
+	case *ast.IntegerLiteral:
+		p.write(e.Token.Literal)
+	case *ast.FloatLiteral:
+		p.write(e.Token.Literal)
+	case *ast.Boolean:
+		p.write(e.Token.Literal)
+	case *ast.NullLiteral:
+		p.write("nil")
+	case *ast.StringLiteral:
+		p.write(quote(e.Value, p.raw[e.Token.Pos]))
+	case *ast.InterpolatedString:
+		p.interpolated(e)
+	case *ast.PrefixExpression:
+		p.write(e.Operator)
+		p.expr(e.Right, precPrefix)
+	case *ast.InfixExpression:
+		prec := operatorPrecedence[e.Operator]
+		p.expr(e.Left, prec)
+		p.write(" ")
+		p.write(e.Operator)
+		p.write(" ")
+		// Operators are left associative
+		p.expr(e.Right, prec+1)
+	case *ast.CompareExpression:
+		// The parser reads everything after and/or as the right side. Mixing
+		// them is grouped anyway since "a and (b or c)" reads differently without parentheses.
+		p.expr(e.Left, precCompare+1)
+		p.write(" ")
+		p.write(e.Token.Literal)
+		p.write(" ")
+		if right, ok := e.Right.(*ast.CompareExpression); ok && right.Token.Type != e.Token.Type {
+			p.expr(e.Right, precCompare+1)
+		} else {
+			p.expr(e.Right, precLowest)
+		}
+	case *ast.AssignStatement:
+		p.assign(e)
+	case *ast.CallExpression:
+		p.expr(e.Function, precPostfix)
+		p.exprList(e.Token.Pos, "(", ")", e.Arguments)
+	case *ast.IndexExpression:
+		p.index(e)
+	case *ast.SliceExpression:
+		p.expr(e.Left, precPostfix)
+		p.write("[")
+		if e.Low != nil {
+			p.expr(e.Low, precLowest)
+		}
+		p.write(":")
+		if e.High != nil {
+			p.expr(e.High, precLowest)
+		}
+		p.write("]")
+	case *ast.Array:
+		p.exprList(e.Token.Pos, "[", "]", e.Elements)
+	case *ast.HashLiteral:
+		p.list(e.Token.Pos, "{", "}", len(e.Pairs),
+			func(i int) token.Position { return exprStart(e.Pairs[i].Key) },
+			func(i int) {
+				p.expr(e.Pairs[i].Key, precLowest)
+				p.write(": ")
+				p.expr(e.Pairs[i].Value, precLowest)
+			})
+	case *ast.FunctionLiteral:
+		p.function("", e)
+	case *ast.ClassLiteral:
+		p.class("", e)
+	case *ast.IfExpression:
+		p.write("if ")
+		p.expr(e.Condition, precLowest)
+		p.write(" ")
+		p.block(e.Consequence)
+		if e.Alternative != nil {
+			p.write(" else ")
+			p.block(e.Alternative)
+		}
+	case *ast.TryCatchExpression:
+		p.tryCatch(e)
+	case *ast.MakeInstance:
+		p.write("make ")
+		p.expr(e.Class, precPostfix)
+		p.write("(")
+		for i, arg := range e.Arguments {
+			if i > 0 {
+				p.write(", ")
+			}
+			p.expr(arg, precLowest)
+		}
+		p.write(")")
+	}
+}
+
+// assign prints an assignment. Compound assignments are parsed as an
+// assignment of an infix expression with the same left side.
+func (p *printer) assign(a *ast.AssignStatement) {
+	p.expr(a.Left, precPostfix)
+	if op, ok := compoundOperators[a.Token.Type]; ok {
+		if infix, ok := a.Value.(*ast.InfixExpression); ok && infix.Left == a.Left {
+			p.write(" " + op + " ")
+			p.expr(infix.Right, precLowest)
+			return
+		}
+	}
+	p.write(" = ")
+	p.expr(a.Value, precLowest)
+}
+
+// index prints an index expression. An identifier after a dot is parsed as a string index.
+func (p *printer) index(e *ast.IndexExpression) {
+	p.expr(e.Left, precPostfix)
+	if e.Token.Type != token.Arrow {
+		p.write("[")
+		p.expr(e.Index, precLowest)
+		p.write("]")
+		return
+	}
+
+	p.write(e.Token.Literal)
+	if s, ok := e.Index.(*ast.StringLiteral); ok && isIdentifier(s.Value) {
+		p.write(s.Value)
+		return
+	}
+	p.expr(e.Index, precAtom)
+}
+
+func isIdentifier(s string) bool {
+	if s == "" || token.LookupIdent(s) != token.Identifier {
+		return false
+	}
+	for i, r := range s {
+		if !unicode.IsLetter(r) && r != '_' && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *printer) tryCatch(e *ast.TryCatchExpression) {
+	p.write("try ")
+	p.block(e.Try)
+	for _, c := range e.Catches {
+		p.write(" catch ")
+		if c.Symbol != nil {
+			p.write(c.Symbol.Value)
+			if c.Class != nil {
+				p.write(": ")
+				p.expr(c.Class, precLowest)
+			}
+			p.write(" ")
+		}
+		p.block(c.Body)
+	}
+	if e.Finally != nil {
+		p.write(" finally ")
+		p.block(e.Finally)
+	}
+}
+
+func (p *printer) interpolated(s *ast.InterpolatedString) {
+	p.write(`"`)
+	for i, part := range s.Parts {
+		if i%2 == 0 {
+			p.write(escape(part.(*ast.StringLiteral).Value))
+			continue
+		}
+		p.write("${")
+		p.expr(part, precLowest)
+		p.write("}")
+	}
+	p.write(`"`)
+}
+
+// quote returns the source of a string. Raw strings stay raw unless they end
+// with a backslash which can't be written in a raw string.
+func quote(s string, raw bool) string {
+	if raw && !strings.HasSuffix(s, `\`) {
+		return "'" + strings.Replace(s, "'", `\'`, -1) + "'"
+	}
+	return `"` + escape(s) + `"`
+}
+
+var escaper = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"${", `\${`,
+	"\b", `\b`,
+	"\n", `\n`,
+	"\r", `\r`,
+	"\t", `\t`,
+	"\v", `\v`,
+	"\f", `\f`,
+)
+
+// escape escapes the text of an interpreted string.
+func escape(s string) string {
+	return escaper.Replace(s)
+}
@@ -0,0 +1,484 @@
+// Package format prints Nitrogen source code in its canonical form.
+//
+// Code is indented with 4 spaces, blocks open on the line of the statement
+// they belong to, and there's one statement per line. Parentheses are only
+// kept where they're needed. Comments and single blank lines between
+// statements are kept. Arrays, maps, and call arguments are printed one
+// element per line if the first element started on a new line.
+package format
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/nitrogen-lang/nitrogen/src/ast"
+	"github.com/nitrogen-lang/nitrogen/src/lexer"
+	"github.com/nitrogen-lang/nitrogen/src/parser"
+	"github.com/nitrogen-lang/nitrogen/src/token"
+)
+
+const indent = "    "
+
+// Source formats Nitrogen source code. The error has the parser's messages if src can't be parsed.
+func Source(src []byte) ([]byte, error) {
+	p := parser.New(lexer.New(bytes.NewReader(src)), nil)
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		return nil, errors.New(strings.Join(p.Errors(), "\n"))
+	}
+
+	pr := newPrinter(src)
+	pr.statements(program.Statements)
+	pr.flushComments(token.Position{Line: int(^uint(0) >> 1)})
+	if pr.out.Len() > 0 {
+		pr.out.WriteByte('\n')
+	}
+	return pr.out.Bytes(), nil
+}
+
+type comment struct {
+	pos  token.Position
+	text string
+	// trailing is set if the comment is on the same line as the code before it
+	trailing bool
+}
+
+// printer writes the formatted code. The parser drops comments and closing
+// brackets so they're found by lexing the source again.
+type printer struct {
+	out    bytes.Buffer
+	indent int
+	// first is set until the first item of a block or list is printed, it
+	// doesn't get a blank line before it.
+	first bool
+
+	comments []comment
+	closers  map[token.Position]token.Position
+	occupied map[int]bool
+	// raw holds the positions of raw strings
+	raw    map[token.Position]bool
+	tokens []token.Token
+}
+
+func newPrinter(src []byte) *printer {
+	p := &printer{
+		first:    true,
+		closers:  make(map[token.Position]token.Position),
+		occupied: make(map[int]bool),
+		raw:      make(map[token.Position]bool),
+	}
+	lines := strings.Split(string(src), "\n")
+
+	l := lexer.New(bytes.NewReader(src))
+	prevLine := 0
+	var open []token.Position
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		p.tokens = append(p.tokens, tok)
+		last := tok.Pos.Line
+
+		switch tok.Type {
+		case token.Comment:
+			last += strings.Count(tok.Literal, "\n")
+			p.comments = append(p.comments, comment{
+				pos:      tok.Pos,
+				text:     commentText(tok, sourceAt(lines, tok.Pos, 2)),
+				trailing: prevLine == tok.Pos.Line,
+			})
+		case token.String:
+			if sourceAt(lines, tok.Pos, 1) == "'" {
+				p.raw[tok.Pos] = true
+				last += strings.Count(tok.Literal, "\n")
+			}
+		case token.LParen, token.LBrace, token.LSquare:
+			open = append(open, tok.Pos)
+		case token.RParen, token.RBrace, token.RSquare:
+			if len(open) > 0 {
+				p.closers[open[len(open)-1]] = tok.Pos
+				open = open[:len(open)-1]
+			}
+		}
+
+		for line := tok.Pos.Line; line <= last; line++ {
+			p.occupied[line] = true
+		}
+		prevLine = last
+	}
+	return p
+}
+
+// sourceAt returns n characters of the source at pos.
+func sourceAt(lines []string, pos token.Position, n int) string {
+	if pos.Line < 1 || pos.Line > len(lines) {
+		return ""
+	}
+	line := []rune(lines[pos.Line-1])
+	if pos.Col < 1 || pos.Col > len(line) {
+		return ""
+	}
+	if end := pos.Col - 1 + n; end < len(line) {
+		line = line[:end]
+	}
+	return string(line[pos.Col-1:])
+}
+
+func before(a, b token.Position) bool {
+	return a.Line < b.Line || a.Line == b.Line && a.Col < b.Col
+}
+
+func (p *printer) write(s string) {
+	p.out.WriteString(s)
+}
+
+// line starts a new line at the current indentation.
+func (p *printer) line() {
+	if p.out.Len() > 0 {
+		p.out.WriteByte('\n')
+	}
+	p.write(strings.Repeat(indent, p.indent))
+}
+
+// item starts the line of a statement, comment, or list element that starts
+// on line in the source. A blank line is kept before it.
+func (p *printer) item(line int) {
+	if !p.first && p.out.Len() > 0 && line > 1 && !p.occupied[line-1] {
+		p.out.WriteByte('\n')
+	}
+	p.line()
+	p.first = false
+}
+
+// flushComments prints the comments before pos. The caller must start a new
+// line after it since a comment may end the current one.
+func (p *printer) flushComments(pos token.Position) {
+	for len(p.comments) > 0 && before(p.comments[0].pos, pos) {
+		c := p.comments[0]
+		p.comments = p.comments[1:]
+
+		if c.trailing && p.out.Len() > 0 {
+			p.write(" ")
+		} else {
+			p.item(c.pos.Line)
+		}
+		p.write(c.text)
+	}
+}
+
+// hasComments returns if there are comments left to print between start and end.
+func (p *printer) hasComments(start, end token.Position) bool {
+	for _, c := range p.comments {
+		if before(start, c.pos) && before(c.pos, end) {
+			return true
+		}
+	}
+	return false
+}
+
+// commentText returns the source of a comment that starts with marker. The
+// lexer doesn't keep the comment markers or the spaces after them.
+func commentText(c token.Token, marker string) string {
+	switch {
+	case strings.HasPrefix(marker, "#"):
+		if strings.HasPrefix(c.Literal, "!") {
+			return "#" + c.Literal // #! header
+		}
+		return strings.TrimSpace("# " + c.Literal)
+	case marker == "/*":
+		return "/*" + c.Literal + "*/"
+	}
+	return strings.TrimSpace("// " + c.Literal)
+}
+
+func (p *printer) statements(stmts []ast.Statement) {
+	for _, s := range stmts {
+		pos := stmtStart(s)
+		p.flushComments(pos)
+		p.item(pos.Line)
+		p.statement(s)
+	}
+}
+
+// stmtStart returns the position of a statement's first token.
+func stmtStart(s ast.Statement) token.Position {
+	if def, ok := s.(*ast.DefStatement); ok && isDeclaration(def) {
+		return ast.NodeToken(def.Value).Pos
+	}
+	return ast.NodeToken(s).Pos
+}
+
+// exprStart returns the position of an expression's first token.
+func exprStart(e ast.Expression) token.Position {
+	switch e := e.(type) {
+	case *ast.InfixExpression:
+		return exprStart(e.Left)
+	case *ast.CompareExpression:
+		return exprStart(e.Left)
+	case *ast.CallExpression:
+		return exprStart(e.Function)
+	case *ast.IndexExpression:
+		return exprStart(e.Left)
+	case *ast.SliceExpression:
+		return exprStart(e.Left)
+	case *ast.AssignStatement:
+		return exprStart(e.Left)
+	}
+	return ast.NodeToken(e).Pos
+}
+
+// isDeclaration returns if def was written as "func name()" or "class name"
+// instead of assigning a literal with let.
+func isDeclaration(def *ast.DefStatement) bool {
+	switch v := def.Value.(type) {
+	case *ast.FunctionLiteral:
+		return before(v.Token.Pos, def.Name.Token.Pos)
+	case *ast.ClassLiteral:
+		return before(v.Token.Pos, def.Name.Token.Pos)
+	}
+	return false
+}
+
+func (p *printer) statement(s ast.Statement) {
+	switch s := s.(type) {
+	case *ast.DefStatement:
+		p.def(s)
+	case *ast.ReturnStatement:
+		p.write("return")
+		if !isBareReturn(s) {
+			p.write(" ")
+			p.expr(s.Value, precLowest)
+		}
+	case *ast.ExpressionStatement:
+		if s.Expression != nil {
+			p.expr(s.Expression, precLowest)
+		}
+	case *ast.ForLoopStatement:
+		p.forLoop(s)
+	case *ast.ContinueStatement:
+		p.write("continue")
+	case *ast.BreakStatement:
+		p.write("break")
+	case *ast.ThrowStatement:
+		p.write("throw ")
+		p.expr(s.Expression, precLowest)
+	case *ast.BlockStatement:
+		p.block(s)
+	}
+}
+
+// isBareReturn returns if s is a return without a value, it's parsed as returning a made up null token.
+func isBareReturn(s ast.Statement) bool {
+	ret, ok := s.(*ast.ReturnStatement)
+	if !ok {
+		return false
+	}
+	null, ok := ret.Value.(*ast.NullLiteral)
+	return ok && null.Token.Literal == "null"
+}
+
+func (p *printer) def(s *ast.DefStatement) {
+	if isDeclaration(s) {
+		switch v := s.Value.(type) {
+		case *ast.FunctionLiteral:
+			p.function(s.Name.Value, v)
+		case *ast.ClassLiteral:
+			p.class(s.Name.Value, v)
+		}
+		return
+	}
+
+	if s.Const {
+		p.write("always ")
+	} else {
+		p.write("let ")
+	}
+	p.write(s.Name.Value)
+	if s.Value != nil {
+		p.write(" = ")
+		p.expr(s.Value, precLowest)
+	}
+}
+
+func (p *printer) forLoop(s *ast.ForLoopStatement) {
+	p.write("for ")
+	switch {
+	case s.Collection != nil:
+		if s.Key != nil {
+			p.write(s.Key.Value)
+			p.write(", ")
+		}
+		p.write(s.Value.Value)
+		p.write(" in ")
+		p.expr(s.Collection, precLowest)
+		p.write(" ")
+	case s.Init != nil:
+		// The initializer is parsed as a let statement without the let
+		p.write(s.Init.Name.Value)
+		p.write(" = ")
+		p.expr(s.Init.Value, precLowest)
+		p.write("; ")
+		p.expr(s.Condition, precLowest)
+		p.write("; ")
+		p.expr(s.Iter, precLowest)
+		p.write(" ")
+	}
+	p.block(s.Body)
+}
+
+// block prints a block. It's kept on one line if it was on one line in the source and has no comments.
+func (p *printer) block(b *ast.BlockStatement) {
+	end := p.closers[b.Token.Pos]
+	comments := p.hasComments(b.Token.Pos, end)
+
+	if len(b.Statements) == 0 && !comments {
+		p.write("{}")
+		return
+	}
+	if b.Token.Pos.Line == end.Line && !comments {
+		p.write("{ ")
+		for i, s := range b.Statements {
+			if i > 0 {
+				p.write("; ")
+			}
+			p.statement(s)
+		}
+		if isBareReturn(b.Statements[len(b.Statements)-1]) {
+			p.write(";") // return would take the brace as its value
+		}
+		p.write(" }")
+		return
+	}
+
+	p.write("{")
+	p.indent++
+	p.first = true
+	p.statements(b.Statements)
+	p.flushComments(end)
+	p.indent--
+	p.first = false
+	p.line()
+	p.write("}")
+}
+
+func (p *printer) function(name string, f *ast.FunctionLiteral) {
+	p.write("func")
+	if name != "" {
+		p.write(" ")
+		p.write(name)
+	}
+	p.write("(")
+	for i, param := range f.Parameters {
+		if i > 0 {
+			p.write(", ")
+		}
+		p.write(param.Value)
+	}
+	p.write(") ")
+	p.block(f.Body)
+}
+
+// class prints a class literal. The parser keeps fields and methods apart,
+// they're printed in the order of the source.
+func (p *printer) class(name string, c *ast.ClassLiteral) {
+	p.write("class")
+	if name != "" {
+		p.write(" ")
+		p.write(name)
+	}
+	if c.Parent != "" {
+		p.write(" ^ ")
+		p.write(c.Parent)
+	}
+	p.write(" ")
+
+	type member struct {
+		pos    token.Position
+		field  *ast.DefStatement
+		method *ast.FunctionLiteral
+	}
+	var members []member
+	for _, f := range c.Fields {
+		members = append(members, member{pos: stmtStart(f), field: f})
+	}
+	for _, m := range c.Methods {
+		members = append(members, member{pos: m.Token.Pos, method: m})
+	}
+	sort.Slice(members, func(i, j int) bool { return before(members[i].pos, members[j].pos) })
+
+	open, end := p.classBrace(c)
+	if len(members) == 0 && !p.hasComments(open, end) {
+		p.write("{}")
+		return
+	}
+
+	p.write("{")
+	p.indent++
+	p.first = true
+	for _, m := range members {
+		p.flushComments(m.pos)
+		p.item(m.pos.Line)
+		if m.field != nil {
+			p.def(m.field)
+		} else {
+			p.function(m.method.Name, m.method)
+		}
+	}
+	p.flushComments(end)
+	p.indent--
+	p.first = false
+	p.line()
+	p.write("}")
+}
+
+// classBrace returns the positions of the braces around a class body, the
+// parser doesn't keep the body's block.
+func (p *printer) classBrace(c *ast.ClassLiteral) (token.Position, token.Position) {
+	found := false
+	for _, tok := range p.tokens {
+		if tok.Pos == c.Token.Pos {
+			found = true
+		}
+		if found && tok.Type == token.LBrace {
+			return tok.Pos, p.closers[tok.Pos]
+		}
+	}
+	return token.Position{}, token.Position{}
+}
+
+// list prints the elements of an array, map, or call between brackets. If
+// the first element started on a line after the opening bracket, each
+// element is printed on its own line with a trailing comma.
+func (p *printer) list(open token.Position, left, right string, n int, start func(int) token.Position, elem func(int)) {
+	p.write(left)
+	if n == 0 || start(0).Line <= open.Line {
+		for i := 0; i < n; i++ {
+			if i > 0 {
+				p.write(", ")
+			}
+			elem(i)
+		}
+		p.write(right)
+		return
+	}
+
+	p.indent++
+	p.first = true
+	for i := 0; i < n; i++ {
+		pos := start(i)
+		p.flushComments(pos)
+		p.item(pos.Line)
+		elem(i)
+		p.write(",")
+	}
+	p.flushComments(p.closers[open])
+	p.indent--
+	p.first = false
+	p.line()
+	p.write(right)
+}
+
+func (p *printer) exprList(open token.Position, left, right string, exprs []ast.Expression) {
+	p.list(open, left, right, len(exprs),
+		func(i int) token.Position { return exprStart(exprs[i]) },
+		func(i int) { p.expr(exprs[i], precLowest) })
+}
@@ -0,0 +1,125 @@
+package format
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/nitrogen-lang/nitrogen/src/lexer"
+	"github.com/nitrogen-lang/nitrogen/src/parser"
+)
+
+func TestFormatSource(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let   x=1+2*3", "let x = 1 + 2 * 3\n"},
+		{"let x = (1+2)*3;", "let x = (1 + 2) * 3\n"},
+		{"let x = 1-(2-3)", "let x = 1 - (2 - 3)\n"},
+		{"always y = !true", "always y = !true\n"},
+		{"x+=1", "x += 1\n"},
+		{"a and (b or c)", "a and (b or c)\n"},
+		{`let s = 'raw\n'`, "let s = 'raw\\n'\n"},
+		{`let s = "a\tb"`, "let s = \"a\\tb\"\n"},
+		{`let s = "a ${b+1}"`, "let s = \"a ${b + 1}\"\n"},
+		{"let m = {\"a\":1,\"b\":[1,2]}", "let m = {\"a\": 1, \"b\": [1, 2]}\n"},
+		{"println(m.a, m['b'])", "println(m.a, m['b'])\n"},
+		{"func f(a,b){return a+b}", "func f(a, b) { return a + b }\n"},
+		{"func f() {\nreturn\n}", "func f() {\n    return\n}\n"},
+		{"func f() {return;}", "func f() { return; }\n"},
+		{"if x{\nprintln(1)\n}else{\nprintln(2)\n}", "if x {\n    println(1)\n} else {\n    println(2)\n}\n"},
+		{"for i=0;i<10;i+=1{}", "for i = 0; i < 10; i += 1 {}\n"},
+		{"for k,v in m{}", "for k, v in m {}\n"},
+		{"let a = [\n1,\n2,\n]", "let a = [\n    1,\n    2,\n]\n"},
+		{"let x = 1 // one\n\n\n// two\nlet y = 2", "let x = 1 // one\n\n// two\nlet y = 2\n"},
+		{"# hash comment\nlet x = 1", "# hash comment\nlet x = 1\n"},
+		{"/* block */\nlet x = 1", "/* block */\nlet x = 1\n"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		res, err := Source([]byte(tt.input))
+		if err != nil {
+			t.Errorf("Source(%q) returned error: %s", tt.input, err)
+			continue
+		}
+		if string(res) != tt.expected {
+			t.Errorf("Source(%q) wrong.\nexpected=%q\ngot=%q", tt.input, tt.expected, res)
+		}
+	}
+}
+
+func TestFormatClass(t *testing.T) {
+	input := `class Foo {
+let x = 1
+func init(x) { this.x = x }
+
+func get() {
+return this.x
+}
+}`
+	expected := `class Foo {
+    let x = 1
+    func init(x) { this.x = x }
+
+    func get() {
+        return this.x
+    }
+}
+`
+
+	res, err := Source([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(res) != expected {
+		t.Errorf("Wrong output.\nexpected=%q\ngot=%q", expected, res)
+	}
+}
+
+func TestFormatError(t *testing.T) {
+	if _, err := Source([]byte("let x =")); err == nil {
+		t.Error("Expected an error for invalid source")
+	}
+}
+
+// TestFormatTests formats the source test suite and checks the output is stable
+// and parses to the same program.
+func TestFormatTests(t *testing.T) {
+	files, err := filepath.Glob("../../tests/*.ni")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range files {
+		src, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := Source(src)
+		if err != nil {
+			t.Errorf("%s: %s", file, err)
+			continue
+		}
+
+		again, err := Source(res)
+		if err != nil {
+			t.Errorf("%s: formatted source doesn't parse: %s", file, err)
+			continue
+		}
+		if !bytes.Equal(res, again) {
+			t.Errorf("%s: formatting isn't stable", file)
+		}
+
+		if parse(src) != parse(res) {
+			t.Errorf("%s: formatted source parses to a different program", file)
+		}
+	}
+}
+
+func parse(src []byte) string {
+	return parser.New(lexer.New(bytes.NewReader(src)), nil).ParseProgram().String()
+}
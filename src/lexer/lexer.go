@@ -13,11 +13,20 @@ import (
 	"github.com/nitrogen-lang/nitrogen/src/token"
 )
 
+// UnterminatedString and UnterminatedRawString are the literals of the illegal
+// token returned when the input ends inside a string.
+const (
+	UnterminatedString    = "Unterminated string"
+	UnterminatedRawString = "Unterminated raw string"
+)
+
 type Lexer struct {
 	input     *bufio.Reader
 	curCh     rune // current char under examination
 	peekCh    rune // peek character
 	lastToken token.Token
+	// interpolations holds the brace depth of each open string interpolation
+	interpolations []int
 
 	fileList    []string
 	line, col   int
@@ -112,7 +121,16 @@ func (l *Lexer) NextToken() token.Token {
 
 	switch l.curCh {
 	case '\n':
-		if l.needSemicolon() {
+		if len(l.interpolations) > 0 {
+			tok = token.Token{
+				Literal:  "Newline not allowed in string",
+				Type:     token.Illegal,
+				Pos:      l.curPosition(),
+				Filename: l.currentFile,
+			}
+			l.interpolations = nil
+			l.resetPos()
+		} else if l.needSemicolon() {
 			tok = l.newToken(token.Semicolon, ';')
 			l.resetPos()
 		} else {
@@ -297,8 +315,21 @@ func (l *Lexer) NextToken() token.Token {
 	case ')':
 		tok = l.newToken(token.RParen, l.curCh)
 	case '{':
+		if depth := len(l.interpolations); depth > 0 {
+			l.interpolations[depth-1]++
+		}
 		tok = l.newToken(token.LBrace, l.curCh)
 	case '}':
+		depth := len(l.interpolations)
+		if depth > 0 && l.interpolations[depth-1] == 0 {
+			// The closing brace of an interpolation continues the string
+			l.interpolations = l.interpolations[:depth-1]
+			tok = l.readString(true)
+			break
+		}
+		if depth > 0 {
+			l.interpolations[depth-1]--
+		}
 		tok = l.newToken(token.RBrace, l.curCh)
 	case '[':
 		tok = l.newToken(token.LSquare, l.curCh)
@@ -306,7 +337,7 @@ func (l *Lexer) NextToken() token.Token {
 		tok = l.newToken(token.RSquare, l.curCh)
 
 	case '"':
-		tok = l.readString()
+		tok = l.readString(false)
 	case '\'':
 		tok = l.readRawString()
 	case '#':
@@ -368,6 +399,7 @@ func (l *Lexer) needSemicolon() bool {
 		token.Integer,
 		token.Float,
 		token.String,
+		token.StringEnd,
 		token.Nil,
 		token.Return,
 		token.Break,
@@ -386,12 +418,30 @@ func (l *Lexer) readIdentifier() string {
 	return ident.String()
 }
 
-func (l *Lexer) readString() token.Token {
+// readString reads a double quoted string. An interpolation "${" ends the
+// token, the lexer then reads the interpolated expression as normal tokens
+// until its closing brace where readString is called again with continued set.
+func (l *Lexer) readString(continued bool) token.Token {
 	var ident bytes.Buffer
 	pos := l.curPosition()
-	l.readRune() // Go past the starting double quote
+	l.readRune() // Go past the starting double quote or closing brace
 
 	for l.curCh != '"' {
+		if l.curCh == '$' && l.peekCh == '{' {
+			l.readRune() // The brace is skipped after the token is returned
+			l.interpolations = append(l.interpolations, 0)
+			tokenType := token.StringStart
+			if continued {
+				tokenType = token.StringMiddle
+			}
+			return token.Token{
+				Literal:  ident.String(),
+				Type:     tokenType,
+				Pos:      pos,
+				Filename: l.currentFile,
+			}
+		}
+
 		if l.curCh == '\n' {
 			return token.Token{
 				Literal:  "Newline not allowed in string",
@@ -401,6 +451,10 @@ func (l *Lexer) readString() token.Token {
 			}
 		}
 
+		if l.curCh == 0 {
+			return l.unterminatedString(UnterminatedString)
+		}
+
 		if l.curCh == '\\' {
 			l.readRune()
 			switch l.curCh {
@@ -420,6 +474,8 @@ func (l *Lexer) readString() token.Token {
 				ident.WriteRune('\\')
 			case '"': // double quote
 				ident.WriteRune('"')
+			case '$': // dollar sign, used to write a literal "${"
+				ident.WriteRune('$')
 			default:
 				ident.WriteByte('\\')
 				ident.WriteRune(l.curCh)
@@ -431,9 +487,13 @@ func (l *Lexer) readString() token.Token {
 		l.readRune()
 	}
 
+	tokenType := token.String
+	if continued {
+		tokenType = token.StringEnd
+	}
 	return token.Token{
 		Literal:  ident.String(),
-		Type:     token.String,
+		Type:     tokenType,
 		Pos:      pos,
 		Filename: l.currentFile,
 	}
@@ -445,6 +505,12 @@ func (l *Lexer) readRawString() token.Token {
 	l.readRune() // Go past the starting double quote
 
 	for l.curCh != '\'' {
+		if l.curCh == 0 {
+			return l.unterminatedString(UnterminatedRawString)
+		}
+		if l.curCh == '\n' {
+			l.resetPos()
+		}
 		if l.curCh == '\\' && l.peekCh == '\'' {
 			l.readRune() // Go past backslash so the next line will write a single quote
 		}
@@ -460,6 +526,16 @@ func (l *Lexer) readRawString() token.Token {
 	}
 }
 
+// unterminatedString is returned when the input ends inside a string.
+func (l *Lexer) unterminatedString(literal string) token.Token {
+	return token.Token{
+		Literal:  literal,
+		Type:     token.Illegal,
+		Pos:      l.curPosition(),
+		Filename: l.currentFile,
+	}
+}
+
 func (l *Lexer) readNumber() token.Token {
 	var number bytes.Buffer
 	pos := l.curPosition()
@@ -490,6 +566,22 @@ func (l *Lexer) readNumber() token.Token {
 	}
 
 	for isDigit(l.curCh) || isHexDigit(l.curCh) {
+		if base == "" && (l.curCh == 'e' || l.curCh == 'E') && isExponentStart(l.peekCh) {
+			// Exponent notation always makes a float
+			tokenType = token.Float
+			number.WriteRune(l.curCh)
+			l.readRune()
+			if l.curCh == '+' || l.curCh == '-' {
+				number.WriteRune(l.curCh)
+				l.readRune()
+			}
+			for '0' <= l.curCh && l.curCh <= '9' {
+				number.WriteRune(l.curCh)
+				l.readRune()
+			}
+			break
+		}
+
 		if l.curCh == '.' {
 			if tokenType != token.Integer {
 				return token.Token{
@@ -602,6 +694,10 @@ func isDigit(ch rune) bool {
 	return ('0' <= ch && ch <= '9') || ch == '.'
 }
 
+func isExponentStart(ch rune) bool {
+	return ('0' <= ch && ch <= '9') || ch == '+' || ch == '-'
+}
+
 func isHexDigit(ch rune) bool {
 	return ('a' <= ch && ch <= 'f') || ('A' <= ch && ch <= 'F')
 }
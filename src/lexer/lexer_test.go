@@ -56,6 +56,8 @@ and
 for
 
 ->
+
+try catch finally
 `
 
 	tests := []struct {
@@ -204,7 +206,11 @@ for
 
 		{token.Arrow, "->", makePos(49, 1)},
 
-		{token.EOF, "", makePos(50, 0)},
+		{token.Try, "try", makePos(51, 1)},
+		{token.Catch, "catch", makePos(51, 5)},
+		{token.Finally, "finally", makePos(51, 11)},
+
+		{token.EOF, "", makePos(52, 0)},
 	}
 
 	l := NewString(input)
@@ -232,3 +238,101 @@ for
 		}
 	}
 }
+
+func TestNumberLiterals(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{"42", token.Integer, "42"},
+		{"1.5", token.Float, "1.5"},
+		{"1e3", token.Float, "1e3"},
+		{"2.5E-4", token.Float, "2.5E-4"},
+		{"6e+2", token.Float, "6e+2"},
+		{"\\x1e5", token.Integer, "0x1e5"},
+		{"1.2.3", token.Illegal, "Invalid float literal"},
+	}
+
+	for _, tt := range tests {
+		tok := NewString(tt.input).NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Errorf("wrong token for %q. expected=%s %q, got=%s %q",
+				tt.input, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestInterpolatedString(t *testing.T) {
+	input := `"a ${x + "b${y}"} c ${ {1: 2}[1] }!" "\${x}"`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.StringStart, "a "},
+		{token.Identifier, "x"},
+		{token.Plus, "+"},
+		{token.StringStart, "b"},
+		{token.Identifier, "y"},
+		{token.StringEnd, ""},
+		{token.StringMiddle, " c "},
+		{token.LBrace, "{"},
+		{token.Integer, "1"},
+		{token.Colon, ":"},
+		{token.Integer, "2"},
+		{token.RBrace, "}"},
+		{token.LSquare, "["},
+		{token.Integer, "1"},
+		{token.RSquare, "]"},
+		{token.StringEnd, "!"},
+		{token.String, "${x}"},
+		{token.Semicolon, ";"},
+		{token.EOF, ""},
+	}
+
+	l := NewString(input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - wrong token. expected=%s %q, got=%s %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestUnterminatedString(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"abc`, UnterminatedString},
+		{`'abc`, UnterminatedRawString},
+		{"'abc\ndef", UnterminatedRawString},
+		{`"a${x}b`, UnterminatedString},
+	}
+
+	for _, tt := range tests {
+		l := NewString(tt.input)
+		var tok token.Token
+		for i := 0; i < 10; i++ {
+			tok = l.NextToken()
+			if tok.Type == token.Illegal || tok.Type == token.EOF {
+				break
+			}
+		}
+		if tok.Type != token.Illegal || tok.Literal != tt.expected {
+			t.Errorf("%q: expected %q, got %s %q", tt.input, tt.expected, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestRawStringLines(t *testing.T) {
+	l := NewString("'a\nb\nc' x")
+	if tok := l.NextToken(); tok.Type != token.String || tok.Literal != "a\nb\nc" {
+		t.Fatalf("expected a raw string, got %s %q", tok.Type, tok.Literal)
+	}
+	if tok := l.NextToken(); tok.Pos.Line != 3 {
+		t.Errorf("expected the token after the string on line 3, got %d", tok.Pos.Line)
+	}
+}
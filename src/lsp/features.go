@@ -0,0 +1,395 @@
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/nitrogen-lang/nitrogen/src/ast"
+	"github.com/nitrogen-lang/nitrogen/src/eval"
+	"github.com/nitrogen-lang/nitrogen/src/token"
+)
+
+// position returns the open document and lexer position of a request.
+func (s *Server) position(params json.RawMessage, p *TextDocumentPositionParams) (*file, token.Position, error) {
+	if err := unmarshalParams(params, p); err != nil {
+		return nil, token.Position{}, err
+	}
+	f, ok := s.ws.open[p.TextDocument.URI]
+	if !ok {
+		return nil, token.Position{}, &rpcError{Code: codeInvalidParams, Message: "document isn't open: " + p.TextDocument.URI}
+	}
+	return f, f.fromLSP(p.Position), nil
+}
+
+func (s *Server) definition(params json.RawMessage) (interface{}, error) {
+	var p TextDocumentPositionParams
+	f, pos, err := s.position(params, &p)
+	if err != nil {
+		return nil, err
+	}
+
+	sym := s.ws.symbolAt(f, pos)
+	if sym == nil {
+		return []Location{}, nil
+	}
+	return []Location{sym.file.location(sym.pos, sym.name)}, nil
+}
+
+func (s *Server) references(params json.RawMessage) (interface{}, error) {
+	var p ReferenceParams
+	f, pos, err := s.position(params, &p.TextDocumentPositionParams)
+	if err != nil {
+		return nil, err
+	}
+	if err := unmarshalParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	sym := s.ws.symbolAt(f, pos)
+	if sym == nil {
+		return []Location{}, nil
+	}
+	return s.ws.references(sym, p.Context.IncludeDeclaration), nil
+}
+
+func (s *Server) hover(params json.RawMessage) (interface{}, error) {
+	var p TextDocumentPositionParams
+	f, pos, err := s.position(params, &p)
+	if err != nil {
+		return nil, err
+	}
+
+	var text string
+	var rng Range
+	if sym := s.ws.symbolAt(f, pos); sym != nil {
+		text = codeBlock(signature(sym))
+		if doc := sym.file.index.docComment(sym.start.Line); doc != "" {
+			text += "\n" + doc
+		}
+		rng = nameRangeAt(f, pos, sym.name)
+	} else if ref := f.index.refAt(pos); ref != nil && eval.GetBuiltin(ref.name) != nil {
+		text = codeBlock("func "+ref.name) + "\nbuiltin function"
+		rng = f.nameRange(ref.pos, ref.name)
+	} else if m := f.index.memberAt(pos); m != nil {
+		base := s.ws.resolveRef(f, m.base)
+		if base == nil || base.module == "" {
+			return nil, nil
+		}
+		module := eval.GetModule(base.module)
+		if module == nil {
+			return nil, nil
+		}
+		if _, ok := module.Methods[m.name]; ok {
+			text = codeBlock(fmt.Sprintf("func %s.%s", base.module, m.name)) + "\nfunction of module " + base.module
+		} else if _, ok := module.Vars[m.name]; ok {
+			text = codeBlock(fmt.Sprintf("%s.%s", base.module, m.name)) + "\nvariable of module " + base.module
+		} else {
+			return nil, nil
+		}
+		rng = f.nameRange(m.pos, m.name)
+	} else {
+		return nil, nil
+	}
+
+	return &Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: text},
+		Range:    &rng,
+	}, nil
+}
+
+// nameRangeAt returns the range of the reference or declaration of name at pos.
+func nameRangeAt(f *file, pos token.Position, name string) Range {
+	if ref := f.index.refAt(pos); ref != nil {
+		return f.nameRange(ref.pos, ref.name)
+	}
+	if m := f.index.memberAt(pos); m != nil {
+		return f.nameRange(m.pos, m.name)
+	}
+	if sym := f.index.declAt(pos); sym != nil {
+		return f.nameRange(sym.pos, sym.name)
+	}
+	return f.nameRange(pos, name)
+}
+
+func codeBlock(code string) string {
+	return "```nitrogen\n" + code + "\n```"
+}
+
+// signature returns the declaration of sym as it would be written in source.
+func signature(sym *symbol) string {
+	switch sym.kind {
+	case functionSymbol, methodSymbol:
+		if fn, ok := sym.value.(*ast.FunctionLiteral); ok {
+			params := make([]string, len(fn.Parameters))
+			for i, param := range fn.Parameters {
+				params[i] = param.Value
+			}
+			return fmt.Sprintf("func %s(%s)", sym.name, strings.Join(params, ", "))
+		}
+		return "func " + sym.name
+	case classSymbol:
+		if class, ok := sym.value.(*ast.ClassLiteral); ok && class.Parent != "" {
+			return fmt.Sprintf("class %s ^ %s", sym.name, class.Parent)
+		}
+		return "class " + sym.name
+	case constantSymbol:
+		return "always " + sym.name
+	case parameterSymbol:
+		return "(parameter) " + sym.name
+	}
+	return "let " + sym.name
+}
+
+var (
+	modulePattern = regexp.MustCompile(`module\(\s*["']([\w.]*)$`)
+	memberPattern = regexp.MustCompile(`([A-Za-z_]\w*)\s*(?:\.|->)\s*(\w*)$`)
+	wordPattern   = regexp.MustCompile(`\w*$`)
+)
+
+func (s *Server) completion(params json.RawMessage) (interface{}, error) {
+	var p TextDocumentPositionParams
+	f, pos, err := s.position(params, &p)
+	if err != nil {
+		return nil, err
+	}
+	prefix := f.linePrefix(pos)
+
+	// The last good parse is used while the document has errors from being edited
+	indexes := []*index{f.index}
+	if last, ok := s.lastGood[f.uri]; ok && len(f.errors) > 0 {
+		indexes = append(indexes, last)
+	}
+
+	items := newCompletions()
+	if m := modulePattern.FindStringSubmatch(prefix); m != nil {
+		for _, name := range eval.ModuleNames() {
+			items.add(m[1], CompletionItem{Label: name, Kind: completionModule})
+		}
+		return items.list, nil
+	}
+
+	if m := memberPattern.FindStringSubmatch(prefix); m != nil {
+		for _, idx := range indexes {
+			sym := idx.lookup(pos, m[1])
+			if sym == nil {
+				sym = s.ws.resolveIncluded(f, m[1], s.ws.files(), map[string]bool{f.uri: true})
+			}
+			if sym != nil {
+				s.memberCompletions(items, sym, m[2])
+				break
+			}
+		}
+		return items.list, nil
+	}
+
+	word := wordPattern.FindString(prefix)
+	for _, idx := range indexes {
+		for _, sym := range idx.visibleAt(pos) {
+			items.add(word, symbolCompletion(sym))
+		}
+	}
+	for _, sym := range s.ws.includedSymbols(f) {
+		items.add(word, symbolCompletion(sym))
+	}
+	for _, name := range eval.BuiltinNames() {
+		items.add(word, CompletionItem{Label: name, Kind: completionFunction, Detail: "builtin function"})
+	}
+	for _, name := range token.Keywords() {
+		items.add(word, CompletionItem{Label: name, Kind: completionKeyword})
+	}
+	return items.list, nil
+}
+
+// memberCompletions adds the members of a module, included script, map, or class.
+func (s *Server) memberCompletions(items *completions, sym *symbol, word string) {
+	if sym.module != "" {
+		module := eval.GetModule(sym.module)
+		if module == nil {
+			return
+		}
+		var names []string
+		for name := range module.Methods {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			items.add(word, CompletionItem{Label: name, Kind: completionFunction, Detail: "module " + sym.module})
+		}
+		names = names[:0]
+		for name := range module.Vars {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			items.add(word, CompletionItem{Label: name, Kind: completionVariable, Detail: "module " + sym.module})
+		}
+		return
+	}
+
+	if sym.include != "" {
+		g := s.ws.load(sym.include)
+		if g == nil {
+			return
+		}
+		var names []string
+		for name := range g.index.exports {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		// Exported variables can have a different name than their key
+		for _, name := range names {
+			item := symbolCompletion(g.index.exports[name])
+			item.Label = name
+			items.add(word, item)
+		}
+		return
+	}
+
+	switch value := sym.value.(type) {
+	case *ast.HashLiteral:
+		for _, pair := range value.Pairs {
+			if key, ok := pair.Key.(*ast.StringLiteral); ok {
+				items.add(word, CompletionItem{Label: key.Value, Kind: completionField})
+			}
+		}
+	case *ast.MakeInstance:
+		if ident, ok := value.Class.(*ast.Identifier); ok {
+			if class := s.ws.symbolAt(sym.file, ident.Token.Pos); class != nil && class.kind == classSymbol {
+				s.memberCompletions(items, class, word)
+			}
+		}
+	case *ast.ClassLiteral:
+		for _, member := range sym.members {
+			items.add(word, symbolCompletion(member))
+		}
+	}
+}
+
+func symbolCompletion(sym *symbol) CompletionItem {
+	item := CompletionItem{Label: sym.name, Kind: completionVariable, Detail: signature(sym)}
+	switch sym.kind {
+	case constantSymbol:
+		item.Kind = completionConstant
+	case functionSymbol:
+		item.Kind = completionFunction
+	case classSymbol:
+		item.Kind = completionClass
+	case fieldSymbol:
+		item.Kind = completionField
+	case methodSymbol:
+		item.Kind = completionMethod
+	}
+	return item
+}
+
+// completions is a list of completion items without duplicate labels.
+type completions struct {
+	list []CompletionItem
+	seen map[string]bool
+}
+
+func newCompletions() *completions {
+	return &completions{list: []CompletionItem{}, seen: make(map[string]bool)}
+}
+
+// add adds item if its label starts with prefix.
+func (c *completions) add(prefix string, item CompletionItem) {
+	if c.seen[item.Label] || !strings.HasPrefix(item.Label, prefix) {
+		return
+	}
+	c.seen[item.Label] = true
+	c.list = append(c.list, item)
+}
+
+// includedSymbols returns the symbols visible where f is included by other scripts.
+func (w *workspace) includedSymbols(f *file) []*symbol {
+	if f.path == "" {
+		return nil
+	}
+	var syms []*symbol
+	visited := map[string]bool{f.uri: true}
+	files := w.files()
+
+	var collect func(f *file)
+	collect = func(f *file) {
+		for _, g := range files {
+			if visited[g.uri] {
+				continue
+			}
+			for _, inc := range g.index.includes {
+				if inc.path == f.path {
+					visited[g.uri] = true
+					syms = append(syms, g.index.visibleAt(inc.pos)...)
+					collect(g)
+					break
+				}
+			}
+		}
+	}
+	collect(f)
+	return syms
+}
+
+func (s *Server) documentSymbol(params json.RawMessage) (interface{}, error) {
+	var p DocumentSymbolParams
+	if err := unmarshalParams(params, &p); err != nil {
+		return nil, err
+	}
+	f, ok := s.ws.open[p.TextDocument.URI]
+	if !ok {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "document isn't open: " + p.TextDocument.URI}
+	}
+
+	symbols := []DocumentSymbol{}
+	for _, sym := range f.index.topLevel {
+		symbols = append(symbols, documentSymbol(sym))
+	}
+	return symbols, nil
+}
+
+func documentSymbol(sym *symbol) DocumentSymbol {
+	f := sym.file
+	name := f.nameRange(sym.pos, sym.name)
+	rng := Range{Start: f.toLSP(sym.start), End: f.toLSP(sym.end)}
+	if sym.end.Line != 0 && sym.end != sym.pos {
+		// The end is the closing brace
+		rng.End.Character++
+	}
+	if afterLSP(rng.Start, name.Start) {
+		rng.Start = name.Start
+	}
+	if afterLSP(name.End, rng.End) {
+		rng.End = name.End
+	}
+
+	ds := DocumentSymbol{
+		Name:           sym.name,
+		Detail:         signature(sym),
+		Kind:           symbolVariable,
+		Range:          rng,
+		SelectionRange: name,
+	}
+	switch sym.kind {
+	case constantSymbol:
+		ds.Kind = symbolConstant
+	case functionSymbol:
+		ds.Kind = symbolFunction
+	case classSymbol:
+		ds.Kind = symbolClass
+	case fieldSymbol:
+		ds.Kind = symbolField
+	case methodSymbol:
+		ds.Kind = symbolMethod
+	}
+	for _, member := range sym.members {
+		ds.Children = append(ds.Children, documentSymbol(member))
+	}
+	return ds
+}
+
+func afterLSP(a, b Position) bool {
+	return a.Line > b.Line || a.Line == b.Line && a.Character > b.Character
+}
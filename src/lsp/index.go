@@ -0,0 +1,763 @@
+package lsp
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nitrogen-lang/nitrogen/src/ast"
+	"github.com/nitrogen-lang/nitrogen/src/lexer"
+	"github.com/nitrogen-lang/nitrogen/src/token"
+)
+
+type symbolKind int
+
+const (
+	variableSymbol symbolKind = iota
+	constantSymbol
+	functionSymbol
+	classSymbol
+	parameterSymbol
+	fieldSymbol
+	methodSymbol
+)
+
+// symbol is a declaration in a file.
+type symbol struct {
+	name string
+	kind symbolKind
+	file *file
+	// pos is the position of the name, start and end are the extent of the declaration.
+	pos, start, end token.Position
+	value           ast.Expression
+	topLevel        bool
+	// members are the fields and methods of a class.
+	members []*symbol
+	// include is the path of the script the symbol is assigned from with include or require.
+	include string
+	// module is the name of the module the symbol is assigned from with module().
+	module string
+}
+
+// reference is an identifier in a file.
+type reference struct {
+	name string
+	pos  token.Position
+	// sym is the declaration in the same file, nil if it isn't declared in the file.
+	sym *symbol
+	// decl is set if the reference is the name in the symbol's declaration.
+	decl bool
+}
+
+// member is a name after a dot or arrow following an identifier.
+type member struct {
+	name string
+	pos  token.Position
+	base *reference
+}
+
+// scopeRange is the part of a file symbols declared in a scope are visible in.
+type scopeRange struct {
+	start, end token.Position
+	symbols    []*symbol
+}
+
+type includeCall struct {
+	path string
+	pos  token.Position
+}
+
+// index holds the declarations and references of a parsed file.
+type index struct {
+	file     *file
+	topLevel []*symbol
+	refs     []*reference
+	members  []*member
+	// scopes are ordered by start position, so inner scopes come after the scopes around them.
+	scopes   []*scopeRange
+	includes []*includeCall
+	// decls are all the symbols declared in the file.
+	decls []*symbol
+	// exports are the string keys of a map returned at the top level of the file.
+	exports map[string]*symbol
+	// comments holds the text of comments on lines without code by the line they end on.
+	comments map[int]string
+}
+
+func newIndex(f *file, program *ast.Program, src string) *index {
+	ix := &indexer{
+		idx: &index{
+			file:     f,
+			exports:  make(map[string]*symbol),
+			comments: make(map[int]string),
+		},
+		closers: make(map[token.Position]token.Position),
+	}
+	ix.scan(src)
+	ix.program(program)
+	return ix.idx
+}
+
+// visibleAt returns the symbols that can be used at pos, inner declarations first.
+func (idx *index) visibleAt(pos token.Position) []*symbol {
+	var syms []*symbol
+	seen := make(map[string]bool)
+	for i := len(idx.scopes) - 1; i >= 0; i-- {
+		s := idx.scopes[i]
+		if before(pos, s.start) || before(s.end, pos) {
+			continue
+		}
+		for _, sym := range s.symbols {
+			if !seen[sym.name] {
+				seen[sym.name] = true
+				syms = append(syms, sym)
+			}
+		}
+	}
+	return syms
+}
+
+// lookup returns the symbol named name that can be used at pos.
+func (idx *index) lookup(pos token.Position, name string) *symbol {
+	for _, sym := range idx.visibleAt(pos) {
+		if sym.name == name {
+			return sym
+		}
+	}
+	return nil
+}
+
+func (idx *index) refAt(pos token.Position) *reference {
+	for _, ref := range idx.refs {
+		if within(pos, ref.pos, ref.name) {
+			return ref
+		}
+	}
+	return nil
+}
+
+func (idx *index) memberAt(pos token.Position) *member {
+	for _, m := range idx.members {
+		if within(pos, m.pos, m.name) {
+			return m
+		}
+	}
+	return nil
+}
+
+// declAt returns the symbol whose name is at pos.
+func (idx *index) declAt(pos token.Position) *symbol {
+	for _, sym := range idx.decls {
+		if within(pos, sym.pos, sym.name) {
+			return sym
+		}
+	}
+	return nil
+}
+
+// within reports if pos is on or right after the name at start.
+func within(pos, start token.Position, name string) bool {
+	return pos.Line == start.Line && pos.Col >= start.Col && pos.Col <= start.Col+len([]rune(name))
+}
+
+func sortSymbols(syms []*symbol) {
+	sort.Slice(syms, func(i, j int) bool { return before(syms[i].pos, syms[j].pos) })
+}
+
+// docComment returns the comments on the lines right before line.
+func (idx *index) docComment(line int) string {
+	var lines []string
+	for l := line - 1; l > 0; l-- {
+		text, ok := idx.comments[l]
+		if !ok {
+			break
+		}
+		lines = append([]string{text}, lines...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+var endOfFile = token.Position{Line: 1 << 30}
+
+func before(a, b token.Position) bool {
+	return a.Line < b.Line || a.Line == b.Line && a.Col < b.Col
+}
+
+type scope struct {
+	outer *scope
+	// env is the scope that owns the environment declarations are stored in.
+	env   *scope
+	class bool
+	decls map[string]*symbol
+	rng   *scopeRange
+}
+
+type indexer struct {
+	idx     *index
+	scope   *scope
+	tokens  []token.Token
+	closers map[token.Position]token.Position
+}
+
+// scan finds the matching braces and the comments of the file.
+func (ix *indexer) scan(src string) {
+	l := lexer.NewString(src)
+	codeLines := make(map[int]bool)
+	var comments []token.Token
+	var open []token.Position
+
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		switch tok.Type {
+		case token.Comment:
+			comments = append(comments, tok)
+			continue
+		case token.LBrace:
+			open = append(open, tok.Pos)
+		case token.RBrace:
+			if len(open) > 0 {
+				ix.closers[open[len(open)-1]] = tok.Pos
+				open = open[:len(open)-1]
+			}
+		}
+		codeLines[tok.Pos.Line] = true
+		ix.tokens = append(ix.tokens, tok)
+	}
+
+	for _, c := range comments {
+		if codeLines[c.Pos.Line] {
+			continue
+		}
+		var lines []string
+		for _, line := range strings.Split(c.Literal, "\n") {
+			lines = append(lines, strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "*")))
+		}
+		ix.idx.comments[c.Pos.Line+len(lines)-1] = strings.TrimSpace(strings.Join(lines, "\n"))
+	}
+}
+
+// closer returns the position of the brace closing the brace at pos. Braces
+// that aren't closed extend to the end of the file.
+func (ix *indexer) closer(pos token.Position) token.Position {
+	if end, ok := ix.closers[pos]; ok {
+		return end
+	}
+	return endOfFile
+}
+
+// braceAfter returns the first opening brace after pos and its closing brace.
+func (ix *indexer) braceAfter(pos token.Position) (token.Position, token.Position) {
+	for _, tok := range ix.tokens {
+		if tok.Type == token.LBrace && before(pos, tok.Pos) {
+			return tok.Pos, ix.closer(tok.Pos)
+		}
+	}
+	return pos, pos
+}
+
+// nameAfter returns the position of the first identifier after pos.
+func (ix *indexer) nameAfter(pos token.Position) token.Position {
+	for _, tok := range ix.tokens {
+		if tok.Type == token.Identifier && before(pos, tok.Pos) {
+			return tok.Pos
+		}
+	}
+	return pos
+}
+
+func (ix *indexer) openScope(start, end token.Position, class bool) *scope {
+	s := &scope{
+		outer: ix.scope,
+		class: class,
+		decls: make(map[string]*symbol),
+	}
+	s.env = s
+	if !class {
+		s.rng = &scopeRange{start: start, end: end}
+		ix.idx.scopes = append(ix.idx.scopes, s.rng)
+	}
+	ix.scope = s
+	return s
+}
+
+// openCatchScope opens a scope that stores its declarations in the enclosing environment.
+func (ix *indexer) openCatchScope(start, end token.Position) {
+	s := ix.openScope(start, end, false)
+	s.env = s.outer.env
+}
+
+func (ix *indexer) closeScope() {
+	ix.scope = ix.scope.outer
+}
+
+func (ix *indexer) program(program *ast.Program) {
+	ix.openScope(token.Position{Line: 1, Col: 1}, endOfFile, false)
+	ix.hoist(program.Statements)
+	ix.statements(program.Statements)
+	ix.closeScope()
+
+	for _, stmt := range program.Statements {
+		if ret, ok := stmt.(*ast.ReturnStatement); ok {
+			ix.exports(ret.Value)
+		}
+	}
+}
+
+// exports records the keys of a map returned by the file.
+func (ix *indexer) exports(exp ast.Expression) {
+	hash, ok := exp.(*ast.HashLiteral)
+	if !ok {
+		return
+	}
+	for _, pair := range hash.Pairs {
+		key, ok := pair.Key.(*ast.StringLiteral)
+		if !ok {
+			continue
+		}
+		// Exported variables are found by the references in the map
+		if ident, ok := pair.Value.(*ast.Identifier); ok {
+			if ref := ix.idx.refAt(ident.Token.Pos); ref != nil && ref.sym != nil {
+				ix.idx.exports[key.Value] = ref.sym
+				continue
+			}
+		}
+
+		sym := &symbol{
+			name:  key.Value,
+			kind:  variableSymbol,
+			file:  ix.idx.file,
+			pos:   key.Token.Pos,
+			start: key.Token.Pos,
+			end:   key.Token.Pos,
+			value: pair.Value,
+		}
+		if _, ok := pair.Value.(*ast.FunctionLiteral); ok {
+			sym.kind = functionSymbol
+		}
+		ix.idx.exports[key.Value] = sym
+		ix.idx.decls = append(ix.idx.decls, sym)
+	}
+}
+
+// hoist declares the names declared in statements since functions can use
+// variables declared after them.
+func (ix *indexer) hoist(statements []ast.Statement) {
+	if ix.scope.class {
+		return
+	}
+	for _, stmt := range statements {
+		switch stmt := stmt.(type) {
+		case *ast.DefStatement:
+			if _, exists := ix.scope.env.decls[stmt.Name.Value]; !exists {
+				ix.declare(ix.scope.env, ix.defSymbol(stmt))
+			}
+			ix.hoistExpression(stmt.Value)
+		case *ast.ExpressionStatement:
+			ix.hoistExpression(stmt.Expression)
+		}
+	}
+}
+
+func (ix *indexer) hoistExpression(exp ast.Expression) {
+	switch exp := exp.(type) {
+	case *ast.AssignStatement:
+		ix.hoistExpression(exp.Value)
+	case *ast.IfExpression:
+		ix.hoistBlock(exp.Consequence)
+		ix.hoistBlock(exp.Alternative)
+	case *ast.TryCatchExpression:
+		ix.hoistBlock(exp.Try)
+		for _, clause := range exp.Catches {
+			ix.hoistBlock(clause.Body)
+		}
+		ix.hoistBlock(exp.Finally)
+	}
+}
+
+func (ix *indexer) hoistBlock(block *ast.BlockStatement) {
+	if block != nil {
+		ix.hoist(block.Statements)
+	}
+}
+
+// defSymbol makes the symbol of a let, always, func, or class declaration.
+func (ix *indexer) defSymbol(def *ast.DefStatement) *symbol {
+	sym := &symbol{
+		name:  def.Name.Value,
+		kind:  variableSymbol,
+		file:  ix.idx.file,
+		pos:   def.Name.Token.Pos,
+		start: def.Token.Pos,
+		end:   def.Name.Token.Pos,
+		value: def.Value,
+	}
+	if def.Const {
+		sym.kind = constantSymbol
+	}
+
+	switch value := def.Value.(type) {
+	case *ast.FunctionLiteral:
+		sym.kind = functionSymbol
+		if value.Body != nil {
+			sym.end = ix.closer(value.Body.Token.Pos)
+		}
+	case *ast.ClassLiteral:
+		sym.kind = classSymbol
+		_, sym.end = ix.braceAfter(value.Token.Pos)
+	case *ast.CallExpression:
+		ix.importedFrom(sym, value)
+	}
+
+	// Declarations made with func and class don't have a position, they start at the keyword
+	if sym.start.Line == 0 || before(sym.pos, sym.start) {
+		sym.start = ast.NodeToken(def.Value).Pos
+	}
+	return sym
+}
+
+// importedFrom sets the script or module sym is assigned from if call is
+// include(), require(), or module() with a string.
+func (ix *indexer) importedFrom(sym *symbol, call *ast.CallExpression) {
+	fn, ok := call.Function.(*ast.Identifier)
+	if !ok || len(call.Arguments) == 0 {
+		return
+	}
+	arg, ok := call.Arguments[0].(*ast.StringLiteral)
+	if !ok {
+		return
+	}
+
+	switch fn.Value {
+	case "include", "require":
+		sym.include = ix.includePath(arg.Value)
+	case "module":
+		sym.module = arg.Value
+	}
+}
+
+// includePath returns the path of a script included by the file. Paths are relative to the file.
+func (ix *indexer) includePath(path string) string {
+	if ix.idx.file.path == "" {
+		return ""
+	}
+	return filepath.Clean(filepath.Join(filepath.Dir(ix.idx.file.path), path))
+}
+
+// declare adds sym to scope s.
+func (ix *indexer) declare(s *scope, sym *symbol) {
+	s.decls[sym.name] = sym
+	ix.idx.decls = append(ix.idx.decls, sym)
+	sym.topLevel = s.outer == nil
+	if s.outer == nil {
+		ix.idx.topLevel = append(ix.idx.topLevel, sym)
+	}
+	if s.rng != nil {
+		s.rng.symbols = append(s.rng.symbols, sym)
+	}
+}
+
+// declareIdent declares a parameter, loop variable, or catch symbol in the current scope.
+func (ix *indexer) declareIdent(ident *ast.Identifier, kind symbolKind) {
+	if ident == nil {
+		return
+	}
+	sym := &symbol{
+		name:  ident.Value,
+		kind:  kind,
+		file:  ix.idx.file,
+		pos:   ident.Token.Pos,
+		start: ident.Token.Pos,
+		end:   ident.Token.Pos,
+	}
+	ix.declare(ix.scope, sym)
+	ix.idx.refs = append(ix.idx.refs, &reference{name: sym.name, pos: sym.pos, sym: sym, decl: true})
+}
+
+// lookup finds the declaration of name, class bodies are looked through.
+func (ix *indexer) lookup(name string) *symbol {
+	for s := ix.scope; s != nil; s = s.outer {
+		if sym, ok := s.decls[name]; ok {
+			return sym
+		}
+	}
+	return nil
+}
+
+func (ix *indexer) use(ident *ast.Identifier) *reference {
+	ref := &reference{name: ident.Value, pos: ident.Token.Pos, sym: ix.lookup(ident.Value)}
+	ix.idx.refs = append(ix.idx.refs, ref)
+	return ref
+}
+
+func (ix *indexer) statements(statements []ast.Statement) {
+	for _, stmt := range statements {
+		ix.statement(stmt)
+	}
+}
+
+func (ix *indexer) block(block *ast.BlockStatement) {
+	if block != nil {
+		ix.statements(block.Statements)
+	}
+}
+
+func (ix *indexer) statement(stmt ast.Statement) {
+	switch stmt := stmt.(type) {
+	case *ast.DefStatement:
+		ix.define(stmt)
+	case *ast.ReturnStatement:
+		ix.expression(stmt.Value)
+	case *ast.ExpressionStatement:
+		ix.expression(stmt.Expression)
+	case *ast.ThrowStatement:
+		ix.expression(stmt.Expression)
+	case *ast.BlockStatement:
+		ix.block(stmt)
+	case *ast.ForLoopStatement:
+		ix.forLoop(stmt)
+	}
+}
+
+func (ix *indexer) define(def *ast.DefStatement) {
+	var sym *symbol
+	if !ix.scope.class {
+		sym = ix.scope.env.decls[def.Name.Value]
+		if sym == nil {
+			// Declarations in expressions aren't hoisted
+			sym = ix.defSymbol(def)
+			ix.declare(ix.scope.env, sym)
+		}
+	}
+
+	switch value := def.Value.(type) {
+	case *ast.FunctionLiteral:
+		ix.function(value)
+	case *ast.ClassLiteral:
+		ix.class(value, sym)
+	default:
+		ix.expression(def.Value)
+	}
+
+	if sym != nil {
+		// Later declarations of a name in the same environment refer to the first one
+		ix.idx.refs = append(ix.idx.refs, &reference{
+			name: def.Name.Value,
+			pos:  def.Name.Token.Pos,
+			sym:  sym,
+			decl: sym.pos == def.Name.Token.Pos,
+		})
+	}
+}
+
+func (ix *indexer) forLoop(loop *ast.ForLoopStatement) {
+	start := loop.Token.Pos
+	end := start
+	if loop.Body != nil {
+		end = ix.closer(loop.Body.Token.Pos)
+	}
+
+	if loop.Collection != nil {
+		ix.expression(loop.Collection)
+
+		ix.openScope(start, end, false)
+		ix.declareIdent(loop.Key, variableSymbol)
+		ix.declareIdent(loop.Value, variableSymbol)
+		ix.hoistBlock(loop.Body)
+		ix.block(loop.Body)
+		ix.closeScope()
+		return
+	}
+
+	ix.openScope(start, end, false)
+	if loop.Init != nil {
+		ix.hoist([]ast.Statement{loop.Init})
+		ix.statement(loop.Init)
+	}
+	ix.expression(loop.Condition)
+
+	ix.openScope(start, end, false)
+	ix.hoistBlock(loop.Body)
+	ix.block(loop.Body)
+	ix.closeScope()
+
+	ix.expression(loop.Iter)
+	ix.closeScope()
+}
+
+func (ix *indexer) function(fn *ast.FunctionLiteral) {
+	end := fn.Token.Pos
+	if fn.Body != nil {
+		end = ix.closer(fn.Body.Token.Pos)
+	}
+
+	ix.openScope(fn.Token.Pos, end, false)
+	for _, param := range fn.Parameters {
+		ix.declareIdent(param, parameterSymbol)
+	}
+	ix.hoistBlock(fn.Body)
+	ix.block(fn.Body)
+	ix.closeScope()
+}
+
+// class indexes a class literal, sym is the symbol it's declared as if any.
+func (ix *indexer) class(class *ast.ClassLiteral, sym *symbol) {
+	if class.Parent != "" {
+		ix.use(&ast.Identifier{
+			Token: token.Token{Pos: ix.nameAfter(ix.caretAfter(class.Token.Pos))},
+			Value: class.Parent,
+		})
+	}
+
+	s := ix.openScope(class.Token.Pos, class.Token.Pos, true)
+	var members []*symbol
+	for _, field := range class.Fields {
+		member := &symbol{
+			name:  field.Name.Value,
+			kind:  fieldSymbol,
+			file:  ix.idx.file,
+			pos:   field.Name.Token.Pos,
+			start: field.Token.Pos,
+			end:   field.Name.Token.Pos,
+			value: field.Value,
+		}
+		s.decls[member.name] = member
+		members = append(members, member)
+	}
+	for name, method := range class.Methods {
+		member := &symbol{
+			name:  name,
+			kind:  methodSymbol,
+			file:  ix.idx.file,
+			pos:   ix.nameAfter(method.Token.Pos),
+			start: method.Token.Pos,
+			end:   method.Token.Pos,
+			value: method,
+		}
+		if method.Body != nil {
+			member.end = ix.closer(method.Body.Token.Pos)
+		}
+		s.decls[name] = member
+		members = append(members, member)
+	}
+	sortSymbols(members)
+	ix.idx.decls = append(ix.idx.decls, members...)
+	if sym != nil {
+		sym.members = members
+	}
+
+	for _, field := range class.Fields {
+		ix.expression(field.Value)
+	}
+	for _, method := range class.Methods {
+		ix.function(method)
+	}
+	ix.closeScope()
+}
+
+// caretAfter returns the position of the ^ before a parent class name.
+func (ix *indexer) caretAfter(pos token.Position) token.Position {
+	for _, tok := range ix.tokens {
+		if tok.Type == token.Carrot && before(pos, tok.Pos) {
+			return tok.Pos
+		}
+	}
+	return pos
+}
+
+func (ix *indexer) expressions(exps []ast.Expression) {
+	for _, exp := range exps {
+		ix.expression(exp)
+	}
+}
+
+func (ix *indexer) expression(exp ast.Expression) {
+	switch exp := exp.(type) {
+	case *ast.Identifier:
+		ix.use(exp)
+	case *ast.AssignStatement:
+		ix.expression(exp.Left)
+		ix.expression(exp.Value)
+	case *ast.PrefixExpression:
+		ix.expression(exp.Right)
+	case *ast.InfixExpression:
+		ix.expression(exp.Left)
+		ix.expression(exp.Right)
+	case *ast.CompareExpression:
+		ix.expression(exp.Left)
+		ix.expression(exp.Right)
+	case *ast.IfExpression:
+		ix.expression(exp.Condition)
+		ix.block(exp.Consequence)
+		ix.block(exp.Alternative)
+	case *ast.CallExpression:
+		ix.call(exp)
+	case *ast.IndexExpression:
+		ix.index(exp)
+	case *ast.SliceExpression:
+		ix.expression(exp.Left)
+		ix.expression(exp.Low)
+		ix.expression(exp.High)
+	case *ast.TryCatchExpression:
+		ix.tryCatch(exp)
+	case *ast.MakeInstance:
+		ix.expression(exp.Class)
+		ix.expressions(exp.Arguments)
+	case *ast.FunctionLiteral:
+		ix.function(exp)
+	case *ast.ClassLiteral:
+		ix.class(exp, nil)
+	case *ast.Array:
+		ix.expressions(exp.Elements)
+	case *ast.InterpolatedString:
+		ix.expressions(exp.Parts)
+	case *ast.HashLiteral:
+		for _, pair := range exp.Pairs {
+			ix.expression(pair.Key)
+			ix.expression(pair.Value)
+		}
+	}
+}
+
+func (ix *indexer) call(call *ast.CallExpression) {
+	ix.expression(call.Function)
+	ix.expressions(call.Arguments)
+
+	fn, ok := call.Function.(*ast.Identifier)
+	if !ok || fn.Value != "include" && fn.Value != "require" || len(call.Arguments) == 0 {
+		return
+	}
+	if arg, ok := call.Arguments[0].(*ast.StringLiteral); ok {
+		if path := ix.includePath(arg.Value); path != "" {
+			ix.idx.includes = append(ix.idx.includes, &includeCall{path: path, pos: fn.Token.Pos})
+		}
+	}
+}
+
+// index records names after a dot or arrow that follow an identifier.
+func (ix *indexer) index(exp *ast.IndexExpression) {
+	ident, ok := exp.Left.(*ast.Identifier)
+	name, isString := exp.Index.(*ast.StringLiteral)
+	if !ok || !isString || exp.Token.Type != token.Arrow {
+		ix.expression(exp.Left)
+		ix.expression(exp.Index)
+		return
+	}
+
+	base := ix.use(ident)
+	ix.idx.members = append(ix.idx.members, &member{name: name.Value, pos: name.Token.Pos, base: base})
+}
+
+func (ix *indexer) tryCatch(exp *ast.TryCatchExpression) {
+	ix.block(exp.Try)
+	for _, clause := range exp.Catches {
+		ix.expression(clause.Class)
+		end := clause.Token.Pos
+		if clause.Body != nil {
+			end = ix.closer(clause.Body.Token.Pos)
+		}
+		ix.openCatchScope(clause.Token.Pos, end)
+		ix.declareIdent(clause.Symbol, variableSymbol)
+		ix.block(clause.Body)
+		ix.closeScope()
+	}
+	ix.block(exp.Finally)
+}
@@ -0,0 +1,264 @@
+// Package lsp implements a Language Server Protocol server for Nitrogen.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"regexp"
+	"strconv"
+
+	"github.com/nitrogen-lang/nitrogen/src/token"
+	"github.com/nitrogen-lang/nitrogen/src/vet"
+)
+
+// Server is a language server that reads requests from one stream and writes
+// responses to another.
+type Server struct {
+	in  *bufio.Reader
+	out io.Writer
+	// Log receives a line for each message and for errors. Logging is off by default.
+	Log *log.Logger
+
+	ws          *workspace
+	initialized bool
+	shutdown    bool
+	// lastGood is the index of each open document the last time it parsed
+	// without errors. Completion uses it while the document is being edited.
+	lastGood map[string]*index
+}
+
+type handler func(s *Server, params json.RawMessage) (interface{}, error)
+
+var requests = map[string]handler{
+	"initialize":                  (*Server).initialize,
+	"shutdown":                    (*Server).shutdownRequest,
+	"textDocument/definition":     (*Server).definition,
+	"textDocument/references":     (*Server).references,
+	"textDocument/hover":          (*Server).hover,
+	"textDocument/completion":     (*Server).completion,
+	"textDocument/documentSymbol": (*Server).documentSymbol,
+}
+
+var notifications = map[string]func(s *Server, params json.RawMessage) error{
+	"textDocument/didOpen":   (*Server).didOpen,
+	"textDocument/didChange": (*Server).didChange,
+	"textDocument/didClose":  (*Server).didClose,
+}
+
+// NewServer returns a server reading messages from in and writing to out.
+func NewServer(in io.Reader, out io.Writer) *Server {
+	return &Server{
+		in:       bufio.NewReader(in),
+		out:      out,
+		Log:      log.New(ioutil.Discard, "", 0),
+		ws:       newWorkspace(),
+		lastGood: make(map[string]*index),
+	}
+}
+
+// Run handles messages until the exit notification or the end of input. It
+// returns an error if the input ends or exit is sent before shutdown.
+func (s *Server) Run() error {
+	for {
+		content, err := readMessage(s.in)
+		if err == io.EOF {
+			return fmt.Errorf("input closed without exit notification")
+		}
+		if err != nil {
+			return err
+		}
+
+		var req request
+		if err := json.Unmarshal(content, &req); err != nil {
+			s.Log.Printf("invalid message: %s", err)
+			s.replyError(nil, &rpcError{Code: codeParseError, Message: err.Error()})
+			continue
+		}
+		s.Log.Printf("<- %s", req.Method)
+
+		if req.Method == "exit" {
+			if !s.shutdown {
+				return fmt.Errorf("exit without shutdown")
+			}
+			return nil
+		}
+		if err := s.handle(&req); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(req *request) error {
+	if req.ID == nil {
+		fn, ok := notifications[req.Method]
+		if !ok || !s.initialized {
+			// Unknown notifications are ignored
+			return nil
+		}
+		if err := fn(s, req.Params); err != nil {
+			s.Log.Printf("%s: %s", req.Method, err)
+		}
+		return nil
+	}
+
+	fn, ok := requests[req.Method]
+	if !ok {
+		return s.replyError(req.ID, &rpcError{Code: codeMethodNotFound, Message: "method not found: " + req.Method})
+	}
+	if !s.initialized && req.Method != "initialize" {
+		return s.replyError(req.ID, &rpcError{Code: codeServerNotInitialized, Message: "server not initialized"})
+	}
+
+	result, err := fn(s, req.Params)
+	if err != nil {
+		rpcErr, ok := err.(*rpcError)
+		if !ok {
+			rpcErr = &rpcError{Code: codeInternalError, Message: err.Error()}
+		}
+		return s.replyError(req.ID, rpcErr)
+	}
+	return s.send(&response{JSONRPC: "2.0", ID: req.ID, Result: result})
+}
+
+func (s *Server) send(v interface{}) error {
+	return writeMessage(s.out, v)
+}
+
+func (s *Server) replyError(id *json.RawMessage, err *rpcError) error {
+	s.Log.Printf("-> error: %s", err.Message)
+	return s.send(&errorResponse{JSONRPC: "2.0", ID: id, Error: err})
+}
+
+func (s *Server) notify(method string, params interface{}) error {
+	return s.send(&notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+func unmarshalParams(params json.RawMessage, v interface{}) error {
+	if err := json.Unmarshal(params, v); err != nil {
+		return &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+func (s *Server) initialize(params json.RawMessage) (interface{}, error) {
+	var p InitializeParams
+	if err := unmarshalParams(params, &p); err != nil {
+		return nil, err
+	}
+	s.ws.root = p.RootPath
+	if p.RootURI != "" {
+		s.ws.root = uriToPath(p.RootURI)
+	}
+	s.initialized = true
+
+	return map[string]interface{}{
+		"capabilities": map[string]interface{}{
+			// Documents are sent in full on each change
+			"textDocumentSync":       1,
+			"definitionProvider":     true,
+			"referencesProvider":     true,
+			"hoverProvider":          true,
+			"documentSymbolProvider": true,
+			"completionProvider": map[string]interface{}{
+				"triggerCharacters": []string{".", ">", `"`},
+			},
+		},
+		"serverInfo": map[string]string{"name": "nitrogen"},
+	}, nil
+}
+
+func (s *Server) shutdownRequest(params json.RawMessage) (interface{}, error) {
+	s.shutdown = true
+	return nil, nil
+}
+
+func (s *Server) didOpen(params json.RawMessage) error {
+	var p DidOpenTextDocumentParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return err
+	}
+	return s.updateDocument(p.TextDocument.URI, p.TextDocument.Text)
+}
+
+func (s *Server) didChange(params json.RawMessage) error {
+	var p DidChangeTextDocumentParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return err
+	}
+	if len(p.ContentChanges) == 0 {
+		return nil
+	}
+	return s.updateDocument(p.TextDocument.URI, p.ContentChanges[len(p.ContentChanges)-1].Text)
+}
+
+func (s *Server) didClose(params json.RawMessage) error {
+	var p DidCloseTextDocumentParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return err
+	}
+	s.ws.close(p.TextDocument.URI)
+	delete(s.lastGood, p.TextDocument.URI)
+	return s.notify("textDocument/publishDiagnostics", &PublishDiagnosticsParams{
+		URI:         p.TextDocument.URI,
+		Diagnostics: []Diagnostic{},
+	})
+}
+
+func (s *Server) updateDocument(uri, text string) error {
+	f := s.ws.update(uri, text)
+	if len(f.errors) == 0 {
+		s.lastGood[uri] = f.index
+	}
+	return s.notify("textDocument/publishDiagnostics", &PublishDiagnosticsParams{
+		URI:         uri,
+		Diagnostics: s.diagnostics(f),
+	})
+}
+
+var parseErrorPattern = regexp.MustCompile(`^at line (\d+), col (\d+) (.*)$`)
+
+// diagnostics returns the parse errors of f, or the problems vet finds if it
+// parses. Names vet reports as undefined are left out if they're declared
+// where f is included.
+func (s *Server) diagnostics(f *file) []Diagnostic {
+	diags := []Diagnostic{}
+	for _, msg := range f.errors {
+		d := Diagnostic{Severity: severityError, Source: "nitrogen", Message: msg}
+		if m := parseErrorPattern.FindStringSubmatch(msg); m != nil {
+			line, _ := strconv.Atoi(m[1])
+			col, _ := strconv.Atoi(m[2])
+			pos := f.toLSP(token.Position{Line: line, Col: col})
+			d.Range = Range{Start: pos, End: pos}
+			d.Message = m[3]
+		}
+		diags = append(diags, d)
+	}
+	if len(diags) > 0 {
+		return diags
+	}
+
+	problems, err := vet.Source(f.path, []byte(f.text))
+	if err != nil {
+		return diags
+	}
+	for _, p := range problems {
+		if p.Check == vet.CheckUndefined {
+			if ref := f.index.refAt(token.Position{Line: p.Line, Col: p.Col}); ref != nil && s.ws.resolveRef(f, ref) != nil {
+				continue
+			}
+		}
+		pos := f.toLSP(token.Position{Line: p.Line, Col: p.Col})
+		diags = append(diags, Diagnostic{
+			Range:    Range{Start: pos, End: pos},
+			Severity: severityWarning,
+			Code:     p.Check,
+			Source:   "nitrogen vet",
+			Message:  p.Message,
+		})
+	}
+	return diags
+}
@@ -0,0 +1,469 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	_ "github.com/nitrogen-lang/nitrogen/src/builtins/imports"
+	_ "github.com/nitrogen-lang/nitrogen/src/builtins/io"
+	_ "github.com/nitrogen-lang/nitrogen/src/builtins/json"
+)
+
+// client sends messages to a server over pipes like an editor would.
+type client struct {
+	t  *testing.T
+	in *io.PipeWriter
+	// messages are read from the server as they're sent so neither side blocks writing.
+	messages chan []byte
+	nextID   int
+	done     chan error
+	// diagnostics are the last diagnostics published for each document.
+	diagnostics map[string][]Diagnostic
+}
+
+func newClient(t *testing.T) *client {
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+
+	c := &client{
+		t:           t,
+		in:          clientOut,
+		messages:    make(chan []byte, 100),
+		done:        make(chan error, 1),
+		diagnostics: make(map[string][]Diagnostic),
+	}
+	go func() {
+		err := NewServer(serverIn, serverOut).Run()
+		serverOut.Close()
+		c.done <- err
+	}()
+	go func() {
+		out := bufio.NewReader(clientIn)
+		for {
+			content, err := readMessage(out)
+			if err != nil {
+				close(c.messages)
+				return
+			}
+			c.messages <- content
+		}
+	}()
+	return c
+}
+
+func (c *client) write(v interface{}) {
+	c.t.Helper()
+	if err := writeMessage(c.in, v); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// message reads a message and records it if it publishes diagnostics.
+func (c *client) message() map[string]json.RawMessage {
+	c.t.Helper()
+	content, ok := <-c.messages
+	if !ok {
+		c.t.Fatal("server closed its output")
+	}
+	var msg map[string]json.RawMessage
+	if err := json.Unmarshal(content, &msg); err != nil {
+		c.t.Fatal(err)
+	}
+
+	if string(msg["method"]) == `"textDocument/publishDiagnostics"` {
+		var p PublishDiagnosticsParams
+		json.Unmarshal(msg["params"], &p)
+		c.diagnostics[p.URI] = p.Diagnostics
+	}
+	return msg
+}
+
+// call sends a request and decodes the result into result. It returns the error of the response.
+func (c *client) call(method string, params, result interface{}) *rpcError {
+	c.t.Helper()
+	c.nextID++
+	c.write(map[string]interface{}{"jsonrpc": "2.0", "id": c.nextID, "method": method, "params": params})
+
+	for {
+		msg := c.message()
+		if _, ok := msg["id"]; !ok {
+			continue
+		}
+		if raw, ok := msg["error"]; ok {
+			var err rpcError
+			json.Unmarshal(raw, &err)
+			return &err
+		}
+		if result != nil {
+			if err := json.Unmarshal(msg["result"], result); err != nil {
+				c.t.Fatal(err)
+			}
+		}
+		return nil
+	}
+}
+
+func (c *client) notify(method string, params interface{}) {
+	c.t.Helper()
+	c.write(map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params})
+}
+
+func (c *client) initialize(root string) {
+	c.t.Helper()
+	if err := c.call("initialize", map[string]string{"rootUri": pathToURI(root)}, nil); err != nil {
+		c.t.Fatal(err)
+	}
+	c.notify("initialized", struct{}{})
+}
+
+// open opens a document and waits for its diagnostics.
+func (c *client) open(uri, text string) []Diagnostic {
+	c.t.Helper()
+	c.notify("textDocument/didOpen", DidOpenTextDocumentParams{
+		TextDocument: TextDocumentItem{URI: uri, LanguageID: "nitrogen", Version: 1, Text: text},
+	})
+	for {
+		msg := c.message()
+		if string(msg["method"]) == `"textDocument/publishDiagnostics"` {
+			return c.diagnostics[uri]
+		}
+	}
+}
+
+// openFile opens a file in testdata.
+func (c *client) openFile(name string) string {
+	c.t.Helper()
+	path, _ := filepath.Abs(filepath.Join("testdata", name))
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	uri := pathToURI(path)
+	c.open(uri, string(src))
+	return uri
+}
+
+func (c *client) close() {
+	c.t.Helper()
+	if err := c.call("shutdown", nil, nil); err != nil {
+		c.t.Fatal(err)
+	}
+	c.notify("exit", nil)
+	if err := <-c.done; err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func positionParams(uri string, line, char int) TextDocumentPositionParams {
+	return TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     Position{Line: line, Character: char},
+	}
+}
+
+func testClient(t *testing.T) (*client, string, string) {
+	root, _ := filepath.Abs("testdata")
+	c := newClient(t)
+	c.initialize(root)
+	return c, c.openFile("main.ni"), c.openFile("lib.ni")
+}
+
+func TestDiagnostics(t *testing.T) {
+	c, _, libURI := testClient(t)
+
+	// offset is declared in main.ni, which includes lib.ni
+	if diags := c.diagnostics[libURI]; len(diags) != 0 {
+		t.Errorf("Expected no diagnostics for lib.ni, got %v", diags)
+	}
+
+	diags := c.open("file:///test/error.ni", "let a = 1\nlet b = (a")
+	if len(diags) != 1 {
+		t.Fatalf("Expected 1 diagnostic, got %v", diags)
+	}
+	if diags[0].Severity != severityError || diags[0].Range.Start.Line != 1 {
+		t.Errorf("Wrong parse error diagnostic %+v", diags[0])
+	}
+
+	diags = c.open("file:///test/vet.ni", "func f() {\n    let unused = 1\n}\nprintln(missing)")
+	if len(diags) != 2 {
+		t.Fatalf("Expected 2 diagnostics, got %v", diags)
+	}
+	expected := []struct {
+		code      string
+		line, col int
+	}{
+		{"unused", 1, 8},
+		{"undefined", 3, 8},
+	}
+	for i, e := range expected {
+		d := diags[i]
+		if d.Severity != severityWarning || d.Code != e.code || d.Range.Start != (Position{Line: e.line, Character: e.col}) {
+			t.Errorf("Expected %s warning at %d:%d, got %+v", e.code, e.line, e.col, d)
+		}
+	}
+
+	// Fixing the document clears its diagnostics
+	c.notify("textDocument/didChange", DidChangeTextDocumentParams{
+		TextDocument:   VersionedTextDocumentIdentifier{URI: "file:///test/vet.ni", Version: 2},
+		ContentChanges: []TextDocumentContentChangeEvent{{Text: "println(1)"}},
+	})
+	c.close()
+	if diags := c.diagnostics["file:///test/vet.ni"]; len(diags) != 0 {
+		t.Errorf("Expected diagnostics to be cleared, got %v", diags)
+	}
+}
+
+func TestDefinition(t *testing.T) {
+	c, mainURI, libURI := testClient(t)
+	defer c.close()
+
+	tests := []struct {
+		uri       string
+		line, col int
+		expected  Location
+	}{
+		// lib->add in main.ni
+		{mainURI, 13, 11, Location{libURI, Range{Position{1, 5}, Position{1, 8}}}},
+		// offset in lib.ni is declared in main.ni
+		{libURI, 8, 32, Location{mainURI, Range{Position{0, 4}, Position{0, 10}}}},
+		// The method of an instance
+		{mainURI, 13, 3, Location{mainURI, Range{Position{7, 9}, Position{7, 13}}}},
+		// A field used in a method
+		{mainURI, 8, 13, Location{mainURI, Range{Position{5, 8}, Position{5, 9}}}},
+		// A parameter
+		{libURI, 2, 11, Location{libURI, Range{Position{1, 9}, Position{1, 10}}}},
+		// A class
+		{mainURI, 12, 14, Location{mainURI, Range{Position{4, 6}, Position{4, 11}}}},
+	}
+
+	for _, test := range tests {
+		var locs []Location
+		if err := c.call("textDocument/definition", positionParams(test.uri, test.line, test.col), &locs); err != nil {
+			t.Fatal(err)
+		}
+		if len(locs) != 1 || locs[0] != test.expected {
+			t.Errorf("Definition at %s %d:%d: expected %v, got %v", test.uri, test.line, test.col, test.expected, locs)
+		}
+	}
+
+	var locs []Location
+	c.call("textDocument/definition", positionParams(mainURI, 14, 2), &locs)
+	if len(locs) != 0 {
+		t.Errorf("Expected no definition for a builtin, got %v", locs)
+	}
+}
+
+func TestReferences(t *testing.T) {
+	c, mainURI, libURI := testClient(t)
+	defer c.close()
+
+	params := ReferenceParams{TextDocumentPositionParams: positionParams(libURI, 1, 6)}
+	params.Context.IncludeDeclaration = true
+	var locs []Location
+	if err := c.call("textDocument/references", params, &locs); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Location{
+		{libURI, Range{Position{1, 5}, Position{1, 8}}},
+		{libURI, Range{Position{8, 11}, Position{8, 14}}},
+		{libURI, Range{Position{12, 11}, Position{12, 14}}},
+		{mainURI, Range{Position{13, 11}, Position{13, 14}}},
+	}
+	if len(locs) != len(expected) {
+		t.Fatalf("Expected %d references, got %v", len(expected), locs)
+	}
+	for i, loc := range expected {
+		if locs[i] != loc {
+			t.Errorf("Reference %d: expected %v, got %v", i, loc, locs[i])
+		}
+	}
+
+	params = ReferenceParams{TextDocumentPositionParams: positionParams(mainURI, 0, 4)}
+	c.call("textDocument/references", params, &locs)
+	if len(locs) != 2 || locs[0].URI != libURI || locs[1].URI != mainURI {
+		t.Errorf("Wrong references of offset without its declaration %v", locs)
+	}
+}
+
+func TestHover(t *testing.T) {
+	c, mainURI, libURI := testClient(t)
+	defer c.close()
+
+	tests := []struct {
+		uri       string
+		line, col int
+		expected  string
+	}{
+		{mainURI, 13, 12, "```nitrogen\nfunc add(a, b)\n```\nadd returns the sum of a and b."},
+		{libURI, 8, 24, "```nitrogen\nalways scale\n```"},
+		{mainURI, 4, 7, "```nitrogen\nclass Point\n```"},
+		{mainURI, 7, 11, "```nitrogen\nfunc move(dx)\n```"},
+		{mainURI, 14, 2, "```nitrogen\nfunc println\n```\nbuiltin function"},
+		{mainURI, 15, 15, "```nitrogen\nfunc json.encode\n```\nfunction of module json"},
+	}
+
+	for _, test := range tests {
+		var hover *Hover
+		if err := c.call("textDocument/hover", positionParams(test.uri, test.line, test.col), &hover); err != nil {
+			t.Fatal(err)
+		}
+		if hover == nil {
+			t.Errorf("No hover at %d:%d", test.line, test.col)
+			continue
+		}
+		if hover.Contents.Value != test.expected {
+			t.Errorf("Hover at %d:%d: expected %q, got %q", test.line, test.col, test.expected, hover.Contents.Value)
+		}
+	}
+
+	var hover *Hover
+	c.call("textDocument/hover", positionParams(mainURI, 9, 0), &hover)
+	if hover != nil {
+		t.Errorf("Expected no hover outside names, got %v", hover)
+	}
+}
+
+func labels(items []CompletionItem) string {
+	names := make([]string, len(items))
+	for i, item := range items {
+		names[i] = item.Label
+	}
+	return strings.Join(names, " ")
+}
+
+func TestCompletion(t *testing.T) {
+	c, _, _ := testClient(t)
+	defer c.close()
+
+	uri := pathToURI(filepath.Join(mustAbs("testdata"), "complete.ni"))
+	complete := func(text string) []CompletionItem {
+		t.Helper()
+		c.open(uri, text)
+		lines := strings.Split(text, "\n")
+		var items []CompletionItem
+		if err := c.call("textDocument/completion", positionParams(uri, len(lines)-1, len(lines[len(lines)-1])), &items); err != nil {
+			t.Fatal(err)
+		}
+		return items
+	}
+
+	items := complete(`let j = module("js`)
+	if labels(items) != "json" {
+		t.Errorf("Expected module names, got %q", labels(items))
+	}
+
+	items = complete("let j = module(\"json\")\nj.")
+	if found := labels(items); !strings.HasPrefix(found, "decode ") || !strings.Contains(found, " encode ") {
+		t.Errorf("Expected module members, got %q", labels(items))
+	}
+
+	items = complete("let config = {\"name\": 1, \"debug\": true}\nconfig->d")
+	if labels(items) != "debug" {
+		t.Errorf("Expected map keys, got %q", labels(items))
+	}
+
+	items = complete("let lib = require(\"lib.ni\")\nlib.")
+	if labels(items) != "add scaled" {
+		t.Errorf("Expected exports, got %q", labels(items))
+	}
+
+	items = complete("let printer = 1\nfunc f(prefix) {\n    pr")
+	found := labels(items)
+	for _, name := range []string{"prefix", "printer", "println", "print"} {
+		if !strings.Contains(" "+found+" ", " "+name+" ") {
+			t.Errorf("Expected %s in completions %q", name, found)
+		}
+	}
+	for _, item := range items {
+		if item.Label == "prefix" && item.Kind != completionVariable {
+			t.Errorf("Wrong kind for parameter %+v", item)
+		}
+		if item.Label == "println" && item.Kind != completionFunction {
+			t.Errorf("Wrong kind for builtin %+v", item)
+		}
+	}
+
+	items = complete("ret")
+	if labels(items) != "return" {
+		t.Errorf("Expected keywords, got %q", labels(items))
+	}
+
+	// Members of the last good parse are completed while typing
+	c.open(uri, "let point = {\"x\": 1, \"y\": 2}\n")
+	items = complete("let point = {\"x\": 1, \"y\": 2}\nlet a = point.")
+	if labels(items) != "x y" {
+		t.Errorf("Expected keys while the document has errors, got %q", labels(items))
+	}
+}
+
+func mustAbs(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		panic(err)
+	}
+	return abs
+}
+
+func TestDocumentSymbol(t *testing.T) {
+	c, mainURI, _ := testClient(t)
+	defer c.close()
+
+	var symbols []DocumentSymbol
+	if err := c.call("textDocument/documentSymbol", DocumentSymbolParams{TextDocument: TextDocumentIdentifier{URI: mainURI}}, &symbols); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		name string
+		kind int
+	}{
+		{"offset", symbolVariable},
+		{"lib", symbolVariable},
+		{"json", symbolVariable},
+		{"Point", symbolClass},
+		{"p", symbolVariable},
+	}
+	if len(symbols) != len(expected) {
+		t.Fatalf("Expected %d symbols, got %v", len(expected), symbols)
+	}
+	for i, e := range expected {
+		if symbols[i].Name != e.name || symbols[i].Kind != e.kind {
+			t.Errorf("Symbol %d: expected %s, got %+v", i, e.name, symbols[i])
+		}
+	}
+
+	point := symbols[3]
+	if point.Range != (Range{Position{4, 0}, Position{10, 1}}) {
+		t.Errorf("Wrong class range %v", point.Range)
+	}
+	if len(point.Children) != 2 || point.Children[0].Name != "x" || point.Children[0].Kind != symbolField ||
+		point.Children[1].Name != "move" || point.Children[1].Kind != symbolMethod {
+		t.Errorf("Wrong class members %+v", point.Children)
+	}
+}
+
+func TestProtocol(t *testing.T) {
+	c := newClient(t)
+
+	if err := c.call("textDocument/hover", positionParams("file:///a.ni", 0, 0), nil); err == nil || err.Code != codeServerNotInitialized {
+		t.Errorf("Expected not initialized error, got %v", err)
+	}
+	c.initialize("")
+	if err := c.call("workspace/unknown", nil, nil); err == nil || err.Code != codeMethodNotFound {
+		t.Errorf("Expected method not found error, got %v", err)
+	}
+	if err := c.call("textDocument/hover", positionParams("file:///a.ni", 0, 0), nil); err == nil || err.Code != codeInvalidParams {
+		t.Errorf("Expected invalid params error for a closed document, got %v", err)
+	}
+
+	c.notify("exit", nil)
+	if err := <-c.done; err == nil {
+		t.Error("Expected an error for exit without shutdown")
+	}
+}
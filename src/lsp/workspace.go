@@ -0,0 +1,339 @@
+package lsp
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/nitrogen-lang/nitrogen/src/ast"
+	"github.com/nitrogen-lang/nitrogen/src/lexer"
+	"github.com/nitrogen-lang/nitrogen/src/parser"
+	"github.com/nitrogen-lang/nitrogen/src/token"
+)
+
+// file is an open document or a script read from disk.
+type file struct {
+	uri  string
+	path string
+	text string
+	// lines are the lines of text as runes, lexer columns count runes.
+	lines   [][]rune
+	program *ast.Program
+	errors  []string
+	index   *index
+}
+
+func newFile(uri, text string) *file {
+	f := &file{
+		uri:  uri,
+		path: uriToPath(uri),
+		text: text,
+	}
+	for _, line := range strings.Split(text, "\n") {
+		f.lines = append(f.lines, []rune(strings.TrimSuffix(line, "\r")))
+	}
+
+	p := parser.New(lexer.NewString(text), nil)
+	f.program = p.ParseProgram()
+	f.program.Filename = f.path
+	f.errors = p.Errors()
+	f.index = newIndex(f, f.program, text)
+	return f
+}
+
+// toLSP converts a lexer position to a protocol position, which counts UTF-16 code units from 0.
+func (f *file) toLSP(pos token.Position) Position {
+	line := pos.Line - 1
+	if line < 0 {
+		return Position{}
+	}
+	if line >= len(f.lines) {
+		last := len(f.lines) - 1
+		return Position{Line: last, Character: len(utf16.Encode(f.lines[last]))}
+	}
+	col := pos.Col - 1
+	if col > len(f.lines[line]) {
+		col = len(f.lines[line])
+	}
+	if col < 0 {
+		col = 0
+	}
+	return Position{Line: line, Character: len(utf16.Encode(f.lines[line][:col]))}
+}
+
+// fromLSP converts a protocol position to a lexer position.
+func (f *file) fromLSP(pos Position) token.Position {
+	if pos.Line < 0 || pos.Line >= len(f.lines) {
+		return token.Position{Line: pos.Line + 1, Col: pos.Character + 1}
+	}
+	units := 0
+	col := 0
+	for _, r := range f.lines[pos.Line] {
+		if units >= pos.Character {
+			break
+		}
+		units += len(utf16.Encode([]rune{r}))
+		col++
+	}
+	return token.Position{Line: pos.Line + 1, Col: col + 1}
+}
+
+// nameRange returns the range of name starting at pos.
+func (f *file) nameRange(pos token.Position, name string) Range {
+	end := pos
+	end.Col += len([]rune(name))
+	return Range{Start: f.toLSP(pos), End: f.toLSP(end)}
+}
+
+func (f *file) location(pos token.Position, name string) Location {
+	return Location{URI: f.uri, Range: f.nameRange(pos, name)}
+}
+
+// linePrefix returns the text of the line pos is on before pos.
+func (f *file) linePrefix(pos token.Position) string {
+	line := pos.Line - 1
+	if line < 0 || line >= len(f.lines) {
+		return ""
+	}
+	col := pos.Col - 1
+	if col > len(f.lines[line]) {
+		col = len(f.lines[line])
+	}
+	return string(f.lines[line][:col])
+}
+
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return ""
+	}
+	return filepath.Clean(filepath.FromSlash(u.Path))
+}
+
+func pathToURI(path string) string {
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(path)}
+	return u.String()
+}
+
+// workspace holds the files the server knows about. Open documents are used
+// instead of the copy on disk.
+type workspace struct {
+	root string
+	open map[string]*file
+	// disk caches the scripts read from disk by path.
+	disk map[string]*diskFile
+	// known caches the result of files until a document changes.
+	known []*file
+}
+
+type diskFile struct {
+	modTime int64
+	file    *file
+}
+
+func newWorkspace() *workspace {
+	return &workspace{
+		open: make(map[string]*file),
+		disk: make(map[string]*diskFile),
+	}
+}
+
+func (w *workspace) update(uri, text string) *file {
+	f := newFile(uri, text)
+	w.open[uri] = f
+	w.known = nil
+	return f
+}
+
+func (w *workspace) close(uri string) {
+	delete(w.open, uri)
+	w.known = nil
+}
+
+// load returns the file at path, nil if it can't be read.
+func (w *workspace) load(path string) *file {
+	if path == "" {
+		return nil
+	}
+	for _, f := range w.open {
+		if f.path == path {
+			return f
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return nil
+	}
+	if cached, ok := w.disk[path]; ok && cached.modTime == info.ModTime().UnixNano() {
+		return cached.file
+	}
+
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	f := newFile(pathToURI(path), string(src))
+	w.disk[path] = &diskFile{modTime: info.ModTime().UnixNano(), file: f}
+	return f
+}
+
+// files returns the open documents, the scripts under the root, and the
+// scripts they include, sorted by path.
+func (w *workspace) files() []*file {
+	if w.known != nil {
+		return w.known
+	}
+
+	seen := make(map[string]bool)
+	var files []*file
+	var add func(f *file)
+	add = func(f *file) {
+		if f == nil || seen[f.uri] {
+			return
+		}
+		seen[f.uri] = true
+		files = append(files, f)
+		for _, inc := range f.index.includes {
+			add(w.load(inc.path))
+		}
+	}
+
+	for _, f := range w.open {
+		add(f)
+	}
+	if w.root != "" {
+		filepath.Walk(w.root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return nil
+			}
+			if info.IsDir() {
+				if path != w.root && strings.HasPrefix(info.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if filepath.Ext(path) == ".ni" && !seen[pathToURI(path)] {
+				add(w.load(path))
+			}
+			return nil
+		})
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].uri < files[j].uri })
+	w.known = files
+	return files
+}
+
+// resolveRef returns the declaration ref refers to. Names not declared in the
+// file are looked up where the file is included.
+func (w *workspace) resolveRef(f *file, ref *reference) *symbol {
+	if ref.sym != nil {
+		return ref.sym
+	}
+	return w.resolveIncluded(f, ref.name, w.files(), map[string]bool{f.uri: true})
+}
+
+func (w *workspace) resolveIncluded(f *file, name string, files []*file, visited map[string]bool) *symbol {
+	if f.path == "" {
+		return nil
+	}
+	for _, g := range files {
+		if visited[g.uri] {
+			continue
+		}
+		for _, inc := range g.index.includes {
+			if inc.path != f.path {
+				continue
+			}
+			if sym := g.index.lookup(inc.pos, name); sym != nil {
+				return sym
+			}
+			visited[g.uri] = true
+			if sym := w.resolveIncluded(g, name, files, visited); sym != nil {
+				return sym
+			}
+		}
+	}
+	return nil
+}
+
+// resolveMember returns the declaration of a member of an included script or
+// of an instance made from a class.
+func (w *workspace) resolveMember(f *file, m *member) *symbol {
+	base := w.resolveRef(f, m.base)
+	if base == nil {
+		return nil
+	}
+
+	if base.include != "" {
+		if g := w.load(base.include); g != nil {
+			return g.index.exports[m.name]
+		}
+		return nil
+	}
+
+	class := base
+	if inst, ok := base.value.(*ast.MakeInstance); ok {
+		ident, ok := inst.Class.(*ast.Identifier)
+		if !ok {
+			return nil
+		}
+		class = w.symbolAt(base.file, ident.Token.Pos)
+	}
+	if class == nil || class.kind != classSymbol {
+		return nil
+	}
+	for _, member := range class.members {
+		if member.name == m.name {
+			return member
+		}
+	}
+	return nil
+}
+
+// symbolAt returns the declaration of the name at pos.
+func (w *workspace) symbolAt(f *file, pos token.Position) *symbol {
+	if ref := f.index.refAt(pos); ref != nil {
+		return w.resolveRef(f, ref)
+	}
+	if m := f.index.memberAt(pos); m != nil {
+		return w.resolveMember(f, m)
+	}
+	return f.index.declAt(pos)
+}
+
+// references returns the locations of the uses of sym in all known files.
+func (w *workspace) references(sym *symbol, includeDecl bool) []Location {
+	locs := []Location{}
+	declared := false
+	for _, f := range w.files() {
+		for _, ref := range f.index.refs {
+			if w.resolveRef(f, ref) != sym {
+				continue
+			}
+			if ref.decl {
+				declared = true
+				if !includeDecl {
+					continue
+				}
+			}
+			locs = append(locs, f.location(ref.pos, ref.name))
+		}
+		for _, m := range f.index.members {
+			if w.resolveMember(f, m) == sym {
+				locs = append(locs, f.location(m.pos, m.name))
+			}
+		}
+	}
+
+	// Class members and exported map keys don't have a declaring reference
+	if includeDecl && !declared {
+		locs = append([]Location{sym.file.location(sym.pos, sym.name)}, locs...)
+	}
+	return locs
+}
@@ -5,7 +5,7 @@ import "github.com/nitrogen-lang/nitrogen/src/object"
 // CheckArgs is a convience function to check if the correct number of arguments were supplied
 func CheckArgs(name string, expected int, args ...object.Object) *object.Exception {
 	if len(args) != expected {
-		return object.NewException("%s expects %d argument(s). Got %d", name, expected, len(args))
+		return object.NewArgumentError("%s expects %d argument(s). Got %d", name, expected, len(args))
 	}
 	return nil
 }
@@ -13,7 +13,27 @@ func CheckArgs(name string, expected int, args ...object.Object) *object.Excepti
 // CheckMinArgs is a convience function to check if the minimum number of arguments were supplied
 func CheckMinArgs(name string, expected int, args ...object.Object) *object.Exception {
 	if len(args) < expected {
-		return object.NewException("%s expects %d argument(s). Got %d", name, expected, len(args))
+		return object.NewArgumentError("%s expects %d argument(s). Got %d", name, expected, len(args))
 	}
 	return nil
 }
+
+// BytesArg returns the bytes of argument i of a function that works on binary data. The
+// argument can be a STRING or an ARRAY of INTEGERs from 0 to 255, like bytes() returns.
+func BytesArg(name string, args []object.Object, i int) ([]byte, *object.Exception) {
+	switch arg := args[i].(type) {
+	case *object.String:
+		return []byte(arg.Value), nil
+	case *object.Array:
+		buf := make([]byte, len(arg.Elements))
+		for j, e := range arg.Elements {
+			b, ok := e.(*object.Integer)
+			if !ok || b.Value < 0 || b.Value > 255 {
+				return nil, object.NewTypeError("Argument %d to `%s` must be STRING or an ARRAY of bytes, got element %s", i+1, name, e.Inspect())
+			}
+			buf[j] = byte(b.Value)
+		}
+		return buf, nil
+	}
+	return nil, object.NewTypeError("Argument %d to `%s` must be STRING or an ARRAY of bytes, got %s", i+1, name, args[i].Type())
+}
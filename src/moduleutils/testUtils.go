@@ -7,6 +7,7 @@ import (
 	"github.com/nitrogen-lang/nitrogen/src/lexer"
 	"github.com/nitrogen-lang/nitrogen/src/object"
 	"github.com/nitrogen-lang/nitrogen/src/parser"
+	"github.com/nitrogen-lang/nitrogen/src/vm"
 )
 
 var testInterpreter = eval.NewInterpreter()
@@ -20,6 +21,40 @@ func TestEval(input string) object.Object {
 	return testInterpreter.Eval(program, env)
 }
 
+// TestEvalModule evaluates input with the tree walking interpreter and the bytecode
+// VM with the registered module available as a constant of the same name. Parser
+// errors fail the test and both backends must give the same result. The result of
+// the interpreter is returned.
+func TestEvalModule(t *testing.T, input, module string) object.Object {
+	t.Helper()
+	backends := []object.Interpreter{eval.NewInterpreter(), vm.New()}
+	results := make([]object.Object, len(backends))
+
+	for i, interpreter := range backends {
+		p := parser.New(lexer.NewString(input), nil)
+		program := p.ParseProgram()
+		if len(p.Errors()) > 0 {
+			t.Fatalf("%s: %s", input, p.Errors()[0])
+		}
+
+		env := object.NewEnvironment()
+		env.CreateConst(module, eval.GetModule(module))
+		results[i] = interpreter.Eval(program, env)
+	}
+
+	if showResult(results[0]) != showResult(results[1]) {
+		t.Errorf("backends disagree on %q: eval=%s, vm=%s", input, showResult(results[0]), showResult(results[1]))
+	}
+	return results[0]
+}
+
+func showResult(obj object.Object) string {
+	if obj == nil {
+		return "nil"
+	}
+	return obj.Type().String() + " " + obj.Inspect()
+}
+
 // Verification functions
 func TestIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
 	result, ok := obj.(*object.Integer)
@@ -29,6 +29,16 @@ func (c *Class) GetMethod(name string) ClassMethod {
 	return c.Parent.GetMethod(name)
 }
 
+// IsA returns if c is class or inherits from it.
+func (c *Class) IsA(class *Class) bool {
+	for ; c != nil; c = c.Parent {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
 type Instance struct {
 	Class  *Class
 	Fields *Environment
@@ -43,17 +53,12 @@ func InstanceOf(class string, i *Instance) bool {
 	if i == nil {
 		return false
 	}
-
-	c := i.Class
-	for {
+	for c := i.Class; c != nil; c = c.Parent {
 		if c.Name == class {
 			return true
 		}
-		if c.Parent == nil {
-			return false
-		}
-		c = c.Parent
 	}
+	return false
 }
 
 func InstanceOfAny(instance Object, classes ...string) bool {
@@ -3,6 +3,7 @@ package object
 import (
 	"errors"
 	"fmt"
+	"sort"
 )
 
 var (
@@ -37,6 +38,15 @@ func NewEnclosedEnv(outer *Environment) *Environment {
 	return env
 }
 
+// Clone returns a copy of e's variables with the same parent.
+func (e *Environment) Clone() *Environment {
+	env := NewEnclosedEnv(e.parent)
+	for k, v := range e.store {
+		env.store[k] = &eco{v: v.v, readonly: v.readonly}
+	}
+	return env
+}
+
 func (e *Environment) SetParent(env *Environment) {
 	e.parent = env
 }
@@ -45,6 +55,32 @@ func (e *Environment) Parent() *Environment {
 	return e.parent
 }
 
+// Names returns the names defined in e and its parents, sorted and without duplicates.
+func (e *Environment) Names() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for env := e; env != nil; env = env.parent {
+		for name := range env.store {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LocalNames returns the sorted names defined in e without its parents.
+func (e *Environment) LocalNames() []string {
+	names := make([]string, 0, len(e.store))
+	for name := range e.store {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func (e *Environment) Print(indent string) {
 	for k, v := range e.store {
 		fmt.Printf("%s%s = %s\n  %sConst: %t\n", indent, k, v.v.Inspect(), indent, v.readonly)
@@ -72,6 +108,14 @@ func (e *Environment) Get(name string) (Object, bool) {
 	return nil, false
 }
 
+// Ancestor returns the environment depth levels above e, or nil if the chain is shorter.
+func (e *Environment) Ancestor(depth int) *Environment {
+	for ; depth > 0 && e != nil; depth-- {
+		e = e.parent
+	}
+	return e
+}
+
 func (e *Environment) GetLocal(name string) (Object, bool) {
 	obj, ok := e.store[name]
 	if ok {
@@ -0,0 +1,146 @@
+package object
+
+import (
+	"fmt"
+
+	"github.com/nitrogen-lang/nitrogen/src/ast"
+)
+
+// These are the built-in exception classes. Exceptions raised by the interpreter
+// use one of the RuntimeError classes, user code can extend any of them.
+//
+//	Exception
+//	└── RuntimeError
+//	    ├── TypeError
+//	    ├── NameError
+//	    ├── IndexError
+//	    ├── ArgumentError
+//	    ├── ConstantError
+//	    └── ZeroDivisionError
+var (
+	ExceptionClass = &Class{
+		Name: "Exception",
+		Fields: []*ast.DefStatement{
+			{
+				Name:  &ast.Identifier{Value: "message"},
+				Value: &ast.StringLiteral{Value: ""},
+			},
+		},
+		Methods: map[string]ClassMethod{
+			"init": MakeBuiltinMethod(exceptionInit),
+		},
+	}
+
+	RuntimeErrorClass      = newExceptionClass("RuntimeError", ExceptionClass)
+	TypeErrorClass         = newExceptionClass("TypeError", RuntimeErrorClass)
+	NameErrorClass         = newExceptionClass("NameError", RuntimeErrorClass)
+	IndexErrorClass        = newExceptionClass("IndexError", RuntimeErrorClass)
+	ArgumentErrorClass     = newExceptionClass("ArgumentError", RuntimeErrorClass)
+	ConstantErrorClass     = newExceptionClass("ConstantError", RuntimeErrorClass)
+	ZeroDivisionErrorClass = newExceptionClass("ZeroDivisionError", RuntimeErrorClass)
+)
+
+// ExceptionClasses lists all built-in exception classes.
+var ExceptionClasses = []*Class{
+	ExceptionClass,
+	RuntimeErrorClass,
+	TypeErrorClass,
+	NameErrorClass,
+	IndexErrorClass,
+	ArgumentErrorClass,
+	ConstantErrorClass,
+	ZeroDivisionErrorClass,
+}
+
+func newExceptionClass(name string, parent *Class) *Class {
+	return &Class{
+		Name:    name,
+		Parent:  parent,
+		Methods: map[string]ClassMethod{},
+	}
+}
+
+// exceptionInit sets the message field of an exception instance.
+func exceptionInit(i Interpreter, self *Instance, env *Environment, args ...Object) Object {
+	this, ok := env.Get("this")
+	if !ok {
+		return NullConst
+	}
+	instance, ok := this.(*Instance)
+	if !ok {
+		return NullConst
+	}
+
+	if len(args) > 0 {
+		if _, err := instance.Fields.Set("message", args[0]); err != nil {
+			instance.Fields.Create("message", args[0])
+		}
+	}
+	return NullConst
+}
+
+// NewExceptionOf creates a catchable exception of the given class.
+func NewExceptionOf(class *Class, format string, a ...interface{}) *Exception {
+	return &Exception{
+		Message:   fmt.Sprintf(format, a...),
+		Catchable: true,
+		Class:     class,
+	}
+}
+
+// NewTypeError creates an exception for an operation applied to the wrong type of value.
+func NewTypeError(format string, a ...interface{}) *Exception {
+	return NewExceptionOf(TypeErrorClass, format, a...)
+}
+
+// NewNameError creates an exception for an identifier or field that doesn't exist.
+func NewNameError(format string, a ...interface{}) *Exception {
+	return NewExceptionOf(NameErrorClass, format, a...)
+}
+
+// NewIndexError creates an exception for an index outside of a collection.
+func NewIndexError(format string, a ...interface{}) *Exception {
+	return NewExceptionOf(IndexErrorClass, format, a...)
+}
+
+// NewArgumentError creates an exception for a function called with bad arguments.
+func NewArgumentError(format string, a ...interface{}) *Exception {
+	return NewExceptionOf(ArgumentErrorClass, format, a...)
+}
+
+// NewConstantError creates an exception for an invalid assignment to a constant.
+func NewConstantError(format string, a ...interface{}) *Exception {
+	return NewExceptionOf(ConstantErrorClass, format, a...)
+}
+
+// NewZeroDivisionError creates an exception for an integer divided by zero.
+func NewZeroDivisionError(format string, a ...interface{}) *Exception {
+	return NewExceptionOf(ZeroDivisionErrorClass, format, a...)
+}
+
+// NewThrownException creates the exception raised when a script throws payload.
+// A thrown instance is matched by its class and its message field becomes the exception
+// message. Any other value is a plain Exception.
+func NewThrownException(payload Object) *Exception {
+	e := &Exception{
+		Message:   payload.Inspect(),
+		Catchable: true,
+		Payload:   payload,
+		Class:     ExceptionClass,
+	}
+
+	if instance, ok := payload.(*Instance); ok {
+		e.Class = instance.Class
+		if instance.Class.IsA(ExceptionClass) {
+			if msg, ok := instance.Fields.Get("message"); ok {
+				e.Message = msg.Inspect()
+			}
+		}
+	}
+	return e
+}
+
+// Is returns if the exception's class is class or a subclass of it.
+func (e *Exception) Is(class *Class) bool {
+	return e.Class != nil && e.Class.IsA(class)
+}
@@ -0,0 +1,189 @@
+package object
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+// Hashable is implemented by objects that can be used as map keys.
+type Hashable interface {
+	Object
+	HashKey() HashKey
+}
+
+type HashKey struct {
+	Type  ObjectType
+	Value uint64
+}
+
+func (k HashKey) Dup() HashKey {
+	return HashKey{
+		Type:  k.Type,
+		Value: k.Value,
+	}
+}
+
+func (i *Integer) HashKey() HashKey {
+	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
+}
+
+func (s *String) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(s.Value))
+	return HashKey{Type: s.Type(), Value: h.Sum64()}
+}
+
+type HashPair struct {
+	Key   Object
+	Value Object
+}
+
+func (p HashPair) Dup() HashPair {
+	return HashPair{
+		Key:   p.Key.Dup(),
+		Value: p.Value.Dup(),
+	}
+}
+
+// Hash is a map that keeps its keys in the order they were first set.
+// The zero value is an empty map ready to use.
+type Hash struct {
+	index map[HashKey]int
+	// pairs holds the pairs in order. Deleted pairs are left with a nil key
+	// until enough of them are deleted to compact the slice.
+	pairs   []HashPair
+	deleted int
+}
+
+// NewHash returns an empty map with room for size pairs.
+func NewHash(size int) *Hash {
+	return &Hash{
+		index: make(map[HashKey]int, size),
+		pairs: make([]HashPair, 0, size),
+	}
+}
+
+// Len returns the number of pairs in the map.
+func (h *Hash) Len() int { return len(h.pairs) - h.deleted }
+
+// Get returns the value stored under key.
+func (h *Hash) Get(key Hashable) (Object, bool) {
+	i, ok := h.index[key.HashKey()]
+	if !ok {
+		return nil, false
+	}
+	return h.pairs[i].Value, true
+}
+
+// Has returns if key is in the map.
+func (h *Hash) Has(key Hashable) bool {
+	_, ok := h.index[key.HashKey()]
+	return ok
+}
+
+// Set stores value under key. A key that already exists keeps its position.
+func (h *Hash) Set(key Hashable, value Object) {
+	hashed := key.HashKey()
+	if i, ok := h.index[hashed]; ok {
+		h.pairs[i] = HashPair{Key: key, Value: value}
+		return
+	}
+
+	if h.index == nil {
+		h.index = make(map[HashKey]int)
+	}
+	h.index[hashed] = len(h.pairs)
+	h.pairs = append(h.pairs, HashPair{Key: key, Value: value})
+}
+
+// Delete removes key from the map and returns if it existed.
+func (h *Hash) Delete(key Hashable) bool {
+	hashed := key.HashKey()
+	i, ok := h.index[hashed]
+	if !ok {
+		return false
+	}
+
+	delete(h.index, hashed)
+	h.pairs[i] = HashPair{}
+	h.deleted++
+	if h.deleted > len(h.pairs)/2 {
+		h.compact()
+	}
+	return true
+}
+
+// compact removes deleted pairs from the pairs slice.
+func (h *Hash) compact() {
+	pairs := h.pairs[:0]
+	for _, pair := range h.pairs {
+		if pair.Key != nil {
+			h.index[pair.Key.(Hashable).HashKey()] = len(pairs)
+			pairs = append(pairs, pair)
+		}
+	}
+	// Clear the rest so deleted objects can be collected
+	for i := len(pairs); i < len(h.pairs); i++ {
+		h.pairs[i] = HashPair{}
+	}
+	h.pairs = pairs
+	h.deleted = 0
+}
+
+// Pairs returns a copy of the map's pairs in order. Changes to the map don't affect the returned slice.
+func (h *Hash) Pairs() []HashPair {
+	pairs := make([]HashPair, 0, h.Len())
+	for _, pair := range h.pairs {
+		if pair.Key != nil {
+			pairs = append(pairs, pair)
+		}
+	}
+	return pairs
+}
+
+// Keys returns the map's keys in order.
+func (h *Hash) Keys() []Object {
+	keys := make([]Object, 0, h.Len())
+	for _, pair := range h.pairs {
+		if pair.Key != nil {
+			keys = append(keys, pair.Key)
+		}
+	}
+	return keys
+}
+
+// Values returns the map's values in order.
+func (h *Hash) Values() []Object {
+	values := make([]Object, 0, h.Len())
+	for _, pair := range h.pairs {
+		if pair.Key != nil {
+			values = append(values, pair.Value)
+		}
+	}
+	return values
+}
+
+func (h *Hash) Type() ObjectType { return HashObj }
+func (h *Hash) Inspect() string {
+	var out bytes.Buffer
+
+	pairs := make([]string, 0, h.Len())
+	for _, pair := range h.Pairs() {
+		pairs = append(pairs, fmt.Sprintf("%s: %s", pair.Key.Inspect(), pair.Value.Inspect()))
+	}
+
+	out.WriteByte('{')
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteByte('}')
+	return out.String()
+}
+func (h *Hash) Dup() Object {
+	newHash := NewHash(h.Len())
+	for _, pair := range h.Pairs() {
+		pair = pair.Dup()
+		newHash.Set(pair.Key.(Hashable), pair.Value)
+	}
+	return newHash
+}
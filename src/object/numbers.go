@@ -0,0 +1,221 @@
+package object
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// BigInt is an integer too large for an Integer. Integer arithmetic that
+// overflows produces a BigInt, use NewInt to make one from a big.Int.
+type BigInt struct {
+	Value *big.Int
+}
+
+// NewInt returns value as an Integer if it fits in an int64, otherwise as a BigInt.
+func NewInt(value *big.Int) Object {
+	if value.IsInt64() {
+		return &Integer{Value: value.Int64()}
+	}
+	return &BigInt{Value: value}
+}
+
+func (i *BigInt) Inspect() string  { return i.Value.String() }
+func (i *BigInt) Type() ObjectType { return BigIntObj }
+func (i *BigInt) Dup() Object      { return &BigInt{Value: new(big.Int).Set(i.Value)} }
+
+func (i *BigInt) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(i.Value.String()))
+	return HashKey{Type: i.Type(), Value: h.Sum64()}
+}
+
+// RoundingMode determines how a Decimal is rounded when digits beyond its scale are dropped.
+type RoundingMode int
+
+const (
+	// RoundHalfEven rounds to the nearest neighbor, ties go to the even neighbor.
+	RoundHalfEven RoundingMode = iota
+	// RoundHalfUp rounds to the nearest neighbor, ties go away from zero.
+	RoundHalfUp
+	// RoundHalfDown rounds to the nearest neighbor, ties go towards zero.
+	RoundHalfDown
+	// RoundUp rounds away from zero.
+	RoundUp
+	// RoundDown rounds towards zero.
+	RoundDown
+	// RoundCeiling rounds towards positive infinity.
+	RoundCeiling
+	// RoundFloor rounds towards negative infinity.
+	RoundFloor
+)
+
+var roundingModeNames = map[RoundingMode]string{
+	RoundHalfEven: "half-even",
+	RoundHalfUp:   "half-up",
+	RoundHalfDown: "half-down",
+	RoundUp:       "up",
+	RoundDown:     "down",
+	RoundCeiling:  "ceiling",
+	RoundFloor:    "floor",
+}
+
+func (m RoundingMode) String() string {
+	return roundingModeNames[m]
+}
+
+// ParseRoundingMode returns the rounding mode with the given name.
+func ParseRoundingMode(name string) (RoundingMode, bool) {
+	for mode, modeName := range roundingModeNames {
+		if modeName == name {
+			return mode, true
+		}
+	}
+	return RoundHalfEven, false
+}
+
+// Decimal is an exact decimal number with a fixed number of digits after the
+// decimal point. Its value is Unscaled / 10^Scale.
+type Decimal struct {
+	Unscaled *big.Int
+	Scale    int
+	Rounding RoundingMode
+}
+
+// ParseDecimal parses a decimal number such as "-12.50". The scale is the
+// number of digits after the decimal point.
+func ParseDecimal(s string, rounding RoundingMode) (*Decimal, error) {
+	digits := strings.TrimLeft(s, "+-")
+	if len(s)-len(digits) > 1 {
+		return nil, fmt.Errorf("Invalid decimal: %q", s)
+	}
+
+	scale := 0
+	if dot := strings.IndexByte(digits, '.'); dot > -1 {
+		scale = len(digits) - dot - 1
+		digits = digits[:dot] + digits[dot+1:]
+	}
+	if digits == "" || strings.IndexFunc(digits, func(r rune) bool { return r < '0' || r > '9' }) > -1 {
+		return nil, fmt.Errorf("Invalid decimal: %q", s)
+	}
+
+	unscaled, _ := new(big.Int).SetString(digits, 10)
+	if s[0] == '-' {
+		unscaled.Neg(unscaled)
+	}
+	return &Decimal{Unscaled: unscaled, Scale: scale, Rounding: rounding}, nil
+}
+
+// NewDecimalFromInt makes a decimal with a scale of zero.
+func NewDecimalFromInt(value *big.Int, rounding RoundingMode) *Decimal {
+	return &Decimal{Unscaled: value, Scale: 0, Rounding: rounding}
+}
+
+func (d *Decimal) Inspect() string {
+	digits := new(big.Int).Abs(d.Unscaled).String()
+	sign := ""
+	if d.Unscaled.Sign() < 0 {
+		sign = "-"
+	}
+	if d.Scale == 0 {
+		return sign + digits
+	}
+	if len(digits) <= d.Scale {
+		digits = strings.Repeat("0", d.Scale-len(digits)+1) + digits
+	}
+	point := len(digits) - d.Scale
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+func (d *Decimal) Type() ObjectType { return DecimalObj }
+func (d *Decimal) Dup() Object {
+	return &Decimal{Unscaled: new(big.Int).Set(d.Unscaled), Scale: d.Scale, Rounding: d.Rounding}
+}
+
+// HashKey is the same for decimals that are equal, regardless of their scale.
+func (d *Decimal) HashKey() HashKey {
+	unscaled, scale := new(big.Int).Set(d.Unscaled), d.Scale
+	ten, rem := big.NewInt(10), new(big.Int)
+	for scale > 0 {
+		q, r := new(big.Int).QuoRem(unscaled, ten, rem)
+		if r.Sign() != 0 {
+			break
+		}
+		unscaled, scale = q, scale-1
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(unscaled.String() + "e" + strconv.Itoa(scale)))
+	return HashKey{Type: d.Type(), Value: h.Sum64()}
+}
+
+// Rescale returns d with the given scale, rounding it with d's rounding mode if digits are dropped.
+func (d *Decimal) Rescale(scale int) *Decimal {
+	if scale >= d.Scale {
+		return &Decimal{
+			Unscaled: new(big.Int).Mul(d.Unscaled, pow10(scale-d.Scale)),
+			Scale:    scale,
+			Rounding: d.Rounding,
+		}
+	}
+	return &Decimal{
+		Unscaled: RoundQuo(d.Unscaled, pow10(d.Scale-scale), d.Rounding),
+		Scale:    scale,
+		Rounding: d.Rounding,
+	}
+}
+
+// Int returns the integer part of d.
+func (d *Decimal) Int() *big.Int {
+	return new(big.Int).Quo(d.Unscaled, pow10(d.Scale))
+}
+
+// Float64 returns the nearest float to d.
+func (d *Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.Inspect(), 64)
+	return f
+}
+
+// RoundQuo returns x / y rounded to an integer with the given rounding mode.
+func RoundQuo(x, y *big.Int, mode RoundingMode) *big.Int {
+	q, r := new(big.Int).QuoRem(x, y, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+
+	// The sign of the exact quotient, q is truncated towards zero
+	sign := x.Sign() * y.Sign()
+	// Compare the remainder with half of the divisor to find ties
+	half := new(big.Int).Abs(r)
+	half.Lsh(half, 1)
+	cmpHalf := half.Cmp(new(big.Int).Abs(y))
+
+	var away bool
+	switch mode {
+	case RoundUp:
+		away = true
+	case RoundDown:
+		away = false
+	case RoundCeiling:
+		away = sign > 0
+	case RoundFloor:
+		away = sign < 0
+	case RoundHalfUp:
+		away = cmpHalf >= 0
+	case RoundHalfDown:
+		away = cmpHalf > 0
+	default:
+		away = cmpHalf > 0 || cmpHalf == 0 && q.Bit(0) == 1
+	}
+
+	if away {
+		q.Add(q, big.NewInt(int64(sign)))
+	}
+	return q
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
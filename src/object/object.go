@@ -3,12 +3,12 @@ package object
 import (
 	"bytes"
 	"fmt"
-	"hash/fnv"
 	"io"
 	"strconv"
 	"strings"
 
 	"github.com/nitrogen-lang/nitrogen/src/ast"
+	"github.com/nitrogen-lang/nitrogen/src/token"
 )
 
 type ObjectType int
@@ -38,6 +38,10 @@ const (
 	ClassObj
 	InstanceObj
 	BuiltinMethodObj
+	BigIntObj
+	DecimalObj
+	TimeObj
+	DurationObj
 )
 
 var objectTypeNames = map[ObjectType]string{
@@ -58,6 +62,10 @@ var objectTypeNames = map[ObjectType]string{
 	ClassObj:         "CLASS",
 	InstanceObj:      "INSTANCE",
 	BuiltinMethodObj: "BUILTIN METHOD",
+	BigIntObj:        "BIGINT",
+	DecimalObj:       "DECIMAL",
+	TimeObj:          "TIME",
+	DurationObj:      "DURATION",
 }
 
 // These are all constants in the language that can be represented with a single instance
@@ -73,6 +81,9 @@ type Interpreter interface {
 	GetStdout() io.Writer
 	GetStderr() io.Writer
 	GetStdin() io.Reader
+	// Call calls fn with args and returns its result. fn may be a function, builtin, or method.
+	// An exception raised by fn is returned.
+	Call(fn Object, args []Object) Object
 }
 
 type BuiltinFunction func(i Interpreter, env *Environment, args ...Object) Object
@@ -152,16 +163,88 @@ func (r *ReturnValue) Inspect() string  { return r.Value.Inspect() }
 func (r *ReturnValue) Type() ObjectType { return ReturnObj }
 func (r *ReturnValue) Dup() Object      { return &ReturnValue{Value: r.Value.Dup()} }
 
-// TODO: Expand with line/column numbers and stack trace
 type Exception struct {
 	Catchable bool
 	Message   string
 	Caught    bool
+	// Trace lists the call frames the exception was raised through, innermost first.
+	// The first frame is where the exception was raised. It's nil until the
+	// interpreter records where the exception happened.
+	Trace []TraceFrame
+	// Payload is the value given to throw. It's nil for exceptions raised by the interpreter.
+	Payload Object
+	// Class is the exception's class used to match typed catch clauses. It's nil for panics.
+	Class *Class
 }
 
 func (e *Exception) Inspect() string  { return e.Message }
 func (e *Exception) Type() ObjectType { return ExceptionObj }
-func (e *Exception) Dup() Object      { return &Exception{Message: e.Message} }
+func (e *Exception) Dup() Object {
+	return &Exception{Message: e.Message, Trace: e.Trace, Payload: e.Payload, Class: e.Class}
+}
+
+// traceEndLines is the number of lines kept at each end of a long stack trace.
+const traceEndLines = 20
+
+// traceLine is a frame of a stack trace and the number of times it's repeated after it.
+type traceLine struct {
+	frame   TraceFrame
+	repeats int
+}
+
+// StackTrace returns the exception's trace with one frame per line. Repeated
+// frames of a recursive call are collapsed into one line, and only the first
+// and last frames of a long trace are kept.
+func (e *Exception) StackTrace() string {
+	var lines []traceLine
+	for _, frame := range e.Trace {
+		if len(lines) > 0 && lines[len(lines)-1].frame == frame {
+			lines[len(lines)-1].repeats++
+			continue
+		}
+		lines = append(lines, traceLine{frame: frame})
+	}
+
+	var out bytes.Buffer
+	for i := 0; i < len(lines); i++ {
+		if i == traceEndLines && len(lines) > 2*traceEndLines {
+			skipped := 0
+			for _, line := range lines[i : len(lines)-traceEndLines] {
+				skipped += line.repeats + 1
+			}
+			fmt.Fprintf(&out, "    ... %d more frames\n", skipped)
+			i = len(lines) - traceEndLines
+		}
+
+		out.WriteString("    at ")
+		out.WriteString(lines[i].frame.String())
+		out.WriteByte('\n')
+		if lines[i].repeats > 0 {
+			fmt.Fprintf(&out, "    ... repeated %d more times\n", lines[i].repeats)
+		}
+	}
+	return out.String()
+}
+
+// TraceFrame is a single function call in an exception's stack trace.
+type TraceFrame struct {
+	Function string
+	Filename string
+	Pos      token.Position
+}
+
+// NewTraceFrame creates a trace frame for the function executing at tok.
+func NewTraceFrame(function string, tok token.Token) TraceFrame {
+	return TraceFrame{Function: function, Filename: tok.Filename, Pos: tok.Pos}
+}
+
+func (f TraceFrame) String() string {
+	filename := f.Filename
+	if filename == "" {
+		filename = "<input>"
+	}
+	return fmt.Sprintf("%s (%s:%d:%d)", f.Function, filename, f.Pos.Line, f.Pos.Col)
+}
 
 type Error struct {
 	Message string
@@ -177,6 +260,8 @@ type Function struct {
 	Body       *ast.BlockStatement
 	Env        *Environment
 	Instance   *Instance
+	// Compiled holds the function's bytecode when it was created by the VM backend.
+	Compiled interface{}
 }
 
 func (f *Function) Inspect() string {
@@ -266,73 +351,6 @@ func MakeStringArray(e []string) *Array {
 	return &Array{Elements: newElements}
 }
 
-type Hashable interface {
-	HashKey() HashKey
-}
-
-type HashKey struct {
-	Type  ObjectType
-	Value uint64
-}
-
-func (k HashKey) Dup() HashKey {
-	return HashKey{
-		Type:  k.Type,
-		Value: k.Value,
-	}
-}
-
-func (i *Integer) HashKey() HashKey {
-	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
-}
-
-func (s *String) HashKey() HashKey {
-	h := fnv.New64a()
-	h.Write([]byte(s.Value))
-	return HashKey{Type: s.Type(), Value: h.Sum64()}
-}
-
-type HashPair struct {
-	Key   Object
-	Value Object
-}
-
-func (p HashPair) Dup() HashPair {
-	return HashPair{
-		Key:   p.Key.Dup(),
-		Value: p.Value.Dup(),
-	}
-}
-
-type Hash struct {
-	Pairs map[HashKey]HashPair
-}
-
-func (h *Hash) Type() ObjectType { return HashObj }
-func (h *Hash) Inspect() string {
-	var out bytes.Buffer
-
-	pairs := []string{}
-	for _, pair := range h.Pairs {
-		pairs = append(pairs, fmt.Sprintf("%s: %s",
-			pair.Key.Inspect(), pair.Value.Inspect()))
-	}
-
-	out.WriteByte('{')
-	out.WriteString(strings.Join(pairs, ", "))
-	out.WriteByte('}')
-	return out.String()
-}
-func (h *Hash) Dup() Object {
-	newElements := make(map[HashKey]HashPair, len(h.Pairs))
-
-	for key, pair := range h.Pairs {
-		newElements[key.Dup()] = pair.Dup()
-	}
-
-	return &Hash{Pairs: newElements}
-}
-
 type LoopControl struct {
 	Continue bool
 }
@@ -356,11 +374,9 @@ func (m *Module) Inspect() string  { return fmt.Sprintf("Module %s", m.Name) }
 func (m *Module) Type() ObjectType { return ModuleObj }
 func (m *Module) Dup() Object      { return NullConst }
 
+// NewException creates a catchable RuntimeError exception.
 func NewException(format string, a ...interface{}) *Exception {
-	return &Exception{
-		Message:   fmt.Sprintf(format, a...),
-		Catchable: true,
-	}
+	return NewExceptionOf(RuntimeErrorClass, format, a...)
 }
 
 func NewPanic(format string, a ...interface{}) *Exception {
@@ -1,6 +1,12 @@
 package object
 
-import "testing"
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/nitrogen-lang/nitrogen/src/token"
+)
 
 func TestStringHashKey(t *testing.T) {
 	hello1 := &String{Value: "Hello World"}
@@ -21,3 +27,145 @@ func TestStringHashKey(t *testing.T) {
 		t.Errorf("strings with different content have same hash keys")
 	}
 }
+
+func TestHashOrder(t *testing.T) {
+	h := NewHash(0)
+	h.Set(&String{Value: "b"}, &Integer{Value: 1})
+	h.Set(&Integer{Value: 2}, &Integer{Value: 2})
+	h.Set(&String{Value: "a"}, &Integer{Value: 3})
+	h.Set(&String{Value: "b"}, &Integer{Value: 4})
+
+	if h.Inspect() != `{b: 4, 2: 2, a: 3}` {
+		t.Errorf("wrong map order. got=%s", h.Inspect())
+	}
+
+	if !h.Delete(&Integer{Value: 2}) {
+		t.Errorf("Delete didn't find existing key")
+	}
+	if h.Delete(&Integer{Value: 2}) {
+		t.Errorf("Delete found deleted key")
+	}
+	h.Set(&Integer{Value: 2}, &Integer{Value: 5})
+
+	if h.Inspect() != `{b: 4, a: 3, 2: 5}` {
+		t.Errorf("wrong map order after delete. got=%s", h.Inspect())
+	}
+
+	if val, ok := h.Get(&String{Value: "a"}); !ok || val.Inspect() != "3" {
+		t.Errorf("wrong value for key a. got=%v", val)
+	}
+	if h.Has(&String{Value: "c"}) {
+		t.Errorf("map has key that wasn't set")
+	}
+
+	dup := h.Dup().(*Hash)
+	dup.Set(&String{Value: "c"}, NullConst)
+	if h.Len() != 3 || dup.Len() != 4 {
+		t.Errorf("Dup shares pairs with original. got lengths %d and %d", h.Len(), dup.Len())
+	}
+	if dup.Inspect() != `{b: 4, a: 3, 2: 5, c: nil}` {
+		t.Errorf("wrong order of duplicated map. got=%s", dup.Inspect())
+	}
+}
+
+func TestHashDeleteMany(t *testing.T) {
+	h := NewHash(0)
+	for i := 0; i < 1000; i++ {
+		h.Set(&Integer{Value: int64(i)}, &Integer{Value: int64(i * 10)})
+	}
+	// Deleting most keys compacts the pairs along the way
+	for i := 0; i < 1000; i++ {
+		if i%4 != 0 && !h.Delete(&Integer{Value: int64(i)}) {
+			t.Fatalf("Delete didn't find key %d", i)
+		}
+	}
+
+	if h.Len() != 250 {
+		t.Fatalf("wrong length. expected=250, got=%d", h.Len())
+	}
+	for i, key := range h.Keys() {
+		if key.(*Integer).Value != int64(i*4) {
+			t.Fatalf("wrong key at %d. expected=%d, got=%s", i, i*4, key.Inspect())
+		}
+	}
+	for i, pair := range h.Pairs() {
+		if val, ok := h.Get(pair.Key.(Hashable)); !ok || val.(*Integer).Value != int64(i*40) {
+			t.Fatalf("wrong value for key %s. got=%v", pair.Key.Inspect(), val)
+		}
+	}
+	if h.Has(&Integer{Value: 1}) {
+		t.Errorf("map has deleted key")
+	}
+}
+
+func TestZeroHash(t *testing.T) {
+	h := &Hash{}
+	if _, ok := h.Get(&String{Value: "a"}); ok {
+		t.Errorf("empty map has a value")
+	}
+	h.Set(&String{Value: "a"}, NullConst)
+	if h.Len() != 1 {
+		t.Errorf("wrong length. got=%d", h.Len())
+	}
+}
+
+func TestNumberHashKeys(t *testing.T) {
+	a, _ := ParseDecimal("1.50", RoundHalfEven)
+	b, _ := ParseDecimal("1.5", RoundHalfUp)
+	c, _ := ParseDecimal("15", RoundHalfEven)
+	if a.HashKey() != b.HashKey() {
+		t.Errorf("equal decimals have different hash keys")
+	}
+	if a.HashKey() == c.HashKey() {
+		t.Errorf("different decimals have the same hash key")
+	}
+
+	big1 := NewInt(new(big.Int).Lsh(big.NewInt(1), 70))
+	big2 := NewInt(new(big.Int).Lsh(big.NewInt(1), 70))
+	if big1.(Hashable).HashKey() != big2.(Hashable).HashKey() {
+		t.Errorf("equal big ints have different hash keys")
+	}
+	if _, ok := NewInt(big.NewInt(5)).(*Integer); !ok {
+		t.Errorf("NewInt didn't return an Integer for a small value")
+	}
+}
+
+func TestStackTrace(t *testing.T) {
+	frame := func(function string, line int) TraceFrame {
+		return TraceFrame{Function: function, Filename: "a.ni", Pos: token.Position{Line: line, Col: 5}}
+	}
+
+	// Recursion repeats the frame of the recursive call
+	recursive := &Exception{Trace: []TraceFrame{frame("f", 2)}}
+	for i := 0; i < 5000; i++ {
+		recursive.Trace = append(recursive.Trace, frame("f", 3))
+	}
+	recursive.Trace = append(recursive.Trace, frame("<main>", 5))
+
+	expected := `    at f (a.ni:2:5)
+    at f (a.ni:3:5)
+    ... repeated 4999 more times
+    at <main> (a.ni:5:5)
+`
+	if trace := recursive.StackTrace(); trace != expected {
+		t.Errorf("wrong recursive stack trace. expected=%q, got=%q", expected, trace)
+	}
+
+	// Mutual recursion is cut to the first and last frames
+	mutual := &Exception{}
+	for i := 0; i < 50; i++ {
+		mutual.Trace = append(mutual.Trace, frame("a", 2), frame("b", 6))
+	}
+	mutual.Trace = append(mutual.Trace, frame("<main>", 9))
+
+	lines := strings.Split(strings.TrimSuffix(mutual.StackTrace(), "\n"), "\n")
+	if len(lines) != 2*traceEndLines+1 {
+		t.Fatalf("wrong number of lines. expected=%d, got=%d", 2*traceEndLines+1, len(lines))
+	}
+	if lines[traceEndLines] != "    ... 61 more frames" {
+		t.Errorf("wrong skipped frames line. got=%q", lines[traceEndLines])
+	}
+	if lines[0] != "    at a (a.ni:2:5)" || lines[len(lines)-1] != "    at <main> (a.ni:9:5)" {
+		t.Errorf("wrong first or last frame. got=%q, %q", lines[0], lines[len(lines)-1])
+	}
+}
@@ -0,0 +1,35 @@
+package object
+
+import (
+	"hash/fnv"
+	"time"
+)
+
+// Time is an instant in time. Its location is only used to display it, times
+// in different locations are equal if they're the same instant.
+type Time struct {
+	Value time.Time
+}
+
+func (t *Time) Inspect() string  { return t.Value.Format(time.RFC3339Nano) }
+func (t *Time) Type() ObjectType { return TimeObj }
+func (t *Time) Dup() Object      { return &Time{Value: t.Value} }
+
+func (t *Time) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(t.Value.UTC().Format(time.RFC3339Nano)))
+	return HashKey{Type: t.Type(), Value: h.Sum64()}
+}
+
+// Duration is the time elapsed between two instants, with nanosecond precision.
+type Duration struct {
+	Value time.Duration
+}
+
+func (d *Duration) Inspect() string  { return d.Value.String() }
+func (d *Duration) Type() ObjectType { return DurationObj }
+func (d *Duration) Dup() Object      { return &Duration{Value: d.Value} }
+
+func (d *Duration) HashKey() HashKey {
+	return HashKey{Type: d.Type(), Value: uint64(d.Value)}
+}
@@ -25,22 +25,21 @@ func (p *Parser) parseStatement() ast.Statement {
 	case token.For:
 		return p.parseForLoop()
 	case token.Throw:
+		t := &ast.ThrowStatement{Token: p.curToken}
 		p.nextToken()
-		t := &ast.ThrowStatement{
-			Expression: p.parseExpression(priLowest),
-		}
+		t.Expression = p.parseExpression(priLowest)
 		if p.peekTokenIs(token.Semicolon) {
 			p.nextToken()
 		}
 		return t
 	case token.Continue:
-		stat := &ast.ContinueStatement{}
+		stat := &ast.ContinueStatement{Token: p.curToken}
 		if p.peekTokenIs(token.Semicolon) {
 			p.nextToken()
 		}
 		return stat
 	case token.Break:
-		stat := &ast.BreakStatement{}
+		stat := &ast.BreakStatement{Token: p.curToken}
 		if p.peekTokenIs(token.Semicolon) {
 			p.nextToken()
 		}
@@ -101,6 +100,12 @@ func (p *Parser) parseReturnStatement() ast.Statement {
 	}
 	stmt := &ast.ReturnStatement{Token: p.curToken}
 
+	// The closing brace of a block is left for the block to read
+	if p.peekTokenIs(token.RBrace) {
+		stmt.Value = &ast.NullLiteral{Token: createKeywordToken("null")}
+		return stmt
+	}
+
 	p.nextToken()
 	if p.curTokenIs(token.Semicolon) {
 		stmt.Value = &ast.NullLiteral{Token: createKeywordToken("null")}
@@ -12,6 +12,7 @@ func (p *Parser) parseClassLiteral() ast.Expression {
 		fmt.Println("parseClassLiteral")
 	}
 	c := &ast.ClassLiteral{
+		Token:   p.curToken,
 		Fields:  make([]*ast.DefStatement, 0),
 		Methods: make(map[string]*ast.FunctionLiteral),
 	}
@@ -56,7 +57,7 @@ func (p *Parser) parseMakeExpression() ast.Expression {
 	if p.settings.Debug {
 		fmt.Println("parseMakeExpression")
 	}
-	m := &ast.MakeInstance{}
+	m := &ast.MakeInstance{Token: p.curToken}
 
 	p.nextToken()
 	cExpression := p.parseExpression(priLowest)
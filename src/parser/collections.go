@@ -21,7 +21,7 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 		fmt.Println("parseHashLiteral")
 	}
 	hash := &ast.HashLiteral{Token: p.curToken}
-	hash.Pairs = make(map[ast.Expression]ast.Expression)
+	hash.Pairs = make([]ast.HashLiteralPair, 0)
 
 	for !p.peekTokenIs(token.RBrace) {
 		p.nextToken()
@@ -34,7 +34,7 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 		p.nextToken()
 		value := p.parseExpression(priLowest)
 
-		hash.Pairs[key] = value
+		hash.Pairs = append(hash.Pairs, ast.HashLiteralPair{Key: key, Value: value})
 
 		if p.peekToken.Type == token.Semicolon {
 			p.addErrorWithPos("Hash pairs must end with a comma")
@@ -82,12 +82,39 @@ func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 		}
 		exp.Index = i
 	} else {
+		if p.peekTokenIs(token.Colon) {
+			return p.parseSliceExpression(exp.Token, left, nil)
+		}
+
 		p.nextToken()
 		exp.Index = p.parseExpression(priLowest)
 
+		if p.peekTokenIs(token.Colon) {
+			return p.parseSliceExpression(exp.Token, left, exp.Index)
+		}
+
 		if !p.expectPeek(token.RSquare) {
 			return nil
 		}
 	}
 	return exp
 }
+
+// parseSliceExpression parses the rest of left[low:high] after low. The peek token is the colon.
+func (p *Parser) parseSliceExpression(tok token.Token, left, low ast.Expression) ast.Expression {
+	if p.settings.Debug {
+		fmt.Println("parseSliceExpression")
+	}
+	exp := &ast.SliceExpression{Token: tok, Left: left, Low: low}
+	p.nextToken()
+
+	if !p.peekTokenIs(token.RSquare) {
+		p.nextToken()
+		exp.High = p.parseExpression(priLowest)
+	}
+
+	if !p.expectPeek(token.RSquare) {
+		return nil
+	}
+	return exp
+}
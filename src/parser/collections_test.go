@@ -51,6 +51,43 @@ func TestParsingIndexExpressions(t *testing.T) {
 	}
 }
 
+func TestParsingSliceExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"myArray[1:3]", "(myArray[1:3])"},
+		{"myArray[:-1]", "(myArray[:(-1)])"},
+		{"myArray[1 + 1:]", "(myArray[(1 + 1):])"},
+		{"myArray[:]", "(myArray[:])"},
+		{"myArray[a:b][0]", "((myArray[a:b])[0])"},
+		{`myArray[{"a": 1}["a"]:]`, `(myArray[({a: 1}[a]):])`},
+	}
+
+	for _, tt := range tests {
+		l := lexer.NewString(tt.input)
+		p := New(l, nil)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("Statement is not ExpressionStatement. got=%T", program.Statements[0])
+		}
+
+		if stmt.Expression.String() != tt.expected {
+			t.Errorf("Incorrect expression. expected=%q, got=%q", tt.expected, stmt.Expression.String())
+		}
+	}
+
+	l := lexer.NewString("myArray[1:2:3]")
+	p := New(l, nil)
+	p.ParseProgram()
+	if len(p.Errors()) == 0 {
+		t.Errorf("Expected error for slice with three indexes")
+	}
+}
+
 func TestParsingHashLiteralsStringKeys(t *testing.T) {
 	input := `{"one": 1, "two": 2, "three": 3}`
 
@@ -75,7 +112,8 @@ func TestParsingHashLiteralsStringKeys(t *testing.T) {
 		"three": 3,
 	}
 
-	for key, value := range hash.Pairs {
+	for _, pair := range hash.Pairs {
+		key, value := pair.Key, pair.Value
 		literal, ok := key.(*ast.StringLiteral)
 		if !ok {
 			t.Errorf("key is not ast.StringLiteral. got=%T", key)
@@ -83,6 +121,12 @@ func TestParsingHashLiteralsStringKeys(t *testing.T) {
 		expectedValue := expected[literal.String()]
 		testIntegerLiteral(t, value, expectedValue)
 	}
+
+	for i, key := range []string{"one", "two", "three"} {
+		if hash.Pairs[i].Key.String() != key {
+			t.Errorf("hash.Pairs[%d] has wrong key. expected=%q, got=%q", i, key, hash.Pairs[i].Key.String())
+		}
+	}
 }
 
 func TestParsingEmptyHashLiteral(t *testing.T) {
@@ -134,7 +178,8 @@ func TestParsingHashLiteralsWithExpressions(t *testing.T) {
 		},
 	}
 
-	for key, value := range hash.Pairs {
+	for _, pair := range hash.Pairs {
+		key, value := pair.Key, pair.Value
 		literal, ok := key.(*ast.StringLiteral)
 		if !ok {
 			t.Errorf("key is not ast.StringLiteral. got=%T", key)
@@ -323,7 +368,8 @@ func TestParsingHashLiteralsMultiLine(t *testing.T) {
 		"three": 3,
 	}
 
-	for key, value := range hash.Pairs {
+	for _, pair := range hash.Pairs {
+		key, value := pair.Key, pair.Value
 		literal, ok := key.(*ast.StringLiteral)
 		if !ok {
 			t.Errorf("key is not ast.StringLiteral. got=%T", key)
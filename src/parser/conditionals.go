@@ -46,7 +46,8 @@ func (p *Parser) parseForLoop() ast.Statement {
 	if p.settings.Debug {
 		fmt.Println("parseForLoop")
 	}
-	loop := &ast.ForLoopStatement{}
+	loop := &ast.ForLoopStatement{Token: p.curToken}
+	functions := p.functions
 	expectClosingParen := false
 
 	if p.peekTokenIs(token.LParen) {
@@ -63,11 +64,24 @@ func (p *Parser) parseForLoop() ast.Statement {
 			p.peekError(token.Identifier)
 			return nil
 		}
-
-		p.insertToken(token.Token{Type: token.Let, Literal: "let"})
 		p.nextToken()
 
-		loop.Init = p.parseDefStatement().(*ast.DefStatement)
+		if p.peekTokenIs(token.Comma) || p.peekIsIn() {
+			if !p.parseForInHeader(loop) {
+				return nil
+			}
+			return p.parseForLoopBody(loop, expectClosingParen, functions)
+		}
+
+		// The initializer is parsed as a let statement
+		p.insertToken(p.curToken)
+		p.curToken = token.Token{Type: token.Let, Literal: "let"}
+
+		init, ok := p.parseDefStatement().(*ast.DefStatement)
+		if !ok {
+			return nil
+		}
+		loop.Init = init
 		if !p.curTokenIs(token.Semicolon) {
 			p.addErrorWithPos("expected semicolon, got %s", p.curToken.Type.String())
 			return nil
@@ -85,6 +99,42 @@ func (p *Parser) parseForLoop() ast.Statement {
 		loop.Iter = p.parseExpression(priLowest)
 	}
 
+	return p.parseForLoopBody(loop, expectClosingParen, functions)
+}
+
+// parseForInHeader parses "key, value in collection". The current token is the first identifier.
+func (p *Parser) parseForInHeader(loop *ast.ForLoopStatement) bool {
+	loop.Value = p.parseIdentifier().(*ast.Identifier)
+
+	if p.peekTokenIs(token.Comma) {
+		p.nextToken()
+		if !p.expectPeek(token.Identifier) {
+			return false
+		}
+		loop.Key = loop.Value
+		loop.Value = p.parseIdentifier().(*ast.Identifier)
+	}
+
+	if !p.peekIsIn() {
+		p.addError("at line %d, col %d Incorrect next token. Expected \"in\", got %q",
+			p.peekToken.Pos.Line, p.peekToken.Pos.Col, p.peekToken.Literal)
+		return false
+	}
+	p.nextToken()
+	p.nextToken()
+
+	loop.Collection = p.parseExpression(priLowest)
+	return loop.Collection != nil
+}
+
+// peekIsIn returns if the next token is "in". It isn't a keyword so it can still be used as an identifier.
+func (p *Parser) peekIsIn() bool {
+	return p.peekTokenIs(token.Identifier) && p.peekToken.Literal == "in"
+}
+
+// parseForLoopBody parses the body of loop. functions is the number of function
+// literals parsed before the loop.
+func (p *Parser) parseForLoopBody(loop *ast.ForLoopStatement, expectClosingParen bool, functions int) ast.Statement {
 	if expectClosingParen && !p.expectPeek(token.RParen) {
 		return nil
 	}
@@ -96,7 +146,7 @@ func (p *Parser) parseForLoop() ast.Statement {
 
 	p.nextToken()
 	loop.Body = p.parseBlockStatements()
-	p.nextToken()
+	loop.HasClosure = p.functions > functions
 
 	if p.peekTokenIs(token.Semicolon) {
 		p.nextToken()
@@ -123,35 +173,73 @@ func (p *Parser) parseTryCatch() ast.Expression {
 	if p.settings.Debug {
 		fmt.Println("parseTryCatch")
 	}
+	tryToken := p.curToken
+
 	if !p.expectPeek(token.LBrace) {
 		return nil
 	}
 
 	try := p.parseBlockStatements()
 
-	if !p.expectPeek(token.Catch) {
-		return nil
+	var catches []*ast.CatchClause
+	for p.peekTokenIs(token.Catch) {
+		p.nextToken()
+		clause := p.parseCatchClause()
+		if clause == nil {
+			return nil
+		}
+		catches = append(catches, clause)
 	}
 
-	var symbol *ast.Identifier
-	if p.peekTokenIs(token.Identifier) {
+	var finally *ast.BlockStatement
+	if p.peekTokenIs(token.Finally) {
 		p.nextToken()
-		symbol = p.parseIdentifier().(*ast.Identifier)
+		if !p.expectPeek(token.LBrace) {
+			return nil
+		}
+		finally = p.parseBlockStatements()
 	}
 
-	if !p.expectPeek(token.LBrace) {
+	// A try needs at least a catch or finally block
+	if len(catches) == 0 && finally == nil {
+		p.peekError(token.Catch)
 		return nil
 	}
 
-	catch := p.parseBlockStatements()
-
 	if p.peekTokenIs(token.Semicolon) {
 		p.nextToken()
 	}
 
 	return &ast.TryCatchExpression{
-		Try:    try,
-		Catch:  catch,
-		Symbol: symbol,
+		Token:   tryToken,
+		Try:     try,
+		Catches: catches,
+		Finally: finally,
+	}
+}
+
+// parseCatchClause parses "catch [symbol[: Class]] { ... }". The current token is catch.
+func (p *Parser) parseCatchClause() *ast.CatchClause {
+	clause := &ast.CatchClause{Token: p.curToken}
+
+	if p.peekTokenIs(token.Identifier) {
+		p.nextToken()
+		clause.Symbol = p.parseIdentifier().(*ast.Identifier)
+
+		if p.peekTokenIs(token.Colon) {
+			p.nextToken()
+			p.nextToken()
+			clause.Class = p.parseExpression(priLowest)
+			if clause.Class == nil {
+				return nil
+			}
+		}
 	}
+
+	if !p.expectPeek(token.LBrace) {
+		return nil
+	}
+
+	clause.Body = p.parseBlockStatements()
+	return clause
 }
@@ -401,3 +401,179 @@ func TestInfiniteForLoopEmptyParens(t *testing.T) {
 		t.Fatalf("Incorrect number of body statements. Expected 1, got %d", len(fl.Body.Statements))
 	}
 }
+
+func TestForInLoop(t *testing.T) {
+	tests := []struct {
+		input      string
+		key        string
+		value      string
+		collection string
+	}{
+		{`for x in items { print(x) }`, "", "x", "items"},
+		{`for (x in items) { print(x) }`, "", "x", "items"},
+		{`for k, v in getMap() { print(v) }`, "k", "v", "getMap()"},
+		{`for (i, in in list[1]) { print(in) }`, "i", "in", "(list[1])"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.NewString(tt.input)
+		p := New(l, nil)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Body does not contain %d statements. got=%d\n",
+				1, len(program.Statements))
+		}
+
+		fl, ok := program.Statements[0].(*ast.ForLoopStatement)
+		if !ok {
+			t.Fatalf("Statement is not for loop. Got %T", program.Statements[0])
+		}
+
+		key := ""
+		if fl.Key != nil {
+			key = fl.Key.Value
+		}
+		if key != tt.key {
+			t.Errorf("Incorrect key variable. Expected %q, got %q", tt.key, key)
+		}
+
+		if fl.Value.Value != tt.value {
+			t.Errorf("Incorrect value variable. Expected %q, got %q", tt.value, fl.Value.Value)
+		}
+
+		if fl.Collection.String() != tt.collection {
+			t.Errorf("Incorrect collection. Expected %s, got %s", tt.collection, fl.Collection.String())
+		}
+
+		if len(fl.Body.Statements) != 1 {
+			t.Fatalf("Incorrect number of body statements. Expected 1, got %d", len(fl.Body.Statements))
+		}
+	}
+}
+
+func TestForLoopHasClosure(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{`for (i = 0; i < 3; i + 1) { print(i) }`, false},
+		{`for (i = 0; i < 3; i + 1) { print(func() { i }) }`, true},
+		{`for (i = 0; i < 3; i + 1) { let c = class { func f() { i } } }`, true},
+		{`for (i = 0; i < 3; i + 1) { for x in [] { print(func() { x }) } }`, true},
+	}
+
+	for _, tt := range tests {
+		l := lexer.NewString(tt.input)
+		p := New(l, nil)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		fl, ok := program.Statements[0].(*ast.ForLoopStatement)
+		if !ok {
+			t.Fatalf("Statement is not for loop. Got %T", program.Statements[0])
+		}
+		if fl.HasClosure != tt.expected {
+			t.Errorf("Incorrect HasClosure for %q. Expected %t, got %t", tt.input, tt.expected, fl.HasClosure)
+		}
+	}
+}
+
+func TestTryCatchClauses(t *testing.T) {
+	input := `try { x } catch e: NotFound { 1 } catch e: errors.Denied { 2 } catch e { 3 }`
+
+	l := lexer.NewString(input)
+	p := New(l, nil)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Body does not contain %d statements. got=%d\n",
+			1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T",
+			program.Statements[0])
+	}
+
+	exp, ok := stmt.Expression.(*ast.TryCatchExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.TryCatchExpression. got=%T",
+			stmt.Expression)
+	}
+
+	expected := []struct {
+		symbol string
+		class  string
+	}{
+		{"e", "NotFound"},
+		{"e", "(errors[Denied])"},
+		{"e", ""},
+	}
+
+	if len(exp.Catches) != len(expected) {
+		t.Fatalf("wrong number of catch clauses. expected=%d, got=%d", len(expected), len(exp.Catches))
+	}
+
+	for i, tt := range expected {
+		clause := exp.Catches[i]
+		if clause.Symbol == nil || clause.Symbol.Value != tt.symbol {
+			t.Errorf("catch %d: wrong symbol. expected=%s, got=%v", i, tt.symbol, clause.Symbol)
+		}
+
+		class := ""
+		if clause.Class != nil {
+			class = clause.Class.String()
+		}
+		if class != tt.class {
+			t.Errorf("catch %d: wrong class. expected=%q, got=%q", i, tt.class, class)
+		}
+
+		if len(clause.Body.Statements) != 1 {
+			t.Errorf("catch %d: body is not 1 statement. got=%d", i, len(clause.Body.Statements))
+		}
+	}
+}
+
+func TestTryFinally(t *testing.T) {
+	tests := []struct {
+		input   string
+		catches int
+	}{
+		{`try { x } finally { y }`, 0},
+		{`try { x } catch e { z } finally { y }`, 1},
+		{`try { x } catch e: A { z } catch { z } finally { y }`, 2},
+	}
+
+	for _, tt := range tests {
+		l := lexer.NewString(tt.input)
+		p := New(l, nil)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		exp, ok := stmt.Expression.(*ast.TryCatchExpression)
+		if !ok {
+			t.Fatalf("stmt.Expression is not ast.TryCatchExpression. got=%T",
+				stmt.Expression)
+		}
+
+		if len(exp.Catches) != tt.catches {
+			t.Errorf("wrong number of catch clauses. expected=%d, got=%d", tt.catches, len(exp.Catches))
+		}
+
+		if exp.Finally == nil || len(exp.Finally.Statements) != 1 {
+			t.Errorf("finally block not parsed for %q", tt.input)
+		}
+	}
+
+	l := lexer.NewString(`try { x }`)
+	p := New(l, nil)
+	p.ParseProgram()
+	if len(p.Errors()) == 0 {
+		t.Error("expected error for try without catch or finally")
+	}
+}
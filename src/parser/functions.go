@@ -12,6 +12,7 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 		fmt.Println("parseFunctionLiteral")
 	}
 	lit := &ast.FunctionLiteral{Token: p.curToken}
+	p.functions++
 
 	if !p.expectPeek(token.LParen) {
 		return nil
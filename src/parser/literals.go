@@ -2,6 +2,7 @@ package parser
 
 import (
 	"fmt"
+	"math/big"
 	"strconv"
 
 	"github.com/nitrogen-lang/nitrogen/src/ast"
@@ -23,6 +24,13 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 
 	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
 	if err != nil {
+		// Literals too large for an int are big ints
+		if err.(*strconv.NumError).Err == strconv.ErrRange {
+			if value, ok := new(big.Int).SetString(p.curToken.Literal, 0); ok {
+				lit.Big = value
+				return lit
+			}
+		}
 		p.addErrorWithPos("Invalid integer: %q", p.curToken.Literal)
 		return nil
 	}
@@ -64,6 +72,36 @@ func (p *Parser) parseStringLiteral() ast.Expression {
 	}
 }
 
+func (p *Parser) parseInterpolatedString() ast.Expression {
+	if p.settings.Debug {
+		fmt.Println("parseInterpolatedString")
+	}
+	str := &ast.InterpolatedString{
+		Token: p.curToken,
+		Parts: []ast.Expression{p.parseStringLiteral()},
+	}
+
+	for {
+		p.nextToken()
+		exp := p.parseExpression(priLowest)
+		if exp == nil {
+			return nil
+		}
+		str.Parts = append(str.Parts, exp)
+
+		if p.peekTokenIs(token.StringMiddle) {
+			p.nextToken()
+			str.Parts = append(str.Parts, p.parseStringLiteral())
+			continue
+		}
+		if !p.expectPeek(token.StringEnd) {
+			return nil
+		}
+		str.Parts = append(str.Parts, p.parseStringLiteral())
+		return str
+	}
+}
+
 func (p *Parser) parseBoolean() ast.Expression {
 	if p.settings.Debug {
 		fmt.Println("parseBoolean")
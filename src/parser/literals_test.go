@@ -69,6 +69,29 @@ func TestIntegerLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestBigIntegerLiteralExpression(t *testing.T) {
+	input := "123456789012345678901234567890;"
+
+	l := lexer.NewString(input)
+	p := New(l, nil)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T",
+			program.Statements[0])
+	}
+
+	literal, ok := stmt.Expression.(*ast.IntegerLiteral)
+	if !ok {
+		t.Fatalf("exp not *ast.IntegerLiteral. got=%T", stmt.Expression)
+	}
+	if literal.Big == nil || literal.Big.String() != "123456789012345678901234567890" {
+		t.Errorf("literal.Big not %s. got=%s", "123456789012345678901234567890", literal.Big)
+	}
+}
+
 func TestFloatLiteralExpression(t *testing.T) {
 	input := "5.5;"
 
@@ -92,7 +115,7 @@ func TestFloatLiteralExpression(t *testing.T) {
 		t.Fatalf("exp not *ast.FloatLiteral. got=%T", stmt.Expression)
 	}
 	if literal.Value != 5.5 {
-		t.Errorf("literal.Value not %d. got=%d", 5, literal.Value)
+		t.Errorf("literal.Value not %g. got=%g", 5.5, literal.Value)
 	}
 	if literal.TokenLiteral() != "5.5" {
 		t.Errorf("literal.TokenLiteral not %s. got=%s", "5.5",
@@ -119,6 +142,38 @@ func TestStringLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestInterpolatedStringExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		parts    int
+		expected string
+	}{
+		{`"Hello ${name}!"`, 3, `"Hello ${name}!"`},
+		{`"${a + 1}${b}"`, 5, `"${(a + 1)}${b}"`},
+		{`"x ${"y ${z}"}"`, 3, `"x ${"y ${z}"}"`},
+	}
+
+	for _, tt := range tests {
+		l := lexer.NewString(tt.input)
+		p := New(l, nil)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		str, ok := stmt.Expression.(*ast.InterpolatedString)
+		if !ok {
+			t.Fatalf("exp not *ast.InterpolatedString. got=%T", stmt.Expression)
+		}
+
+		if len(str.Parts) != tt.parts {
+			t.Errorf("wrong number of parts for %s. expected=%d, got=%d", tt.input, tt.parts, len(str.Parts))
+		}
+		if str.String() != tt.expected {
+			t.Errorf("wrong string. expected=%s, got=%s", tt.expected, str.String())
+		}
+	}
+}
+
 func TestNullLiteral(t *testing.T) {
 	input := "nil"
 
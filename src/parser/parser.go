@@ -12,7 +12,7 @@ const (
 	priLowest      int = iota
 	priCompare         // and, or
 	priEquals          // ==
-	priLessGreater     // > or <
+	priLessGreater     // > or <, in
 	priSum             // +, -
 	priProduct         // *, /
 	priPrefix          // -x or !x
@@ -70,6 +70,10 @@ type Parser struct {
 	peekToken token.Token
 
 	insertedTokens []token.Token
+	// unclosedBlock is set when a block reaches the end of input
+	unclosedBlock bool
+	// functions counts the parsed function literals
+	functions int
 
 	prefixParseFns map[token.TokenType]prefixParseFn
 	infixParseFns  map[token.TokenType]infixParseFn
@@ -94,6 +98,7 @@ func New(l *lexer.Lexer, settings *Settings) *Parser {
 	p.registerPrefix(token.Float, p.parseFloatLiteral)
 	p.registerPrefix(token.Nil, p.parseNullLiteral)
 	p.registerPrefix(token.String, p.parseStringLiteral)
+	p.registerPrefix(token.StringStart, p.parseInterpolatedString)
 	p.registerPrefix(token.True, p.parseBoolean)
 	p.registerPrefix(token.False, p.parseBoolean)
 	p.registerPrefix(token.LSquare, p.parseArrayLiteral)
@@ -137,6 +142,8 @@ func New(l *lexer.Lexer, settings *Settings) *Parser {
 	p.registerInfix(token.BitwiseAndNot, p.parseInfixExpression)
 	p.registerInfix(token.BitwiseOr, p.parseInfixExpression)
 	p.registerInfix(token.Carrot, p.parseInfixExpression)
+	// "in" isn't a keyword, an identifier only parses as an infix operator when it's "in"
+	p.registerInfix(token.Identifier, p.parseInfixExpression)
 
 	// Read the first two tokens to populate curToken and peekToken
 	p.nextToken()
@@ -293,6 +300,14 @@ func (p *Parser) parseBlockStatements() *ast.BlockStatement {
 	p.nextToken()
 
 	for !p.curTokenIs(token.RBrace) {
+		if p.curTokenIs(token.EOF) {
+			// Blocks around this one also end here, only the innermost is reported
+			if !p.unclosedBlock {
+				p.addErrorWithPos("Unexpected end of input, expected %q", token.RBrace.String())
+				p.unclosedBlock = true
+			}
+			return block
+		}
 		stmt := p.parseStatement()
 		if stmt != nil {
 			block.Statements = append(block.Statements, stmt)
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/nitrogen-lang/nitrogen/src/ast"
+	"github.com/nitrogen-lang/nitrogen/src/lexer"
 )
 
 func testDefStatement(t *testing.T, s ast.Statement, name string) bool {
@@ -191,3 +192,50 @@ func checkParserErrors(t *testing.T, p *Parser) {
 	}
 	t.FailNow()
 }
+
+func TestReturnBeforeBrace(t *testing.T) {
+	l := lexer.NewString("func f() { return }")
+	p := New(l, nil)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	fn := program.Statements[0].(*ast.DefStatement).Value.(*ast.FunctionLiteral)
+	if len(fn.Body.Statements) != 1 {
+		t.Fatalf("function body does not contain 1 statement. got=%d", len(fn.Body.Statements))
+	}
+	returnStmt, ok := fn.Body.Statements[0].(*ast.ReturnStatement)
+	if !ok {
+		t.Fatalf("stmt not *ast.ReturnStatement. got=%T", fn.Body.Statements[0])
+	}
+	if _, ok := returnStmt.Value.(*ast.NullLiteral); !ok {
+		t.Fatalf("return value not *ast.NullLiteral. got=%T", returnStmt.Value)
+	}
+}
+
+func TestIncompleteInput(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"func f() {\n    if true {", `at line 2, col 12 Unexpected end of input, expected "}"`},
+		{"class A {", `at line 1, col 8 Unexpected end of input, expected "}"`},
+		{"for item i", `at line 1, col 9 Incorrect next token. Expected "=", got "IDENT"`},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.NewString(tt.input), nil)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("expected an error for %q", tt.input)
+			continue
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("wrong error for %q. expected=%q, got=%q", tt.input, tt.expected, errors[0])
+		}
+		if len(errors) > 1 && errors[1] == errors[0] {
+			t.Errorf("error reported twice for %q", tt.input)
+		}
+	}
+}
@@ -23,14 +23,18 @@ func (p *Parser) expectPeek(t token.TokenType) bool {
 }
 
 func (p *Parser) peekPrecedence() int {
-	if p, ok := precedences[p.peekToken.Type]; ok {
-		return p
-	}
-	return priLowest
+	return tokenPrecedence(p.peekToken)
 }
 
 func (p *Parser) curPrecedence() int {
-	if p, ok := precedences[p.curToken.Type]; ok {
+	return tokenPrecedence(p.curToken)
+}
+
+func tokenPrecedence(t token.Token) int {
+	if t.Type == token.Identifier && t.Literal == "in" {
+		return priLessGreater
+	}
+	if p, ok := precedences[t.Type]; ok {
 		return p
 	}
 	return priLowest
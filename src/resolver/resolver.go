@@ -0,0 +1,436 @@
+// Package resolver statically resolves the identifiers of a program to the
+// scope that declares them before the program is run, reporting variables
+// that are used before they're declared or assigned without a declaration.
+// Resolved identifiers record how many environments up their declaration is
+// so the interpreter doesn't need to search for it.
+package resolver
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/nitrogen-lang/nitrogen/src/ast"
+	"github.com/nitrogen-lang/nitrogen/src/token"
+)
+
+// Error is a problem found while resolving a program.
+type Error struct {
+	Token   token.Token
+	Message string
+}
+
+func (e *Error) Error() string {
+	filename := e.Token.Filename
+	if filename == "" {
+		filename = "<input>"
+	}
+	return fmt.Sprintf("%s at %s:%d:%d", e.Message, filename, e.Token.Pos.Line, e.Token.Pos.Col)
+}
+
+// Result holds the errors of a resolved program.
+type Result struct {
+	Errors []*Error
+}
+
+type scopeKind int
+
+const (
+	programScope scopeKind = iota
+	functionScope
+	// Block scopes are loop headers and bodies, each has its own environment.
+	blockScope
+	// Catch scopes hold the exception symbol in the environment of the enclosing scope.
+	catchScope
+	// Class bodies are resolved at runtime against the environment the instance is made in.
+	classScope
+)
+
+type scope struct {
+	kind  scopeKind
+	outer *scope
+	// env is the scope that owns the environment declarations are stored in.
+	env *scope
+	// names holds every name declared anywhere in the scope.
+	names    map[string]bool
+	declared map[string]bool
+}
+
+type resolver struct {
+	scope    *scope
+	isGlobal func(name string) bool
+	result   *Result
+}
+
+// Resolve checks the identifiers of program against their declarations. isGlobal
+// reports if a name is defined before the program runs, such as builtins or
+// variables of the environment the program is evaluated in. It's
+// an error to use a variable before it's declared in the same function, or to
+// assign a variable that's never declared.
+func Resolve(program *ast.Program, isGlobal func(name string) bool) *Result {
+	r := &resolver{
+		isGlobal: isGlobal,
+		result:   &Result{},
+	}
+	r.openScope(programScope)
+	r.hoist(program.Statements)
+	r.statements(program.Statements)
+	return r.result
+}
+
+func (r *resolver) openScope(kind scopeKind) *scope {
+	s := &scope{
+		kind:     kind,
+		outer:    r.scope,
+		names:    make(map[string]bool),
+		declared: make(map[string]bool),
+	}
+	s.env = s
+	if kind == catchScope {
+		s.env = r.scope.env
+	}
+	r.scope = s
+	return s
+}
+
+func (r *resolver) closeScope() {
+	r.scope = r.scope.outer
+}
+
+// hoist records every name declared in statements so uses can be
+// checked against declarations that come later in the scope.
+func (r *resolver) hoist(statements []ast.Statement) {
+	for _, stmt := range statements {
+		switch stmt := stmt.(type) {
+		case *ast.DefStatement:
+			if stmt == nil {
+				continue
+			}
+			if r.scope.kind != classScope {
+				r.scope.env.names[stmt.Name.Value] = true
+			}
+			r.hoistExpression(stmt.Value)
+		case *ast.ExpressionStatement:
+			if stmt != nil {
+				r.hoistExpression(stmt.Expression)
+			}
+		}
+	}
+}
+
+// hoistExpression hoists the declarations of blocks that don't have their own environment.
+func (r *resolver) hoistExpression(exp ast.Expression) {
+	switch exp := exp.(type) {
+	case *ast.AssignStatement:
+		if exp != nil {
+			r.hoistExpression(exp.Value)
+		}
+	case *ast.IfExpression:
+		if exp == nil {
+			return
+		}
+		r.hoistBlock(exp.Consequence)
+		r.hoistBlock(exp.Alternative)
+	case *ast.TryCatchExpression:
+		if exp == nil {
+			return
+		}
+		r.hoistBlock(exp.Try)
+		for _, c := range exp.Catches {
+			r.hoistBlock(c.Body)
+		}
+		r.hoistBlock(exp.Finally)
+	}
+}
+
+func (r *resolver) hoistBlock(block *ast.BlockStatement) {
+	if block != nil {
+		r.hoist(block.Statements)
+	}
+}
+
+// declare marks name as declared in the current environment.
+func (r *resolver) declare(ident *ast.Identifier) {
+	if ident == nil || r.scope.kind == classScope {
+		return
+	}
+	r.declareIn(r.scope.env, ident)
+}
+
+func (r *resolver) declareIn(s *scope, ident *ast.Identifier) {
+	s.names[ident.Value] = true
+	s.declared[ident.Value] = true
+	ident.Resolved, ident.Depth = true, 0
+}
+
+type lookupResult int
+
+const (
+	unknown lookupResult = iota
+	resolved
+	// Dynamic names are inside a class body and resolved at runtime.
+	dynamic
+	// Later names are declared after their use in the same function.
+	later
+)
+
+// lookup finds the declaration of ident and binds ident to it if it's resolved.
+func (r *resolver) lookup(ident *ast.Identifier) lookupResult {
+	result := unknown
+	crossedFunction := false
+	depth := 0
+	ident.Resolved = false
+
+	for s := r.scope; s != nil; s = s.outer {
+		if s.kind == classScope {
+			return dynamic
+		}
+		if s.names[ident.Value] {
+			// A function can be called after variables declared after it
+			if s.declared[ident.Value] || crossedFunction {
+				ident.Resolved, ident.Depth = true, depth
+				return resolved
+			}
+			result = later
+		}
+		if s.kind == functionScope {
+			crossedFunction = true
+		}
+		if s.env == s {
+			depth++
+		}
+	}
+	return result
+}
+
+func (r *resolver) use(ident *ast.Identifier) {
+	if r.lookup(ident) == later && !r.isGlobal(ident.Value) {
+		r.error(ident.Token, "Use of variable %s before declaration", ident.Value)
+	}
+}
+
+func (r *resolver) assign(ident *ast.Identifier) {
+	switch r.lookup(ident) {
+	case later:
+		if !r.isGlobal(ident.Value) {
+			r.error(ident.Token, "Use of variable %s before declaration", ident.Value)
+		}
+	case unknown:
+		if !r.isGlobal(ident.Value) {
+			r.error(ident.Token, "Assignment to uninitialized variable %s", ident.Value)
+		}
+	}
+}
+
+func (r *resolver) error(tok token.Token, format string, args ...interface{}) {
+	r.result.Errors = append(r.result.Errors, &Error{
+		Token:   tok,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (r *resolver) statements(statements []ast.Statement) {
+	for _, stmt := range statements {
+		r.statement(stmt)
+	}
+}
+
+func (r *resolver) block(block *ast.BlockStatement) {
+	if block != nil {
+		r.statements(block.Statements)
+	}
+}
+
+func (r *resolver) statement(stmt ast.Statement) {
+	switch stmt := stmt.(type) {
+	case *ast.DefStatement:
+		if stmt == nil {
+			return
+		}
+		r.expression(stmt.Value)
+		r.declare(stmt.Name)
+	case *ast.ReturnStatement:
+		if stmt != nil {
+			r.expression(stmt.Value)
+		}
+	case *ast.ExpressionStatement:
+		if stmt != nil {
+			r.expression(stmt.Expression)
+		}
+	case *ast.ThrowStatement:
+		if stmt != nil {
+			r.expression(stmt.Expression)
+		}
+	case *ast.BlockStatement:
+		r.block(stmt)
+	case *ast.ForLoopStatement:
+		if stmt != nil {
+			r.forLoop(stmt)
+		}
+	}
+}
+
+func (r *resolver) forLoop(loop *ast.ForLoopStatement) {
+	if loop.Collection != nil {
+		r.expression(loop.Collection)
+
+		r.openScope(blockScope)
+		r.declare(loop.Key)
+		r.declare(loop.Value)
+		r.hoistBlock(loop.Body)
+		r.block(loop.Body)
+		r.closeScope()
+		return
+	}
+
+	r.openScope(blockScope)
+	if loop.Init != nil {
+		r.statement(loop.Init)
+	}
+	r.expression(loop.Condition)
+
+	r.openScope(blockScope)
+	r.hoistBlock(loop.Body)
+	r.block(loop.Body)
+	r.closeScope()
+
+	r.expression(loop.Iter)
+	r.closeScope()
+}
+
+func (r *resolver) function(fn *ast.FunctionLiteral) {
+	r.openScope(functionScope)
+	for _, param := range fn.Parameters {
+		r.declare(param)
+	}
+	r.scope.names["args"] = true
+	r.scope.declared["args"] = true
+
+	r.hoistBlock(fn.Body)
+	r.block(fn.Body)
+	r.closeScope()
+}
+
+func (r *resolver) class(class *ast.ClassLiteral) {
+	r.openScope(classScope)
+	for _, field := range class.Fields {
+		r.statement(field)
+	}
+	names := make([]string, 0, len(class.Methods))
+	for name := range class.Methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		r.function(class.Methods[name])
+	}
+	r.closeScope()
+}
+
+func (r *resolver) expressions(exps []ast.Expression) {
+	for _, exp := range exps {
+		r.expression(exp)
+	}
+}
+
+func (r *resolver) expression(exp ast.Expression) {
+	switch exp := exp.(type) {
+	case *ast.Identifier:
+		if exp != nil {
+			r.use(exp)
+		}
+	case *ast.AssignStatement:
+		if exp == nil {
+			return
+		}
+		r.expression(exp.Value)
+		if ident, ok := exp.Left.(*ast.Identifier); ok && ident != nil {
+			r.assign(ident)
+		} else {
+			r.expression(exp.Left)
+		}
+	case *ast.PrefixExpression:
+		if exp != nil {
+			r.expression(exp.Right)
+		}
+	case *ast.InfixExpression:
+		if exp != nil {
+			r.expression(exp.Left)
+			r.expression(exp.Right)
+		}
+	case *ast.CompareExpression:
+		if exp != nil {
+			r.expression(exp.Left)
+			r.expression(exp.Right)
+		}
+	case *ast.IfExpression:
+		if exp != nil {
+			r.expression(exp.Condition)
+			r.block(exp.Consequence)
+			r.block(exp.Alternative)
+		}
+	case *ast.CallExpression:
+		if exp != nil {
+			r.expression(exp.Function)
+			r.expressions(exp.Arguments)
+		}
+	case *ast.IndexExpression:
+		if exp != nil {
+			r.expression(exp.Left)
+			r.expression(exp.Index)
+		}
+	case *ast.SliceExpression:
+		if exp != nil {
+			r.expression(exp.Left)
+			r.expression(exp.Low)
+			r.expression(exp.High)
+		}
+	case *ast.TryCatchExpression:
+		if exp != nil {
+			r.tryCatch(exp)
+		}
+	case *ast.MakeInstance:
+		if exp != nil {
+			r.expression(exp.Class)
+			r.expressions(exp.Arguments)
+		}
+	case *ast.FunctionLiteral:
+		if exp != nil {
+			r.function(exp)
+		}
+	case *ast.ClassLiteral:
+		if exp != nil {
+			r.class(exp)
+		}
+	case *ast.Array:
+		if exp != nil {
+			r.expressions(exp.Elements)
+		}
+	case *ast.InterpolatedString:
+		if exp != nil {
+			r.expressions(exp.Parts)
+		}
+	case *ast.HashLiteral:
+		if exp != nil {
+			for _, pair := range exp.Pairs {
+				r.expression(pair.Key)
+				r.expression(pair.Value)
+			}
+		}
+	}
+}
+
+func (r *resolver) tryCatch(exp *ast.TryCatchExpression) {
+	r.block(exp.Try)
+	for _, clause := range exp.Catches {
+		r.expression(clause.Class)
+		if clause.Symbol == nil {
+			r.block(clause.Body)
+			continue
+		}
+		// The symbol gets its own scope since it's only visible in the catch block
+		r.declareIn(r.openScope(catchScope), clause.Symbol)
+		r.block(clause.Body)
+		r.closeScope()
+	}
+	r.block(exp.Finally)
+}
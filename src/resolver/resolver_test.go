@@ -0,0 +1,156 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/nitrogen-lang/nitrogen/src/ast"
+	"github.com/nitrogen-lang/nitrogen/src/lexer"
+	"github.com/nitrogen-lang/nitrogen/src/parser"
+)
+
+func resolveTest(input string, t *testing.T) *Result {
+	p := parser.New(lexer.NewString(input), nil)
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatal(p.Errors()[0])
+	}
+	return Resolve(program, func(name string) bool { return name == "println" })
+}
+
+// findIdents returns the identifiers named name in node in the order they're evaluated.
+func findIdents(node ast.Node, name string) []*ast.Identifier {
+	var idents []*ast.Identifier
+	var walk func(node ast.Node)
+	walk = func(node ast.Node) {
+		switch node := node.(type) {
+		case *ast.Program:
+			for _, stmt := range node.Statements {
+				walk(stmt)
+			}
+		case *ast.BlockStatement:
+			for _, stmt := range node.Statements {
+				walk(stmt)
+			}
+		case *ast.DefStatement:
+			walk(node.Name)
+			walk(node.Value)
+		case *ast.ExpressionStatement:
+			walk(node.Expression)
+		case *ast.AssignStatement:
+			walk(node.Left)
+			walk(node.Value)
+		case *ast.ForLoopStatement:
+			walk(node.Init)
+			walk(node.Condition)
+			walk(node.Iter)
+			walk(node.Body)
+		case *ast.FunctionLiteral:
+			for _, param := range node.Parameters {
+				walk(param)
+			}
+			walk(node.Body)
+		case *ast.CallExpression:
+			walk(node.Function)
+			for _, arg := range node.Arguments {
+				walk(arg)
+			}
+		case *ast.InfixExpression:
+			walk(node.Left)
+			walk(node.Right)
+		case *ast.Identifier:
+			if node.Value == name {
+				idents = append(idents, node)
+			}
+		}
+	}
+	walk(node)
+	return idents
+}
+
+func TestBindings(t *testing.T) {
+	input := `let a = 1
+let b = 2
+func f(x) {
+    for (i = 0; i < x; i + 1) {
+        println(a + b + i)
+    }
+}`
+	p := parser.New(lexer.NewString(input), nil)
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatal(p.Errors()[0])
+	}
+	if result := Resolve(program, func(name string) bool { return name == "println" }); len(result.Errors) > 0 {
+		t.Fatal(result.Errors[0])
+	}
+
+	tests := []struct {
+		name   string
+		depths []int
+	}{
+		// Declaration in the program and the use in the loop body
+		{"a", []int{0, 3}},
+		{"b", []int{0, 3}},
+		// Parameter, then the use in the loop condition
+		{"x", []int{0, 1}},
+		// Declaration, condition, iterator, and body
+		{"i", []int{0, 0, 0, 1}},
+	}
+
+	for _, tt := range tests {
+		idents := findIdents(program, tt.name)
+		if len(idents) != len(tt.depths) {
+			t.Errorf("wrong number of uses of %s. expected=%d, got=%d", tt.name, len(tt.depths), len(idents))
+			continue
+		}
+		for i, ident := range idents {
+			if !ident.Resolved || ident.Depth != tt.depths[i] {
+				t.Errorf("wrong binding %d for %s. expected depth %d, got resolved=%t depth=%d",
+					i, tt.name, tt.depths[i], ident.Resolved, ident.Depth)
+			}
+		}
+	}
+
+	for _, ident := range findIdents(program, "println") {
+		if ident.Resolved {
+			t.Error("globals should not be resolved")
+		}
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		line     int
+	}{
+		{"println(a)\nlet a = 1", "Use of variable a before declaration", 1},
+		{"let a = 1\nfunc f() {\n    b = a\n}", "Assignment to uninitialized variable b", 3},
+		{"for x in [] {\n    println(y)\n    let y = x\n}", "Use of variable y before declaration", 2},
+		{"try {} catch e {\n    e = 1\n}\ne = 2", "Assignment to uninitialized variable e", 4},
+		{"println = 1", "", 0},
+		{"func f() { g() }\nfunc g() { f() }", "", 0},
+		{"class A { func f() { x = 1 } }", "", 0},
+		{"let a = 1\nfunc f(x) {\n    for (i = 0; i < x; i + 1) {\n        println(a + i)\n    }\n}", "", 0},
+		{"for (i = 0; i < 3; i + 1) {\n    let j = i\n    i = j + 1\n}", "", 0},
+	}
+
+	for _, tt := range tests {
+		result := resolveTest(tt.input, t)
+		if tt.expected == "" {
+			if len(result.Errors) > 0 {
+				t.Errorf("unexpected error for %q: %s", tt.input, result.Errors[0])
+			}
+			continue
+		}
+
+		if len(result.Errors) != 1 {
+			t.Errorf("expected 1 error for %q, got %d", tt.input, len(result.Errors))
+			continue
+		}
+		err := result.Errors[0]
+		if err.Message != tt.expected || err.Token.Pos.Line != tt.line {
+			t.Errorf("wrong error for %q. expected=%s on line %d, got=%s", tt.input, tt.expected, tt.line, err)
+		}
+	}
+}
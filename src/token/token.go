@@ -30,6 +30,11 @@ const (
 	Integer
 	Float
 	String
+	// An interpolated string is split into the text before the first
+	// interpolation, the text between interpolations, and the text after the last.
+	StringStart
+	StringMiddle
+	StringEnd
 
 	// Operators
 	Assign
@@ -94,6 +99,7 @@ const (
 	Break
 	Try
 	Catch
+	Finally
 	Throw
 	Class
 	Make
@@ -112,6 +118,10 @@ var tokens = [...]string{
 	Float:      "FLOAT",
 	String:     "STRING",
 
+	StringStart:  "STRING_START",
+	StringMiddle: "STRING_MIDDLE",
+	StringEnd:    "STRING_END",
+
 	// Operators
 	Assign:   "=",
 	Plus:     "+",
@@ -173,6 +183,7 @@ var tokens = [...]string{
 	Break:    "break",
 	Try:      "try",
 	Catch:    "catch",
+	Finally:  "finally",
 	Throw:    "throw",
 	Class:    "class",
 	Make:     "make",
@@ -187,6 +198,15 @@ func init() {
 	}
 }
 
+// Keywords returns the keywords of the language.
+func Keywords() []string {
+	names := make([]string, 0, keywordEnd-keywordBeg-1)
+	for i := keywordBeg + 1; i < keywordEnd; i++ {
+		names = append(names, tokens[i])
+	}
+	return names
+}
+
 func LookupIdent(ident string) TokenType {
 	if tok, ok := keywords[ident]; ok {
 		return tok
@@ -0,0 +1,102 @@
+package vet
+
+import "fmt"
+
+// argCount is the number of arguments a builtin function takes. A max of -1
+// means there's no limit.
+type argCount struct {
+	min, max int
+}
+
+// check returns a message if n arguments aren't valid for the builtin name.
+func (a argCount) check(name string, n int) string {
+	switch {
+	case a.min == a.max && n != a.min:
+		return fmt.Sprintf("%s expects %d argument(s). Got %d", name, a.min, n)
+	case n < a.min && a.max == -1:
+		return fmt.Sprintf("%s expects at least %d argument(s). Got %d", name, a.min, n)
+	case n < a.min || a.max != -1 && n > a.max:
+		return fmt.Sprintf("%s expects %d to %d arguments. Got %d", name, a.min, a.max, n)
+	}
+	return ""
+}
+
+// builtinArgs holds the argument counts of the builtin functions, they
+// match the checks in the builtins packages and the documented signatures.
+var builtinArgs = map[string]argCount{
+	// Types
+	"toInt":      {1, 1},
+	"toFloat":    {1, 1},
+	"toString":   {1, 1},
+	"decimal":    {1, 3},
+	"parseInt":   {1, 1},
+	"parseFloat": {1, 1},
+	"varType":    {1, 1},
+	"isDefined":  {1, 1},
+	"isFloat":    {1, 1},
+	"isInt":      {1, 1},
+	"isDecimal":  {1, 1},
+	"isBool":     {1, 1},
+	"isNull":     {1, 1},
+	"isFunc":     {1, 1},
+	"isString":   {1, 1},
+	"isArray":    {1, 1},
+	"isMap":      {1, 1},
+	"isError":    {1, 1},
+	"isClass":    {1, 1},
+	"isInstance": {1, 1},
+	"errorVal":   {1, 1},
+	"errorTrace": {1, 1},
+	"is_a":       {2, 2},
+	"classOf":    {1, 1},
+
+	// Imports
+	"module":           {1, 2},
+	"modulesSupported": {0, 0},
+	"include":          {1, 2},
+	"require":          {1, 2},
+	"evalScript":       {1, 2},
+
+	// IO
+	"print":    {0, -1},
+	"println":  {0, -1},
+	"printlnb": {1, 1},
+	"printenv": {0, 0},
+	"readline": {0, 1},
+
+	// Collections
+	"bytes":      {1, 1},
+	"fromBytes":  {1, 1},
+	"byteLen":    {1, 1},
+	"byteSlice":  {2, 3},
+	"len":        {1, 1},
+	"first":      {1, 1},
+	"last":       {1, 1},
+	"rest":       {1, 1},
+	"push":       {2, 2},
+	"pop":        {1, 1},
+	"shift":      {1, 1},
+	"unshift":    {2, 2},
+	"insert":     {3, 3},
+	"splice":     {3, -1},
+	"slice":      {2, 3},
+	"indexOf":    {2, 2},
+	"contains":   {2, 2},
+	"reverse":    {1, 1},
+	"unique":     {1, 1},
+	"sort":       {1, 2},
+	"sortBy":     {2, 2},
+	"hashMerge":  {2, 3},
+	"hashKeys":   {1, 1},
+	"hashValues": {1, 1},
+	"hashHasKey": {2, 2},
+	"hashDelete": {2, 2},
+	"map":        {2, 2},
+	"filter":     {2, 2},
+	"reduce":     {2, 3},
+	"any":        {1, 2},
+	"all":        {1, 2},
+	"zip":        {1, -1},
+	"enumerate":  {1, 1},
+	"range":      {1, 3},
+}
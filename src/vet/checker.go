@@ -0,0 +1,496 @@
+package vet
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nitrogen-lang/nitrogen/src/ast"
+	"github.com/nitrogen-lang/nitrogen/src/resolver"
+	"github.com/nitrogen-lang/nitrogen/src/token"
+)
+
+// The scopes match the environments the interpreter creates, see the resolver package.
+type scopeKind int
+
+const (
+	programScope scopeKind = iota
+	functionScope
+	blockScope
+	catchScope
+	classScope
+)
+
+type declKind int
+
+const (
+	variableDecl declKind = iota
+	constantDecl
+	paramDecl
+	// Other declarations are loop variables, catch symbols, and args.
+	otherDecl
+)
+
+type decl struct {
+	ident *ast.Identifier
+	kind  declKind
+	used  bool
+	// local declarations are reported if they're never used
+	local bool
+}
+
+type scope struct {
+	kind  scopeKind
+	outer *scope
+	// env is the scope that owns the environment declarations are stored in.
+	env   *scope
+	decls map[string]*decl
+}
+
+type checker struct {
+	filename string
+	scope    *scope
+	// loops is the number of loops around the current statement in the current function.
+	loops int
+	diags []*Diagnostic
+}
+
+func newChecker(filename string) *checker {
+	if filename == "" {
+		filename = "<input>"
+	}
+	return &checker{filename: filename}
+}
+
+func (c *checker) report(pos token.Position, check, format string, args ...interface{}) {
+	c.diags = append(c.diags, &Diagnostic{
+		Filename: c.filename,
+		Line:     pos.Line,
+		Col:      pos.Col,
+		Check:    check,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (c *checker) program(program *ast.Program) {
+	// Uses before declaration and assignments to undeclared variables are
+	// found by the resolver like when the program is run.
+	for _, err := range resolver.Resolve(program, isGlobal).Errors {
+		c.report(err.Token.Pos, CheckUndeclared, "%s", err.Message)
+	}
+
+	c.openScope(programScope)
+	c.hoist(program.Statements)
+	c.statements(program.Statements)
+	c.closeScope()
+}
+
+func (c *checker) openScope(kind scopeKind) *scope {
+	s := &scope{
+		kind:  kind,
+		outer: c.scope,
+		decls: make(map[string]*decl),
+	}
+	s.env = s
+	if kind == catchScope {
+		s.env = c.scope.env
+	}
+	c.scope = s
+	return s
+}
+
+// closeScope reports the unused local variables of the scope.
+func (c *checker) closeScope() {
+	s := c.scope
+	c.scope = s.outer
+	if s.env != s {
+		return
+	}
+	for _, d := range s.decls {
+		if d.local && !d.used && !strings.HasPrefix(d.ident.Value, "_") {
+			c.report(d.ident.Token.Pos, CheckUnused, "%s is declared but never used", d.ident.Value)
+		}
+	}
+}
+
+// hoist adds every name declared in statements to the current environment
+// since functions can use variables declared after them.
+func (c *checker) hoist(statements []ast.Statement) {
+	if c.scope.kind == classScope {
+		return
+	}
+	for _, stmt := range statements {
+		switch stmt := stmt.(type) {
+		case *ast.DefStatement:
+			env := c.scope.env
+			if _, exists := env.decls[stmt.Name.Value]; !exists {
+				kind := variableDecl
+				if stmt.Const {
+					kind = constantDecl
+				}
+				env.decls[stmt.Name.Value] = &decl{
+					ident: stmt.Name,
+					kind:  kind,
+					local: env.kind != programScope,
+				}
+			}
+			c.hoistExpression(stmt.Value)
+		case *ast.ExpressionStatement:
+			c.hoistExpression(stmt.Expression)
+		}
+	}
+}
+
+// hoistExpression hoists the declarations of blocks that don't have their own environment.
+func (c *checker) hoistExpression(exp ast.Expression) {
+	switch exp := exp.(type) {
+	case *ast.AssignStatement:
+		c.hoistExpression(exp.Value)
+	case *ast.IfExpression:
+		c.hoistBlock(exp.Consequence)
+		c.hoistBlock(exp.Alternative)
+	case *ast.TryCatchExpression:
+		c.hoistBlock(exp.Try)
+		for _, clause := range exp.Catches {
+			c.hoistBlock(clause.Body)
+		}
+		c.hoistBlock(exp.Finally)
+	}
+}
+
+func (c *checker) hoistBlock(block *ast.BlockStatement) {
+	if block != nil {
+		c.hoist(block.Statements)
+	}
+}
+
+// declare adds a declaration that isn't hoisted to the current scope.
+func (c *checker) declare(ident *ast.Identifier, kind declKind) {
+	if ident == nil {
+		return
+	}
+	c.checkShadow(ident, c.scope.outer)
+	c.scope.decls[ident.Value] = &decl{ident: ident, kind: kind}
+}
+
+// checkShadow reports if ident hides a parameter declared in from or a scope around it.
+func (c *checker) checkShadow(ident *ast.Identifier, from *scope) {
+	for s := from; s != nil; s = s.outer {
+		d, ok := s.decls[ident.Value]
+		if !ok {
+			continue
+		}
+		if d.kind == paramDecl {
+			c.report(ident.Token.Pos, CheckShadow, "%s shadows the parameter declared at line %d",
+				ident.Value, d.ident.Token.Pos.Line)
+		}
+		return
+	}
+}
+
+// lookup finds the declaration of name. Names used in a class body are
+// resolved at runtime so dynamic is true if the lookup crossed a class.
+func (c *checker) lookup(name string) (d *decl, dynamic bool) {
+	for s := c.scope; s != nil; s = s.outer {
+		if s.kind == classScope {
+			dynamic = true
+		}
+		if d, ok := s.decls[name]; ok {
+			return d, dynamic
+		}
+	}
+	return nil, dynamic
+}
+
+// use marks the declaration of ident as used.
+func (c *checker) use(ident *ast.Identifier, call bool) {
+	d, dynamic := c.lookup(ident.Value)
+	if d != nil {
+		d.used = true
+		return
+	}
+	if dynamic || isGlobal(ident.Value) {
+		return
+	}
+
+	if call {
+		c.report(ident.Token.Pos, CheckUndefined, "Call of undefined function %s", ident.Value)
+	} else {
+		c.report(ident.Token.Pos, CheckUndefined, "Undefined variable %s", ident.Value)
+	}
+}
+
+func (c *checker) statements(statements []ast.Statement) {
+	terminated := false
+	for _, stmt := range statements {
+		if terminated {
+			c.report(stmtPos(stmt), CheckUnreachable, "Unreachable code")
+			terminated = false
+		}
+		c.statement(stmt)
+		if terminates(stmt) {
+			terminated = true
+		}
+	}
+}
+
+// stmtPos returns the position of a statement's first token. Function and
+// class declarations are parsed as let statements without a position.
+func stmtPos(stmt ast.Statement) token.Position {
+	if def, ok := stmt.(*ast.DefStatement); ok && def.Token.Pos.Line == 0 {
+		return ast.NodeToken(def.Value).Pos
+	}
+	return ast.NodeToken(stmt).Pos
+}
+
+// terminates reports if the statements after stmt in a block never run.
+func terminates(stmt ast.Statement) bool {
+	switch stmt := stmt.(type) {
+	case *ast.ReturnStatement, *ast.ThrowStatement, *ast.BreakStatement, *ast.ContinueStatement:
+		return true
+	case *ast.ExpressionStatement:
+		ifExp, ok := stmt.Expression.(*ast.IfExpression)
+		return ok && ifExp.Alternative != nil && blockTerminates(ifExp.Consequence) && blockTerminates(ifExp.Alternative)
+	}
+	return false
+}
+
+func blockTerminates(block *ast.BlockStatement) bool {
+	for _, stmt := range block.Statements {
+		if terminates(stmt) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *checker) block(block *ast.BlockStatement) {
+	if block != nil {
+		c.statements(block.Statements)
+	}
+}
+
+func (c *checker) statement(stmt ast.Statement) {
+	switch stmt := stmt.(type) {
+	case *ast.DefStatement:
+		c.expression(stmt.Value)
+		c.define(stmt)
+	case *ast.ReturnStatement:
+		c.expression(stmt.Value)
+	case *ast.ExpressionStatement:
+		c.expression(stmt.Expression)
+	case *ast.ThrowStatement:
+		c.expression(stmt.Expression)
+	case *ast.BlockStatement:
+		c.block(stmt)
+	case *ast.ForLoopStatement:
+		c.forLoop(stmt)
+	case *ast.BreakStatement:
+		if c.loops == 0 {
+			c.report(stmt.Token.Pos, CheckLoopControl, "break outside of a loop")
+		}
+	case *ast.ContinueStatement:
+		if c.loops == 0 {
+			c.report(stmt.Token.Pos, CheckLoopControl, "continue outside of a loop")
+		}
+	}
+}
+
+// define checks a hoisted declaration when it's reached.
+func (c *checker) define(stmt *ast.DefStatement) {
+	if c.scope.kind == classScope {
+		return
+	}
+
+	env := c.scope.env
+	d, ok := env.decls[stmt.Name.Value]
+	if !ok {
+		// Declarations in expressions aren't hoisted
+		c.hoist([]ast.Statement{stmt})
+		d = env.decls[stmt.Name.Value]
+	}
+
+	if d.kind == paramDecl {
+		// Creating a variable that already exists in the environment fails at runtime
+		c.report(stmt.Name.Token.Pos, CheckShadow, "%s redeclares the parameter declared at line %d",
+			stmt.Name.Value, d.ident.Token.Pos.Line)
+		return
+	}
+	if d.ident == stmt.Name {
+		c.checkShadow(stmt.Name, env.outer)
+	}
+}
+
+func (c *checker) forLoop(loop *ast.ForLoopStatement) {
+	if loop.Collection != nil {
+		c.expression(loop.Collection)
+
+		c.openScope(blockScope)
+		c.declare(loop.Key, otherDecl)
+		c.declare(loop.Value, otherDecl)
+		c.loopBody(loop.Body)
+		c.closeScope()
+		return
+	}
+
+	c.openScope(blockScope)
+	if loop.Init != nil {
+		c.hoist([]ast.Statement{loop.Init})
+		c.statement(loop.Init)
+	}
+	c.expression(loop.Condition)
+
+	c.openScope(blockScope)
+	c.loopBody(loop.Body)
+	c.closeScope()
+
+	c.expression(loop.Iter)
+	c.closeScope()
+}
+
+func (c *checker) loopBody(body *ast.BlockStatement) {
+	c.hoistBlock(body)
+	c.loops++
+	c.block(body)
+	c.loops--
+}
+
+func (c *checker) function(fn *ast.FunctionLiteral) {
+	c.openScope(functionScope)
+	for _, param := range fn.Parameters {
+		c.declare(param, paramDecl)
+	}
+	c.scope.decls["args"] = &decl{kind: otherDecl, used: true}
+
+	// Loops don't continue into functions declared in them
+	loops := c.loops
+	c.loops = 0
+	c.hoistBlock(fn.Body)
+	c.block(fn.Body)
+	c.loops = loops
+	c.closeScope()
+}
+
+func (c *checker) class(class *ast.ClassLiteral) {
+	if class.Parent != "" {
+		if d, _ := c.lookup(class.Parent); d != nil {
+			d.used = true
+		} else if !isGlobal(class.Parent) {
+			c.report(class.Token.Pos, CheckUndefined, "Undefined parent class %s", class.Parent)
+		}
+	}
+
+	// Fields and methods can be used by name in methods
+	s := c.openScope(classScope)
+	for _, field := range class.Fields {
+		s.decls[field.Name.Value] = &decl{ident: field.Name, kind: otherDecl, used: true}
+	}
+	for name := range class.Methods {
+		s.decls[name] = &decl{kind: otherDecl, used: true}
+	}
+
+	for _, field := range class.Fields {
+		c.statement(field)
+	}
+	for _, method := range class.Methods {
+		c.function(method)
+	}
+	c.closeScope()
+}
+
+func (c *checker) expressions(exps []ast.Expression) {
+	for _, exp := range exps {
+		c.expression(exp)
+	}
+}
+
+func (c *checker) expression(exp ast.Expression) {
+	switch exp := exp.(type) {
+	case *ast.Identifier:
+		c.use(exp, false)
+	case *ast.AssignStatement:
+		c.expression(exp.Value)
+		if ident, ok := exp.Left.(*ast.Identifier); ok {
+			c.assign(ident)
+		} else {
+			c.expression(exp.Left)
+		}
+	case *ast.PrefixExpression:
+		c.expression(exp.Right)
+	case *ast.InfixExpression:
+		c.expression(exp.Left)
+		c.expression(exp.Right)
+	case *ast.CompareExpression:
+		c.expression(exp.Left)
+		c.expression(exp.Right)
+	case *ast.IfExpression:
+		c.expression(exp.Condition)
+		c.block(exp.Consequence)
+		c.block(exp.Alternative)
+	case *ast.CallExpression:
+		c.call(exp)
+	case *ast.IndexExpression:
+		c.expression(exp.Left)
+		c.expression(exp.Index)
+	case *ast.SliceExpression:
+		c.expression(exp.Left)
+		c.expression(exp.Low)
+		c.expression(exp.High)
+	case *ast.TryCatchExpression:
+		c.tryCatch(exp)
+	case *ast.MakeInstance:
+		c.expression(exp.Class)
+		c.expressions(exp.Arguments)
+	case *ast.FunctionLiteral:
+		c.function(exp)
+	case *ast.ClassLiteral:
+		c.class(exp)
+	case *ast.Array:
+		c.expressions(exp.Elements)
+	case *ast.InterpolatedString:
+		c.expressions(exp.Parts)
+	case *ast.HashLiteral:
+		for _, pair := range exp.Pairs {
+			c.expression(pair.Key)
+			c.expression(pair.Value)
+		}
+	}
+}
+
+// assign checks an assignment to ident. Assigning a variable isn't a use of it.
+func (c *checker) assign(ident *ast.Identifier) {
+	d, _ := c.lookup(ident.Value)
+	if d != nil && d.kind == constantDecl {
+		c.report(ident.Token.Pos, CheckConstAssign, "Assignment to constant %s", ident.Value)
+	}
+}
+
+func (c *checker) call(call *ast.CallExpression) {
+	ident, ok := call.Function.(*ast.Identifier)
+	if !ok {
+		c.expression(call.Function)
+		c.expressions(call.Arguments)
+		return
+	}
+
+	if d, _ := c.lookup(ident.Value); d == nil {
+		if args, ok := builtinArgs[ident.Value]; ok && isGlobal(ident.Value) {
+			if msg := args.check(ident.Value, len(call.Arguments)); msg != "" {
+				c.report(ident.Token.Pos, CheckBuiltinArgs, "%s", msg)
+			}
+		}
+	}
+	c.use(ident, true)
+	c.expressions(call.Arguments)
+}
+
+func (c *checker) tryCatch(exp *ast.TryCatchExpression) {
+	c.block(exp.Try)
+	for _, clause := range exp.Catches {
+		c.expression(clause.Class)
+		c.openScope(catchScope)
+		c.declare(clause.Symbol, otherDecl)
+		c.block(clause.Body)
+		c.closeScope()
+	}
+	c.block(exp.Finally)
+}
@@ -0,0 +1,144 @@
+// Package vet finds likely mistakes in Nitrogen programs without running them.
+package vet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nitrogen-lang/nitrogen/src/ast"
+	"github.com/nitrogen-lang/nitrogen/src/eval"
+	"github.com/nitrogen-lang/nitrogen/src/lexer"
+	"github.com/nitrogen-lang/nitrogen/src/parser"
+	"github.com/nitrogen-lang/nitrogen/src/token"
+)
+
+// Names of the checks, they're used in diagnostics and suppression comments.
+const (
+	CheckUndefined   = "undefined"
+	CheckUndeclared  = "undeclared"
+	CheckConstAssign = "const-assign"
+	CheckUnreachable = "unreachable"
+	CheckLoopControl = "loop-control"
+	CheckUnused      = "unused"
+	CheckShadow      = "shadow"
+	CheckBuiltinArgs = "builtin-args"
+)
+
+// Diagnostic is a problem found in a program.
+type Diagnostic struct {
+	Filename string `json:"file"`
+	Line     int    `json:"line"`
+	Col      int    `json:"col"`
+	Check    string `json:"check"`
+	Message  string `json:"message"`
+}
+
+func (d *Diagnostic) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s (%s)", d.Filename, d.Line, d.Col, d.Message, d.Check)
+}
+
+// predefined are the variables the interpreter creates before running a script.
+var predefined = map[string]bool{
+	"_FILE": true,
+	"_ENV":  true,
+	"_ARGV": true,
+}
+
+func isGlobal(name string) bool {
+	return predefined[name] || eval.GetBuiltin(name) != nil
+}
+
+// Source parses src and returns the problems found in it sorted by position.
+// Problems on lines with a suppression comment are left out.
+func Source(filename string, src []byte) ([]*Diagnostic, error) {
+	p := parser.New(lexer.New(bytes.NewReader(src)), nil)
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		return nil, errors.New(strings.Join(p.Errors(), "\n"))
+	}
+	program.Filename = filename
+
+	ignored := suppressions(src)
+	var diags []*Diagnostic
+	for _, d := range Check(program) {
+		if !ignored.matches(d) {
+			diags = append(diags, d)
+		}
+	}
+	return diags, nil
+}
+
+// Check returns the problems found in program sorted by position.
+func Check(program *ast.Program) []*Diagnostic {
+	c := newChecker(program.Filename)
+	c.program(program)
+
+	sort.SliceStable(c.diags, func(i, j int) bool {
+		a, b := c.diags[i], c.diags[j]
+		return a.Line < b.Line || a.Line == b.Line && a.Col < b.Col
+	})
+	return c.diags
+}
+
+// ignoreDirective starts a comment that suppresses diagnostics. It can be
+// followed by the names of the checks to suppress, otherwise all are.
+const ignoreDirective = "vet:ignore"
+
+// suppressed maps line numbers to the checks suppressed on them. A nil
+// slice suppresses every check.
+type suppressed map[int][]string
+
+func (s suppressed) matches(d *Diagnostic) bool {
+	checks, ok := s[d.Line]
+	if !ok {
+		return false
+	}
+	if checks == nil {
+		return true
+	}
+	for _, check := range checks {
+		if check == d.Check {
+			return true
+		}
+	}
+	return false
+}
+
+// suppressions finds the suppression comments in src. A comment after code
+// applies to its own line, a comment on a line by itself applies to the next line.
+func suppressions(src []byte) suppressed {
+	l := lexer.New(bytes.NewReader(src))
+	codeLines := make(map[int]bool)
+	var comments []token.Token
+
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		if tok.Type == token.Comment {
+			comments = append(comments, tok)
+		} else {
+			codeLines[tok.Pos.Line] = true
+		}
+	}
+
+	s := make(suppressed)
+	for _, c := range comments {
+		fields := strings.Fields(strings.Replace(c.Literal, ",", " ", -1))
+		if len(fields) == 0 || fields[0] != ignoreDirective {
+			continue
+		}
+
+		var checks []string
+		if len(fields) > 1 {
+			checks = fields[1:]
+		}
+
+		line := c.Pos.Line
+		if !codeLines[line] {
+			line++
+		}
+		s[line] = checks
+	}
+	return s
+}
@@ -0,0 +1,97 @@
+package vet
+
+import (
+	"fmt"
+	"testing"
+
+	_ "github.com/nitrogen-lang/nitrogen/src/builtins/collections"
+	_ "github.com/nitrogen-lang/nitrogen/src/builtins/io"
+)
+
+func vetTest(input string, t *testing.T) []string {
+	diags, err := Source("test.ni", []byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := make([]string, len(diags))
+	for i, d := range diags {
+		found[i] = fmt.Sprintf("%d:%d %s: %s", d.Line, d.Col, d.Check, d.Message)
+	}
+	return found
+}
+
+func TestChecks(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"println(x)", []string{"1:9 undefined: Undefined variable x"}},
+		{"f(1)", []string{"1:1 undefined: Call of undefined function f"}},
+		{"class A ^ B {}", []string{"1:1 undefined: Undefined parent class B"}},
+		{"x = 1", []string{"1:1 undeclared: Assignment to uninitialized variable x"}},
+		{"println(x)\nlet x = 1", []string{"1:9 undeclared: Use of variable x before declaration"}},
+		{"always x = 1\nx = 2", []string{"2:1 const-assign: Assignment to constant x"}},
+		{"func f() {\n    return 1\n    println(2)\n}", []string{"3:5 unreachable: Unreachable code"}},
+		{"func f(a) {\n    if a { return 1 } else { throw 2 }\n    a\n}", []string{"3:5 unreachable: Unreachable code"}},
+		{"break", []string{"1:1 loop-control: break outside of a loop"}},
+		{"for x in [] { func() { continue } }", []string{"1:24 loop-control: continue outside of a loop"}},
+		{"func f() {\n    let x = 1\n}", []string{"2:9 unused: x is declared but never used"}},
+		{"func f(a) {\n    for a in [] { println(a) }\n}", []string{"2:9 shadow: a shadows the parameter declared at line 1"}},
+		{"func f(a) {\n    let a = 1\n}", []string{"2:9 shadow: a redeclares the parameter declared at line 1"}},
+		{"len(1, 2)", []string{"1:1 builtin-args: len expects 1 argument(s). Got 2"}},
+		{"range()", []string{"1:1 builtin-args: range expects 1 to 3 arguments. Got 0"}},
+		{"zip()", []string{"1:1 builtin-args: zip expects at least 1 argument(s). Got 0"}},
+
+		// Valid programs
+		{"let x = 1\nx = 2", nil},
+		{"func f() { g() }\nfunc g() { f() }", nil},
+		{"func f() {\n    let _x = 1\n    let y = 2\n    y = 3\n    return y\n}", nil},
+		{"for i = 0; i < 2; i += 1 { if i { break } }", nil},
+		{"class A { let n = 1\n func f() { println(n, this) } }", nil},
+		{"func len(a, b) { a }\nlen(1, 2)", nil},
+		{"try { throw 1 } catch e { println(e) }", nil},
+		{"println(_FILE, _ARGV, _ENV)", nil},
+	}
+
+	for _, tt := range tests {
+		found := vetTest(tt.input, t)
+		if len(found) != len(tt.expected) {
+			t.Errorf("wrong diagnostics for %q.\nexpected=%q\ngot=%q", tt.input, tt.expected, found)
+			continue
+		}
+		for i, d := range found {
+			if d != tt.expected[i] {
+				t.Errorf("wrong diagnostic for %q. expected=%q, got=%q", tt.input, tt.expected[i], d)
+			}
+		}
+	}
+}
+
+func TestSuppression(t *testing.T) {
+	input := `f() // vet:ignore
+g() // vet:ignore unused
+// vet:ignore undefined, unused
+h()
+i()`
+	expected := []string{
+		"2:1 undefined: Call of undefined function g",
+		"5:1 undefined: Call of undefined function i",
+	}
+
+	found := vetTest(input, t)
+	if len(found) != len(expected) {
+		t.Fatalf("wrong diagnostics.\nexpected=%q\ngot=%q", expected, found)
+	}
+	for i, d := range found {
+		if d != expected[i] {
+			t.Errorf("wrong diagnostic. expected=%q, got=%q", expected[i], d)
+		}
+	}
+}
+
+func TestSyntaxError(t *testing.T) {
+	if _, err := Source("test.ni", []byte("let x =")); err == nil {
+		t.Error("expected a syntax error")
+	}
+}
@@ -0,0 +1,85 @@
+package vm
+
+import (
+	"github.com/nitrogen-lang/nitrogen/src/compiler"
+	"github.com/nitrogen-lang/nitrogen/src/eval"
+	"github.com/nitrogen-lang/nitrogen/src/object"
+)
+
+// Closure is a compiled function and the variables it captured when created.
+type Closure struct {
+	Code *compiler.CodeBlock
+	Free []*Cell
+}
+
+// Cell holds a local variable shared between a function and the closures created inside it.
+// An empty cell has a nil Value.
+type Cell struct {
+	Value object.Object
+}
+
+type frame struct {
+	name string
+	cl   *Closure
+	ip   int
+	// base is the stack position the frame's return value is stored at
+	base   int
+	locals []object.Object
+	cells  []*Cell
+	// env is used for names not resolved at compile time
+	env      *object.Environment
+	instance *object.Instance
+	// result, if not nil, replaces the return value of the frame. Used by class initializers.
+	result object.Object
+}
+
+func newFrame(name string, cl *Closure, env *object.Environment, instance *object.Instance, base int) *frame {
+	f := &frame{
+		name:     name,
+		cl:       cl,
+		base:     base,
+		locals:   make([]object.Object, cl.Code.NumLocals()),
+		env:      env,
+		instance: instance,
+	}
+	if cl.Code.HasCells {
+		f.cells = make([]*Cell, cl.Code.NumLocals())
+	}
+	return f
+}
+
+// bindArguments stores function arguments in the parameter slots and any extras in "args".
+func (f *frame) bindArguments(args []object.Object) {
+	code := f.cl.Code
+	copy(f.locals, args[:code.NumParams])
+
+	if len(args) > code.NumParams {
+		f.locals[code.NumParams] = &object.Array{Elements: args[code.NumParams:]}
+	} else {
+		f.locals[code.NumParams] = object.NullConst
+	}
+
+	if f.cells != nil {
+		for i := 0; i <= code.NumParams; i++ {
+			if code.Captured[i] {
+				f.cells[i] = &Cell{Value: f.locals[i]}
+			}
+		}
+	}
+}
+
+func (f *frame) cell(slot int) *Cell {
+	if f.cells[slot] == nil {
+		f.cells[slot] = &Cell{}
+	}
+	return f.cells[slot]
+}
+
+// iterator is the state of a for-in loop kept on the stack.
+type iterator struct {
+	eval.Iterator
+}
+
+func (i *iterator) Inspect() string         { return "iterator" }
+func (i *iterator) Type() object.ObjectType { return object.ResourceObj }
+func (i *iterator) Dup() object.Object      { return i }
@@ -0,0 +1,467 @@
+package vm
+
+import (
+	"github.com/nitrogen-lang/nitrogen/src/compiler"
+	"github.com/nitrogen-lang/nitrogen/src/eval"
+	"github.com/nitrogen-lang/nitrogen/src/object"
+)
+
+const (
+	initialStackSize = 2048
+	maxFrames        = 10000
+)
+
+type handler struct {
+	frame   int
+	sp      int
+	catchIP int
+}
+
+// machine holds the execution state of a single call to VM.Eval.
+type machine struct {
+	vm       *VM
+	stack    []object.Object
+	sp       int
+	frames   []*frame
+	handlers []handler
+}
+
+func newMachine(vm *VM) *machine {
+	return &machine{
+		vm:    vm,
+		stack: make([]object.Object, initialStackSize),
+	}
+}
+
+func (m *machine) push(obj object.Object) {
+	if m.sp >= len(m.stack) {
+		m.stack = append(m.stack, make([]object.Object, len(m.stack))...)
+	}
+	m.stack[m.sp] = obj
+	m.sp++
+}
+
+func (m *machine) pop() object.Object {
+	m.sp--
+	obj := m.stack[m.sp]
+	m.stack[m.sp] = nil
+	return obj
+}
+
+func (m *machine) peek() object.Object {
+	return m.stack[m.sp-1]
+}
+
+// popN removes the top n objects of the stack and returns them in a new slice.
+func (m *machine) popN(n int) []object.Object {
+	objs := make([]object.Object, n)
+	copy(objs, m.stack[m.sp-n:m.sp])
+	for i := m.sp - n; i < m.sp; i++ {
+		m.stack[i] = nil
+	}
+	m.sp -= n
+	return objs
+}
+
+func (m *machine) pushFrame(name string, cl *Closure, env *object.Environment, instance *object.Instance, args []object.Object) (*frame, object.Object) {
+	if len(m.frames) >= maxFrames {
+		return nil, object.NewPanic("Stack overflow")
+	}
+
+	f := newFrame(name, cl, env, instance, m.sp)
+	if args != nil {
+		f.bindArguments(args)
+	}
+	m.frames = append(m.frames, f)
+	return f, nil
+}
+
+// raise unwinds the stack to the nearest exception handler. If the exception
+// can't be handled, it's returned as the result of the machine.
+func (m *machine) raise(exc object.Object) object.Object {
+	e := exc.(*object.Exception)
+	if e.Trace == nil {
+		e.Trace = m.vm.stackTrace()
+	}
+	if !e.Catchable || len(m.handlers) == 0 {
+		return e
+	}
+
+	h := m.handlers[len(m.handlers)-1]
+	m.handlers = m.handlers[:len(m.handlers)-1]
+
+	for i := h.frame + 1; i < len(m.frames); i++ {
+		m.frames[i] = nil
+	}
+	m.frames = m.frames[:h.frame+1]
+	for i := h.sp; i < m.sp; i++ {
+		m.stack[i] = nil
+	}
+	m.sp = h.sp
+	m.frames[h.frame].ip = h.catchIP
+
+	e.Caught = true
+	m.push(e)
+	return nil
+}
+
+func isRaised(obj object.Object) bool {
+	return eval.IsException(obj) || eval.IsPanic(obj)
+}
+
+func (m *machine) run() object.Object {
+	f := m.frames[len(m.frames)-1]
+
+	for {
+		code := f.cl.Code
+		ins := code.Instructions
+		op := compiler.Opcode(ins[f.ip])
+		f.ip++
+
+		var err object.Object
+
+		switch op {
+		case compiler.OpConstant:
+			idx := int(compiler.ReadUint32(ins[f.ip:]))
+			f.ip += 4
+			m.push(code.Constants[idx])
+		case compiler.OpNull:
+			m.push(object.NullConst)
+		case compiler.OpTrue:
+			m.push(object.TrueConst)
+		case compiler.OpFalse:
+			m.push(object.FalseConst)
+		case compiler.OpPop:
+			m.pop()
+
+		case compiler.OpAdd, compiler.OpSub, compiler.OpMul, compiler.OpDiv, compiler.OpMod,
+			compiler.OpShiftLeft, compiler.OpShiftRight, compiler.OpBitAnd, compiler.OpBitOr,
+			compiler.OpBitXor, compiler.OpBitAndNot, compiler.OpEqual, compiler.OpNotEqual,
+			compiler.OpLessThan, compiler.OpGreaterThan, compiler.OpLessThanEq, compiler.OpGreaterThanEq,
+			compiler.OpIn:
+			left := m.pop()
+			right := m.pop()
+			result := binaryOperation(op, left, right)
+			if isRaised(result) {
+				err = result
+				break
+			}
+			m.push(result)
+
+		case compiler.OpMinus, compiler.OpBang:
+			operator := "-"
+			if op == compiler.OpBang {
+				operator = "!"
+			}
+			result := eval.PrefixOperation(operator, m.pop())
+			if isRaised(result) {
+				err = result
+				break
+			}
+			m.push(result)
+
+		case compiler.OpTruthy:
+			side := ins[f.ip]
+			f.ip++
+			b, valid := eval.ConvertToBoolean(m.pop())
+			if !valid {
+				if side == 0 {
+					err = object.NewTypeError("Left side of conditional must be truthy or falsey")
+				} else {
+					err = object.NewTypeError("Right side of condition must be truthy or falsey")
+				}
+				break
+			}
+			m.push(object.NativeBoolToBooleanObj(b))
+		case compiler.OpJump:
+			f.ip = int(compiler.ReadUint32(ins[f.ip:]))
+		case compiler.OpIter:
+			var iter eval.Iterator
+			iter, err = eval.NewIterator(m.pop(), func(fn object.Object, args []object.Object) object.Object {
+				return m.vm.callFunction(fn, args, f.env)
+			})
+			if err == nil {
+				m.push(&iterator{iter})
+			}
+		case compiler.OpIterNext:
+			target := int(compiler.ReadUint32(ins[f.ip:]))
+			f.ip += 4
+			key, value, ok, iterErr := m.peek().(*iterator).Next()
+			if iterErr != nil {
+				err = iterErr
+				break
+			}
+			if !ok {
+				f.ip = target
+				break
+			}
+			m.push(key)
+			m.push(value)
+		case compiler.OpJumpNotTruthy:
+			target := int(compiler.ReadUint32(ins[f.ip:]))
+			f.ip += 4
+			if !eval.IsTruthy(m.pop()) {
+				f.ip = target
+			}
+		case compiler.OpJumpTrueOrPop, compiler.OpJumpFalseOrPop:
+			target := int(compiler.ReadUint32(ins[f.ip:]))
+			f.ip += 4
+			if (m.peek() == object.TrueConst) == (op == compiler.OpJumpTrueOrPop) {
+				f.ip = target
+			} else {
+				m.pop()
+			}
+
+		case compiler.OpGetName, compiler.OpGetFunc:
+			name := code.Names[compiler.ReadUint32(ins[f.ip:])]
+			f.ip += 4
+			if val, ok := f.env.Get(name); ok {
+				m.push(val)
+			} else if builtin := eval.GetBuiltin(name); builtin != nil {
+				m.push(builtin)
+			} else if op == compiler.OpGetFunc {
+				err = object.NewNameError("function not found: %s", name)
+			} else {
+				err = object.NewNameError("identifier not found: %s", name)
+			}
+		case compiler.OpSetName:
+			name := code.Names[compiler.ReadUint32(ins[f.ip:])]
+			f.ip += 4
+			err = setName(f.env, name, m.pop())
+		case compiler.OpDefineName:
+			name := code.Names[compiler.ReadUint32(ins[f.ip:])]
+			f.ip += 4
+			err = defineName(f.env, name, m.pop())
+		case compiler.OpDefineConstName:
+			name := code.Names[compiler.ReadUint32(ins[f.ip:])]
+			f.ip += 4
+			err = defineConstName(f.env, name, m.pop())
+		case compiler.OpCheckName:
+			name := code.Names[compiler.ReadUint32(ins[f.ip:])]
+			constant := ins[f.ip+4] == 1
+			f.ip += 5
+			err = checkDeclaration(f.env, name, constant)
+		case compiler.OpCheckConst:
+			err = checkConstValue(m.peek())
+
+		case compiler.OpGetLocal:
+			slot := int(compiler.ReadUint32(ins[f.ip:]))
+			f.ip += 4
+			val := f.locals[slot]
+			if val == nil {
+				err = object.NewNameError("identifier not found: %s", code.Locals[slot])
+				break
+			}
+			m.push(val)
+		case compiler.OpSetLocal, compiler.OpDefineLocal:
+			f.locals[compiler.ReadUint32(ins[f.ip:])] = m.pop()
+			f.ip += 4
+		case compiler.OpClearLocal:
+			f.locals[compiler.ReadUint32(ins[f.ip:])] = nil
+			f.ip += 4
+		case compiler.OpGetCell:
+			slot := int(compiler.ReadUint32(ins[f.ip:]))
+			f.ip += 4
+			val := f.cell(slot).Value
+			if val == nil {
+				err = object.NewNameError("identifier not found: %s", code.Locals[slot])
+				break
+			}
+			m.push(val)
+		case compiler.OpSetCell:
+			f.cell(int(compiler.ReadUint32(ins[f.ip:]))).Value = m.pop()
+			f.ip += 4
+		case compiler.OpDefineCell:
+			// Cells are filled if they were cleared for a recursive function, otherwise a
+			// new cell is made so closures from previous loop iterations keep their value.
+			slot := int(compiler.ReadUint32(ins[f.ip:]))
+			f.ip += 4
+			if c := f.cells[slot]; c != nil && c.Value == nil {
+				c.Value = m.pop()
+			} else {
+				f.cells[slot] = &Cell{Value: m.pop()}
+			}
+		case compiler.OpClearCell:
+			f.cells[compiler.ReadUint32(ins[f.ip:])] = &Cell{}
+			f.ip += 4
+		case compiler.OpGetFree:
+			idx := int(compiler.ReadUint32(ins[f.ip:]))
+			f.ip += 4
+			val := f.cl.Free[idx].Value
+			if val == nil {
+				err = object.NewNameError("identifier not found: %s", code.Free[idx].Name)
+				break
+			}
+			m.push(val)
+		case compiler.OpSetFree:
+			f.cl.Free[compiler.ReadUint32(ins[f.ip:])].Value = m.pop()
+			f.ip += 4
+
+		case compiler.OpArray:
+			n := int(compiler.ReadUint32(ins[f.ip:]))
+			f.ip += 4
+			m.push(&object.Array{Elements: m.popN(n)})
+		case compiler.OpInterpolate:
+			n := int(compiler.ReadUint32(ins[f.ip:]))
+			f.ip += 4
+			m.push(eval.Interpolate(m.popN(n)))
+		case compiler.OpHash:
+			n := int(compiler.ReadUint32(ins[f.ip:]))
+			f.ip += 4
+			var hash object.Object
+			hash, err = buildHash(m.popN(n * 2))
+			if err == nil {
+				m.push(hash)
+			}
+		case compiler.OpIndex:
+			index := m.pop()
+			left := m.pop()
+			result := eval.IndexOperation(left, index, f.instance)
+			if isRaised(result) {
+				err = result
+				break
+			}
+			m.push(result)
+		case compiler.OpSlice:
+			high := m.pop()
+			low := m.pop()
+			left := m.pop()
+			result := eval.SliceOperation(left, low, high)
+			if isRaised(result) {
+				err = result
+				break
+			}
+			m.push(result)
+		case compiler.OpSetIndex:
+			value := m.pop()
+			index := m.pop()
+			indexed := m.pop()
+			if result := eval.AssignIndex(indexed, index, value); isRaised(result) {
+				err = result
+			}
+
+		case compiler.OpClosure:
+			fnCode := code.Functions[compiler.ReadUint32(ins[f.ip:])]
+			f.ip += 4
+			m.push(f.makeClosure(fnCode))
+		case compiler.OpCall:
+			argc := int(compiler.ReadUint16(ins[f.ip:]))
+			f.ip += 2
+			args := m.popN(argc)
+			fn := m.pop()
+			var result object.Object
+			result, err = m.call(fn, args, f)
+			if err == nil && result != nil {
+				m.push(result)
+			}
+			f = m.frames[len(m.frames)-1]
+		case compiler.OpReturn:
+			val := m.pop()
+			if f.result != nil {
+				val = f.result
+			}
+
+			frameIdx := len(m.frames) - 1
+			for len(m.handlers) > 0 && m.handlers[len(m.handlers)-1].frame >= frameIdx {
+				m.handlers = m.handlers[:len(m.handlers)-1]
+			}
+			m.frames[frameIdx] = nil
+			m.frames = m.frames[:frameIdx]
+
+			if frameIdx == 0 {
+				return val
+			}
+			for i := f.base; i < m.sp; i++ {
+				m.stack[i] = nil
+			}
+			m.sp = f.base
+			m.push(val)
+			f = m.frames[frameIdx-1]
+		case compiler.OpClass:
+			lit := code.Classes[compiler.ReadUint32(ins[f.ip:])]
+			parentOnStack := ins[f.ip+4] == 1
+			f.ip += 5
+			var class object.Object
+			class, err = m.makeClass(lit, parentOnStack, f.env)
+			if err == nil {
+				m.push(class)
+			}
+		case compiler.OpMake:
+			name := code.Names[compiler.ReadUint32(ins[f.ip:])]
+			argc := int(compiler.ReadUint16(ins[f.ip+4:]))
+			f.ip += 6
+			args := m.popN(argc)
+			class := m.pop()
+			var result object.Object
+			result, err = m.makeInstance(name, class, args, f)
+			if err == nil && result != nil {
+				m.push(result)
+			}
+			f = m.frames[len(m.frames)-1]
+
+		case compiler.OpSetupTry:
+			m.handlers = append(m.handlers, handler{
+				frame:   len(m.frames) - 1,
+				sp:      m.sp,
+				catchIP: int(compiler.ReadUint32(ins[f.ip:])),
+			})
+			f.ip += 4
+		case compiler.OpPopTry:
+			m.handlers = m.handlers[:len(m.handlers)-1]
+		case compiler.OpThrow:
+			val := m.pop()
+			if exc, ok := val.(*object.Exception); ok {
+				exc.Caught = false // Reset since exception is being rethrown.
+				err = exc
+			} else {
+				err = object.NewThrownException(val)
+			}
+		case compiler.OpError:
+			exc := code.Constants[compiler.ReadUint32(ins[f.ip:])].(*object.Exception)
+			f.ip += 4
+			err = object.NewExceptionOf(exc.Class, "%s", exc.Message)
+
+		case compiler.OpMatchException:
+			target := int(compiler.ReadUint32(ins[f.ip:]))
+			f.ip += 4
+			val := m.pop()
+			class, ok := val.(*object.Class)
+			if !ok {
+				err = object.NewTypeError("Catch type must be a class, got %s", val.Type())
+				break
+			}
+			if !m.peek().(*object.Exception).Is(class) {
+				f.ip = target
+			}
+
+		default:
+			return object.NewPanic("Unknown opcode %d", op)
+		}
+
+		if err != nil {
+			if uncaught := m.raise(err); uncaught != nil {
+				return uncaught
+			}
+			f = m.frames[len(m.frames)-1]
+		}
+	}
+}
+
+func (f *frame) makeClosure(code *compiler.CodeBlock) *object.Function {
+	free := make([]*Cell, len(code.Free))
+	for i, v := range code.Free {
+		if v.Local {
+			free[i] = f.cell(v.Index)
+		} else {
+			free[i] = f.cl.Free[v.Index]
+		}
+	}
+
+	return &object.Function{
+		Name:       code.Literal.Name,
+		Parameters: code.Literal.Parameters,
+		Body:       code.Literal.Body,
+		Env:        f.env,
+		Compiled:   &Closure{Code: code, Free: free},
+	}
+}
@@ -0,0 +1,281 @@
+package vm
+
+import (
+	"github.com/nitrogen-lang/nitrogen/src/ast"
+	"github.com/nitrogen-lang/nitrogen/src/compiler"
+	"github.com/nitrogen-lang/nitrogen/src/eval"
+	"github.com/nitrogen-lang/nitrogen/src/object"
+)
+
+func binaryOperation(op compiler.Opcode, left, right object.Object) object.Object {
+	// Fast path for the most common integer operations
+	if l, ok := left.(*object.Integer); ok {
+		if r, ok := right.(*object.Integer); ok {
+			switch op {
+			case compiler.OpAdd:
+				// Overflowing sums are promoted to a BIGINT by the slow path
+				if sum := l.Value + r.Value; (sum > l.Value) == (r.Value > 0) {
+					return &object.Integer{Value: sum}
+				}
+			case compiler.OpSub:
+				if diff := l.Value - r.Value; (diff < l.Value) == (r.Value > 0) {
+					return &object.Integer{Value: diff}
+				}
+			case compiler.OpLessThan:
+				return object.NativeBoolToBooleanObj(l.Value < r.Value)
+			case compiler.OpGreaterThan:
+				return object.NativeBoolToBooleanObj(l.Value > r.Value)
+			case compiler.OpEqual:
+				return object.NativeBoolToBooleanObj(l.Value == r.Value)
+			case compiler.OpNotEqual:
+				return object.NativeBoolToBooleanObj(l.Value != r.Value)
+			}
+		}
+	}
+
+	return eval.InfixOperation(compiler.InfixOperator(op), left, right)
+}
+
+func setName(env *object.Environment, name string, val object.Object) object.Object {
+	if eval.GetBuiltin(name) != nil {
+		return object.NewNameError("Attempted redeclaration of builtin function '%s'", name)
+	}
+
+	if _, exists := env.Get(name); !exists {
+		return object.NewNameError("Assignment to uninitialized variable %s", name)
+	}
+
+	if env.IsConst(name) {
+		return object.NewConstantError("Assignment to declared constant %s", name)
+	}
+
+	env.Set(name, val)
+	return nil
+}
+
+func defineName(env *object.Environment, name string, val object.Object) object.Object {
+	if err := checkDeclaration(env, name, false); err != nil {
+		return err
+	}
+
+	// Redeclaring a variable in the same environment keeps its value
+	env.Create(name, val)
+	return nil
+}
+
+func defineConstName(env *object.Environment, name string, val object.Object) object.Object {
+	if err := checkDeclaration(env, name, true); err != nil {
+		return err
+	}
+
+	if err := checkConstValue(val); err != nil {
+		return err
+	}
+
+	env.CreateConst(name, val)
+	return nil
+}
+
+// checkDeclaration checks if name can be declared in env.
+func checkDeclaration(env *object.Environment, name string, constant bool) object.Object {
+	if eval.GetBuiltin(name) != nil {
+		return object.NewNameError("Attempted redeclaration of builtin function '%s'", name)
+	}
+
+	if constant {
+		if _, exists := env.Get(name); exists { // Constants can't redeclare an existing var
+			return object.NewConstantError("Can't assign constant to variable `%s`", name)
+		}
+	} else if env.IsConst(name) {
+		return object.NewConstantError("Assignment to declared constant %s", name)
+	}
+	return nil
+}
+
+func checkConstValue(val object.Object) object.Object {
+	if !object.ObjectIs(val, object.IntergerObj, object.FloatObj, object.StringObj, object.NullObj, object.BooleanObj, object.ModuleObj) {
+		return object.NewTypeError("Constants must be int, float, string, bool or null")
+	}
+	return nil
+}
+
+func buildHash(elements []object.Object) (object.Object, object.Object) {
+	hash := object.NewHash(len(elements) / 2)
+
+	for i := 0; i < len(elements); i += 2 {
+		key := elements[i]
+		hashKey, ok := key.(object.Hashable)
+		if !ok {
+			return nil, object.NewTypeError("Invalid map key: %s", key.Type())
+		}
+		hash.Set(hashKey, elements[i+1])
+	}
+
+	return hash, nil
+}
+
+// call calls fn with args. If fn is a compiled function, a new frame is pushed and
+// the result is nil, the return value will be pushed when the frame returns.
+func (m *machine) call(fn object.Object, args []object.Object, caller *frame) (object.Object, object.Object) {
+	switch fn := fn.(type) {
+	case *object.Function:
+		if len(args) < len(fn.Parameters) {
+			return nil, object.NewArgumentError("Not enough parameters to call function %s", fn.Name)
+		}
+		cl, err := m.vm.closureOf(fn)
+		if err != nil {
+			return nil, err
+		}
+		_, err = m.pushFrame(eval.FunctionName(fn), cl, fn.Env, fn.Instance, args)
+		return nil, err
+
+	case *object.Builtin:
+		return checkResult(fn.Fn(m.vm, caller.env, args...))
+
+	case *object.BuiltinMethod:
+		return checkResult(fn.Fn(m.vm, fn.Instance, caller.env, args...))
+
+	case *object.Class: // Class init function
+		init := fn.GetMethod("init")
+		if init == nil {
+			return object.NullConst, nil
+		}
+
+		if initBuiltin, ok := init.(*object.BuiltinMethod); ok {
+			return checkResult(initBuiltin.Fn(m.vm, caller.instance, caller.env, args...))
+		}
+
+		initFn := init.(*object.Function)
+		if len(args) < len(initFn.Parameters) {
+			return nil, object.NewArgumentError("Not enough parameters to call class initializer %s", fn.Name)
+		}
+		cl, err := m.vm.closureOf(initFn)
+		if err != nil {
+			return nil, err
+		}
+
+		// Parent initializers run in the scope of the caller so they can set its fields
+		env := object.NewEnclosedEnv(caller.env)
+		if fn.Parent != nil {
+			env.CreateConst("parent", fn.Parent)
+		}
+		_, err = m.pushFrame(eval.FunctionName(initFn), cl, env, caller.instance, args)
+		return nil, err
+	}
+
+	return nil, object.NewTypeError("%s is not a function", fn.Type())
+}
+
+func checkResult(result object.Object) (object.Object, object.Object) {
+	if result == nil {
+		return object.NullConst, nil
+	}
+	if isRaised(result) {
+		return nil, result
+	}
+	return result, nil
+}
+
+func (m *machine) makeClass(lit *ast.ClassLiteral, parentOnStack bool, env *object.Environment) (object.Object, object.Object) {
+	var parent object.Object
+	if parentOnStack {
+		parent = m.pop()
+	} else if lit.Parent != "" {
+		var ok bool
+		parent, ok = env.Get(lit.Parent)
+		if !ok {
+			parent = eval.GetBuiltin(lit.Parent)
+		}
+		if parent == nil {
+			m.popN(len(lit.Methods))
+			return nil, object.NewNameError("Parent class %s not declared", lit.Parent)
+		}
+	}
+
+	names := compiler.MethodNames(lit)
+	methods := m.popN(len(names))
+
+	var parentClass *object.Class
+	if parent != nil {
+		var ok bool
+		parentClass, ok = parent.(*object.Class)
+		if !ok {
+			return nil, object.NewTypeError("Ident %s is not a class", lit.Parent)
+		}
+	}
+
+	c := &object.Class{
+		Name:    lit.Name,
+		Parent:  parentClass,
+		Fields:  lit.Fields,
+		Methods: make(map[string]object.ClassMethod, len(names)),
+	}
+	for i, name := range names {
+		c.Methods[name] = methods[i].(object.ClassMethod)
+	}
+	return c, nil
+}
+
+func (m *machine) makeInstance(name string, class object.Object, args []object.Object, caller *frame) (object.Object, object.Object) {
+	if class == object.NullConst {
+		return nil, object.NewNameError("Class %s not defined", name)
+	}
+
+	classObj, ok := class.(*object.Class)
+	if !ok {
+		return nil, object.NewTypeError("%s is not a class", name)
+	}
+
+	classChain := make([]*object.Class, 0, 3)
+	for c := classObj; c != nil; c = c.Parent {
+		classChain = append(classChain, c)
+	}
+
+	iFields := object.NewEnvironment()
+	iFields.SetParent(caller.env)
+
+	for c := len(classChain) - 1; c >= 0; c-- {
+		for _, def := range classChain[c].Fields {
+			m.vm.Eval(def, iFields)
+		}
+		iFields = object.NewEnclosedEnv(iFields)
+	}
+
+	instance := &object.Instance{
+		Class:  classObj,
+		Fields: iFields.Parent(),
+	}
+
+	init := classObj.GetMethod("init")
+	if init == nil {
+		return instance, nil
+	}
+
+	initEnv := object.NewEnclosedEnv(instance.Fields)
+	initEnv.CreateConst("this", instance)
+	if len(classChain) > 1 {
+		initEnv.CreateConst("parent", classChain[1])
+	}
+
+	switch init := init.(type) {
+	case *object.BuiltinMethod:
+		if _, err := checkResult(init.Fn(m.vm, caller.instance, initEnv, args...)); err != nil {
+			return nil, err
+		}
+		return instance, nil
+	case *object.Function:
+		if len(args) < len(init.Parameters) {
+			return nil, object.NewArgumentError("Not enough parameters to call function %s", init.Name)
+		}
+		cl, err := m.vm.closureOf(init)
+		if err != nil {
+			return nil, err
+		}
+		f, err := m.pushFrame(classObj.Name+"."+init.Name, cl, initEnv, caller.instance, args)
+		if err != nil {
+			return nil, err
+		}
+		f.result = instance
+	}
+	return nil, nil
+}
@@ -0,0 +1,166 @@
+package vm
+
+import (
+	"io"
+	"os"
+
+	"github.com/nitrogen-lang/nitrogen/src/ast"
+	"github.com/nitrogen-lang/nitrogen/src/compiler"
+	"github.com/nitrogen-lang/nitrogen/src/eval"
+	"github.com/nitrogen-lang/nitrogen/src/object"
+	"github.com/nitrogen-lang/nitrogen/src/resolver"
+)
+
+// VM executes Nitrogen code by compiling it to bytecode. It implements object.Interpreter
+// and can be used anywhere the tree walking interpreter is used.
+type VM struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	scriptNameStack []string
+	machines        []*machine
+	units           map[ast.Node]*compiler.CodeBlock
+	detached        map[*ast.BlockStatement]*compiler.CodeBlock
+}
+
+// New creates a VM using the process's standard streams.
+func New() *VM {
+	return &VM{
+		Stdin:    os.Stdin,
+		Stdout:   os.Stdout,
+		Stderr:   os.Stderr,
+		units:    make(map[ast.Node]*compiler.CodeBlock),
+		detached: make(map[*ast.BlockStatement]*compiler.CodeBlock),
+	}
+}
+
+// Eval compiles and executes node in env. Top level declarations are stored in env.
+func (vm *VM) Eval(node ast.Node, env *object.Environment) object.Object {
+	code, ok := vm.units[node]
+	if !ok {
+		var err error
+		code, err = compiler.Compile(node)
+		if err != nil {
+			return object.NewException("%s", err)
+		}
+		vm.units[node] = code
+	}
+
+	if program, ok := node.(*ast.Program); ok {
+		vm.scriptNameStack = append(vm.scriptNameStack, program.Filename)
+		defer func() { vm.scriptNameStack = vm.scriptNameStack[:len(vm.scriptNameStack)-1] }()
+		env.CreateConst("_FILE", &object.String{Value: program.Filename})
+
+		if exc := vm.resolve(program, env); exc != nil {
+			return exc
+		}
+	}
+
+	m := newMachine(vm)
+	m.pushFrame("<main>", &Closure{Code: code}, env, nil, nil)
+
+	vm.machines = append(vm.machines, m)
+	defer func() { vm.machines = vm.machines[:len(vm.machines)-1] }()
+	return m.run()
+}
+
+// resolve statically checks the variable declarations of program.
+func (vm *VM) resolve(program *ast.Program, env *object.Environment) *object.Exception {
+	res := resolver.Resolve(program, func(name string) bool {
+		_, ok := env.Get(name)
+		return ok || eval.GetBuiltin(name) != nil
+	})
+	if len(res.Errors) == 0 {
+		return nil
+	}
+
+	err := res.Errors[0]
+	exc := object.NewNameError("%s", err.Message)
+	exc.Trace = append([]object.TraceFrame{object.NewTraceFrame("<main>", err.Token)}, vm.stackTrace()...)
+	return exc
+}
+
+// GetCurrentScriptPath returns the filepath of the current executing script
+func (vm *VM) GetCurrentScriptPath() string {
+	if len(vm.scriptNameStack) == 0 {
+		return ""
+	}
+	return vm.scriptNameStack[len(vm.scriptNameStack)-1]
+}
+
+func (vm *VM) GetStdout() io.Writer {
+	return vm.Stdout
+}
+func (vm *VM) GetStderr() io.Writer {
+	return vm.Stderr
+}
+func (vm *VM) GetStdin() io.Reader {
+	return vm.Stdin
+}
+
+// stackTrace builds the trace of an exception raised by the current instruction.
+func (vm *VM) stackTrace() []object.TraceFrame {
+	var trace []object.TraceFrame
+	for i := len(vm.machines) - 1; i >= 0; i-- {
+		frames := vm.machines[i].frames
+		for j := len(frames) - 1; j >= 0; j-- {
+			f := frames[j]
+			pos, _ := f.cl.Code.PositionOf(f.ip - 1)
+			trace = append(trace, object.TraceFrame{
+				Function: f.name,
+				Filename: pos.Filename,
+				Pos:      pos.Pos,
+			})
+		}
+	}
+	return trace
+}
+
+// Call calls fn with args and returns its result.
+func (vm *VM) Call(fn object.Object, args []object.Object) object.Object {
+	if _, ok := fn.(*object.Class); ok {
+		return object.NewTypeError("%s is not a function", fn.Type())
+	}
+	return vm.callFunction(fn, args, object.NewEnvironment())
+}
+
+// callFunction calls fn with args and returns its result. env is the environment
+// of the caller.
+func (vm *VM) callFunction(fn object.Object, args []object.Object, env *object.Environment) object.Object {
+	if args == nil {
+		args = []object.Object{}
+	}
+
+	m := newMachine(vm)
+	vm.machines = append(vm.machines, m)
+	defer func() { vm.machines = vm.machines[:len(vm.machines)-1] }()
+
+	result, err := m.call(fn, args, &frame{env: env})
+	if err != nil {
+		return err
+	}
+	if result != nil {
+		return result
+	}
+	return m.run()
+}
+
+// closureOf returns the compiled code of fn. Functions that weren't created
+// by the VM are compiled the first time they're called.
+func (vm *VM) closureOf(fn *object.Function) (*Closure, object.Object) {
+	if cl, ok := fn.Compiled.(*Closure); ok {
+		return cl, nil
+	}
+
+	code, ok := vm.detached[fn.Body]
+	if !ok {
+		var err error
+		code, err = compiler.CompileFunction(fn.Name, fn.Parameters, fn.Body)
+		if err != nil {
+			return nil, object.NewException("%s", err)
+		}
+		vm.detached[fn.Body] = code
+	}
+	return &Closure{Code: code}, nil
+}